@@ -4,15 +4,30 @@
 // the configuration of one or more nodes on the cluster at some point in time, with the fields of
 // `NodeConfigRecord` below.
 //
-// Given a timestamp and a host, the config record can be found for the host closest to the
-// timestamp.  Alternatively, the latest config record for a host can be found.
-//
-// At this time, the time dimension has not been implemented.
+// Given a timestamp and a host, the config record can be found for the host that was valid at the
+// timestamp.  Alternatively, the latest config record for a host can be found.  Only the v3 format
+// has a time dimension; in older formats each node has a single record that is valid at all times.
 //
 // See ../../../production/sonar-nodes/$CLUSTER/$CLUSTER-config.json for examples of config files.
 
 // File formats.
 //
+// v3 file format:
+//
+// As v2 (below), plus these fields:
+//
+//   version - integer, must be 3
+//
+// and in the node records these fields:
+//
+//   from - string, optional, an RFC3339 timestamp for the start of the period in which the record
+//      is valid (inclusive); if missing the period has no start
+//   to - string, optional, an RFC3339 timestamp for the end of the period in which the record is
+//      valid (exclusive); if missing the record is still valid
+//
+// A node may have several records, eg when it gets more memory or new GPUs, but their periods must
+// not overlap.  The periods may have gaps, and in a gap the node is not defined.
+//
 // v2 file format:
 //
 // An object { ... } with the following named fields and value types:
@@ -22,10 +37,20 @@
 //   aliases - array of strings, optional, aliases / short names for the cluster
 //   exclude-user - array of strings, optional, user names whose records should
 //      be excluded when filtering records
+//   time-zone - string, optional, an IANA time zone name (eg "Europe/Oslo") for the
+//      cluster's local time, used by default for input and output of times
 //   nodes - array of objects, the list of nodes in the v1 format (see below)
+//   groups - object, optional, maps node group names to host lists, see below
+//   groups-file - string, optional, name of a JSON file holding an object like `groups`, relative
+//      to the directory of the config file
 //
 // Any field name starting with '#' is reserved for arbitrary comments.
 //
+// A node group names a set of nodes so that it can be used as `@name` in host lists, eg
+// "groups": {"a100": "gpu-[1-4,9]", "bigmem": "c1-[1-30],!c1-17"}.  The host list is a
+// multi-pattern in the sense of go-utils/hostglob, without wildcards and without groups.  A group
+// must not be defined both in `groups` and in the groups file.
+//
 // The `exclude-user` option is a hack and is used to add post-hoc filtering of data (when Sonar
 // should have filtered it to begin with, but didn't).  It is on purpose very limited, in contrast
 // with e.g. a mechanism to add arbitrary arguments to the command line.  Additional filters, eg
@@ -55,11 +80,16 @@ package config
 
 import (
 	"bytes"
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
-	"sort"
+	"path"
+	"slices"
+	"strings"
+	"time"
 
 	"go-utils/hostglob"
 	umaps "go-utils/maps"
@@ -100,17 +130,26 @@ type NodeConfigRecord struct {
 	// measurement
 	GpuMemPct bool `json:"gpumem_pct,omitempty"`
 
+	// The period in which the record is valid, RFC3339 timestamps (v3 only)
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
 	// Carries additional information used by code generators.  This field is not intended to appear
 	// in "production" configuration files, only in background files.
 	Metadata []NodeMeta `json:"metadata,omitempty"`
 }
 
+// Also the v3 representation.
 type ClusterConfigV2Repr struct {
+	Version     int                 `json:"version,omitempty"`
 	Name        string              `json:"name"`
 	Description string              `json:"description"`
 	Aliases     []string            `json:"aliases,omitempty"`
 	ExcludeUser []string            `json:"exclude-user,omitempty"`
+	TimeZone    string              `json:"time-zone,omitempty"`
 	Nodes       []*NodeConfigRecord `json:"nodes"`
+	Groups      map[string]string   `json:"groups,omitempty"`
+	GroupsFile  string              `json:"groups-file,omitempty"`
 }
 
 // Immutable
@@ -120,19 +159,33 @@ type ClusterConfig struct {
 	Description string
 	Aliases     []string
 	ExcludeUser []string
-	// Currently only one dimension of data
-	nodes map[string]*NodeConfigRecord
+	TimeZone    string // IANA name, "" for none
+	// Node groups from the config file, not including those from the groups file
+	Groups     map[string]string
+	GroupsFile string
+	// All node groups, name -> expanded host names
+	groups map[string][]string
+	// Host name -> the host's records, sorted by ascending time and not overlapping
+	nodes map[string][]timedRecord
+}
+
+// A record is valid in [from, to).
+type timedRecord struct {
+	from, to int64
+	record   *NodeConfigRecord
 }
 
+// For v3 the From and To fields of the nodes must be good and periods must not overlap, for older
+// versions there must be only one record per host.  An error is returned if they are not.
 func NewClusterConfig(
 	version int,
 	name, desc string,
 	aliases, excludeUsers []string,
 	nodes []*NodeConfigRecord,
-) *ClusterConfig {
-	nodemap := make(map[string]*NodeConfigRecord, len(nodes))
-	for _, v := range nodes {
-		nodemap[v.Hostname] = v
+) (*ClusterConfig, error) {
+	nodemap, err := makeTimelines(version, nodes)
+	if err != nil {
+		return nil, err
 	}
 	return &ClusterConfig{
 		Version:     version,
@@ -141,36 +194,135 @@ func NewClusterConfig(
 		Aliases:     append([]string{}, aliases...),
 		ExcludeUser: append([]string{}, excludeUsers...),
 		nodes:       nodemap,
+	}, nil
+}
+
+// The records must have been expanded (no host name patterns).
+
+func makeTimelines(version int, records []*NodeConfigRecord) (map[string][]timedRecord, error) {
+	nodes := make(map[string][]timedRecord)
+	for _, r := range records {
+		tr := timedRecord{from: math.MinInt64, to: math.MaxInt64, record: r}
+		if version < 3 {
+			if r.From != "" || r.To != "" {
+				return nil, fmt.Errorf("'from' and 'to' require version 3, for %s", r.Hostname)
+			}
+			if _, found := nodes[r.Hostname]; found {
+				return nil, fmt.Errorf("Duplicate host name in config: %s", r.Hostname)
+			}
+		}
+		if r.From != "" {
+			t, err := time.Parse(time.RFC3339, r.From)
+			if err != nil {
+				return nil, fmt.Errorf("Bad 'from' for %s: %w", r.Hostname, err)
+			}
+			tr.from = t.Unix()
+		}
+		if r.To != "" {
+			t, err := time.Parse(time.RFC3339, r.To)
+			if err != nil {
+				return nil, fmt.Errorf("Bad 'to' for %s: %w", r.Hostname, err)
+			}
+			tr.to = t.Unix()
+		}
+		if tr.from >= tr.to {
+			return nil, fmt.Errorf("Empty period for %s", r.Hostname)
+		}
+		nodes[r.Hostname] = append(nodes[r.Hostname], tr)
 	}
+	for hostname, timeline := range nodes {
+		slices.SortFunc(timeline, func(a, b timedRecord) int {
+			return cmp.Compare(a.from, b.from)
+		})
+		for i := 1; i < len(timeline); i++ {
+			if timeline[i].from < timeline[i-1].to {
+				return nil, fmt.Errorf("Overlapping periods for %s", hostname)
+			}
+		}
+	}
+	return nodes, nil
 }
 
 // Returns the most recent node configuration for the node.  Returns nil if not found.
 func (cc *ClusterConfig) LookupHost(hostname string) *NodeConfigRecord {
-	if probe, found := cc.nodes[hostname]; found {
-		return probe
+	if timeline, found := cc.nodes[hostname]; found {
+		return timeline[len(timeline)-1].record
 	}
 	return nil
 }
 
-// Return a fresh slice of all nodes in the config
+// Returns the node configuration that was valid at time t (seconds since Unix epoch UTC).  Returns
+// nil if not found.
+func (cc *ClusterConfig) LookupHostByTime(hostname string, t int64) *NodeConfigRecord {
+	for _, tr := range cc.nodes[hostname] {
+		if tr.from <= t && t < tr.to {
+			return tr.record
+		}
+	}
+	return nil
+}
+
+// Returns the node configurations that were valid at some point within the time window, in
+// ascending time order.
+func (cc *ClusterConfig) LookupHostInTimeWindow(hostname string, fromIncl, toExcl int64) []*NodeConfigRecord {
+	result := make([]*NodeConfigRecord, 0)
+	for _, tr := range cc.nodes[hostname] {
+		if tr.from < toExcl && fromIncl < tr.to {
+			result = append(result, tr.record)
+		}
+	}
+	return result
+}
+
+// Return a fresh slice of all nodes in the config, with the most recent configuration for each.
 func (cc *ClusterConfig) Hosts() []*NodeConfigRecord {
-	return umaps.Values(cc.nodes)
+	result := make([]*NodeConfigRecord, 0, len(cc.nodes))
+	for _, timeline := range cc.nodes {
+		result = append(result, timeline[len(timeline)-1].record)
+	}
+	return result
 }
 
-// Returns the hosts that were defined within the time window.  With our current structure we don't
-// have reliable time window information, so just return all hosts.
+// Returns the hosts that were defined within the time window.
 func (cc *ClusterConfig) HostsDefinedInTimeWindow(fromIncl, toExcl int64) []string {
 	result := make([]string, 0)
-	for _, n := range cc.nodes {
-		result = append(result, n.Hostname)
+	for hostname, timeline := range cc.nodes {
+		for _, tr := range timeline {
+			if tr.from < toExcl && fromIncl < tr.to {
+				result = append(result, hostname)
+				break
+			}
+		}
 	}
 	return result
 }
 
+// Returns the cluster's time zone, or nil if none is configured.
+func (cc *ClusterConfig) Location() *time.Location {
+	if cc.TimeZone == "" {
+		return nil
+	}
+	// The name was checked when the config was read.
+	loc, err := time.LoadLocation(cc.TimeZone)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// Returns the host names of the node group, or false if the group is not defined.  The returned
+// slice must not be modified.
+func (cc *ClusterConfig) LookupGroup(name string) ([]string, bool) {
+	hosts, found := cc.groups[name]
+	return hosts, found
+}
+
 func (cc *ClusterConfig) HasCrossNodeJobs() bool {
-	for _, n := range cc.nodes {
-		if n.CrossNodeJobs {
-			return true
+	for _, timeline := range cc.nodes {
+		for _, tr := range timeline {
+			if tr.record.CrossNodeJobs {
+				return true
+			}
 		}
 	}
 	return false
@@ -185,10 +337,15 @@ func ReadConfig(configFilename string) (*ClusterConfig, error) {
 	}
 	defer configFile.Close()
 
-	return ReadConfigFrom(configFile)
+	return readConfigFrom(configFile, path.Dir(configFilename))
 }
 
+// A groups file named in the config is read relative to the current directory.
 func ReadConfigFrom(input io.Reader) (*ClusterConfig, error) {
+	return readConfigFrom(input, ".")
+}
+
+func readConfigFrom(input io.Reader, dir string) (*ClusterConfig, error) {
 	var configInfo []*NodeConfigRecord
 	bs, err := io.ReadAll(input)
 	if err != nil {
@@ -212,10 +369,19 @@ func ReadConfigFrom(input io.Reader) (*ClusterConfig, error) {
 			var v2 ClusterConfigV2Repr
 			err = json.Unmarshal(bs, &v2)
 			config.Version = 2
+			if err == nil && v2.Version != 0 {
+				if v2.Version != 3 {
+					err = fmt.Errorf("Unsupported config version %d", v2.Version)
+				}
+				config.Version = v2.Version
+			}
 			config.Name = v2.Name
 			config.Description = v2.Description
 			config.Aliases = v2.Aliases
 			config.ExcludeUser = v2.ExcludeUser
+			config.TimeZone = v2.TimeZone
+			config.Groups = v2.Groups
+			config.GroupsFile = v2.GroupsFile
 			configInfo = v2.Nodes
 		default:
 			err = fmt.Errorf("Unexpected delimiter in JSON file %c", delim)
@@ -226,6 +392,11 @@ func ReadConfigFrom(input io.Reader) (*ClusterConfig, error) {
 	if err != nil {
 		return nil, fmt.Errorf("While unmarshaling config data: %w", err)
 	}
+	if config.TimeZone != "" {
+		if _, err := time.LoadLocation(config.TimeZone); err != nil {
+			return nil, fmt.Errorf("Bad time-zone %s: %w", config.TimeZone, err)
+		}
+	}
 
 	for _, c := range configInfo {
 		if c.CpuCores == 0 {
@@ -239,15 +410,94 @@ func ReadConfigFrom(input io.Reader) (*ClusterConfig, error) {
 		}
 	}
 
-	m, err := ExpandNodeConfigs(configInfo)
+	expanded, err := expandNodeConfigs(configInfo)
+	if err != nil {
+		return nil, err
+	}
+	config.nodes, err = makeTimelines(config.Version, expanded)
+	if err != nil {
+		return nil, err
+	}
+
+	config.groups, err = expandGroups(config.Groups, config.GroupsFile, dir)
 	if err != nil {
 		return nil, err
 	}
-	config.nodes = m
 	return config, nil
 }
 
+func expandGroups(groups map[string]string, groupsFile, dir string) (map[string][]string, error) {
+	all := make(map[string]string)
+	for name, hosts := range groups {
+		all[name] = hosts
+	}
+	if groupsFile != "" {
+		if !path.IsAbs(groupsFile) {
+			groupsFile = path.Join(dir, groupsFile)
+		}
+		bs, err := os.ReadFile(groupsFile)
+		if err != nil {
+			return nil, err
+		}
+		var fileGroups map[string]string
+		if err := json.Unmarshal(bs, &fileGroups); err != nil {
+			return nil, fmt.Errorf("While unmarshaling groups file: %w", err)
+		}
+		for name, hosts := range fileGroups {
+			if _, found := all[name]; found {
+				return nil, fmt.Errorf("Group %s defined both in config and in groups file", name)
+			}
+			all[name] = hosts
+		}
+	}
+
+	expanded := make(map[string][]string, len(all))
+	for name, hosts := range all {
+		if err := hostglob.SyntaxCheckPattern("@" + name); err != nil {
+			return nil, fmt.Errorf("Bad group name %s: %w", name, err)
+		}
+		patterns, err := hostglob.SplitMultiPattern(hosts)
+		if err == nil {
+			for _, p := range patterns {
+				p, _ := hostglob.CutExclusion(p)
+				if strings.HasPrefix(p, "@") {
+					err = fmt.Errorf("Nested group %s", p)
+					break
+				}
+			}
+		}
+		var names []string
+		if err == nil {
+			names, err = hostglob.ExpandPatterns(patterns)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Bad host list for group %s: %w", name, err)
+		}
+		expanded[name] = names
+	}
+	return expanded, nil
+}
+
 func ExpandNodeConfigs(configInfo []*NodeConfigRecord) (map[string]*NodeConfigRecord, error) {
+	configInfo, err := expandNodeConfigs(configInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	finalInfo := make(map[string]*NodeConfigRecord)
+	for _, c := range configInfo {
+		if _, found := finalInfo[c.Hostname]; found {
+			return nil, fmt.Errorf("Duplicate host name in config: %s", c.Hostname)
+		}
+		finalInfo[c.Hostname] = c
+	}
+
+	return finalInfo, nil
+}
+
+// Expand host name patterns, returning one record per host name.
+
+func expandNodeConfigs(configInfo []*NodeConfigRecord) ([]*NodeConfigRecord, error) {
 	moreInfo := []*NodeConfigRecord{}
 	for _, c := range configInfo {
 		expanded, err := hostglob.ExpandPattern(c.Hostname)
@@ -268,36 +518,21 @@ func ExpandNodeConfigs(configInfo []*NodeConfigRecord) (map[string]*NodeConfigRe
 			}
 		}
 	}
-	configInfo = append(configInfo, moreInfo...)
-
-	finalInfo := make(map[string]*NodeConfigRecord)
-	for _, c := range configInfo {
-		if _, found := finalInfo[c.Hostname]; found {
-			return nil, fmt.Errorf("Duplicate host name in config: %s", c.Hostname)
-		}
-		finalInfo[c.Hostname] = c
-	}
-
-	return finalInfo, nil
+	return append(configInfo, moreInfo...), nil
 }
 
-// Sorting boilerplate.
-type configSlice []*NodeConfigRecord
-
-func (a configSlice) Len() int           { return len(a) }
-func (a configSlice) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a configSlice) Less(i, j int) bool { return a[i].Hostname < a[j].Hostname }
-
 // Write the database.  The database is formatted text and we want it to be diffable, so the
-// database is sorted on output.  At the moment we have only one record per host and the database is
-// sorted in ascending hostname order.
+// database is sorted on output, in ascending hostname order and then in ascending time order.
 
 func WriteConfigTo(output io.Writer, config *ClusterConfig) error {
+	hostnames := umaps.Keys(config.nodes)
+	slices.Sort(hostnames)
 	records := make([]*NodeConfigRecord, 0, len(config.nodes))
-	for _, v := range config.nodes {
-		records = append(records, v)
+	for _, hn := range hostnames {
+		for _, tr := range config.nodes[hn] {
+			records = append(records, tr.record)
+		}
 	}
-	sort.Sort(configSlice(records))
 
 	var err error
 	var outBytes []byte
@@ -305,10 +540,16 @@ func WriteConfigTo(output io.Writer, config *ClusterConfig) error {
 		outBytes, err = json.MarshalIndent(&records, "", " ")
 	} else {
 		var v2repr ClusterConfigV2Repr
+		if config.Version >= 3 {
+			v2repr.Version = config.Version
+		}
 		v2repr.Name = config.Name
 		v2repr.Description = config.Description
 		v2repr.Aliases = config.Aliases
 		v2repr.ExcludeUser = config.ExcludeUser
+		v2repr.TimeZone = config.TimeZone
+		v2repr.Groups = config.Groups
+		v2repr.GroupsFile = config.GroupsFile
 		v2repr.Nodes = records
 		outBytes, err = json.MarshalIndent(&v2repr, "", " ")
 	}
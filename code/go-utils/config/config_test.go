@@ -3,7 +3,9 @@ package config
 import (
 	"bytes"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestReadConfigV1(t *testing.T) {
@@ -47,8 +49,8 @@ func testRoundtrip(t *testing.T, cfg *ClusterConfig) {
 		t.Fatalf("Could not read: %v", err)
 	}
 	// This depends on implementation details: the "nodes" are represented as a map, and maps are
-	// equal if they have the same keys with the same values.  If the nodes were implemented as an
-	// array we'd additionally depend on ordering.
+	// equal if they have the same keys with the same values.  The per-node timelines are sorted, so
+	// their ordering is deterministic.
 	if !reflect.DeepEqual(cfg, newCfg) {
 		t.Fatalf("Failed roundtripping")
 	}
@@ -74,6 +76,21 @@ func TestReadConfigV2(t *testing.T) {
 	if len(cfg.ExcludeUser) != 2 || cfg.ExcludeUser[0] != "root" || cfg.ExcludeUser[1] != "toor" {
 		t.Fatalf("ExcludeUser %v", cfg.ExcludeUser)
 	}
+	if cfg.TimeZone != "Europe/Oslo" || cfg.Location() == nil || cfg.Location().String() != "Europe/Oslo" {
+		t.Fatalf("TimeZone %v", cfg.TimeZone)
+	}
+	for name, want := range map[string][]string{
+		"gpu": []string{"ml7.hpc.uio.no", "ml8.hpc.uio.no"},
+		"c1":  []string{"c1-10", "c1-12"},
+		"big": []string{"ml8.hpc.uio.no", "c1-10"},
+	} {
+		if hosts, found := cfg.LookupGroup(name); !found || !reflect.DeepEqual(hosts, want) {
+			t.Fatalf("Group %s: %v", name, hosts)
+		}
+	}
+	if _, found := cfg.LookupGroup("nope"); found {
+		t.Fatal("Group nope")
+	}
 	c0 := cfg.LookupHost("ml7.hpc.uio.no")
 	if c0.CpuCores != 64 || c0.MemGB != 256 || c0.GpuCards != 8 || c0.GpuMemGB != 88 || c0.GpuMemPct != false {
 		t.Fatalf("element 0: %v", c0)
@@ -94,3 +111,82 @@ func TestReadConfigV2(t *testing.T) {
 	}
 	testRoundtrip(t, cfg)
 }
+
+func TestReadConfigV3(t *testing.T) {
+	cfg, err := ReadConfig("test-config-v3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Version != 3 {
+		t.Fatalf("Expected Version=3, got %d", cfg.Version)
+	}
+	unix := func(s string) int64 {
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm.Unix()
+	}
+	upgrade := unix("2024-03-01T00:00:00Z")
+
+	if c := cfg.LookupHost("ml7.hpc.uio.no"); c == nil || c.MemGB != 256 {
+		t.Fatalf("Latest: %v", c)
+	}
+	if c := cfg.LookupHostByTime("ml7.hpc.uio.no", upgrade-1); c == nil || c.MemGB != 128 {
+		t.Fatalf("Before: %v", c)
+	}
+	if c := cfg.LookupHostByTime("ml7.hpc.uio.no", upgrade); c == nil || c.MemGB != 256 {
+		t.Fatalf("After: %v", c)
+	}
+	if cs := cfg.LookupHostInTimeWindow("ml7.hpc.uio.no", upgrade-10, upgrade+10); len(cs) != 2 ||
+		cs[0].MemGB != 128 || cs[1].MemGB != 256 {
+		t.Fatalf("Window: %v", cs)
+	}
+	if cs := cfg.LookupHostInTimeWindow("ml7.hpc.uio.no", upgrade-10, upgrade); len(cs) != 1 ||
+		cs[0].MemGB != 128 {
+		t.Fatalf("Window: %v", cs)
+	}
+	if len(cfg.Hosts()) != 3 {
+		t.Fatalf("Hosts: %v", cfg.Hosts())
+	}
+
+	// c1-10 was only defined in the first half of 2024 (local time)
+	if c := cfg.LookupHostByTime("c1-10", unix("2023-12-31T23:00:00Z")); c == nil || c.CpuCores != 128 {
+		t.Fatalf("c1-10 start: %v", c)
+	}
+	if c := cfg.LookupHostByTime("c1-10", unix("2023-12-31T22:59:59Z")); c != nil {
+		t.Fatalf("c1-10 before: %v", c)
+	}
+	if c := cfg.LookupHostByTime("c1-10", unix("2024-05-31T22:00:00Z")); c != nil {
+		t.Fatalf("c1-10 after: %v", c)
+	}
+	hosts := cfg.HostsDefinedInTimeWindow(unix("2024-07-01T00:00:00Z"), unix("2024-08-01T00:00:00Z"))
+	if len(hosts) != 1 || hosts[0] != "ml7.hpc.uio.no" {
+		t.Fatalf("Defined: %v", hosts)
+	}
+
+	testRoundtrip(t, cfg)
+}
+
+func TestBadConfigV3(t *testing.T) {
+	for _, text := range []string{
+		// Overlapping periods
+		`{"version":3, "nodes":[
+{"hostname":"a", "cpu_cores":8, "mem_gb":16, "to":"2024-03-01T00:00:00Z"},
+{"hostname":"a", "cpu_cores":8, "mem_gb":16, "from":"2024-02-01T00:00:00Z"}]}`,
+		// Same host twice without periods
+		`{"version":3, "nodes":[{"hostname":"a", "cpu_cores":8, "mem_gb":16}, {"hostname":"a", "cpu_cores":8, "mem_gb":16}]}`,
+		// Empty period
+		`{"version":3, "nodes":[{"hostname":"a", "cpu_cores":8, "mem_gb":16, "from":"2024-03-01T00:00:00Z", "to":"2024-03-01T00:00:00Z"}]}`,
+		// Bad timestamp
+		`{"version":3, "nodes":[{"hostname":"a", "cpu_cores":8, "mem_gb":16, "from":"2024-03-01"}]}`,
+		// Periods require v3
+		`{"name":"x", "nodes":[{"hostname":"a", "cpu_cores":8, "mem_gb":16, "from":"2024-03-01T00:00:00Z"}]}`,
+		// Unknown version
+		`{"version":4, "nodes":[{"hostname":"a", "cpu_cores":8, "mem_gb":16}]}`,
+	} {
+		if _, err := ReadConfigFrom(strings.NewReader(text)); err == nil || strings.Contains(err.Error(), "missing") {
+			t.Fatalf("Expected error for %s", text)
+		}
+	}
+}
@@ -1,27 +1,70 @@
 package gpuset
 
 import (
+	"cmp"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"math/bits"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // Representation:
-// - the set is a bit vector
-// - bits 0..30 represent GPUs, 0x0000_0000 is "empty"
-// - if bit i is set then GPU i is in the set
+// - the set is a 32-bit value, 0x0000_0000 is "empty"
 // - value 0x8000_0000 represents "unknown"
+// - if the high bit is clear then bits 0..30 are a bit vector of GPUs: if bit i is set then GPU i
+//   is in the set ("narrow" sets)
+// - if the high bit is set and the value is not "unknown" then the low 31 bits are an index into a
+//   global table of "wide" sets, which is where sets with GPUs numbered above 30 and sets with MIG
+//   instances go
 //
 // There will be *a lot* of these both in input and in memory, so:
 // - representation compactness is important
 // - avoiding pointers in the representation is important (or Sample will have pointers too)
 // - avoiding a lot of garbage generation during parsing is important
+//
+// Nodes with more than 31 devices and MIG partitioning are uncommon and the number of distinct wide
+// sets is small, so the table is tiny in practice.  Wide sets are interned (as for Ustr in
+// sonalyze): a set that can be represented as a narrow set always is, and there is only one table
+// entry per distinct wide set, so two GpuSet values are equal iff they represent the same set.
+//
+// The index of a wide set is specific to the process, so a GpuSet that is serialized must go through
+// GobEncode/GobDecode or String/NewGpuSet.
+//
+// The elements of the set are Devices: a whole card, or a MIG instance on a card.  A MIG instance
+// is distinct from the card it's on and from the card's other instances, so {3} and {3:1} are
+// different sets, and neither is a subset of the other.  The textual form of a MIG instance is
+// card:instance.
+//
+// Currently MIG instances only come from NewGpuSet and AdjoinMig.  Sonar does not report MIG
+// devices, so the sample parsers never produce them and only whole cards reach `jobs` and the other
+// consumers of sample data.
 
 type GpuSet uint32
 
+type Device struct {
+	Card     uint32
+	Instance uint32 // Meaningful only if IsMig
+	IsMig    bool
+}
+
 const (
 	unknown = GpuSet(0x8000_0000)
 	empty   = GpuSet(0x0000_0000)
+	wideBit = GpuSet(0x8000_0000)
+
+	// Cards 0..narrowLimit-1 can be in narrow sets
+	narrowLimit = 31
+)
+
+var (
+	// MT: Locked
+	wideLock  sync.RWMutex
+	wideIndex = make(map[string]GpuSet)
+	wideSets  = [][]Device{nil} // Index 0 is not used, wideBit|0 is "unknown"
 )
 
 func EmptyGpuSet() GpuSet {
@@ -33,30 +76,139 @@ func UnknownGpuSet() GpuSet {
 }
 
 func NewGpuSet(s string) (GpuSet, error) {
-	gpuData := unknown
-	if s != "unknown" {
-		gpuData = empty
-		if s != "none" {
-			for {
-				before, after, found := strings.Cut(s, ",")
-				n, err := strconv.ParseUint(before, 10, 32)
-				if err != nil {
-					return unknown, fmt.Errorf("While parsing GPU list: %w", err)
-				}
-				if n > 30 {
-					return unknown, fmt.Errorf("While parsing GPU list: GPU #%d", n)
-				}
-				gpuData |= (1 << n)
-				if !found {
-					break
-				}
-				s = after
+	if s == "unknown" {
+		return unknown, nil
+	}
+	if s == "none" {
+		return empty, nil
+	}
+	gpuData := empty
+	var devices []Device
+	for {
+		before, after, found := strings.Cut(s, ",")
+		d, err := parseDevice(before)
+		if err != nil {
+			return unknown, fmt.Errorf("While parsing GPU list: %w", err)
+		}
+		if devices == nil && !d.IsMig && d.Card < narrowLimit {
+			gpuData |= (1 << d.Card)
+		} else {
+			if devices == nil {
+				devices = gpuData.Devices()
 			}
+			devices = append(devices, d)
 		}
+		if !found {
+			break
+		}
+		s = after
+	}
+	if devices != nil {
+		return makeGpuSet(devices), nil
 	}
 	return gpuData, nil
 }
 
+func parseDevice(s string) (Device, error) {
+	card, instance, isMig := strings.Cut(s, ":")
+	n, err := strconv.ParseUint(card, 10, 32)
+	if err != nil {
+		return Device{}, err
+	}
+	var m uint64
+	if isMig {
+		m, err = strconv.ParseUint(instance, 10, 32)
+		if err != nil {
+			return Device{}, err
+		}
+	}
+	return Device{Card: uint32(n), Instance: uint32(m), IsMig: isMig}, nil
+}
+
+func (d Device) String() string {
+	if d.IsMig {
+		return strconv.Itoa(int(d.Card)) + ":" + strconv.Itoa(int(d.Instance))
+	}
+	return strconv.Itoa(int(d.Card))
+}
+
+func compareDevices(a, b Device) int {
+	if c := cmp.Compare(a.Card, b.Card); c != 0 {
+		return c
+	}
+	// The whole card sorts before its MIG instances
+	if a.IsMig != b.IsMig {
+		if a.IsMig {
+			return 1
+		}
+		return -1
+	}
+	return cmp.Compare(a.Instance, b.Instance)
+}
+
+// Make the canonical set for the devices, which need not be sorted or unique.  The slice is
+// clobbered.
+
+func makeGpuSet(devices []Device) GpuSet {
+	slices.SortFunc(devices, compareDevices)
+	devices = slices.Compact(devices)
+
+	narrow := empty
+	for _, d := range devices {
+		if d.IsMig || d.Card >= narrowLimit {
+			return internWide(devices)
+		}
+		narrow |= (1 << d.Card)
+	}
+	return narrow
+}
+
+func internWide(devices []Device) GpuSet {
+	key := devicesString(devices)
+
+	wideLock.RLock()
+	g, found := wideIndex[key]
+	wideLock.RUnlock()
+	if found {
+		return g
+	}
+
+	wideLock.Lock()
+	defer wideLock.Unlock()
+	if g, found := wideIndex[key]; found {
+		return g
+	}
+	if len(wideSets) > int(^wideBit) {
+		panic("Too many distinct wide GPU sets")
+	}
+	g = wideBit | GpuSet(len(wideSets))
+	wideSets = append(wideSets, slices.Clip(devices))
+	wideIndex[key] = g
+	return g
+}
+
+func (g GpuSet) isWide() bool {
+	return g&wideBit != 0 && g != unknown
+}
+
+// The returned slice must not be modified.
+func (g GpuSet) wideDevices() []Device {
+	wideLock.RLock()
+	defer wideLock.RUnlock()
+	return wideSets[g&^wideBit]
+}
+
+func devicesString(devices []Device) string {
+	var b strings.Builder
+	for i, d := range devices {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(d.String())
+	}
+	return b.String()
+}
+
 func (this GpuSet) Equal(that GpuSet) bool {
 	return this == that
 }
@@ -65,17 +217,49 @@ func (this GpuSet) HasSubset(that GpuSet, proper bool) bool {
 	if this == unknown || that == unknown {
 		return false
 	}
-	return this&that == that && (!proper || this != that)
+	if !this.isWide() && !that.isWide() {
+		return this&that == that && (!proper || this != that)
+	}
+	xs, ys := this.Devices(), that.Devices()
+	i := 0
+	for _, y := range ys {
+		for i < len(xs) && compareDevices(xs[i], y) < 0 {
+			i++
+		}
+		if i == len(xs) || compareDevices(xs[i], y) != 0 {
+			return false
+		}
+		i++
+	}
+	return !proper || this != that
 }
 
-func Adjoin(s GpuSet, xs ...uint32) (GpuSet, error) {
-	for _, e := range xs {
-		if e >= 31 {
-			return unknown, fmt.Errorf("Out of range: %d", e)
+// Add the cards to the set.  The unknown set stays unknown.
+
+func Adjoin(s GpuSet, xs ...uint32) GpuSet {
+	if s == unknown {
+		return s
+	}
+	for i, e := range xs {
+		if s.isWide() || e >= narrowLimit {
+			devices := s.Devices()
+			for _, e := range xs[i:] {
+				devices = append(devices, Device{Card: e})
+			}
+			return makeGpuSet(devices)
 		}
 		s |= (1 << e)
 	}
-	return s, nil
+	return s
+}
+
+// Add the MIG instance on the card to the set.  The unknown set stays unknown.
+
+func AdjoinMig(s GpuSet, card, instance uint32) GpuSet {
+	if s == unknown {
+		return s
+	}
+	return makeGpuSet(append(s.Devices(), Device{Card: card, Instance: instance, IsMig: true}))
 }
 
 func UnionGpuSets(a, b GpuSet) GpuSet {
@@ -85,7 +269,13 @@ func UnionGpuSets(a, b GpuSet) GpuSet {
 	if b == unknown {
 		return b
 	}
-	return a | b
+	if !a.isWide() && !b.isWide() {
+		return a | b
+	}
+	if a == b {
+		return a
+	}
+	return makeGpuSet(append(a.Devices(), b.Devices()...))
 }
 
 func (g GpuSet) IsEmpty() bool {
@@ -96,29 +286,44 @@ func (g GpuSet) IsUnknown() bool {
 	return g == unknown
 }
 
+// The number of devices in the set, counting MIG instances separately.
+
 func (g GpuSet) Size() int {
 	if g == unknown {
 		panic("Size of unknown set")
 	}
-	g = (g & 0x55555555) + ((g >> 1) & 0x55555555)
-	g = (g & 0x33333333) + ((g >> 2) & 0x33333333)
-	g = (g & 0x0f0f0f0f) + ((g >> 4) & 0x0f0f0f0f)
-	g = (g & 0x00ff00ff) + ((g >> 8) & 0x00ff00ff)
-	g = (g & 0x0000ffff) + ((g >> 16) & 0x0000ffff)
-	return int(g)
+	if g.isWide() {
+		return len(g.wideDevices())
+	}
+	return bits.OnesCount32(uint32(g))
 }
 
+// True if the whole card n is in the set.
+
 func (g GpuSet) IsSet(n int) bool {
-	if g == unknown {
+	if g == unknown || n < 0 {
 		return false
 	}
-	return (g & (1 << n)) != 0
+	if g.isWide() {
+		_, found := slices.BinarySearchFunc(g.wideDevices(), Device{Card: uint32(n)}, compareDevices)
+		return found
+	}
+	return n < narrowLimit && (g&(1<<n)) != 0
 }
 
+// The sorted, distinct indices of the cards in the set.  A card with MIG instances in the set is
+// included even if the whole card is not.
+
 func (g GpuSet) AsSlice() []int {
 	xs := make([]int, 0)
-	if g != unknown {
-		for k := 0; k < 31; k++ {
+	if g.isWide() {
+		for _, d := range g.wideDevices() {
+			if len(xs) == 0 || xs[len(xs)-1] != int(d.Card) {
+				xs = append(xs, int(d.Card))
+			}
+		}
+	} else if g != unknown {
+		for k := 0; k < narrowLimit; k++ {
 			if (g & (1 << k)) != 0 {
 				xs = append(xs, k)
 			}
@@ -127,6 +332,24 @@ func (g GpuSet) AsSlice() []int {
 	return xs
 }
 
+// The devices of the set in sorted order, nil for the empty and unknown sets.  The returned slice is
+// fresh.
+
+func (g GpuSet) Devices() []Device {
+	if g.isWide() {
+		return slices.Clone(g.wideDevices())
+	}
+	var xs []Device
+	if g != unknown {
+		for k := 0; k < narrowLimit; k++ {
+			if (g & (1 << k)) != 0 {
+				xs = append(xs, Device{Card: uint32(k)})
+			}
+		}
+	}
+	return xs
+}
+
 func (g GpuSet) String() string {
 	if g == unknown {
 		return "unknown"
@@ -134,8 +357,11 @@ func (g GpuSet) String() string {
 	if g == empty {
 		return "none"
 	}
+	if g.isWide() {
+		return devicesString(g.wideDevices())
+	}
 	s := ""
-	for k := 0; k < 31; k++ {
+	for k := 0; k < narrowLimit; k++ {
 		if (g & (1 << k)) != 0 {
 			if s != "" {
 				s += ","
@@ -145,3 +371,30 @@ func (g GpuSet) String() string {
 	}
 	return s
 }
+
+// Wide set indices are specific to the process, so a wide set is serialized by its string.  Narrow
+// sets are serialized by their value to keep it cheap.
+
+const (
+	gobNarrow = 0
+	gobWide   = 1
+)
+
+func (g GpuSet) GobEncode() ([]byte, error) {
+	if g.isWide() {
+		return append([]byte{gobWide}, g.String()...), nil
+	}
+	return binary.LittleEndian.AppendUint32([]byte{gobNarrow}, uint32(g)), nil
+}
+
+func (g *GpuSet) GobDecode(bs []byte) (err error) {
+	if len(bs) == 5 && bs[0] == gobNarrow {
+		*g = GpuSet(binary.LittleEndian.Uint32(bs[1:]))
+		return nil
+	}
+	if len(bs) > 1 && bs[0] == gobWide {
+		*g, err = NewGpuSet(string(bs[1:]))
+		return err
+	}
+	return errors.New("Bad GpuSet encoding")
+}
@@ -115,3 +115,87 @@ func TestGpuset(t *testing.T) {
 		t.Fatal("Overlapping")
 	}
 }
+
+func TestWideGpuset(t *testing.T) {
+	s, err := NewGpuSet("1,40,3:1,2,3:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.String() != "1,2,3:0,3:1,40" {
+		t.Fatalf("String %s", s.String())
+	}
+	if s.Size() != 5 {
+		t.Fatalf("Size")
+	}
+	if !reflect.DeepEqual(s.AsSlice(), []int{1, 2, 3, 40}) {
+		t.Fatalf("Set values %v", s.AsSlice())
+	}
+	if !s.IsSet(40) || !s.IsSet(2) || s.IsSet(3) || s.IsSet(0) {
+		t.Fatalf("IsSet")
+	}
+
+	// Interning: equal sets are equal values, regardless of how they were made
+	u := Adjoin(EmptyGpuSet(), 40, 2, 1)
+	u = AdjoinMig(u, 3, 1)
+	u = AdjoinMig(u, 3, 0)
+	if !s.Equal(u) || s != u {
+		t.Fatal("Equal")
+	}
+	u = AdjoinMig(u, 3, 0)
+	if !s.Equal(u) {
+		t.Fatal("Equal after duplicate")
+	}
+
+	// Narrow sets stay narrow
+	n, _ := NewGpuSet("30,1")
+	if n.isWide() || n != Adjoin(EmptyGpuSet(), 1, 30) {
+		t.Fatal("Narrow")
+	}
+	n, _ = NewGpuSet("31")
+	if !n.isWide() || n.String() != "31" || !n.IsSet(31) {
+		t.Fatal("Wide")
+	}
+
+	// Subsets across representations
+	v, _ := NewGpuSet("1,2")
+	w, _ := NewGpuSet("1,40")
+	if !s.HasSubset(v, true) || !s.HasSubset(w, true) || w.HasSubset(s, false) {
+		t.Fatal("Subset")
+	}
+	if !w.HasSubset(w, false) || w.HasSubset(w, true) {
+		t.Fatal("Improper subset")
+	}
+	m, _ := NewGpuSet("3")
+	if s.HasSubset(m, false) {
+		t.Fatal("MIG instances are not the card")
+	}
+
+	// Union
+	if UnionGpuSets(v, w).String() != "1,2,40" {
+		t.Fatal("Union")
+	}
+	if !UnionGpuSets(w, UnknownGpuSet()).IsUnknown() {
+		t.Fatal("Union unknown")
+	}
+	if !Adjoin(UnknownGpuSet(), 40).IsUnknown() {
+		t.Fatal("Adjoin unknown")
+	}
+
+	// Serialization
+	for _, x := range []GpuSet{s, v, EmptyGpuSet(), UnknownGpuSet()} {
+		bs, err := x.GobEncode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var y GpuSet
+		if err := y.GobDecode(bs); err != nil || y != x {
+			t.Fatalf("Gob %v", x)
+		}
+	}
+
+	for _, bad := range []string{"", "x", "1,", "3:", ":1", "3:x"} {
+		if _, err := NewGpuSet(bad); err == nil {
+			t.Fatalf("Should fail: %s", bad)
+		}
+	}
+}
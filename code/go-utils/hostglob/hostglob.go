@@ -8,7 +8,10 @@
 //
 // The following grammar pertains to all of these:
 //
-//	multi-pattern   ::= pattern ("," pattern)*
+//	multi-pattern   ::= item ("," item)*
+//	item            ::= "!"? (pattern | group)
+//	group           ::= "@" group-name
+//	group-name      ::= <nonempty string of letters, digits, "_", "-", and ".">
 //	pattern         ::= pattern-element ("." pattern-element)*
 //	pattern-element ::= fragment+
 //	fragment        ::= literal | range | wildcard
@@ -24,6 +27,12 @@
 // The following restrictions apply:
 //
 //   - In a range A-B, A must be no greater than B or the pattern is invalid
+//   - An item prefixed by "!" is an exclusion: a list of items selects the hosts matched by any of
+//     the items that are not exclusions, except those matched by any of the exclusions.  If there
+//     are only exclusions then every host not matched by an exclusion is selected
+//   - A group names a set of hosts that is defined elsewhere (eg in the cluster configuration).
+//     This package does not know the definitions, so groups must be replaced by the patterns they
+//     stand for before matching or expansion
 //   - It is not possible to expand a pattern or multi-pattern that contains a wildcard
 //   - The expansion of the result of compression of a set of hostnames H must yield exactly
 //     the set H
@@ -64,7 +73,7 @@ import (
 )
 
 // SplitMultiPattern takes a <multi-pattern> according to the grammar above and returns a list of
-// individual <pattern>s in that list.  It requires a bit of logic because each pattern may contain
+// individual <item>s in that list.  It requires a bit of logic because each pattern may contain
 // a fragment that contains a comma.  Resulting patterns are trimmed.
 func SplitMultiPattern(s string) ([]string, error) {
 	patterns := make([]string, 0)
@@ -104,10 +113,27 @@ func SplitMultiPattern(s string) ([]string, error) {
 	return patterns, nil
 }
 
+// CutExclusion returns the item without the "!" and true if the item is an exclusion, otherwise the
+// item and false.
+func CutExclusion(item string) (string, bool) {
+	return strings.CutPrefix(item, "!")
+}
+
+// CutGroup returns the group name and true if the item (with any "!" removed) is a group, otherwise
+// the item and false.
+func CutGroup(item string) (string, bool) {
+	return strings.CutPrefix(item, "@")
+}
+
+// SyntaxCheckPattern checks an <item> from the grammar above.
 func SyntaxCheckPattern(p string) error {
+	p, _ = CutExclusion(p)
 	if p == "" {
 		return errors.New("Empty pattern")
 	}
+	if name, isGroup := CutGroup(p); isGroup {
+		return syntaxCheckGroupName(name)
+	}
 	elements := strings.Split(p, ".")
 
 	// The first element can have wildcards and so on
@@ -154,9 +180,75 @@ func SyntaxCheckPattern(p string) error {
 	return nil
 }
 
+func syntaxCheckGroupName(name string) error {
+	if name == "" {
+		return errors.New("Empty group name")
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			c == '_' || c == '-' || c == '.') {
+			return fmt.Errorf("Illegal character in group name: %s", name)
+		}
+	}
+	return nil
+}
+
 // ExpandPattern takes a single <pattern> from the grammar above and expands it.  Restriction: The pattern
-// must contain no "*" wildcards.
+// must contain no "*" wildcards, and must not be an exclusion or a group.
 func ExpandPattern(s string) ([]string, error) {
+	if strings.HasPrefix(s, "!") {
+		return nil, errors.New("Can't expand an exclusion: " + s)
+	}
+	if strings.HasPrefix(s, "@") {
+		return nil, errors.New("Can't expand a group: " + s)
+	}
+	return expandPattern(s)
+}
+
+// ExpandPatterns takes a list of <item>s from the grammar above, expands the patterns that are not
+// exclusions, and removes the names matched by the exclusions.  The exclusions may contain "*"
+// wildcards, the other patterns may not, and there must be no groups.  There must be at least one
+// pattern that is not an exclusion.  The result has no duplicates.
+func ExpandPatterns(items []string) ([]string, error) {
+	var excluded []string
+	var names []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if _, isExclusion := CutExclusion(item); isExclusion {
+			excluded = append(excluded, item)
+			continue
+		}
+		expanded, err := ExpandPattern(item)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range expanded {
+			if !seen[n] {
+				seen[n] = true
+				names = append(names, n)
+			}
+		}
+	}
+	if len(seen) == 0 {
+		return nil, errors.New("Only exclusions in host list")
+	}
+	if len(excluded) == 0 {
+		return names, nil
+	}
+	excluders, err := compileExcluders(excluded, false, "", "")
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(names))
+	for _, n := range names {
+		if !matchesAny(excluders, n) {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func expandPattern(s string) ([]string, error) {
 	before, after, has_tail := strings.Cut(s, ".")
 	head_expansions, err := expandPatternElement(before)
 	if err != nil {
@@ -166,7 +258,7 @@ func ExpandPattern(s string) ([]string, error) {
 		return head_expansions, nil
 	}
 
-	tail_expansions, err := ExpandPattern(after)
+	tail_expansions, err := expandPattern(after)
 	if err != nil {
 		return nil, err
 	}
@@ -397,11 +489,11 @@ func compressRange(xs []int) string {
 
 // A HostGlobber is a matcher of patterns against hostnames.
 //
-// The matcher holds a number of patterns.  Each pattern is a <pattern> in the sense of the
-// grammar referenced above.
+// The matcher holds a number of patterns.  Each pattern is an <item> in the sense of the grammar
+// referenced above, but must not be a group.
 //
-// The Match method attempts to match its argument against the patterns in the matcher,
-// returning true if any of them match.
+// The Match method attempts to match its argument against the patterns in the matcher, returning
+// true if any of them match and none of the exclusions match.
 //
 // The HostGlobber is immutable and thread-safe: the embedded regexes are defined to be thread-safe
 // for concurrent use. cf https://pkg.go.dev/regexp#Regexp:
@@ -415,7 +507,14 @@ func compressRange(xs []int) string {
 // like it is only used for fairly complex regexes; ours will tend to be simple (single host pattern
 // that should require no backtracking).
 type HostGlobber struct {
-	matchers []*regexp.Regexp
+	matchers []matcher
+}
+
+// A host is matched by the matcher if it matches `re` and none of the `excluders`.  The excluders
+// are shared among all the matchers from the same set of patterns.
+type matcher struct {
+	re        *regexp.Regexp
+	excluders []*regexp.Regexp
 }
 
 // Create a new filter matching against the patterns.  The flag indicates whether the globbers in
@@ -433,17 +532,63 @@ func NewGlobberWithFix(
 	patterns []string,
 	suffix string,
 ) (*HostGlobber, error) {
-	matchers := make([]*regexp.Regexp, 0, len(patterns))
+	var excluded []string
+	for _, p := range patterns {
+		if _, isExclusion := CutExclusion(p); isExclusion {
+			excluded = append(excluded, p)
+		}
+	}
+	excluders, err := compileExcluders(excluded, isPrefixMatcher, prefix, suffix)
+	if err != nil {
+		return nil, err
+	}
+	matchers := make([]matcher, 0, len(patterns))
 	for _, p := range patterns {
+		if _, isExclusion := CutExclusion(p); isExclusion {
+			continue
+		}
+		if _, isGroup := CutGroup(p); isGroup {
+			return nil, errors.New("Unresolved group: " + p)
+		}
 		re, _, err := compileGlobber(p, isPrefixMatcher, prefix, suffix)
 		if err != nil {
 			return nil, err
 		}
-		matchers = append(matchers, re)
+		matchers = append(matchers, matcher{re, excluders})
+	}
+	if len(matchers) == 0 && len(excluders) > 0 {
+		// Only exclusions: everything else is matched.
+		re := regexp.MustCompile("^" + regexp.QuoteMeta(prefix) + ".*" + regexp.QuoteMeta(suffix) + "$")
+		matchers = append(matchers, matcher{re, excluders})
 	}
 	return &HostGlobber{matchers}, nil
 }
 
+func compileExcluders(excluded []string, isPrefixMatcher bool, prefix, suffix string) ([]*regexp.Regexp, error) {
+	var excluders []*regexp.Regexp
+	for _, p := range excluded {
+		p, _ = CutExclusion(p)
+		if _, isGroup := CutGroup(p); isGroup {
+			return nil, errors.New("Unresolved group: " + p)
+		}
+		re, _, err := compileGlobber(p, isPrefixMatcher, prefix, suffix)
+		if err != nil {
+			return nil, err
+		}
+		excluders = append(excluders, re)
+	}
+	return excluders, nil
+}
+
+func matchesAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
 // Return a globber that matches a string if any of the constituent globbers match it.
 func Join(globbers []*HostGlobber) *HostGlobber {
 	if len(globbers) == 0 {
@@ -453,7 +598,7 @@ func Join(globbers []*HostGlobber) *HostGlobber {
 	for _, g := range globbers {
 		n += len(g.matchers)
 	}
-	matchers := make([]*regexp.Regexp, n)
+	matchers := make([]matcher, n)
 	n = 0
 	for _, g := range globbers {
 		copy(matchers[n:n+len(g.matchers)], g.matchers)
@@ -469,16 +614,21 @@ func (hg *HostGlobber) IsEmpty() bool {
 
 func (hg *HostGlobber) String() string {
 	return fmt.Sprint(
-		uslices.Map(hg.matchers, func(re *regexp.Regexp) string {
-			return re.String()
+		uslices.Map(hg.matchers, func(m matcher) string {
+			s := m.re.String()
+			for _, re := range m.excluders {
+				s += " !" + re.String()
+			}
+			return s
 		}),
 	)
 }
 
-// Match s against the patterns and return true iff it matches at least one pattern.
+// Match s against the patterns and return true iff it matches at least one pattern and none of
+// that pattern's exclusions.
 func (hg *HostGlobber) Match(host string) bool {
-	for _, re := range hg.matchers {
-		if re.MatchString(host) {
+	for _, m := range hg.matchers {
+		if m.re.MatchString(host) && !matchesAny(m.excluders, host) {
 			return true
 		}
 	}
@@ -1,6 +1,7 @@
 package hostglob
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -216,3 +217,69 @@ func TestGlobber3(t *testing.T) {
 		t.Fatal(hf.String())
 	}
 }
+
+func TestExclusion(t *testing.T) {
+	xs, err := SplitMultiPattern("gpu-[1-20],!gpu-13,!@a100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(xs) != 3 || xs[1] != "!gpu-13" || xs[2] != "!@a100" {
+		t.Fatalf("Split %v", xs)
+	}
+	for _, p := range xs {
+		if err := SyntaxCheckPattern(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, p := range []string{"!", "@", "!@", "@a/b"} {
+		if SyntaxCheckPattern(p) == nil {
+			t.Fatalf("Should fail: %s", p)
+		}
+	}
+
+	hf, err := NewGlobber(true, []string{"gpu-[1-20]", "!gpu-13", "!gpu-1*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hf.Match("gpu-2") || !hf.Match("gpu-20.fox") {
+		t.Fatal("Match 1")
+	}
+	if hf.Match("gpu-13") || hf.Match("gpu-1") || hf.Match("gpu-17.fox") || hf.Match("gpu-21") {
+		t.Fatal("Match 2")
+	}
+
+	// Only exclusions: everything else matches
+	hf, err = NewGlobber(true, []string{"!gpu-13"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hf.Match("gpu-13") || hf.Match("gpu-13.fox") || !hf.Match("gpu-12") || !hf.Match("c1-1") {
+		t.Fatal("Match 3")
+	}
+
+	// Exclusions apply per joined globber
+	hf1, _ := NewGlobberWithFix(true, "", []string{"!c1-1"}, ".csv")
+	hf2, _ := NewGlobberWithFix(true, "", []string{"c1-1"}, ".json")
+	hf = Join([]*HostGlobber{hf1, hf2})
+	if hf.Match("c1-1.csv") || !hf.Match("c1-2.csv") || !hf.Match("c1-1.json") || hf.Match("c1-2.json") {
+		t.Fatal("Match 4")
+	}
+
+	if _, err = NewGlobber(true, []string{"@a100"}); err == nil {
+		t.Fatal("Groups can't be matched")
+	}
+
+	names, err := ExpandPatterns([]string{"gpu-[1-5]", "gpu-[4-6]", "!gpu-[2,3]", "!*-6"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"gpu-1", "gpu-4", "gpu-5"}) {
+		t.Fatalf("Expand %v", names)
+	}
+	if _, err = ExpandPatterns([]string{"!gpu-1"}); err == nil {
+		t.Fatal("Only exclusions")
+	}
+	if _, err = ExpandPattern("!gpu-1"); err == nil {
+		t.Fatal("Expand exclusion")
+	}
+}
@@ -110,6 +110,37 @@ func (c *HttpClient) ProcessRetries() {
 }
 
 func (c *HttpClient) postDataByHttp(prevAttempts uint, path, mimetype string, buf []byte) {
+	retriable, err := c.TryPostData(path, mimetype, buf)
+	if err != nil {
+		// There doesn't seem to be any good way to determine that a host is currently unreachable
+		// vs all sorts of other errors that can happen along the way.  So when a sending error
+		// occurs, always retry.
+		if retriable && prevAttempts+1 <= c.maxAttempts {
+			c.addRetry(prevAttempts+1, path, mimetype, buf)
+		} else {
+			status.Infof("Failed to post to %s: %v", c.target, err)
+		}
+	}
+}
+
+// The error returned by TryPostData when the server responds with a status that is not OK.
+
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP status=%d", e.StatusCode)
+}
+
+// Post the data once, without queueing it for retry.  Returns nil if the data were accepted.
+// Otherwise, `retriable` is true if the data might be accepted later (the server could not be
+// reached or reported a temporary problem) and false if they will never be (the server rejected
+// them).  If the server responded then the error is a *StatusError.
+//
+// The "path" is as for PostDataByHttp().
+
+func (c *HttpClient) TryPostData(path, mimetype string, buf []byte) (retriable bool, err error) {
 	if c.verbose {
 		status.Infof("Trying to send %s\n", string(buf))
 	}
@@ -117,8 +148,7 @@ func (c *HttpClient) postDataByHttp(prevAttempts uint, path, mimetype string, bu
 	// Go down a level from http.Post() in order to be able to set authentication header.
 	req, err := http.NewRequest("POST", c.target.String()+path, bytes.NewReader(buf))
 	if err != nil {
-		status.Infof("Failed to post: %v", err)
-		return
+		return false, err
 	}
 	req.Header.Set("Content-Type", mimetype)
 	if c.authUser != "" {
@@ -126,17 +156,15 @@ func (c *HttpClient) postDataByHttp(prevAttempts uint, path, mimetype string, bu
 	}
 	resp, err := c.client.Do(req)
 	if err != nil {
-		// There doesn't seem to be any good way to determine that a host is currently unreachable
-		// vs all sorts of other errors that can happen along the way.  So when a sending error
-		// occurs, always retry.
-		if prevAttempts+1 <= c.maxAttempts {
-			c.addRetry(prevAttempts+1, path, mimetype, buf)
-		} else {
-			status.Infof("Failed to post to %s after max retries: %v", c.target, err)
-		}
-		return
+		return true, err
 	}
 
+	// API requires that we read and close the body
+	defer func() {
+		_, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}()
+
 	if c.verbose {
 		status.Infof("Response %s\n", resp.Status)
 	}
@@ -145,24 +173,17 @@ func (c *HttpClient) postDataByHttp(prevAttempts uint, path, mimetype string, bu
 	// Really we should expect
 	//  202 (StatusAccepted) for when a new record is created
 	//  208 (StatusAlreadyReported) for when the record is a dup
+	if resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = &StatusError{resp.StatusCode}
 
 	// Plausible "temporarily borked" response codes.
 	if resp.StatusCode == 500 || resp.StatusCode == 503 || resp.StatusCode == 504 {
-		if prevAttempts+1 <= c.maxAttempts {
-			c.addRetry(prevAttempts+1, path, mimetype, buf)
-		}
-		// Fall through: must read response body
+		return true, err
 	}
-
-	// Log the problem for 5xx too
-	if resp.StatusCode >= 300 {
-		status.Infof("Failed to post: HTTP status=%d", resp.StatusCode)
-		// Fall through: must read response body
-	}
-
-	// API requires that we read and close the body
-	_, _ = io.ReadAll(resp.Body)
-	resp.Body.Close()
+	return false, err
 }
 
 func (c *HttpClient) addRetry(prevAttempts uint, path, mimetype string, buf []byte) {
@@ -25,6 +25,8 @@ func MaxTime(a, b time.Time) time.Time {
 //  YYYY-MM-DD
 //  Nd (days ago)
 //  Nw (weeks ago)
+// or any of the other forms accepted by ParseTime, with the current time as the base.  The result
+// of the first three forms is truncated to the start of the day, as it has always been.
 
 // MT: Constant after initialization; immutable (except for configuration methods).
 var dateRe = regexp.MustCompile(`^(\d\d\d\d)-(\d\d)-(\d\d)$`)
@@ -49,7 +51,149 @@ func ParseRelativeDate(s string) (time.Time, error) {
 		weeks, _ := strconv.ParseUint(string(probe[1]), 10, 32)
 		return ThisDay(time.Now().UTC().AddDate(0, 0, -int(weeks)*7)), nil
 	}
-	return time.Now(), errors.New("Bad time specification")
+	return ParseTime(time.Now(), s, false, time.UTC)
+}
+
+// ParseTime parses a time expression relative to `now` and returns the time in UTC.  Dates and
+// times without an explicit offset, and calendar names, are interpreted in the time zone `loc`.
+// The format is one of:
+//
+//  YYYY-MM-DD                      start of the day, or its last second if endOfDay
+//  YYYY-MM-DDTHH:MM[:SS][offset]   the time; the T may be a space, the offset is Z or +HH:MM etc
+//  <offset>                        that long ago, eg 3d, 6h, 90m, 2d3h
+//  now[(+|-)<offset>]              now, or that long before or after now
+//  <name>[(+|-)<offset>]           a calendar period, see below, adjusted by the offset
+//
+// An offset is a sequence of Nw, Nd, Nh and Nm, for weeks, days, hours and minutes.  Weeks and
+// days are calendar steps in `loc`, so a day can be 23 or 25 hours across a DST transition; hours
+// and minutes are fixed lengths of time.
+//
+// The calendar names are today, yesterday, this-week, last-week, this-month and last-month.  Weeks
+// start on Monday.  A name denotes the start of the period, or its last second if endOfDay, so that
+// `-from last-month -to last-month` selects all of last month.  endOfDay is not applied to the
+// other relative forms.
+
+func ParseTime(now time.Time, s string, endOfDay bool, loc *time.Location) (time.Time, error) {
+	now = now.In(loc)
+
+	if probe := dateRe.FindStringSubmatch(s); probe != nil {
+		yyyy, _ := strconv.Atoi(probe[1])
+		mm, _ := strconv.Atoi(probe[2])
+		dd, _ := strconv.Atoi(probe[3])
+		var h, m, s int
+		if endOfDay {
+			h, m, s = 23, 59, 59
+		}
+		return time.Date(yyyy, time.Month(mm), dd, h, m, s, 0, loc).UTC(), nil
+	}
+
+	if probe := dateTimeRe.FindStringSubmatch(s); probe != nil {
+		yyyy, _ := strconv.Atoi(probe[1])
+		mm, _ := strconv.Atoi(probe[2])
+		dd, _ := strconv.Atoi(probe[3])
+		h, _ := strconv.Atoi(probe[4])
+		m, _ := strconv.Atoi(probe[5])
+		var sec int
+		if probe[6] != "" {
+			sec, _ = strconv.Atoi(probe[6])
+		}
+		zone := loc
+		if probe[7] == "Z" {
+			zone = time.UTC
+		} else if probe[7] != "" {
+			oh, _ := strconv.Atoi(probe[9])
+			om, _ := strconv.Atoi(probe[10])
+			offset := oh*3600 + om*60
+			if probe[8] == "-" {
+				offset = -offset
+			}
+			zone = time.FixedZone("", offset)
+		}
+		return time.Date(yyyy, time.Month(mm), dd, h, m, sec, 0, zone).UTC(), nil
+	}
+
+	if offsetRe.MatchString(s) {
+		return addOffset(now, "-", s).UTC(), nil
+	}
+
+	if probe := namedRe.FindStringSubmatch(s); probe != nil {
+		base, ok := namedTime(now, probe[1], endOfDay)
+		if !ok {
+			return now.UTC(), errors.New("Bad time specification")
+		}
+		return addOffset(base, probe[2], probe[3]).UTC(), nil
+	}
+
+	return now.UTC(), errors.New("Bad time specification")
+}
+
+// MT: Constant after initialization; immutable (except for configuration methods).
+var dateTimeRe = regexp.MustCompile(
+	`^(\d\d\d\d)-(\d\d)-(\d\d)[T ](\d\d):(\d\d)(?::(\d\d))?(Z|([+-])(\d\d):?(\d\d))?$`)
+var offsetRe = regexp.MustCompile(`^(?:\d+[wdhm])+$`)
+var offsetPartRe = regexp.MustCompile(`(\d+)([wdhm])`)
+var namedRe = regexp.MustCompile(`^([a-z]+(?:-[a-z]+)?)(?:([+-])((?:\d+[wdhm])+))?$`)
+
+// Move t by the offset in the direction given by sign, which is "+" or "-" (or "" for no offset).
+
+func addOffset(t time.Time, sign, offset string) time.Time {
+	if offset == "" {
+		return t
+	}
+	n := 1
+	if sign == "-" {
+		n = -1
+	}
+	for _, part := range offsetPartRe.FindAllStringSubmatch(offset, -1) {
+		k, _ := strconv.Atoi(part[1])
+		k *= n
+		switch part[2] {
+		case "w":
+			t = t.AddDate(0, 0, 7*k)
+		case "d":
+			t = t.AddDate(0, 0, k)
+		case "h":
+			t = t.Add(time.Duration(k) * time.Hour)
+		case "m":
+			t = t.Add(time.Duration(k) * time.Minute)
+		}
+	}
+	return t
+}
+
+// Return the start of the named period containing `now` (in now's location), or the last second of
+// the period if endOfDay.
+
+func namedTime(now time.Time, name string, endOfDay bool) (time.Time, bool) {
+	if name == "now" {
+		return now, true
+	}
+	loc := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	// Week starts on Monday, while Weekday starts on Sunday
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	var start, next time.Time
+	switch name {
+	case "today":
+		start, next = today, today.AddDate(0, 0, 1)
+	case "yesterday":
+		start, next = today.AddDate(0, 0, -1), today
+	case "this-week":
+		start, next = monday, monday.AddDate(0, 0, 7)
+	case "last-week":
+		start, next = monday.AddDate(0, 0, -7), monday
+	case "this-month":
+		start, next = month, month.AddDate(0, 1, 0)
+	case "last-month":
+		start, next = month.AddDate(0, -1, 0), month
+	default:
+		return now, false
+	}
+	if endOfDay {
+		return next.Add(-time.Second), true
+	}
+	return start, true
 }
 
 // The time returned is UTC; the input ought to be UTC as well.
@@ -23,3 +23,65 @@ func TestParseRelativeDate(t *testing.T) {
 		t.Fatalf("Failed parsing weeks-ago")
 	}
 }
+
+func TestParseTime(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 3, 5, 14, 30, 0, 0, time.UTC)
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		s        string
+		endOfDay bool
+		loc      *time.Location
+		want     time.Time
+	}{
+		{"2025-03-04", false, time.UTC, time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"2025-03-04", true, time.UTC, time.Date(2025, 3, 4, 23, 59, 59, 0, time.UTC)},
+		{"2025-03-04T13:00", false, time.UTC, time.Date(2025, 3, 4, 13, 0, 0, 0, time.UTC)},
+		{"2025-03-04 13:00:15", true, time.UTC, time.Date(2025, 3, 4, 13, 0, 15, 0, time.UTC)},
+		{"2025-03-04T13:00", false, oslo, time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)},
+		{"2025-03-04T13:00Z", false, oslo, time.Date(2025, 3, 4, 13, 0, 0, 0, time.UTC)},
+		{"2025-03-04T13:00+02:00", false, time.UTC, time.Date(2025, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{"2025-03-04T13:00-0130", false, time.UTC, time.Date(2025, 3, 4, 14, 30, 0, 0, time.UTC)},
+		{"3d", true, time.UTC, time.Date(2025, 3, 2, 14, 30, 0, 0, time.UTC)},
+		{"6h", false, time.UTC, time.Date(2025, 3, 5, 8, 30, 0, 0, time.UTC)},
+		{"90m", false, time.UTC, time.Date(2025, 3, 5, 13, 0, 0, 0, time.UTC)},
+		{"1w2d3h", false, time.UTC, time.Date(2025, 2, 24, 11, 30, 0, 0, time.UTC)},
+		{"now", true, time.UTC, now},
+		{"now-2d3h", false, time.UTC, time.Date(2025, 3, 3, 11, 30, 0, 0, time.UTC)},
+		{"now+1h", false, time.UTC, time.Date(2025, 3, 5, 15, 30, 0, 0, time.UTC)},
+		{"today", false, time.UTC, time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"today", true, time.UTC, time.Date(2025, 3, 5, 23, 59, 59, 0, time.UTC)},
+		{"today-2d", false, time.UTC, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"yesterday", false, time.UTC, time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"yesterday", true, time.UTC, time.Date(2025, 3, 4, 23, 59, 59, 0, time.UTC)},
+		{"this-week", false, time.UTC, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"last-week", false, time.UTC, time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC)},
+		{"last-week", true, time.UTC, time.Date(2025, 3, 2, 23, 59, 59, 0, time.UTC)},
+		{"this-month", false, time.UTC, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"last-month", false, time.UTC, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"last-month", true, time.UTC, time.Date(2025, 2, 28, 23, 59, 59, 0, time.UTC)},
+		{"last-month-1d", false, time.UTC, time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"today", false, oslo, time.Date(2025, 3, 4, 23, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		got, err := ParseTime(now, test.s, test.endOfDay, test.loc)
+		if err != nil {
+			t.Fatalf("%s: %v", test.s, err)
+		}
+		if got.Location() != time.UTC {
+			t.Fatalf("%s: Should return UTC time", test.s)
+		}
+		if !got.Equal(test.want) {
+			t.Fatalf("%s: got %v want %v", test.s, got, test.want)
+		}
+	}
+
+	for _, s := range []string{"", "3", "3x", "tomorrow", "now-", "now-3", "2025-03-04T13", "last-month+"} {
+		if _, err := ParseTime(now, s, false, time.UTC); err == nil {
+			t.Fatalf("%s: Should fail", s)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Compare two cluster configurations.
+//
+// Nodes are compared by their latest configuration.  The output has one line per difference,
+// sorted by host name:
+//
+//   + host: description           host is only in the new config
+//   - host: description           host is only in the old config
+//   ~ host: field old -> new      field differs
+//
+// Timestamps and validity periods are not compared, as they will nearly always differ, except when
+// both configs are v3.  Then the timelines are compared period by period, a period being identified
+// by its start time, and "host" above is "host from start-time" for the periods that differ.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"go-utils/config"
+)
+
+func diffConfigs(out io.Writer, oldConfig, newConfig *config.ClusterConfig) {
+	if oldConfig.Name != newConfig.Name {
+		fmt.Fprintf(out, "~ name %q -> %q\n", oldConfig.Name, newConfig.Name)
+	}
+	if oldConfig.Description != newConfig.Description {
+		fmt.Fprintf(out, "~ description %q -> %q\n", oldConfig.Description, newConfig.Description)
+	}
+
+	oldNodes := make(map[string]*config.NodeConfigRecord)
+	for _, n := range oldConfig.Hosts() {
+		oldNodes[n.Hostname] = n
+	}
+	newNodes := make(map[string]*config.NodeConfigRecord)
+	for _, n := range newConfig.Hosts() {
+		newNodes[n.Hostname] = n
+	}
+	hostnames := make([]string, 0, len(oldNodes)+len(newNodes))
+	for hn := range oldNodes {
+		hostnames = append(hostnames, hn)
+	}
+	for hn := range newNodes {
+		if oldNodes[hn] == nil {
+			hostnames = append(hostnames, hn)
+		}
+	}
+	slices.Sort(hostnames)
+
+	for _, hn := range hostnames {
+		o, n := oldNodes[hn], newNodes[hn]
+		switch {
+		case o == nil:
+			fmt.Fprintf(out, "+ %s: %s\n", hn, n.Description)
+		case n == nil:
+			fmt.Fprintf(out, "- %s: %s\n", hn, o.Description)
+		case oldConfig.Version >= 3 && newConfig.Version >= 3:
+			diffTimelines(
+				out,
+				hn,
+				oldConfig.LookupHostInTimeWindow(hn, math.MinInt64, math.MaxInt64),
+				newConfig.LookupHostInTimeWindow(hn, math.MinInt64, math.MaxInt64),
+			)
+		default:
+			diffNodes(out, hn, o, n)
+		}
+	}
+}
+
+// The timelines are in ascending time order.  Periods are matched by start time, and the periods
+// that are in only one of the timelines are printed in time order after those that are in both.
+
+func diffTimelines(out io.Writer, hostname string, oldTimeline, newTimeline []*config.NodeConfigRecord) {
+	oldPeriods := make(map[int64]*config.NodeConfigRecord)
+	for _, o := range oldTimeline {
+		oldPeriods[periodStart(o)] = o
+	}
+	matched := make(map[int64]bool)
+	added := make([]*config.NodeConfigRecord, 0)
+	for _, n := range newTimeline {
+		start := periodStart(n)
+		if o := oldPeriods[start]; o != nil {
+			label := periodLabel(hostname, n)
+			if periodEnd(o) != periodEnd(n) {
+				fmt.Fprintf(out, "~ %s: to %q -> %q\n", label, o.To, n.To)
+			}
+			diffNodes(out, label, o, n)
+			matched[start] = true
+		} else {
+			added = append(added, n)
+		}
+	}
+	for _, o := range oldTimeline {
+		if !matched[periodStart(o)] {
+			fmt.Fprintf(out, "- %s: %s\n", periodLabel(hostname, o), o.Description)
+		}
+	}
+	for _, n := range added {
+		fmt.Fprintf(out, "+ %s: %s\n", periodLabel(hostname, n), n.Description)
+	}
+}
+
+func periodLabel(hostname string, r *config.NodeConfigRecord) string {
+	if r.From == "" {
+		return hostname + " from the start"
+	}
+	return hostname + " from " + r.From
+}
+
+// The start time of a period in seconds since the epoch, so that the same time with different time
+// zone offsets is the same start.  A period without a start is open to the past.
+
+func periodStart(r *config.NodeConfigRecord) int64 {
+	return periodTime(r.From, math.MinInt64)
+}
+
+// The end time of a period in seconds since the epoch, likewise.  A period without an end is open
+// to the future.
+
+func periodEnd(r *config.NodeConfigRecord) int64 {
+	return periodTime(r.To, math.MaxInt64)
+}
+
+func periodTime(s string, open int64) int64 {
+	if s == "" {
+		return open
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		// The config has been validated, so this should not happen.
+		return open
+	}
+	return t.Unix()
+}
+
+func diffNodes(out io.Writer, label string, o, n *config.NodeConfigRecord) {
+	diffField(out, label, "description", strconv.Quote(o.Description), strconv.Quote(n.Description))
+	diffField(out, label, "cross_node_jobs", o.CrossNodeJobs, n.CrossNodeJobs)
+	diffField(out, label, "cpu_cores", o.CpuCores, n.CpuCores)
+	diffField(out, label, "mem_gb", o.MemGB, n.MemGB)
+	diffField(out, label, "gpu_cards", o.GpuCards, n.GpuCards)
+	diffField(out, label, "gpumem_gb", o.GpuMemGB, n.GpuMemGB)
+	diffField(out, label, "gpumem_pct", o.GpuMemPct, n.GpuMemPct)
+}
+
+func diffField[T comparable](out io.Writer, label, field string, o, n T) {
+	if o != n {
+		fmt.Fprintf(out, "~ %s: %s %v -> %v\n", label, field, o, n)
+	}
+}
@@ -1,16 +1,21 @@
-// This creates a cluster config file for a cluster based on raw data collected by `sysinfo` or
-// (equivalently) `sonar sysinfo`.
+// This creates a cluster config file for a cluster based on the sysinfo data collected by `sonar
+// sysinfo` (or the older `sysinfo`).
 //
-// `sysinfo` will probe a node's configuration and emit a JSON object with information about the
-// node.  The data format is defined jointly by the `sysinfo` program in this repo and the `sysinfo`
-// verb in Sonar.
+// make-cluster-config runs `sonalyze node` to obtain the node information for the cluster, and will
+// create a configuration file for the cluster, filling in any missing data from the `background`
+// file, if provided.  Since sonalyze does the data access, the data can be in a directory tree
+// (-data-dir), in a database (-jobanalyzer-dir or -database-uri), or behind a remote daemon
+// (-remote, with -auth-file if needed).  For the latter two the cluster is identified by -name.
 //
-// make-cluster-config collects sysinfo for nodes and will create a configuration file for the
-// cluster, filling in any missing data from the `background` file, if provided.
+// With -diff, the generated configuration is compared to an existing configuration file, typically
+// the deployed cluster-config/CLUSTER-config.json, and the differences are printed instead of the
+// new configuration.  If both configurations are v3 then their timelines are compared, see diff.go.
 //
 // The cluster configuration file format is defined jointly by a number of programs, chiefly by
-// ../rustutils/src/configs.rs and ../go-utils/config/config.go.  make-cluster-config currently only
-// produces the v2 format but will eventually have to produce a new format with a per-host timeline.
+// ../rustutils/src/configs.rs and ../go-utils/config/config.go.  make-cluster-config produces the v2
+// format by default, with the latest configuration for each host.  With -v3 it produces the v3
+// format, with a per-host timeline: a new record starts whenever the host's configuration changes.
+// The first record for a host has no start time and the last has no end time.
 //
 // The background file, if present, also must currently be v2 but may eventually have to be v3
 // (since the background also changes over time).
@@ -23,85 +28,88 @@
 //    -desc "UiO ML nodes" \
 //    -from 2023-01-01 \
 //    -background ~/.../misc/mlx.hpc.uio.no/mlx.hpc.uio.no-background.json
+//
+// make-cluster-config \
+//    -remote https://naic-monitor.uio.no \
+//    -auth-file ~/.ssh/sonalyzed-auth.txt \
+//    -name fox.educloud.no \
+//    -desc "UiO HPC cluster" \
+//    -from 30d \
+//    -diff ~/.../cluster-config/fox.educloud.no-config.json
 
 package main
 
 import (
+	"cmp"
 	"encoding/json"
 	"flag"
-	"io"
 	"log"
 	"os"
 	"path"
+	"slices"
 	"strings"
 	"time"
 
 	"go-utils/config"
-	"go-utils/filesys"
-	ut "go-utils/time"
+	"go-utils/process"
+	"go-utils/sonalyze"
 )
 
 // Command-line parameters
 var (
-	dataDir       string
-	from          time.Time
-	to            time.Time
-	backgroundDir string
-	clusterName   string
-	clusterDesc   string
-	excludeUsers  []string
-	aliases       []string
+	sonalyzePath   string
+	dataDir        string
+	jobanalyzerDir string
+	databaseURI    string
+	remote         string
+	authFile       string
+	from           string
+	to             string
+	backgroundDir  string
+	clusterName    string
+	clusterDesc    string
+	excludeUsers   []string
+	aliases        []string
+	v3             bool
+	diffFile       string
 )
 
 func main() {
 	parseFlags()
 
 	bg := readBackground()
-	info := readSysinfo()
+	info := readNodes()
 
 	nodes := make([]*config.NodeConfigRecord, 0)
 	known := make(map[string]bool)
 	for _, infos := range info {
-		// For the v2 format we can only have one timestamp, so take the latest always.
-		var latest *config.NodeConfigRecord
-		for _, info := range infos {
-			if latest == nil || info.Timestamp > latest.Timestamp {
-				latest = info
-			}
-		}
-
-		if latest == nil {
+		if len(infos) == 0 {
 			// Wow, weird
 			continue
 		}
-
-		// Apply background information.
-		if bginfo := bg[latest.Hostname]; bginfo != nil {
-			// latest.Timestamp is always valid
-			// latest.Hostname is always valid
-			if latest.Description == "" {
-				latest.Description = bginfo.Description
-			}
-			latest.CrossNodeJobs = bginfo.CrossNodeJobs
-			if latest.CpuCores == 0 {
-				latest.CpuCores = bginfo.CpuCores
-			}
-			if latest.MemGB == 0 {
-				latest.MemGB = bginfo.MemGB
-			}
-			if latest.GpuCards == 0 {
-				latest.GpuCards = bginfo.GpuCards
-			}
-			if latest.GpuMemGB == 0 {
-				latest.GpuMemGB = bginfo.GpuMemGB
-			}
-			if !latest.GpuMemPct {
-				latest.GpuMemPct = bginfo.GpuMemPct
+		slices.SortFunc(infos, func(a, b *config.NodeConfigRecord) int {
+			// The timestamps may have different time zone offsets (DST), so compare times if possible.
+			ta, ea := time.Parse(time.RFC3339, a.Timestamp)
+			tb, eb := time.Parse(time.RFC3339, b.Timestamp)
+			if ea == nil && eb == nil {
+				return ta.Compare(tb)
 			}
+			return cmp.Compare(a.Timestamp, b.Timestamp)
+		})
+
+		var timeline []*config.NodeConfigRecord
+		if v3 {
+			timeline = makeTimeline(infos)
+		} else {
+			// For the v2 format we can only have one timestamp, so take the latest always.
+			timeline = infos[len(infos)-1:]
 		}
 
-		nodes = append(nodes, latest)
-		known[latest.Hostname] = true
+		for _, latest := range timeline {
+			applyBackground(latest, bg[latest.Hostname])
+			nodes = append(nodes, latest)
+			known[latest.Hostname] = true
+		}
 	}
 
 	// Add missing hosts
@@ -122,50 +130,166 @@ func main() {
 		}
 	}
 
-	cc := config.NewClusterConfig(
-		2,
+	version := 2
+	if v3 {
+		version = 3
+	}
+	cc, err := config.NewClusterConfig(
+		version,
 		clusterName,
 		clusterDesc,
 		aliases,
 		excludeUsers,
 		nodes,
 	)
-
-	err := config.WriteConfigTo(os.Stdout, cc)
 	if err != nil {
 		log.Fatal(err)
 	}
-}
 
-func readSysinfo() map[string][]*config.NodeConfigRecord {
-	files, err := filesys.EnumerateFiles(dataDir, from, to, "sysinfo-*.json")
+	if diffFile != "" {
+		deployed, err := config.ReadConfig(diffFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		diffConfigs(os.Stdout, deployed, cc)
+		return
+	}
+
+	err = config.WriteConfigTo(os.Stdout, cc)
 	if err != nil {
 		log.Fatal(err)
 	}
-	if len(files) == 0 {
-		log.Fatalf("No sysinfo files found in %s", dataDir)
-	}
-	info := make(map[string][]*config.NodeConfigRecord)
-	for _, fn := range files {
-		input, err := os.Open(path.Join(dataDir, fn))
+}
+
+// The infos are sorted by ascending timestamp.  Consecutive records with the same configuration are
+// merged, and each merged record is valid from the timestamp of its first record until the next
+// configuration starts.  Records whose timestamps can't be parsed are dropped.  The config periods
+// have a resolution of one second, so of several configurations starting within the same second the
+// last wins, and it is merged with the configuration before it if they are the same.
+
+func makeTimeline(infos []*config.NodeConfigRecord) []*config.NodeConfigRecord {
+	timeline := make([]*config.NodeConfigRecord, 0)
+	starts := make([]int64, 0) // The start second of each timeline record
+	for _, info := range infos {
+		t, err := time.Parse(time.RFC3339, info.Timestamp)
 		if err != nil {
-			log.Fatal(err)
+			log.Printf("Dropping record for %s with bad timestamp %s", info.Hostname, info.Timestamp)
+			continue
 		}
-		// The sysinfo file has zero or more records in a row, but not wrapped in an array or
-		// separated by anything more than space.  Thus, use a decoder to read the successive
-		// records.
-		dec := json.NewDecoder(input)
-		for {
-			var d config.NodeConfigRecord
-			err := dec.Decode(&d)
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				log.Fatal(err)
+		n := len(timeline)
+		if n > 0 && sameConfig(timeline[n-1], info) {
+			continue
+		}
+		if n > 0 && starts[n-1] == t.Unix() {
+			// The previous configuration would be valid for less than a second, replace it.
+			from := timeline[n-1].From
+			timeline, starts = timeline[:n-1], starts[:n-1]
+			n--
+			if n > 0 && sameConfig(timeline[n-1], info) {
+				timeline[n-1].To = ""
+				continue
 			}
-			info[d.Hostname] = append(info[d.Hostname], &d)
+			info.From = from
+		} else if n > 0 {
+			timeline[n-1].To = info.Timestamp
+			info.From = info.Timestamp
 		}
+		timeline = append(timeline, info)
+		starts = append(starts, t.Unix())
+	}
+	return timeline
+}
+
+func sameConfig(a, b *config.NodeConfigRecord) bool {
+	return a.Description == b.Description &&
+		a.CpuCores == b.CpuCores &&
+		a.MemGB == b.MemGB &&
+		a.GpuCards == b.GpuCards &&
+		a.GpuMemGB == b.GpuMemGB &&
+		a.GpuMemPct == b.GpuMemPct
+}
+
+func applyBackground(latest, bginfo *config.NodeConfigRecord) {
+	if bginfo != nil {
+		// latest.Timestamp is always valid
+		// latest.Hostname is always valid
+		if latest.Description == "" {
+			latest.Description = bginfo.Description
+		}
+		latest.CrossNodeJobs = bginfo.CrossNodeJobs
+		if latest.CpuCores == 0 {
+			latest.CpuCores = bginfo.CpuCores
+		}
+		if latest.MemGB == 0 {
+			latest.MemGB = bginfo.MemGB
+		}
+		if latest.GpuCards == 0 {
+			latest.GpuCards = bginfo.GpuCards
+		}
+		if latest.GpuMemGB == 0 {
+			latest.GpuMemGB = bginfo.GpuMemGB
+		}
+		if !latest.GpuMemPct {
+			latest.GpuMemPct = bginfo.GpuMemPct
+		}
+	}
+}
+
+// The output of `sonalyze node` has all values as strings.
+type nodeRecord struct {
+	Timestamp   string `json:"timestamp"`
+	Hostname    string `json:"host"`
+	Description string `json:"desc"`
+	CpuCores    string `json:"cores"`
+	MemGB       string `json:"mem"`
+	GpuCards    string `json:"gpus"`
+	GpuMemGB    string `json:"gpumem"`
+	GpuMemPct   string `json:"gpumempct"`
+}
+
+func readNodes() map[string][]*config.NodeConfigRecord {
+	arguments := []string{"node", "-fmt", "json,timestamp,host,desc,cores,mem,gpus,gpumem,gpumempct"}
+	switch {
+	case dataDir != "":
+		arguments = append(arguments, "-data-dir", dataDir)
+	case jobanalyzerDir != "":
+		arguments = append(arguments, "-jobanalyzer-dir", jobanalyzerDir, "-cluster", clusterName)
+	case databaseURI != "":
+		arguments = append(arguments, "-database-uri", databaseURI, "-cluster", clusterName)
+	default:
+		arguments = append(arguments, "-remote", remote, "-cluster", clusterName)
+		if authFile != "" {
+			arguments = append(arguments, "-auth-file", authFile)
+		}
+	}
+	arguments = append(arguments, "-from", from)
+	if to != "" {
+		arguments = append(arguments, "-to", to)
+	}
+	stdout, stderr, err := process.RunSubprocess("sonalyze", sonalyzePath, arguments)
+	if err != nil {
+		log.Fatalf("sonalyze failed: %v\n%s", err, stderr)
+	}
+
+	var records []nodeRecord
+	if err := json.Unmarshal([]byte(stdout), &records); err != nil {
+		log.Fatalf("Bad output from sonalyze: %v", err)
+	}
+	if len(records) == 0 {
+		log.Fatalf("No node data found")
+	}
+	info := make(map[string][]*config.NodeConfigRecord)
+	for _, r := range records {
+		info[r.Hostname] = append(info[r.Hostname], &config.NodeConfigRecord{
+			Timestamp:   r.Timestamp,
+			Hostname:    r.Hostname,
+			Description: r.Description,
+			CpuCores:    sonalyze.JsonInt(r.CpuCores),
+			MemGB:       sonalyze.JsonInt(r.MemGB),
+			GpuCards:    sonalyze.JsonInt(r.GpuCards),
+			GpuMemGB:    sonalyze.JsonInt(r.GpuMemGB),
+			GpuMemPct:   r.GpuMemPct == "yes" || r.GpuMemPct == "true",
+		})
 	}
 	return info
 }
@@ -185,36 +309,34 @@ func readBackground() map[string]*config.NodeConfigRecord {
 }
 
 func parseFlags() {
-	dataDirStr := flag.String("data-dir", "", "Find sysinfo files in tree below `directory` (required)")
-	fromStr := flag.String("from", "1d", "Start `date` of log window, yyyy-mm-dd or Nd (days ago) or Nw (weeks ago)")
-	toStr := flag.String("to", "", "End `date` of log window, yyyy-mm-dd or Nd (days ago) or Nw (weeks ago)")
+	flag.StringVar(&sonalyzePath, "sonalyze", "sonalyze", "The sonalyze executable `filename`")
+	flag.StringVar(&dataDir, "data-dir", "", "Find sysinfo data in tree below `directory`")
+	flag.StringVar(&jobanalyzerDir, "jobanalyzer-dir", "", "Find sysinfo data via Jobanalyzer root `directory`")
+	flag.StringVar(&databaseURI, "database-uri", "", "Find sysinfo data in the database at `uri`")
+	flag.StringVar(&remote, "remote", "", "Find sysinfo data by querying the daemon at `url`")
+	flag.StringVar(&authFile, "auth-file", "", "Credentials for -remote in `filename`")
+	flag.StringVar(&from, "from", "1d", "Start `time` of log window, as for sonalyze")
+	flag.StringVar(&to, "to", "", "End `time` of log window, as for sonalyze")
 	backgroundStr := flag.String("background", "", "Find background data in `filename`")
 	nameStr := flag.String("name", "", "Canonical cluster `name` (required)")
 	descStr := flag.String("desc", "", "Cluster `description` (required)")
 	aliasStr := flag.String("alias", "", "Cluster `alias,alias,...`")
 	excludeStr := flag.String("exclude", "", "Exclude processes from `user,user,...`")
+	flag.BoolVar(&v3, "v3", false, "Produce the v3 format, with a timeline of configurations per host")
+	flag.StringVar(&diffFile, "diff", "", "Print the differences from the config in `filename` instead of the config")
 	flag.Parse()
 
-	var err error
-	if *dataDirStr == "" {
-		log.Fatal("-data-dir is required")
-	}
-	dataDir = path.Clean(*dataDirStr)
-	if *fromStr != "" {
-		from, err = ut.ParseRelativeDate(*fromStr)
-		if err != nil {
-			log.Fatalf("-from: %v", err)
+	sources := 0
+	for _, s := range []string{dataDir, jobanalyzerDir, databaseURI, remote} {
+		if s != "" {
+			sources++
 		}
-	} else {
-		from = ut.PreviousDay(time.Now())
 	}
-	if *toStr != "" {
-		to, err = ut.ParseRelativeDate(*toStr)
-		if err != nil {
-			log.Fatalf("-to: %v", err)
-		}
-	} else {
-		to = ut.NextDay(time.Now())
+	if sources != 1 {
+		log.Fatal("Exactly one of -data-dir, -jobanalyzer-dir, -database-uri and -remote is required")
+	}
+	if dataDir != "" {
+		dataDir = path.Clean(dataDir)
 	}
 	if *backgroundStr != "" {
 		backgroundDir = path.Clean(*backgroundStr)
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"go-utils/config"
+)
+
+func rec(timestamp string, cores int) *config.NodeConfigRecord {
+	return &config.NodeConfigRecord{
+		Timestamp: timestamp,
+		Hostname:  "a",
+		CpuCores:  cores,
+		MemGB:     16,
+	}
+}
+
+type period struct {
+	from, to string
+	cores    int
+}
+
+func periods(timeline []*config.NodeConfigRecord) []period {
+	ps := make([]period, 0)
+	for _, r := range timeline {
+		ps = append(ps, period{r.From, r.To, r.CpuCores})
+	}
+	return ps
+}
+
+func TestMakeTimeline(t *testing.T) {
+	// Unchanged consecutive configs collapse, and the last period is open-ended.
+	timeline := makeTimeline([]*config.NodeConfigRecord{
+		rec("2024-01-01T00:00:00Z", 8),
+		rec("2024-01-02T00:00:00Z", 8),
+		rec("2024-01-03T00:00:00Z", 16),
+		rec("2024-01-04T00:00:00Z", 16),
+		rec("2024-01-05T00:00:00Z", 8),
+	})
+	expect := []period{
+		{"", "2024-01-03T00:00:00Z", 8},
+		{"2024-01-03T00:00:00Z", "2024-01-05T00:00:00Z", 16},
+		{"2024-01-05T00:00:00Z", "", 8},
+	}
+	if got := periods(timeline); !slices.Equal(got, expect) {
+		t.Fatalf("Collapse: %v", got)
+	}
+
+	// Bad timestamps are dropped.
+	timeline = makeTimeline([]*config.NodeConfigRecord{
+		rec("2024-01-01T00:00:00Z", 8),
+		rec("yesterday", 16),
+	})
+	if got := periods(timeline); !slices.Equal(got, []period{{"", "", 8}}) {
+		t.Fatalf("Bad timestamp: %v", got)
+	}
+}
+
+func TestMakeTimelineSameSecond(t *testing.T) {
+	// A config starting in the same second as the previous one replaces it, also when the
+	// timestamps have different time zone offsets.
+	timeline := makeTimeline([]*config.NodeConfigRecord{
+		rec("2024-01-01T00:00:00Z", 8),
+		rec("2024-01-02T00:00:00Z", 16),
+		rec("2024-01-02T01:00:00+01:00", 32),
+	})
+	expect := []period{
+		{"", "2024-01-02T00:00:00Z", 8},
+		{"2024-01-02T00:00:00Z", "", 32},
+	}
+	if got := periods(timeline); !slices.Equal(got, expect) {
+		t.Fatalf("Replace: %v", got)
+	}
+
+	// If the replacement is the same as the config before the replaced one then they merge.
+	timeline = makeTimeline([]*config.NodeConfigRecord{
+		rec("2024-01-01T00:00:00Z", 8),
+		rec("2024-01-02T00:00:00Z", 16),
+		rec("2024-01-02T00:00:00Z", 8),
+		rec("2024-01-03T00:00:00Z", 8),
+	})
+	if got := periods(timeline); !slices.Equal(got, []period{{"", "", 8}}) {
+		t.Fatalf("Replace and merge: %v", got)
+	}
+
+	// A replacement of the first config keeps it open to the past.
+	timeline = makeTimeline([]*config.NodeConfigRecord{
+		rec("2024-01-01T00:00:00Z", 8),
+		rec("2024-01-01T00:00:00Z", 16),
+	})
+	if got := periods(timeline); !slices.Equal(got, []period{{"", "", 16}}) {
+		t.Fatalf("Replace first: %v", got)
+	}
+}
+
+func readConfig(t *testing.T, text string) *config.ClusterConfig {
+	cfg, err := config.ReadConfigFrom(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func diff(t *testing.T, oldText, newText string) []string {
+	var out strings.Builder
+	diffConfigs(&out, readConfig(t, oldText), readConfig(t, newText))
+	lines := strings.Split(out.String(), "\n")
+	return lines[:len(lines)-1]
+}
+
+func TestDiffV2(t *testing.T) {
+	got := diff(t,
+		`{"name":"x", "description":"X", "nodes":[
+{"hostname":"a", "description":"A", "cpu_cores":8, "mem_gb":16},
+{"hostname":"b", "description":"B", "cpu_cores":8, "mem_gb":16},
+{"hostname":"c", "description":"C", "cpu_cores":8, "mem_gb":16}]}`,
+		`{"name":"y", "description":"X", "nodes":[
+{"hostname":"a", "description":"A", "cpu_cores":8, "mem_gb":16},
+{"hostname":"b", "description":"B2", "cpu_cores":16, "mem_gb":16, "gpu_cards":2},
+{"hostname":"d", "description":"D", "cpu_cores":8, "mem_gb":16}]}`)
+	expect := []string{
+		`~ name "x" -> "y"`,
+		`~ b: description "B" -> "B2"`,
+		`~ b: cpu_cores 8 -> 16`,
+		`~ b: gpu_cards 0 -> 2`,
+		`- c: C`,
+		`+ d: D`,
+	}
+	if !slices.Equal(got, expect) {
+		t.Fatalf("Diff: %q", got)
+	}
+}
+
+func TestDiffV3(t *testing.T) {
+	got := diff(t,
+		`{"version":3, "nodes":[
+{"hostname":"a", "description":"A", "cpu_cores":8, "mem_gb":16, "to":"2024-02-01T00:00:00Z"},
+{"hostname":"a", "description":"A", "cpu_cores":16, "mem_gb":16, "from":"2024-02-01T00:00:00Z", "to":"2024-03-01T00:00:00Z"},
+{"hostname":"a", "description":"A", "cpu_cores":32, "mem_gb":16, "from":"2024-03-01T00:00:00Z"}]}`,
+		`{"version":3, "nodes":[
+{"hostname":"a", "description":"A", "cpu_cores":8, "mem_gb":16, "to":"2024-02-01T01:00:00+01:00"},
+{"hostname":"a", "description":"A", "cpu_cores":16, "mem_gb":32, "from":"2024-02-01T00:00:00Z", "to":"2024-04-01T00:00:00Z"},
+{"hostname":"a", "description":"A", "cpu_cores":32, "mem_gb":16, "from":"2024-04-01T00:00:00Z"}]}`)
+	// The first period ends at the same time, written differently, so it is unchanged.
+	expect := []string{
+		`~ a from 2024-02-01T00:00:00Z: to "2024-03-01T00:00:00Z" -> "2024-04-01T00:00:00Z"`,
+		`~ a from 2024-02-01T00:00:00Z: mem_gb 16 -> 32`,
+		`- a from 2024-03-01T00:00:00Z: A`,
+		`+ a from 2024-04-01T00:00:00Z: A`,
+	}
+	if !slices.Equal(got, expect) {
+		t.Fatalf("Diff: %q", got)
+	}
+}
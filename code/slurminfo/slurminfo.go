@@ -1,8 +1,13 @@
 // This runs `sinfo` on a cluster and processes the output to generate JSON describing the cluster.
 // The output is printed on stdout.
 //
-// Optionally, it can take its input from a file generated by `sinfo -a -o '%n/%f/%m/%X/%Y/%Z'`,
-// with the -input option.
+// By default, `sinfo -a -o '%n/%f/%m/%X/%Y/%Z'` is run.  With -json, `sinfo -a --json` is run, and
+// with -scontrol, `scontrol show node --json`.  The JSON output has the nodes' GRES, from which the
+// number and type of GPUs are taken (the GPU memory is not known to Slurm, so the GPU memory
+// reading is then preferred as a percentage, see go-utils/config).
+//
+// Optionally, it can take its input from a file generated by one of those commands, with the
+// -input option.  The format of the file is detected from its contents.
 //
 // Data not available from `sinfo` can optionally be provided by a file named by the -background
 // parameter.  This is a JSON file containing an array of NodeConfigRecords, where each field for a
@@ -19,6 +24,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -33,15 +39,30 @@ const (
 	header = "HOSTNAMES/AVAIL_FEATURES/MEMORY/SOCKETS/CORES/THREADS"
 )
 
+// What we know about a node from Slurm.  If haveGres is false then the GPU information is not known.
+type slurmNode struct {
+	name                    string
+	features                []string
+	memMB                   uint64
+	sockets, cores, threads int
+	haveGres                bool
+	gpus                    int
+	gpuModel                string
+}
+
 func main() {
 	var backgroundFilename string
 	var inputFilename string
+	var useSinfoJSON, useScontrol bool
 
 	flag.StringVar(&backgroundFilename, "background", "", "Background information in `filename`")
 	flag.StringVar(&inputFilename, "input", "", "Input in `filename`, don't run sinfo")
+	flag.BoolVar(&useSinfoJSON, "json", false, "Run `sinfo --json` to get GPU information too")
+	flag.BoolVar(&useScontrol, "scontrol", false, "Run `scontrol show node --json` to get GPU information too")
 	flag.Parse()
+	Assert(!(useSinfoJSON && useScontrol), "At most one of -json and -scontrol")
 
-	// Read sinfo data.
+	// Read Slurm data.
 
 	var stdout string
 	if inputFilename != "" {
@@ -51,13 +72,31 @@ func main() {
 	} else {
 		var err error
 		var stderr string
-		stdout, stderr, err = process.RunSubprocess("sinfo", "sinfo", []string{"-a", "-o", "%n/%f/%m/%X/%Y/%Z"})
-		Check(err, "Running 'sinfo'")
+		switch {
+		case useSinfoJSON:
+			stdout, stderr, err = process.RunSubprocess("sinfo", "sinfo", []string{"-a", "--json"})
+			Check(err, "Running 'sinfo'")
+		case useScontrol:
+			stdout, stderr, err = process.RunSubprocess("scontrol", "scontrol", []string{"show", "node", "--json"})
+			Check(err, "Running 'scontrol'")
+		default:
+			stdout, stderr, err = process.RunSubprocess("sinfo", "sinfo", []string{"-a", "-o", "%n/%f/%m/%X/%Y/%Z"})
+			Check(err, "Running 'sinfo'")
+		}
 		if stderr != "" {
 			fmt.Fprintln(os.Stderr, stderr)
 		}
 	}
 
+	var slurmNodes []*slurmNode
+	if strings.HasPrefix(strings.TrimSpace(stdout), "{") {
+		var err error
+		slurmNodes, err = parseJSON([]byte(stdout))
+		Check(err, "Parsing JSON input")
+	} else {
+		slurmNodes = parseText(stdout)
+	}
+
 	// Read background information.  `referenced` will be used to track whether an entry is used for
 	// background information or entirely.
 
@@ -82,29 +121,10 @@ func main() {
 
 	var systems = make(map[sysAttrs][]string)
 
-	inputLines := strings.Split(strings.TrimSpace(stdout), "\n")
-	Assert(len(inputLines) > 0, "Empty input")
-	Assert(inputLines[0] == header, "Bad header in input: "+inputLines[0])
-	inputLines = inputLines[1:]
-
-	for _, l := range inputLines {
-		fields := strings.Split(l, "/")
-		Assert(len(fields) == 6, "Bad fields in input line: "+l)
-		name := fields[0]
-		features := make(map[string]bool)
-		for _, f := range strings.Split(fields[1], ",") {
-			features[f] = true
-		}
-		mem, err := strconv.ParseUint(fields[2], 10, 64)
-		Check(err, fields[2])
-		sockets, err := strconv.ParseUint(fields[3], 10, 64)
-		Check(err, fields[3])
-		cores, err := strconv.ParseUint(fields[4], 10, 64)
-		Check(err, fields[4])
-		threads, err := strconv.ParseUint(fields[5], 10, 64)
-		Check(err, fields[5])
+	for _, node := range slurmNodes {
+		name := node.name
 		manufacturer := "intel"
-		if _, found := features["amd"]; found {
+		if slices.Contains(node.features, "amd") {
 			manufacturer = "amd"
 		}
 		var (
@@ -115,13 +135,25 @@ func main() {
 			gpuModel      string
 			suffix        string
 		)
+		if node.haveGres && node.gpus > 0 {
+			gpus = node.gpus
+			gpuModel = node.gpuModel
+			gpuMemPct = true
+		}
 		if background, found := backgroundConfig[name]; found {
 			crossNodeJobs = background.CrossNodeJobs
-			gpus = background.GpuCards
-			gpuMemGB = background.GpuMemGB
-			gpuMemPct = background.GpuMemPct
-			if gpus > 0 {
-				gpuModel = "unknown-gpu"
+			if background.GpuCards > 0 {
+				// The background is more precise than the GRES, if there is GRES information.
+				gpus = background.GpuCards
+				gpuMemGB = background.GpuMemGB
+				gpuMemPct = background.GpuMemPct
+				if gpuModel == "" {
+					gpuModel = "unknown-gpu"
+				}
+			} else if !node.haveGres {
+				gpus = background.GpuCards
+				gpuMemGB = background.GpuMemGB
+				gpuMemPct = background.GpuMemPct
 			}
 			for _, m := range background.Metadata {
 				switch m.Key {
@@ -134,13 +166,17 @@ func main() {
 			referenced[name] = true
 		}
 		if (strings.HasPrefix(name, "gpu") || strings.HasPrefix(name, "accel")) && gpus == 0 {
-			fmt.Fprintf(os.Stderr, "WARNING: Host name '%s' suggests a GPU node but no GPU info is found in background data\n", name)
+			if node.haveGres {
+				fmt.Fprintf(os.Stderr, "WARNING: Host name '%s' suggests a GPU node but it has no GPU GRES\n", name)
+			} else {
+				fmt.Fprintf(os.Stderr, "WARNING: Host name '%s' suggests a GPU node but no GPU info is found in background data\n", name)
+			}
 		}
 		sd := sysAttrs{
-			memMB:        mem,
-			sockets:      int(sockets),
-			cores:        int(cores),
-			threads:      int(threads),
+			memMB:        node.memMB,
+			sockets:      node.sockets,
+			cores:        node.cores,
+			threads:      node.threads,
 			gpus:         gpus,
 			gpuMemGB:     gpuMemGB,
 			manufacturer: manufacturer,
@@ -168,8 +204,10 @@ func main() {
 			}
 			memgb := desc.memMB / 1024
 			gpu := ""
-			if desc.gpus > 0 {
+			if desc.gpus > 0 && desc.gpuMemGB > 0 {
 				gpu = fmt.Sprintf(", %dx %s @ %dGB", desc.gpus, desc.gpuModel, desc.gpuMemGB/desc.gpus)
+			} else if desc.gpus > 0 {
+				gpu = fmt.Sprintf(", %dx %s", desc.gpus, desc.gpuModel)
 			}
 			description := fmt.Sprintf(
 				"%dx%d %s%s, %dGB%s",
@@ -198,6 +236,10 @@ func main() {
 	// We would have preferred to do this earlier so that the information could be (re)compressed, but
 	// that turns out to require some reengineering I'm not prepared to do yet.
 
+	known := make(map[string]bool)
+	for _, n := range nodes {
+		known[n.Hostname] = true
+	}
 	for name, background := range backgroundConfig {
 		if _, found := referenced[name]; !found {
 			if background.CpuCores == 0 || background.MemGB == 0 {
@@ -209,12 +251,18 @@ func main() {
 					background.Hostname += m.Value
 				}
 			}
+			// With a host suffix the background entry can name a host that sinfo also reported.
+			if known[background.Hostname] {
+				fmt.Fprintf(os.Stderr, "WARNING: Background host '%s' is already known, ignoring it\n", background.Hostname)
+				continue
+			}
 			background.Metadata = nil
 			nodes = append(nodes, background)
+			known[background.Hostname] = true
 		}
 	}
 
-	results := config.NewClusterConfig(
+	results, err := config.NewClusterConfig(
 		0,
 		"",
 		"",
@@ -222,8 +270,41 @@ func main() {
 		[]string{},
 		nodes,
 	)
+	Check(err, "Building config")
 
 	// Save the config.  It is JSON text and is just dumped on stdout.
 
 	config.WriteConfigTo(os.Stdout, results)
 }
+
+// Parse the output of `sinfo -a -o '%n/%f/%m/%X/%Y/%Z'`.
+
+func parseText(stdout string) []*slurmNode {
+	inputLines := strings.Split(strings.TrimSpace(stdout), "\n")
+	Assert(len(inputLines) > 0, "Empty input")
+	Assert(inputLines[0] == header, "Bad header in input: "+inputLines[0])
+	inputLines = inputLines[1:]
+
+	nodes := make([]*slurmNode, 0)
+	for _, l := range inputLines {
+		fields := strings.Split(l, "/")
+		Assert(len(fields) == 6, "Bad fields in input line: "+l)
+		mem, err := strconv.ParseUint(fields[2], 10, 64)
+		Check(err, fields[2])
+		sockets, err := strconv.ParseUint(fields[3], 10, 64)
+		Check(err, fields[3])
+		cores, err := strconv.ParseUint(fields[4], 10, 64)
+		Check(err, fields[4])
+		threads, err := strconv.ParseUint(fields[5], 10, 64)
+		Check(err, fields[5])
+		nodes = append(nodes, &slurmNode{
+			name:     fields[0],
+			features: strings.Split(fields[1], ","),
+			memMB:    mem,
+			sockets:  int(sockets),
+			cores:    int(cores),
+			threads:  int(threads),
+		})
+	}
+	return nodes
+}
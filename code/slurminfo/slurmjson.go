@@ -0,0 +1,223 @@
+// Parsing of the JSON output of `sinfo --json` and `scontrol show node --json`, and of GRES strings.
+//
+// The JSON formats vary a little between Slurm versions.  We only use fields that have been stable
+// since 22.05, and accept both the old string form and the newer array form of the feature list.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// `sinfo --json`: one record per group of similar nodes per partition, so a node may appear more
+// than once.
+type sinfoOutput struct {
+	Sinfo []struct {
+		Nodes struct {
+			Nodes     []string `json:"nodes"`
+			Hostnames []string `json:"hostnames"`
+		} `json:"nodes"`
+		Features struct {
+			Total string `json:"total"`
+		} `json:"features"`
+		Memory  minMax `json:"memory"`
+		Sockets minMax `json:"sockets"`
+		Cores   minMax `json:"cores"`
+		Threads minMax `json:"threads"`
+		Gres    struct {
+			Total string `json:"total"`
+		} `json:"gres"`
+	} `json:"sinfo"`
+}
+
+type minMax struct {
+	Minimum uint64 `json:"minimum"`
+	Maximum uint64 `json:"maximum"`
+}
+
+// `scontrol show node --json`: one record per node.
+type scontrolOutput struct {
+	Nodes []struct {
+		Name       string          `json:"name"`
+		Features   json.RawMessage `json:"features"`
+		RealMemory uint64          `json:"real_memory"`
+		Sockets    uint64          `json:"sockets"`
+		Cores      uint64          `json:"cores"`
+		Threads    uint64          `json:"threads"`
+		Gres       string          `json:"gres"`
+	} `json:"nodes"`
+}
+
+// Parse the output of either command, the format is detected from the top-level field.
+
+func parseJSON(input []byte) ([]*slurmNode, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(input, &probe); err != nil {
+		return nil, err
+	}
+	if _, found := probe["sinfo"]; found {
+		return parseSinfoJSON(input)
+	}
+	if _, found := probe["nodes"]; found {
+		return parseScontrolJSON(input)
+	}
+	return nil, errors.New("Neither sinfo nor scontrol JSON output")
+}
+
+func parseSinfoJSON(input []byte) ([]*slurmNode, error) {
+	var data sinfoOutput
+	if err := json.Unmarshal(input, &data); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	nodes := make([]*slurmNode, 0)
+	for _, r := range data.Sinfo {
+		gpus, gpuModel, err := parseGres(r.Gres.Total)
+		if err != nil {
+			return nil, err
+		}
+		names := r.Nodes.Nodes
+		if len(names) == 0 {
+			names = r.Nodes.Hostnames
+		}
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			nodes = append(nodes, &slurmNode{
+				name:     name,
+				features: splitFeatures(r.Features.Total),
+				memMB:    r.Memory.Maximum,
+				sockets:  int(r.Sockets.Maximum),
+				cores:    int(r.Cores.Maximum),
+				threads:  int(r.Threads.Maximum),
+				haveGres: true,
+				gpus:     gpus,
+				gpuModel: gpuModel,
+			})
+		}
+	}
+	return nodes, nil
+}
+
+func parseScontrolJSON(input []byte) ([]*slurmNode, error) {
+	var data scontrolOutput
+	if err := json.Unmarshal(input, &data); err != nil {
+		return nil, err
+	}
+	nodes := make([]*slurmNode, 0)
+	for _, r := range data.Nodes {
+		gpus, gpuModel, err := parseGres(r.Gres)
+		if err != nil {
+			return nil, err
+		}
+		var features []string
+		if len(r.Features) > 0 {
+			var s string
+			if err := json.Unmarshal(r.Features, &s); err == nil {
+				features = splitFeatures(s)
+			} else if err := json.Unmarshal(r.Features, &features); err != nil {
+				return nil, fmt.Errorf("Bad features for %s: %w", r.Name, err)
+			}
+		}
+		nodes = append(nodes, &slurmNode{
+			name:     r.Name,
+			features: features,
+			memMB:    r.RealMemory,
+			sockets:  int(r.Sockets),
+			cores:    int(r.Cores),
+			threads:  int(r.Threads),
+			haveGres: true,
+			gpus:     gpus,
+			gpuModel: gpuModel,
+		})
+	}
+	return nodes, nil
+}
+
+func splitFeatures(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// A GRES string is a comma-separated list of name[:type][:count][(extra)], eg
+// "gpu:a100:4(S:0-1),shard:8".  The count defaults to 1.  Only the gpu resources are of interest, and
+// the model is the GPU type, or several types joined by "+", or "unknown-gpu" if there is no type.
+// "(null)" and "" mean no resources.
+
+func parseGres(s string) (gpus int, model string, err error) {
+	if s == "" || s == "(null)" {
+		return
+	}
+	var models []string
+	var untyped bool
+	for _, item := range splitGres(s) {
+		if i := strings.IndexByte(item, '('); i != -1 {
+			item = item[:i]
+		}
+		parts := strings.Split(item, ":")
+		if parts[0] != "gpu" {
+			continue
+		}
+		count := 1
+		if len(parts) > 1 {
+			if n, e := strconv.Atoi(parts[len(parts)-1]); e == nil {
+				count = n
+				parts = parts[:len(parts)-1]
+			}
+		}
+		if count < 0 {
+			return 0, "", fmt.Errorf("Bad GRES count in %s", s)
+		}
+		// Skip flags, what's left is the type, if any.
+		var ty string
+		for _, p := range parts[1:] {
+			if p != "no_consume" {
+				ty = p
+				break
+			}
+		}
+		if ty == "" {
+			untyped = true
+		} else if !slices.Contains(models, ty) {
+			models = append(models, ty)
+		}
+		gpus += count
+	}
+	if gpus > 0 {
+		if untyped || len(models) == 0 {
+			models = append(models, "unknown-gpu")
+		}
+		model = strings.Join(models, "+")
+	}
+	return
+}
+
+// Split at commas that are not inside parentheses, "(S:0,1)" is a socket list.
+
+func splitGres(s string) []string {
+	items := make([]string, 0)
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(items, s[start:])
+}
@@ -6,10 +6,18 @@ import (
 
 	"sonalyze/cmd"
 	"sonalyze/cmd/cards"
+	"sonalyze/cmd/cardusage"
 	"sonalyze/cmd/clusters"
+	"sonalyze/cmd/compare"
 	"sonalyze/cmd/configs"
+	"sonalyze/cmd/coverage"
 	"sonalyze/cmd/diskprof"
+	"sonalyze/cmd/drift"
+	"sonalyze/cmd/efficiency"
+	"sonalyze/cmd/energy"
+	"sonalyze/cmd/fsck"
 	"sonalyze/cmd/gpus"
+	"sonalyze/cmd/idle"
 	"sonalyze/cmd/jobs"
 	"sonalyze/cmd/load"
 	"sonalyze/cmd/metadata"
@@ -24,32 +32,42 @@ import (
 	"sonalyze/cmd/top"
 	"sonalyze/cmd/uptime"
 	"sonalyze/cmd/version"
+	"sonalyze/cmd/waittime"
 )
 
 // TODO: Group these, probably.
 
 func CommandHelp(out io.Writer) {
 	// Keep these alphabetical.
-	fmt.Fprintf(out, "  card     - print card information extracted from sysinfo table\n")
-	fmt.Fprintf(out, "  cluster  - print cluster information\n")
-	fmt.Fprintf(out, "  config   - print node information extracted from cluster config\n")
-	fmt.Fprintf(out, "  diskprof - print disk profile information extracted from sample table\n")
-	fmt.Fprintf(out, "  gpu      - print per-gpu load information data across time\n")
-	fmt.Fprintf(out, "  jobs     - summarize and filter jobs\n")
-	fmt.Fprintf(out, "  load     - print system load across time\n")
-	fmt.Fprintf(out, "  metadata - parse data, print stats and metadata\n")
-	fmt.Fprintf(out, "  node     - print node information extracted from sysinfo table\n")
-	fmt.Fprintf(out, "  nodeprof - print node profile information extracted from sample table\n")
-	fmt.Fprintf(out, "  profile  - print the profile of a particular job\n")
-	fmt.Fprintf(out, "  report   - print a precomputed report\n")
-	fmt.Fprintf(out, "  sacct    - print job information extracted from Slurm sacct data\n")
-	fmt.Fprintf(out, "  sample   - print sonar sample information (aka `parse`)\n")
-	fmt.Fprintf(out, "  snode    - print node information extracted from Slurm sinfo data\n")
-	fmt.Fprintf(out, "  spart    - print partition information extracted from Slurm sinfo data\n")
-	fmt.Fprintf(out, "  top      - print per-cpu load information across time\n")
-	fmt.Fprintf(out, "  uptime   - print aggregated information about system uptime\n")
-	fmt.Fprintf(out, "  version  - print information about the program (or the server, with -remote)\n")
-	fmt.Fprintf(out, "  help     - print this message\n")
+	fmt.Fprintf(out, "  card       - print card information extracted from sysinfo table\n")
+	fmt.Fprintf(out, "  cardusage  - print intervals of which job used which GPU card\n")
+	fmt.Fprintf(out, "  cluster    - print cluster information\n")
+	fmt.Fprintf(out, "  compare    - compare the profiles of two jobs over elapsed time\n")
+	fmt.Fprintf(out, "  config     - print node information extracted from cluster config\n")
+	fmt.Fprintf(out, "  coverage   - print per-day record counts and gaps per host and data type\n")
+	fmt.Fprintf(out, "  diskprof   - print disk profile information extracted from sample table\n")
+	fmt.Fprintf(out, "  drift      - print changes in node and card configuration over time\n")
+	fmt.Fprintf(out, "  efficiency - compare requested and used resources for Slurm jobs\n")
+	fmt.Fprintf(out, "  energy     - attribute GPU energy to jobs, users, and accounts\n")
+	fmt.Fprintf(out, "  fsck       - check the integrity of a directory tree data store\n")
+	fmt.Fprintf(out, "  gpu        - print per-gpu load information data across time\n")
+	fmt.Fprintf(out, "  idle       - find still-running jobs that have made no progress for a while\n")
+	fmt.Fprintf(out, "  jobs       - summarize and filter jobs\n")
+	fmt.Fprintf(out, "  load       - print system load across time\n")
+	fmt.Fprintf(out, "  metadata   - parse data, print stats and metadata\n")
+	fmt.Fprintf(out, "  node       - print node information extracted from sysinfo table\n")
+	fmt.Fprintf(out, "  nodeprof   - print node profile information extracted from sample table\n")
+	fmt.Fprintf(out, "  profile    - print the profile of a particular job\n")
+	fmt.Fprintf(out, "  report     - print a precomputed report\n")
+	fmt.Fprintf(out, "  sacct      - print job information extracted from Slurm sacct data\n")
+	fmt.Fprintf(out, "  sample     - print sonar sample information (aka `parse`)\n")
+	fmt.Fprintf(out, "  snode      - print node information extracted from Slurm sinfo data\n")
+	fmt.Fprintf(out, "  spart      - print partition information extracted from Slurm sinfo data\n")
+	fmt.Fprintf(out, "  top        - print per-cpu load information across time\n")
+	fmt.Fprintf(out, "  uptime     - print aggregated information about system uptime\n")
+	fmt.Fprintf(out, "  version    - print information about the program (or the server, with -remote)\n")
+	fmt.Fprintf(out, "  waittime   - print queue wait time distributions from Slurm sacct data\n")
+	fmt.Fprintf(out, "  help       - print this message\n")
 }
 
 // Keep these alphabetical
@@ -59,14 +77,30 @@ func ConstructCommand(verb string) (command cmd.Command, actualVerb string) {
 	switch verb {
 	case "card":
 		command = new(cards.CardCommand)
+	case "cardusage":
+		command = new(cardusage.CardUsageCommand)
 	case "cluster":
 		command = new(clusters.ClusterCommand)
+	case "compare":
+		command = new(compare.CompareCommand)
 	case "config":
 		command = new(configs.ConfigCommand)
+	case "coverage":
+		command = new(coverage.CoverageCommand)
 	case "diskprof":
 		command = new(diskprof.DiskProfCommand)
+	case "drift":
+		command = new(drift.DriftCommand)
+	case "efficiency":
+		command = new(efficiency.EfficiencyCommand)
+	case "energy":
+		command = new(energy.EnergyCommand)
+	case "fsck":
+		command = new(fsck.FsckCommand)
 	case "gpu":
 		command = new(gpus.GpuCommand)
+	case "idle":
+		command = new(idle.IdleCommand)
 	case "jobs":
 		command = new(jobs.JobsCommand)
 	case "load":
@@ -97,6 +131,8 @@ func ConstructCommand(verb string) (command cmd.Command, actualVerb string) {
 		command = new(uptime.UptimeCommand)
 	case "version":
 		command = new(version.VersionCommand)
+	case "waittime":
+		command = new(waittime.WaittimeCommand)
 	}
 	actualVerb = verb
 	return
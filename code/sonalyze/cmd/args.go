@@ -119,6 +119,7 @@ func (va *VerboseArgs) Validate() error {
 type DatabaseArgs struct {
 	// Shared arguments
 	clusterName string
+	timeZone    string
 
 	// Remote arguments
 	remoteHost string
@@ -133,10 +134,14 @@ type DatabaseArgs struct {
 	configFile     string
 	logFiles       []string
 	cacheSize      int64
+	diskCacheDir   string
 
 	// Creation options, sometimes used during validation
 	options DBArgOptions
 
+	// The time zone for input and output, from -tz or the cluster configuration; nil for UTC
+	location *time.Location
+
 	// Temporary
 	cache string
 }
@@ -199,6 +204,10 @@ func (db *DatabaseArgs) CacheSize() int64 {
 	return db.cacheSize
 }
 
+func (db *DatabaseArgs) DiskCacheDir() string {
+	return db.diskCacheDir
+}
+
 func (db *DatabaseArgs) ClusterName() string {
 	return db.clusterName
 }
@@ -219,6 +228,24 @@ func (db *DatabaseArgs) Dataless() bool {
 	return db.options.NoDatabase
 }
 
+// The -tz argument, if any.
+func (db *DatabaseArgs) TimeZoneName() string {
+	return db.timeZone
+}
+
+// The time zone for interpreting and printing times, never nil.
+func (db *DatabaseArgs) TimeZone() *time.Location {
+	if db.location == nil {
+		return time.UTC
+	}
+	return db.location
+}
+
+func (db *DatabaseArgs) SetTimeZone(loc *time.Location) error {
+	db.location = loc
+	return nil
+}
+
 type DBArgOptions struct {
 	// Require -jobanalyzer-dir (typically for the daemon) and disable all other data source
 	// options.
@@ -237,11 +264,14 @@ type DBArgOptions struct {
 func (db *DatabaseArgs) Add(fs *CLI, opts DBArgOptions) {
 	db.options = opts
 
+	fs.Group("application-control")
 	if !opts.OmitCluster {
-		fs.Group("application-control")
 		fs.StringVar(&db.clusterName, "cluster", "",
 			"Select the cluster `name` for the operation is targeting [default: none].")
 	}
+	fs.StringVar(&db.timeZone, "tz", "",
+		"Interpret and print times in this time `zone`, eg Europe/Oslo or Local [default: the\n"+
+			"cluster's configured time zone, or UTC]")
 
 	if !opts.RequireFullDatabase {
 		fs.Group("remote-data-source")
@@ -257,7 +287,8 @@ func (db *DatabaseArgs) Add(fs *CLI, opts DBArgOptions) {
 	fs.StringVar(&db.jobanalyzerDir, "jobanalyzer-dir", "",
 		"Jobanalyzer root `directory`, precludes all other local data source arguments.")
 	fs.StringVar(&db.databaseUri, "database-uri", "",
-		"Data store external to Jobanalyzer root `directory`.")
+		"Data store external to Jobanalyzer root directory: a TimescaleDB `uri`, or sqlite:///path\n"+
+			"for an SQLite database file.")
 	if !opts.RequireFullDatabase {
 		fs.StringVar(&db.dataDir, "data-dir", "",
 			"Select the root `directory` for log files [default: none]")
@@ -272,6 +303,8 @@ func (db *DatabaseArgs) Add(fs *CLI, opts DBArgOptions) {
 	}
 
 	fs.StringVar(&db.cache, "cache", "", "Enable data caching with this size (nM for megs, nG for gigs)")
+	fs.StringVar(&db.diskCacheDir, "disk-cache", "",
+		"Enable caching of parsed data in this `directory`, which persists across runs [default: none]")
 }
 
 func (db *DatabaseArgs) SetRestArguments(args []string) {
@@ -375,7 +408,7 @@ func (db *DatabaseArgs) Validate() error {
 		ApplyDefault(&db.databaseUri, DataSourceDatabaseUri)
 	}
 
-	var e1, e2, e3, e4, e5, e6, e7 error
+	var e1, e2, e3, e4, e5, e6, e7, e8 error
 
 	// Clean all local names and check that they exist, for better error reporting.
 	if db.jobanalyzerDir != "" {
@@ -430,7 +463,20 @@ func (db *DatabaseArgs) Validate() error {
 		}
 	}
 
-	return errors.Join(e1, e2, e3, e4, e5, e6, e7)
+	e8 = db.validateTimeZone()
+
+	return errors.Join(e1, e2, e3, e4, e5, e6, e7, e8)
+}
+
+func (db *DatabaseArgs) validateTimeZone() error {
+	if db.timeZone != "" {
+		loc, err := time.LoadLocation(db.timeZone)
+		if err != nil {
+			return fmt.Errorf("Bad -tz value %s", db.timeZone)
+		}
+		db.location = loc
+	}
+	return nil
 }
 
 func (db *DatabaseArgs) ReifyForRemote(x *ArgReifier) error {
@@ -438,6 +484,7 @@ func (db *DatabaseArgs) ReifyForRemote(x *ArgReifier) error {
 	// Cluster, and AuthFile are consistent for remote and local execution.  None of those except
 	// Cluster is forwarded for remote execution.
 	x.String("cluster", db.clusterName)
+	x.String("tz", db.timeZone)
 	return nil
 }
 
@@ -472,15 +519,17 @@ func (s *SourceArgs) Add(fs *CLI, defaultDelta uint) {
 	}
 	fs.StringVar(&s.FromDateStr, "from", "",
 		fmt.Sprintf(
-			"Select records by this `time` and later.  Format can be YYYY-MM-DD, or Nd or Nw\n"+
-				"signifying N days or weeks ago [default: %dd, ie %s ago]",
+			"Select records by this `time` and later.  Format can be YYYY-MM-DD, YYYY-MM-DDTHH:MM\n"+
+				"with optional offset, Nw, Nd, Nh or Nm (or eg 2d3h) signifying that long ago, now-2d3h,\n"+
+				"or today, yesterday, this-week, last-week, this-month, last-month, optionally\n"+
+				"followed by an offset (eg today-2d) [default: %dd, ie %s ago]",
 			defaultDelta,
 			delta,
 		))
 	fs.StringVar(&s.FromDateStr, "f", "", "Short for -from `time`")
 	fs.StringVar(&s.ToDateStr, "to", "",
-		"Select records by this `time` and earlier.  Format can be YYYY-MM-DD, or Nd or Nw\n"+
-			"signifying N days or weeks ago [default: now]")
+		"Select records by this `time` and earlier.  Format as for -from; a date or a calendar\n"+
+			"name means the end of that day or period [default: now]")
 	fs.StringVar(&s.ToDateStr, "t", "", "Short for -to `time`")
 }
 
@@ -498,10 +547,23 @@ func (s *SourceArgs) Validate() error {
 	ApplyDefault(&s.FromDateStr, DataSourceFrom)
 	ApplyDefault(&s.ToDateStr, DataSourceTo)
 
+	// The time zone is needed to interpret the dates; it may be changed later by SetTimeZone.
+	if err := s.validateTimeZone(); err != nil {
+		return err
+	}
+	if err := s.parseFromTo(); err != nil {
+		return err
+	}
+
+	return s.DatabaseArgs.Validate()
+}
+
+func (s *SourceArgs) parseFromTo() error {
 	now := time.Now().UTC()
+	loc := s.TimeZone()
 	if s.FromDateStr != "" {
 		var err error
-		s.FromDate, err = ParseRelativeDateUtc(now, s.FromDateStr, false)
+		s.FromDate, err = ParseRelativeDate(now, s.FromDateStr, false, loc)
 		if err != nil {
 			return fmt.Errorf("Invalid -from argument %s", s.FromDateStr)
 		}
@@ -513,7 +575,7 @@ func (s *SourceArgs) Validate() error {
 
 	if s.ToDateStr != "" {
 		var err error
-		s.ToDate, err = ParseRelativeDateUtc(now, s.ToDateStr, true)
+		s.ToDate, err = ParseRelativeDate(now, s.ToDateStr, true, loc)
 		if err != nil {
 			return fmt.Errorf("Invalid -to argument %s", s.ToDateStr)
 		}
@@ -526,8 +588,14 @@ func (s *SourceArgs) Validate() error {
 	if s.FromDate.After(s.ToDate) {
 		return errors.New("The -from time is greater than the -to time")
 	}
+	return nil
+}
 
-	return s.DatabaseArgs.Validate()
+// Changing the time zone changes the meaning of the -from and -to dates.
+
+func (s *SourceArgs) SetTimeZone(loc *time.Location) error {
+	s.DatabaseArgs.SetTimeZone(loc)
+	return s.parseFromTo()
 }
 
 // Grab FromDate and ToDate from args if available, otherwise infer from the bounds, otherwise use
@@ -567,7 +635,8 @@ type HostArgs struct {
 func (h *HostArgs) Add(fs *CLI) {
 	fs.Group("record-filter")
 	fs.Var(NewRepeatableSemiSeparated(&h.HostStrings), "host",
-		"Select records for this `host` (repeatable) [default: all]")
+		"Select records for this `host` (repeatable), !pattern excludes, @group names a node group\n"+
+			"from the cluster config [default: all]")
 }
 
 func (h *HostArgs) ReifyForRemote(x *ArgReifier) error {
@@ -696,6 +765,12 @@ func (fa *FormatArgs) ReifyForRemote(x *ArgReifier) error {
 	return nil
 }
 
+func (fa *FormatArgs) SetPrintTimeZone(loc *time.Location) {
+	if fa.PrintOpts != nil {
+		fa.PrintOpts.Location = loc
+	}
+}
+
 func ValidateFormatArgs[T any](
 	fa *FormatArgs,
 	defaultFields string,
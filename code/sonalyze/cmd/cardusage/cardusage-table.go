@@ -0,0 +1,292 @@
+// DO NOT EDIT.  Generated from cardusage.go by generate-table
+
+package cardusage
+
+import (
+	"cmp"
+	"fmt"
+	"go-utils/gpuset"
+	"io"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+var (
+	_ = cmp.Compare(0, 0)
+	_ fmt.Formatter
+	_ = io.SeekStart
+	_ = UstrEmpty
+	_ gpuset.GpuSet
+)
+
+// MT: Constant after initialization; immutable
+var cardusageFormatters = map[string]Formatter[*usageInterval]{
+	"Start": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatDateTimeValue((d.Start), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.Start
+		},
+		Help: "(DateTimeValue) Time of the first sample of the job on the card in the interval",
+	},
+	"End": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatDateTimeValue((d.End), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.End
+		},
+		Help: "(DateTimeValue) Time of the last sample of the job on the card in the interval",
+	},
+	"Duration": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatDurationValue((d.Duration), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.Duration
+		},
+		Help: "(DurationValue) Length of the interval",
+	},
+	"Hostname": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatUstr((d.Hostname), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.Hostname
+		},
+		Help: "(string) Name of the node holding the card",
+	},
+	"UUID": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatString((d.UUID), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.UUID
+		},
+		Help: "(string) Card's unique identifier, blank if not known",
+	},
+	"Index": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatInt((d.Index), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.Index
+		},
+		Help: "(int) Card's index on its node",
+	},
+	"Model": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatString((d.Model), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.Model
+		},
+		Help: "(string) Card model, blank if not known",
+	},
+	"Job": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatUint32((d.Job), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.Job
+		},
+		Help: "(uint32) Job ID",
+	},
+	"User": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatUstr((d.User), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.User
+		},
+		Help: "(string) Owner of the job",
+	},
+	"GpuAvgPct": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatF64Ceil((d.GpuAvgPct), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.GpuAvgPct
+		},
+		Help: "(int) Average utilization of the card in percent during the interval",
+	},
+	"GpuPeakPct": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatF64Ceil((d.GpuPeakPct), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.GpuPeakPct
+		},
+		Help: "(int) Peak utilization of the card in percent during the interval",
+	},
+	"GpuMemPeakGB": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatF64Ceil((d.GpuMemPeakGB), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.GpuMemPeakGB
+		},
+		Help: "(int) Peak memory in use on the card in GB during the interval",
+	},
+	"Failing": {
+		Fmt: func(d *usageInterval, ctx PrintMods) string {
+			return FormatInt((d.Failing), ctx)
+		},
+		Xtract: func(d *usageInterval) any {
+			return d.Failing
+		},
+		Help: "(int) Nonzero if the card reported a failure during the interval",
+	},
+}
+
+func init() {
+	DefAlias(cardusageFormatters, "Start", "start")
+	DefAlias(cardusageFormatters, "End", "end")
+	DefAlias(cardusageFormatters, "Duration", "duration")
+	DefAlias(cardusageFormatters, "Hostname", "host")
+	DefAlias(cardusageFormatters, "UUID", "uuid")
+	DefAlias(cardusageFormatters, "Index", "index")
+	DefAlias(cardusageFormatters, "Model", "model")
+	DefAlias(cardusageFormatters, "Job", "job")
+	DefAlias(cardusageFormatters, "User", "user")
+	DefAlias(cardusageFormatters, "GpuAvgPct", "gpu-avg")
+	DefAlias(cardusageFormatters, "GpuPeakPct", "gpu-peak")
+	DefAlias(cardusageFormatters, "GpuMemPeakGB", "gpumem-peak")
+	DefAlias(cardusageFormatters, "Failing", "failing")
+}
+
+// MT: Constant after initialization; immutable
+var cardusagePredicates = map[string]Predicate[*usageInterval]{
+	"Start": Predicate[*usageInterval]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.Start), v.(DateTimeValue))
+		},
+	},
+	"End": Predicate[*usageInterval]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.End), v.(DateTimeValue))
+		},
+	},
+	"Duration": Predicate[*usageInterval]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.Duration), v.(DurationValue))
+		},
+	},
+	"Hostname": Predicate[*usageInterval]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.Hostname), v.(Ustr))
+		},
+	},
+	"UUID": Predicate[*usageInterval]{
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.UUID), v.(string))
+		},
+	},
+	"Index": Predicate[*usageInterval]{
+		Convert: CvtString2Int,
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.Index), v.(int))
+		},
+	},
+	"Model": Predicate[*usageInterval]{
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.Model), v.(string))
+		},
+	},
+	"Job": Predicate[*usageInterval]{
+		Convert: CvtString2Uint32,
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.Job), v.(uint32))
+		},
+	},
+	"User": Predicate[*usageInterval]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.User), v.(Ustr))
+		},
+	},
+	"GpuAvgPct": Predicate[*usageInterval]{
+		Convert: CvtString2Float64,
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.GpuAvgPct), v.(F64Ceil))
+		},
+	},
+	"GpuPeakPct": Predicate[*usageInterval]{
+		Convert: CvtString2Float64,
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.GpuPeakPct), v.(F64Ceil))
+		},
+	},
+	"GpuMemPeakGB": Predicate[*usageInterval]{
+		Convert: CvtString2Float64,
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.GpuMemPeakGB), v.(F64Ceil))
+		},
+	},
+	"Failing": Predicate[*usageInterval]{
+		Convert: CvtString2Int,
+		Compare: func(d *usageInterval, v any) int {
+			return cmp.Compare((d.Failing), v.(int))
+		},
+	},
+}
+
+type usageInterval struct {
+	Start        DateTimeValue
+	End          DateTimeValue
+	Duration     DurationValue
+	Hostname     Ustr
+	UUID         string
+	Index        int
+	Model        string
+	Job          uint32
+	User         Ustr
+	GpuAvgPct    F64Ceil
+	GpuPeakPct   F64Ceil
+	GpuMemPeakGB F64Ceil
+	Failing      int
+}
+
+func (c *CardUsageCommand) Summary(out io.Writer) {
+	fmt.Fprint(out, `Experimental: Print the intervals during which jobs used GPU cards.
+
+The process samples record which cards a job's processes were using.  For
+each card on each node, the job's consecutive samples on the card are joined
+into an interval, which is broken where there are no samples for the job on
+the card for longer than the -gap.  The utilization, memory and failure state
+of the card during the interval are taken from the per-card GPU samples, and
+the card's UUID and model from the GPU samples and the system information.
+
+The utilization and memory are for the card as a whole: if several jobs share a
+card, their intervals overlap and they all see the same card figures.
+
+Use this to find out who was on a card at some time (eg -host gpu-7 -q
+'Index = 3'), for chargeback, or to find the jobs that were running on a
+failing card.
+`)
+}
+
+const cardusageHelp = `
+cardusage
+  Print intervals of (host, card, job, user, utilization).  Output records are
+  sorted by host, card index, and start time.  The default format is 'fixed'.
+`
+
+func (c *CardUsageCommand) MaybeFormatHelp() *FormatHelp {
+	return StandardFormatHelp(c.Fmt, cardusageHelp, cardusageFormatters, cardusageAliases, cardusageDefaultFields)
+}
+
+// MT: Constant after initialization; immutable
+var cardusageAliases = map[string][]string{
+	"default": []string{"host", "index", "start", "end", "job", "user", "gpu-avg", "gpu-peak"},
+	"Default": []string{"Hostname", "Index", "Start", "End", "Job", "User", "GpuAvgPct", "GpuPeakPct"},
+	"all":     []string{"start", "end", "duration", "host", "uuid", "index", "model", "job", "user", "gpu-avg", "gpu-peak", "gpumem-peak", "failing"},
+	"All":     []string{"Start", "End", "Duration", "Hostname", "UUID", "Index", "Model", "Job", "User", "GpuAvgPct", "GpuPeakPct", "GpuMemPeakGB", "Failing"},
+}
+
+const cardusageDefaultFields = "default"
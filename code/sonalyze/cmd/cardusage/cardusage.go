@@ -0,0 +1,131 @@
+// Produce a timeline of which job used which GPU card when.
+
+package cardusage
+
+import (
+	"errors"
+
+	. "sonalyze/cmd"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+//go:generate ../../../generate-table/generate-table -o cardusage-table.go cardusage.go
+
+/*TABLE cardusage
+
+package cardusage
+
+%%
+
+FIELDS *usageInterval
+
+ Start          DateTimeValue alias:"start"    desc:"Time of the first sample of the job on the card in the interval"
+ End            DateTimeValue alias:"end"      desc:"Time of the last sample of the job on the card in the interval"
+ Duration       DurationValue alias:"duration" desc:"Length of the interval"
+ Hostname       Ustr          alias:"host"     desc:"Name of the node holding the card"
+ UUID           string        alias:"uuid"     desc:"Card's unique identifier, blank if not known"
+ Index          int           alias:"index"    desc:"Card's index on its node"
+ Model          string        alias:"model"    desc:"Card model, blank if not known"
+ Job            uint32        alias:"job"      desc:"Job ID"
+ User           Ustr          alias:"user"     desc:"Owner of the job"
+ GpuAvgPct      F64Ceil       alias:"gpu-avg"  desc:"Average utilization of the card in percent during the interval"
+ GpuPeakPct     F64Ceil       alias:"gpu-peak" desc:"Peak utilization of the card in percent during the interval"
+ GpuMemPeakGB   F64Ceil       alias:"gpumem-peak" \
+                              desc:"Peak memory in use on the card in GB during the interval"
+ Failing        int           alias:"failing"  desc:"Nonzero if the card reported a failure during the interval"
+
+GENERATE usageInterval
+
+SUMMARY CardUsageCommand
+
+Experimental: Print the intervals during which jobs used GPU cards.
+
+The process samples record which cards a job's processes were using.  For
+each card on each node, the job's consecutive samples on the card are joined
+into an interval, which is broken where there are no samples for the job on
+the card for longer than the -gap.  The utilization, memory and failure state
+of the card during the interval are taken from the per-card GPU samples, and
+the card's UUID and model from the GPU samples and the system information.
+
+The utilization and memory are for the card as a whole: if several jobs share a
+card, their intervals overlap and they all see the same card figures.
+
+Use this to find out who was on a card at some time (eg -host gpu-7 -q
+'Index = 3'), for chargeback, or to find the jobs that were running on a
+failing card.
+
+HELP CardUsageCommand
+
+  Print intervals of (host, card, job, user, utilization).  Output records are
+  sorted by host, card index, and start time.  The default format is 'fixed'.
+
+ALIASES
+
+  default  host,index,start,end,job,user,gpu-avg,gpu-peak
+  Default  Hostname,Index,Start,End,Job,User,GpuAvgPct,GpuPeakPct
+  all      start,end,duration,host,uuid,index,model,job,user,gpu-avg,gpu-peak,gpumem-peak,failing
+  All      Start,End,Duration,Hostname,UUID,Index,Model,Job,User,GpuAvgPct,GpuPeakPct,GpuMemPeakGB,Failing
+
+DEFAULTS default
+
+ELBAT*/
+
+type CardUsageCommand struct /* implements SampleAnalysisCommand */ {
+	SampleAnalysisArgs
+	FormatArgs
+
+	// Aggregation
+	GapSec int64
+
+	// Synthesized and other
+	gapStr string
+}
+
+var _ = SampleAnalysisCommand((*CardUsageCommand)(nil))
+
+func (cc *CardUsageCommand) Add(fs *CLI) {
+	cc.SampleAnalysisArgs.Add(fs)
+	cc.FormatArgs.Add(fs)
+
+	fs.Group("aggregation")
+	fs.StringVar(&cc.gapStr, "gap", "1h",
+		"Break a job's use of a card into separate intervals at gaps in the samples longer than this, "+
+			"format `WwDdHhMm`, all parts optional")
+}
+
+func (cc *CardUsageCommand) ReifyForRemote(x *ArgReifier) error {
+	e1 := errors.Join(
+		cc.SampleAnalysisArgs.ReifyForRemote(x),
+		cc.FormatArgs.ReifyForRemote(x),
+	)
+	x.String("gap", cc.gapStr)
+	return e1
+}
+
+func (cc *CardUsageCommand) Validate() error {
+	var e1 error
+	cc.GapSec, e1 = DurationToSeconds("-gap", cc.gapStr)
+	if e1 == nil && cc.GapSec <= 0 {
+		e1 = errors.New("Invalid -gap, must be positive")
+	}
+	return errors.Join(
+		e1,
+		cc.SampleAnalysisArgs.Validate(),
+		ValidateFormatArgs(
+			&cc.FormatArgs, cardusageDefaultFields, cardusageFormatters, cardusageAliases, DefaultFixed),
+	)
+}
+
+func (cc *CardUsageCommand) DefaultRecordFilters() (
+	allUsers, skipSystemUsers, excludeSystemCommands, excludeHeartbeat bool,
+) {
+	// Card usage is about who was on the cards, so select all users by default.
+	allUsers, skipSystemUsers, determined := cc.RecordFilterArgs.DefaultUserFilters()
+	if !determined {
+		allUsers, skipSystemUsers = true, false
+	}
+	excludeSystemCommands = false
+	excludeHeartbeat = true
+	return
+}
@@ -0,0 +1,239 @@
+package cardusage
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+
+	. "sonalyze/common"
+	"sonalyze/data/card"
+	"sonalyze/data/gpusample"
+	"sonalyze/data/sample"
+	"sonalyze/db/repr"
+	"sonalyze/db/types"
+	. "sonalyze/table"
+)
+
+type cardKey struct {
+	host  Ustr
+	index int
+}
+
+type cardInfo struct {
+	uuid  string
+	model string
+}
+
+// A job seen on a card at a time.  Job IDs that are not from Slurm are only unique per node and
+// user, hence the user is part of the identity of the job.
+type occupancy struct {
+	time int64
+	job  uint32
+	user Ustr
+}
+
+func (cc *CardUsageCommand) Perform(
+	out io.Writer,
+	meta types.Context,
+	filter sample.QueryFilter,
+	hosts Hosts,
+	recordFilter *sample.SampleFilter,
+) error {
+	sdp, err := sample.OpenSampleDataProvider(meta)
+	if err != nil {
+		return err
+	}
+	streams, _, read, dropped, err :=
+		sdp.Query(
+			filter.FromDate,
+			filter.ToDate,
+			hosts,
+			recordFilter,
+			false,
+		)
+	if err != nil {
+		return fmt.Errorf("Failed to read log records: %v", err)
+	}
+	if Verbose {
+		Log.Infof("%d records read + %d dropped\n", read, dropped)
+	}
+
+	// Who was on each card when.  A job usually has several processes on a card at the same time,
+	// these are folded into one occupancy below.
+	cards := make(map[cardKey][]occupancy)
+	for _, stream := range streams {
+		for _, s := range *stream {
+			if s.Gpus.IsEmpty() || s.Gpus.IsUnknown() {
+				continue
+			}
+			for _, index := range s.Gpus.AsSlice() {
+				k := cardKey{s.Hostname, index}
+				cards[k] = append(cards[k], occupancy{s.Timestamp, s.Job, s.User})
+			}
+		}
+	}
+
+	gpuSamples, err := cc.queryGpuSamples(meta, filter, hosts)
+	if err != nil {
+		return err
+	}
+	info, err := cc.queryCardInfo(meta, filter, hosts)
+	if err != nil {
+		return err
+	}
+
+	intervals := make([]*usageInterval, 0)
+	for k, occ := range cards {
+		slices.SortFunc(occ, func(a, b occupancy) int {
+			if c := cmp.Compare(a.job, b.job); c != 0 {
+				return c
+			}
+			if c := cmp.Compare(a.user.String(), b.user.String()); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.time, b.time)
+		})
+		var current *usageInterval
+		for _, o := range occ {
+			if current != nil && current.Job == o.job && current.User == o.user &&
+				o.time-int64(current.End) <= cc.GapSec {
+				current.End = DateTimeValue(o.time)
+				continue
+			}
+			current = &usageInterval{
+				Start:    DateTimeValue(o.time),
+				End:      DateTimeValue(o.time),
+				Hostname: k.host,
+				Index:    k.index,
+				Job:      o.job,
+				User:     o.user,
+			}
+			intervals = append(intervals, current)
+		}
+	}
+
+	for _, iv := range intervals {
+		iv.Duration = DurationValue(iv.End - iv.Start)
+		k := cardKey{iv.Hostname, iv.Index}
+		if ci, found := info[k]; found {
+			iv.UUID = ci.uuid
+			iv.Model = ci.model
+		}
+		cardFigures(iv, gpuSamples[iv.Hostname])
+	}
+
+	intervals, err = ApplyQuery(cc.ParsedQuery, cardusageFormatters, cardusagePredicates, intervals)
+	if err != nil {
+		return err
+	}
+
+	slices.SortFunc(intervals, func(a, b *usageInterval) int {
+		if c := cmp.Compare(a.Hostname.String(), b.Hostname.String()); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Index, b.Index); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Start, b.Start); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Job, b.Job)
+	})
+
+	FormatData(
+		out,
+		cc.PrintFields,
+		cardusageFormatters,
+		cc.PrintOpts,
+		intervals,
+	)
+
+	return nil
+}
+
+func (cc *CardUsageCommand) queryGpuSamples(
+	meta types.Context,
+	filter sample.QueryFilter,
+	hosts Hosts,
+) (gpusample.GpuSamplesByHostSet, error) {
+	gsd, err := gpusample.OpenGpuSampleDataProvider(meta)
+	if err != nil {
+		return nil, err
+	}
+	streams, _, _, _, err := gsd.Query(filter.FromDate, filter.ToDate, hosts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read log records: %v", err)
+	}
+	return streams, nil
+}
+
+// The system information has the card model, and the UUID if the GPU samples don't have it.  If a
+// card changes (eg is replaced) during the time window then the most recent information is used.
+
+func (cc *CardUsageCommand) queryCardInfo(
+	meta types.Context,
+	filter sample.QueryFilter,
+	hosts Hosts,
+) (map[cardKey]cardInfo, error) {
+	cdp, err := card.OpenCardDataProvider(meta)
+	if err != nil {
+		return nil, err
+	}
+	records, err := cdp.Query(
+		card.QueryFilter{
+			HaveFrom: cc.HaveFrom,
+			FromDate: filter.FromDate,
+			HaveTo:   cc.HaveTo,
+			ToDate:   filter.ToDate,
+			Host:     hosts,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read log records: %v", err)
+	}
+	slices.SortFunc(records, func(a, b *repr.SysinfoCardData) int {
+		return cmp.Compare(a.Time, b.Time)
+	})
+	info := make(map[cardKey]cardInfo)
+	for _, r := range records {
+		info[cardKey{StringToUstr(r.Node), int(r.Index)}] = cardInfo{r.UUID, r.Model}
+	}
+	return info, nil
+}
+
+// Fill in the card figures for the interval from the samples for the card's host.
+
+func cardFigures(iv *usageInterval, samples *gpusample.GpuSamplesByHost) {
+	if samples == nil {
+		return
+	}
+	start, _ := slices.BinarySearchFunc(samples.Data, int64(iv.Start), func(s gpusample.GpuSamples, t int64) int {
+		return cmp.Compare(s.Time, t)
+	})
+	var sum float64
+	var n int
+	for _, s := range samples.Data[start:] {
+		if s.Time > int64(iv.End) {
+			break
+		}
+		for _, g := range s.Decoded {
+			if int(g.Index) != iv.Index {
+				continue
+			}
+			if g.UUID != "" {
+				iv.UUID = string(g.UUID)
+			}
+			sum += float64(g.CEUtil)
+			n++
+			iv.GpuPeakPct = max(iv.GpuPeakPct, float64(g.CEUtil))
+			iv.GpuMemPeakGB = max(iv.GpuMemPeakGB, float64(g.Memory)*KBToGB)
+			if g.Failing != 0 {
+				iv.Failing = int(g.Failing)
+			}
+		}
+	}
+	if n > 0 {
+		iv.GpuAvgPct = sum / float64(n)
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"io"
+	"time"
 
 	. "sonalyze/common"
 	"sonalyze/data/sample"
@@ -56,10 +57,40 @@ type Command interface {
 	LogFiles() []string
 	ConfigFile() string
 	CacheSize() int64
+	DiskCacheDir() string
 	ClusterName() string
 	RemoteHost() string
 	Remoting() bool
 	AuthFile() string
+	TimeZoneName() string
+	TimeZone() *time.Location
+	SetTimeZone(loc *time.Location) error
+}
+
+type PrintTimeZoneAPI interface {
+	// Set the time zone for printed timestamps
+	SetPrintTimeZone(loc *time.Location)
+}
+
+var _ = PrintTimeZoneAPI((*FormatArgs)(nil))
+
+// Once the cluster is known, settle the time zone for the command: -tz if present, otherwise the
+// cluster's configured time zone, otherwise UTC.
+
+func ResolveTimeZone(command Command, meta types.Context) error {
+	loc := command.TimeZone()
+	if command.TimeZoneName() == "" && meta.HaveConfig() {
+		if l := meta.Config().Location(); l != nil {
+			loc = l
+			if err := command.SetTimeZone(loc); err != nil {
+				return err
+			}
+		}
+	}
+	if p, ok := command.(PrintTimeZoneAPI); ok {
+		p.SetPrintTimeZone(loc)
+	}
+	return nil
 }
 
 ///////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,264 @@
+// DO NOT EDIT.  Generated from print.go by generate-table
+
+package compare
+
+import (
+	"cmp"
+	"fmt"
+	"go-utils/gpuset"
+	"io"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+var (
+	_ = cmp.Compare(0, 0)
+	_ fmt.Formatter
+	_ = io.SeekStart
+	_ = UstrEmpty
+	_ gpuset.GpuSet
+)
+
+// MT: Constant after initialization; immutable
+var seriesFormatters = map[string]Formatter[*seriesRecord]{
+	"Elapsed": {
+		Fmt: func(d *seriesRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.Elapsed), ctx)
+		},
+		Xtract: func(d *seriesRecord) any {
+			return d.Elapsed
+		},
+		Help: "(DurationValue) Elapsed time since the start of the jobs",
+	},
+	"CpuPctA": {
+		Fmt: func(d *seriesRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.CpuPctA), ctx)
+		},
+		Xtract: func(d *seriesRecord) any {
+			return d.CpuPctA
+		},
+		Help: "(int) CPU utilization of the first job in percent (100% = 1 core)",
+	},
+	"CpuPctB": {
+		Fmt: func(d *seriesRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.CpuPctB), ctx)
+		},
+		Xtract: func(d *seriesRecord) any {
+			return d.CpuPctB
+		},
+		Help: "(int) CPU utilization of the second job in percent (100% = 1 core)",
+	},
+	"ResidentGBA": {
+		Fmt: func(d *seriesRecord, ctx PrintMods) string {
+			return FormatFloat64((d.ResidentGBA), ctx)
+		},
+		Xtract: func(d *seriesRecord) any {
+			return d.ResidentGBA
+		},
+		Help: "(float64) Resident main memory of the first job in GB",
+	},
+	"ResidentGBB": {
+		Fmt: func(d *seriesRecord, ctx PrintMods) string {
+			return FormatFloat64((d.ResidentGBB), ctx)
+		},
+		Xtract: func(d *seriesRecord) any {
+			return d.ResidentGBB
+		},
+		Help: "(float64) Resident main memory of the second job in GB",
+	},
+	"GpuPctA": {
+		Fmt: func(d *seriesRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.GpuPctA), ctx)
+		},
+		Xtract: func(d *seriesRecord) any {
+			return d.GpuPctA
+		},
+		Help: "(int) GPU utilization of the first job in percent (100% = 1 card)",
+	},
+	"GpuPctB": {
+		Fmt: func(d *seriesRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.GpuPctB), ctx)
+		},
+		Xtract: func(d *seriesRecord) any {
+			return d.GpuPctB
+		},
+		Help: "(int) GPU utilization of the second job in percent (100% = 1 card)",
+	},
+	"GpuMemGBA": {
+		Fmt: func(d *seriesRecord, ctx PrintMods) string {
+			return FormatFloat64((d.GpuMemGBA), ctx)
+		},
+		Xtract: func(d *seriesRecord) any {
+			return d.GpuMemGBA
+		},
+		Help: "(float64) GPU memory of the first job in GB",
+	},
+	"GpuMemGBB": {
+		Fmt: func(d *seriesRecord, ctx PrintMods) string {
+			return FormatFloat64((d.GpuMemGBB), ctx)
+		},
+		Xtract: func(d *seriesRecord) any {
+			return d.GpuMemGBB
+		},
+		Help: "(float64) GPU memory of the second job in GB",
+	},
+	"IoKBsA": {
+		Fmt: func(d *seriesRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.IoKBsA), ctx)
+		},
+		Xtract: func(d *seriesRecord) any {
+			return d.IoKBsA
+		},
+		Help: "(int) I/O rate (read + written) of the first job in KB/s",
+	},
+	"IoKBsB": {
+		Fmt: func(d *seriesRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.IoKBsB), ctx)
+		},
+		Xtract: func(d *seriesRecord) any {
+			return d.IoKBsB
+		},
+		Help: "(int) I/O rate (read + written) of the second job in KB/s",
+	},
+}
+
+func init() {
+	DefAlias(seriesFormatters, "Elapsed", "elapsed")
+	DefAlias(seriesFormatters, "CpuPctA", "cpu-a")
+	DefAlias(seriesFormatters, "CpuPctB", "cpu-b")
+	DefAlias(seriesFormatters, "ResidentGBA", "res-a")
+	DefAlias(seriesFormatters, "ResidentGBB", "res-b")
+	DefAlias(seriesFormatters, "GpuPctA", "gpu-a")
+	DefAlias(seriesFormatters, "GpuPctB", "gpu-b")
+	DefAlias(seriesFormatters, "GpuMemGBA", "gpumem-a")
+	DefAlias(seriesFormatters, "GpuMemGBB", "gpumem-b")
+	DefAlias(seriesFormatters, "IoKBsA", "io-a")
+	DefAlias(seriesFormatters, "IoKBsB", "io-b")
+}
+
+// MT: Constant after initialization; immutable
+var seriesPredicates = map[string]Predicate[*seriesRecord]{
+	"Elapsed": Predicate[*seriesRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *seriesRecord, v any) int {
+			return cmp.Compare((d.Elapsed), v.(DurationValue))
+		},
+	},
+	"CpuPctA": Predicate[*seriesRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *seriesRecord, v any) int {
+			return cmp.Compare((d.CpuPctA), v.(F64Ceil))
+		},
+	},
+	"CpuPctB": Predicate[*seriesRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *seriesRecord, v any) int {
+			return cmp.Compare((d.CpuPctB), v.(F64Ceil))
+		},
+	},
+	"ResidentGBA": Predicate[*seriesRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *seriesRecord, v any) int {
+			return cmp.Compare((d.ResidentGBA), v.(float64))
+		},
+	},
+	"ResidentGBB": Predicate[*seriesRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *seriesRecord, v any) int {
+			return cmp.Compare((d.ResidentGBB), v.(float64))
+		},
+	},
+	"GpuPctA": Predicate[*seriesRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *seriesRecord, v any) int {
+			return cmp.Compare((d.GpuPctA), v.(F64Ceil))
+		},
+	},
+	"GpuPctB": Predicate[*seriesRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *seriesRecord, v any) int {
+			return cmp.Compare((d.GpuPctB), v.(F64Ceil))
+		},
+	},
+	"GpuMemGBA": Predicate[*seriesRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *seriesRecord, v any) int {
+			return cmp.Compare((d.GpuMemGBA), v.(float64))
+		},
+	},
+	"GpuMemGBB": Predicate[*seriesRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *seriesRecord, v any) int {
+			return cmp.Compare((d.GpuMemGBB), v.(float64))
+		},
+	},
+	"IoKBsA": Predicate[*seriesRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *seriesRecord, v any) int {
+			return cmp.Compare((d.IoKBsA), v.(F64Ceil))
+		},
+	},
+	"IoKBsB": Predicate[*seriesRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *seriesRecord, v any) int {
+			return cmp.Compare((d.IoKBsB), v.(F64Ceil))
+		},
+	},
+}
+
+type seriesRecord struct {
+	Elapsed     DurationValue
+	CpuPctA     F64Ceil
+	CpuPctB     F64Ceil
+	ResidentGBA float64
+	ResidentGBB float64
+	GpuPctA     F64Ceil
+	GpuPctB     F64Ceil
+	GpuMemGBA   float64
+	GpuMemGBB   float64
+	IoKBsA      F64Ceil
+	IoKBsB      F64Ceil
+}
+
+func (c *CompareCommand) Summary(out io.Writer) {
+	fmt.Fprint(out, `Experimental: Compare the profiles of two jobs.
+
+The two jobs are selected with -job A -job B.  Each job's samples are
+merged across its processes (and nodes, for Slurm jobs), aligned on the
+time elapsed since the job's first sample, and resampled to a grid with
+the spacing given by -step.  After the shorter job has ended, its values
+are zero.
+
+The time series of CPU, memory, GPU, and I/O for the two jobs are printed
+side by side, followed by a summary of the differences: the average and
+peak of each quantity for both jobs and the change from the first job
+to the second in percent.  With -summary, only the summary is printed.
+
+For HTML output a single quantity must be selected with -fmt (eg -fmt
+html,gpu) and is plotted for both jobs.  For CSV, AWK, and JSON output
+only one of the tables is printed, the time series by default.
+`)
+}
+
+const seriesHelp = `
+series
+  Compare the resource usage of two jobs over elapsed time.  The default format
+  is 'fixed'.
+`
+
+func (c *CompareCommand) MaybeFormatHelp() *FormatHelp {
+	return StandardFormatHelp(c.Fmt, seriesHelp, seriesFormatters, seriesAliases, seriesDefaultFields)
+}
+
+// MT: Constant after initialization; immutable
+var seriesAliases = map[string][]string{
+	"default": []string{"elapsed", "cpu-a", "cpu-b", "res-a", "res-b", "gpu-a", "gpu-b", "gpumem-a", "gpumem-b", "io-a", "io-b"},
+	"Default": []string{"Elapsed", "CpuPctA", "CpuPctB", "ResidentGBA", "ResidentGBB", "GpuPctA", "GpuPctB", "GpuMemGBA", "GpuMemGBB", "IoKBsA", "IoKBsB"},
+	"cpu":     []string{"cpu-a", "cpu-b"},
+	"res":     []string{"res-a", "res-b"},
+	"gpu":     []string{"gpu-a", "gpu-b"},
+	"gpumem":  []string{"gpumem-a", "gpumem-b"},
+	"io":      []string{"io-a", "io-b"},
+}
+
+const seriesDefaultFields = "default"
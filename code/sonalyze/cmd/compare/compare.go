@@ -0,0 +1,121 @@
+// Compare the profiles of two jobs, aligned on elapsed time.
+
+package compare
+
+import (
+	"errors"
+	"fmt"
+
+	. "sonalyze/cmd"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+type CompareCommand struct /* implements SampleAnalysisCommand */ {
+	SampleAnalysisArgs
+	FormatArgs
+
+	// Resampling and output
+	StepSec     int64
+	SummaryOnly bool
+
+	// Synthesized and other
+	stepStr       string
+	htmlOutput    bool
+	summaryFields []FieldSpec
+}
+
+var _ = SampleAnalysisCommand((*CompareCommand)(nil))
+
+func (cc *CompareCommand) Add(fs *CLI) {
+	cc.SampleAnalysisArgs.Add(fs)
+	cc.FormatArgs.Add(fs)
+
+	fs.Group("aggregation")
+	fs.StringVar(&cc.stepStr, "step", "5m",
+		"Resample the jobs to a grid with this spacing in elapsed time, format `WwDdHhMm`, all parts optional")
+
+	fs.Group("printing")
+	fs.BoolVar(&cc.SummaryOnly, "summary", false,
+		"Print only the summary of the differences, not the time series")
+}
+
+func (cc *CompareCommand) ReifyForRemote(x *ArgReifier) error {
+	e1 := errors.Join(
+		cc.SampleAnalysisArgs.ReifyForRemote(x),
+		cc.FormatArgs.ReifyForRemote(x),
+	)
+	x.String("step", cc.stepStr)
+	x.Bool("summary", cc.SummaryOnly)
+	return e1
+}
+
+func (cc *CompareCommand) Validate() error {
+	// FormatArgs are handled specially below
+	e1 := cc.SampleAnalysisArgs.Validate()
+
+	var e2 error
+	cc.StepSec, e2 = DurationToSeconds("-step", cc.stepStr)
+	if e2 == nil && cc.StepSec <= 0 {
+		e2 = errors.New("Invalid -step, must be positive")
+	}
+
+	var e3 error
+	if len(cc.Job) != 2 || len(cc.ExcludeJob) != 0 {
+		e3 = errors.New("Exactly two specific job numbers are required by `compare`")
+	} else if cc.Job[0] == cc.Job[1] {
+		e3 = fmt.Errorf("The jobs to compare must be different: %d", cc.Job[0])
+	}
+
+	// As for `profile`, html is handled on the side: it plots a single quantity for both jobs.  The
+	// summary is printed after the time series for fixed output, with the default summary fields.
+	var others map[string]bool
+	var e4 error
+	if cc.SummaryOnly {
+		cc.PrintFields, others, e4 = ParseFormatSpec(
+			summaryDefaultFields, cc.Fmt, summaryFormatters, summaryAliases)
+	} else {
+		cc.PrintFields, others, e4 = ParseFormatSpec(
+			seriesDefaultFields, cc.Fmt, seriesFormatters, seriesAliases)
+	}
+	if e4 == nil && len(cc.PrintFields) == 0 {
+		e4 = errors.New("No valid output fields were selected in format string")
+	}
+	cc.htmlOutput = others["html"]
+
+	def := DefaultFixed
+	if cc.htmlOutput {
+		def = DefaultNone
+	}
+	cc.PrintOpts = StandardFormatOptions(others, def)
+
+	var e5 error
+	if cc.htmlOutput {
+		if !cc.PrintOpts.IsDefaultFormat() {
+			e5 = errors.New("Multiple output formats requested")
+		} else if cc.SummaryOnly {
+			e5 = errors.New("The summary can't be printed as html")
+		} else if _, err := htmlQuantity(cc.PrintFields); err != nil {
+			e5 = err
+		}
+	}
+	if !cc.SummaryOnly && cc.PrintOpts.Fixed {
+		cc.summaryFields, _, _ = ParseFormatSpec(
+			summaryDefaultFields, "", summaryFormatters, summaryAliases)
+	}
+
+	return errors.Join(e1, e2, e3, e4, e5)
+}
+
+func (cc *CompareCommand) DefaultRecordFilters() (
+	allUsers, skipSystemUsers, excludeSystemCommands, excludeHeartbeat bool,
+) {
+	// The jobs are named explicitly, so select all users by default.
+	allUsers, skipSystemUsers, determined := cc.RecordFilterArgs.DefaultUserFilters()
+	if !determined {
+		allUsers, skipSystemUsers = true, false
+	}
+	excludeSystemCommands = false
+	excludeHeartbeat = true
+	return
+}
@@ -0,0 +1,201 @@
+package compare
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	. "sonalyze/common"
+	"sonalyze/data/sample"
+	"sonalyze/db/types"
+	. "sonalyze/table"
+)
+
+// A job resampled to the grid.  Index i holds the values at elapsed time i*step; the slices are as
+// long as the job's duration allows.
+
+type resampled struct {
+	job      sample.MergedJob
+	cpuPct   []float64
+	resGB    []float64
+	gpuPct   []float64
+	gpuMemGB []float64
+	ioKBs    []float64
+}
+
+func (cc *CompareCommand) Perform(
+	out io.Writer,
+	meta types.Context,
+	filter sample.QueryFilter,
+	hosts Hosts,
+	recordFilter *sample.SampleFilter,
+) error {
+	sdp, err := sample.OpenSampleDataProvider(meta)
+	if err != nil {
+		return err
+	}
+	streams, bounds, read, dropped, err :=
+		sdp.Query(
+			filter.FromDate,
+			filter.ToDate,
+			hosts,
+			recordFilter,
+			true,
+		)
+	if err != nil {
+		return fmt.Errorf("Failed to read log records: %v", err)
+	}
+	if Verbose {
+		Log.Infof("%d records read + %d dropped\n", read, dropped)
+	}
+
+	jobs, _ := sample.MergeSlurmByJob(streams, bounds)
+	var a, b *resampled
+	for i, id := range cc.Job {
+		var found []sample.MergedJob
+		for _, job := range jobs {
+			if job.Samples[0].Job == id {
+				found = append(found, job)
+			}
+		}
+		switch {
+		case len(found) == 0:
+			return fmt.Errorf("No processes matching job ID %d", id)
+		case len(found) > 1:
+			// Non-Slurm jobs have node-local IDs.
+			return fmt.Errorf("Job ID %d is ambiguous, it is found on several nodes; use -host", id)
+		}
+		r := resample(found[0], cc.StepSec)
+		if i == 0 {
+			a = r
+		} else {
+			b = r
+		}
+	}
+	if Verbose {
+		Log.Infof("Grid points: %d and %d", len(a.cpuPct), len(b.cpuPct))
+	}
+
+	return cc.printComparison(out, a, b, buildSeries(a, b, cc.StepSec), buildSummary(a, b))
+}
+
+// Memory is a level, so it is interpolated linearly between the samples around a grid point.  The
+// utilization and I/O in a sample are for the interval since the previous sample, so the value at a
+// grid point is the value for the interval that contains it.
+
+func resample(job sample.MergedJob, step int64) *resampled {
+	samples := job.Samples
+	first := samples[0].Timestamp
+	n := int((samples[len(samples)-1].Timestamp-first)/step) + 1
+	r := &resampled{
+		job:      job,
+		cpuPct:   make([]float64, n),
+		resGB:    make([]float64, n),
+		gpuPct:   make([]float64, n),
+		gpuMemGB: make([]float64, n),
+		ioKBs:    make([]float64, n),
+	}
+	for i := range n {
+		t := first + int64(i)*step
+		ix, _ := slices.BinarySearchFunc(samples, t, func(s sample.Sample, t int64) int {
+			return int(s.Timestamp - t)
+		})
+		s := samples[ix]
+		r.cpuPct[i] = float64(s.CpuUtilPct)
+		r.gpuPct[i] = float64(s.GpuPct)
+		if ix == 0 || s.Timestamp == t {
+			r.resGB[i] = float64(s.RssAnonKB) * KBToGB
+			r.gpuMemGB[i] = float64(s.GpuKB) * KBToGB
+		} else {
+			prev := samples[ix-1]
+			f := float64(t-prev.Timestamp) / float64(s.Timestamp-prev.Timestamp)
+			r.resGB[i] = lerp(float64(prev.RssAnonKB), float64(s.RssAnonKB), f) * KBToGB
+			r.gpuMemGB[i] = lerp(float64(prev.GpuKB), float64(s.GpuKB), f) * KBToGB
+		}
+		if ix > 0 {
+			prev := samples[ix-1]
+			before := prev.DataReadKB + prev.DataWrittenKB
+			after := s.DataReadKB + s.DataWrittenKB
+			if after > before {
+				r.ioKBs[i] = float64(after-before) / float64(s.Timestamp-prev.Timestamp)
+			}
+		}
+	}
+	return r
+}
+
+func lerp(x, y, f float64) float64 {
+	return x + (y-x)*f
+}
+
+func buildSeries(a, b *resampled, step int64) []*seriesRecord {
+	n := max(len(a.cpuPct), len(b.cpuPct))
+	series := make([]*seriesRecord, n)
+	for i := range n {
+		r := &seriesRecord{Elapsed: DurationValue(int64(i) * step)}
+		if i < len(a.cpuPct) {
+			r.CpuPctA = a.cpuPct[i]
+			r.ResidentGBA = RoundTo(a.resGB[i], 2)
+			r.GpuPctA = a.gpuPct[i]
+			r.GpuMemGBA = RoundTo(a.gpuMemGB[i], 2)
+			r.IoKBsA = a.ioKBs[i]
+		}
+		if i < len(b.cpuPct) {
+			r.CpuPctB = b.cpuPct[i]
+			r.ResidentGBB = RoundTo(b.resGB[i], 2)
+			r.GpuPctB = b.gpuPct[i]
+			r.GpuMemGBB = RoundTo(b.gpuMemGB[i], 2)
+			r.IoKBsB = b.ioKBs[i]
+		}
+		series[i] = r
+	}
+	return series
+}
+
+func buildSummary(a, b *resampled) []*summaryRecord {
+	hours := func(r *resampled) float64 {
+		samples := r.job.Samples
+		return float64(samples[len(samples)-1].Timestamp-samples[0].Timestamp) / 3600
+	}
+	summary := []*summaryRecord{
+		newSummaryRecord("elapsed", "h", []float64{hours(a)}, []float64{hours(b)}),
+		newSummaryRecord("cpu", "%", a.cpuPct, b.cpuPct),
+		newSummaryRecord("res", "GB", a.resGB, b.resGB),
+		newSummaryRecord("gpu", "%", a.gpuPct, b.gpuPct),
+		newSummaryRecord("gpumem", "GB", a.gpuMemGB, b.gpuMemGB),
+		newSummaryRecord("io", "KB/s", a.ioKBs, b.ioKBs),
+	}
+	return summary
+}
+
+func newSummaryRecord(metric, unit string, a, b []float64) *summaryRecord {
+	avgA, peakA := avgAndPeak(a)
+	avgB, peakB := avgAndPeak(b)
+	return &summaryRecord{
+		Metric:        metric,
+		Unit:          unit,
+		AvgA:          RoundTo(avgA, 2),
+		AvgB:          RoundTo(avgB, 2),
+		AvgChangePct:  changePct(avgA, avgB),
+		PeakA:         RoundTo(peakA, 2),
+		PeakB:         RoundTo(peakB, 2),
+		PeakChangePct: changePct(peakA, peakB),
+	}
+}
+
+func avgAndPeak(xs []float64) (avg, peak float64) {
+	for _, x := range xs {
+		avg += x
+		peak = max(peak, x)
+	}
+	return avg / float64(len(xs)), peak
+}
+
+// The change from a to b in percent of a, zero if a is zero.
+func changePct(a, b float64) float64 {
+	if a == 0 {
+		return 0
+	}
+	return math.Round((b - a) * 100 / a)
+}
@@ -0,0 +1,229 @@
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	. "sonalyze/table"
+)
+
+//go:generate ../../../generate-table/generate-table -o compare-table.go print.go
+
+/*TABLE series
+
+package compare
+
+%%
+
+FIELDS *seriesRecord
+
+ Elapsed     DurationValue alias:"elapsed"  desc:"Elapsed time since the start of the jobs"
+ CpuPctA     F64Ceil       alias:"cpu-a"    desc:"CPU utilization of the first job in percent (100% = 1 core)"
+ CpuPctB     F64Ceil       alias:"cpu-b"    desc:"CPU utilization of the second job in percent (100% = 1 core)"
+ ResidentGBA float64       alias:"res-a"    desc:"Resident main memory of the first job in GB"
+ ResidentGBB float64       alias:"res-b"    desc:"Resident main memory of the second job in GB"
+ GpuPctA     F64Ceil       alias:"gpu-a"    desc:"GPU utilization of the first job in percent (100% = 1 card)"
+ GpuPctB     F64Ceil       alias:"gpu-b"    desc:"GPU utilization of the second job in percent (100% = 1 card)"
+ GpuMemGBA   float64       alias:"gpumem-a" desc:"GPU memory of the first job in GB"
+ GpuMemGBB   float64       alias:"gpumem-b" desc:"GPU memory of the second job in GB"
+ IoKBsA      F64Ceil       alias:"io-a"     desc:"I/O rate (read + written) of the first job in KB/s"
+ IoKBsB      F64Ceil       alias:"io-b"     desc:"I/O rate (read + written) of the second job in KB/s"
+
+GENERATE seriesRecord
+
+SUMMARY CompareCommand
+
+Experimental: Compare the profiles of two jobs.
+
+The two jobs are selected with -job A -job B.  Each job's samples are
+merged across its processes (and nodes, for Slurm jobs), aligned on the
+time elapsed since the job's first sample, and resampled to a grid with
+the spacing given by -step.  After the shorter job has ended, its values
+are zero.
+
+The time series of CPU, memory, GPU, and I/O for the two jobs are printed
+side by side, followed by a summary of the differences: the average and
+peak of each quantity for both jobs and the change from the first job
+to the second in percent.  With -summary, only the summary is printed.
+
+For HTML output a single quantity must be selected with -fmt (eg -fmt
+html,gpu) and is plotted for both jobs.  For CSV, AWK, and JSON output
+only one of the tables is printed, the time series by default.
+
+HELP CompareCommand
+
+  Compare the resource usage of two jobs over elapsed time.  The default format
+  is 'fixed'.
+
+ALIASES
+
+  default elapsed,cpu-a,cpu-b,res-a,res-b,gpu-a,gpu-b,gpumem-a,gpumem-b,io-a,io-b
+  Default Elapsed,CpuPctA,CpuPctB,ResidentGBA,ResidentGBB,GpuPctA,GpuPctB,GpuMemGBA,GpuMemGBB,\
+          IoKBsA,IoKBsB
+  cpu     cpu-a,cpu-b
+  res     res-a,res-b
+  gpu     gpu-a,gpu-b
+  gpumem  gpumem-a,gpumem-b
+  io      io-a,io-b
+
+DEFAULTS default
+
+ELBAT*/
+
+func (cc *CompareCommand) printComparison(
+	out io.Writer,
+	a, b *resampled,
+	series []*seriesRecord,
+	summary []*summaryRecord,
+) error {
+	if cc.htmlOutput {
+		quant, _ := htmlQuantity(cc.PrintFields)
+		formatHtml(out, a, b, quant, series)
+		return nil
+	}
+	if cc.SummaryOnly {
+		FormatData(out, cc.PrintFields, summaryFormatters, cc.PrintOpts, summary)
+		return nil
+	}
+	FormatData(out, cc.PrintFields, seriesFormatters, cc.PrintOpts, series)
+	if cc.summaryFields != nil {
+		fmt.Fprintln(out)
+		FormatData(out, cc.summaryFields, summaryFormatters, cc.PrintOpts, summary)
+	}
+	return nil
+}
+
+// For html the fields must all be for the same quantity, eg -fmt html,gpu.  Return that quantity.
+
+func htmlQuantity(fields []FieldSpec) (string, error) {
+	quant := ""
+	for _, f := range fields {
+		q := quantities[f.Name]
+		if q == "" {
+			continue
+		}
+		if quant != "" && q != quant {
+			quant = ""
+			break
+		}
+		quant = q
+	}
+	if quant == "" {
+		return "", errors.New("Html output needs exactly one quantity: cpu, res, gpu, gpumem, or io")
+	}
+	return quant, nil
+}
+
+var quantities = map[string]string{
+	"cpu-a":       "cpu",
+	"cpu-b":       "cpu",
+	"CpuPctA":     "cpu",
+	"CpuPctB":     "cpu",
+	"res-a":       "res",
+	"res-b":       "res",
+	"ResidentGBA": "res",
+	"ResidentGBB": "res",
+	"gpu-a":       "gpu",
+	"gpu-b":       "gpu",
+	"GpuPctA":     "gpu",
+	"GpuPctB":     "gpu",
+	"gpumem-a":    "gpumem",
+	"gpumem-b":    "gpumem",
+	"GpuMemGBA":   "gpumem",
+	"GpuMemGBB":   "gpumem",
+	"io-a":        "io",
+	"io-b":        "io",
+	"IoKBsA":      "io",
+	"IoKBsB":      "io",
+}
+
+var htmlCaptions = map[string]string{
+	"cpu":    "Y axis: CPU utilization in percent (100% = 1 core)",
+	"res":    "Y axis: Resident primary memory in GB",
+	"gpu":    "Y axis: GPU utilization in percent (100% = 1 card)",
+	"gpumem": "Y axis: GPU memory in GB",
+	"io":     "Y axis: I/O rate in KB/s",
+}
+
+func formatHtml(
+	unbufOut io.Writer,
+	a, b *resampled,
+	quant string,
+	series []*seriesRecord,
+) {
+	out := Buffered(unbufOut)
+	defer out.Flush()
+
+	value := func(r *seriesRecord, second bool) float64 {
+		switch quant {
+		case "cpu":
+			return pick(second, r.CpuPctA, r.CpuPctB)
+		case "res":
+			return pick(second, r.ResidentGBA, r.ResidentGBB)
+		case "gpu":
+			return pick(second, r.GpuPctA, r.GpuPctB)
+		case "gpumem":
+			return pick(second, r.GpuMemGBA, r.GpuMemGBB)
+		case "io":
+			return pick(second, r.IoKBsA, r.IoKBsB)
+		default:
+			panic("Unknown quantity")
+		}
+	}
+	labels := make([]string, len(series))
+	dataA := make([]string, len(series))
+	dataB := make([]string, len(series))
+	for i, r := range series {
+		labels[i] = "\"" + FormatDurationValue(r.Elapsed, 0) + "\""
+		dataA[i] = fmt.Sprint(value(r, false))
+		dataB[i] = fmt.Sprint(value(r, true))
+	}
+	title := fmt.Sprintf("Job %d vs job %d", a.job.Samples[0].Job, b.job.Samples[0].Job)
+	fmt.Fprintf(out, `
+<html>
+ <head>
+  <title>%s</title>
+  <script src="https://cdn.jsdelivr.net/npm/chart.js"></script>
+  <script>
+var LABELS = [%s];
+var DATASETS = [{label: "%s", data: [%s]}, {label: "%s", data: [%s]}];
+function render() {
+  new Chart(document.getElementById("chart_node"), {
+    type: 'line',
+    data: {
+      labels: LABELS,
+      datasets: DATASETS
+    },
+    options: { scales: { x: { beginAtZero: true }, y: { beginAtZero: true } } }
+  })
+}
+  </script>
+ </head>
+ <body onload="render()">
+  <center><h1>%s</h1></center>
+  <div><canvas id="chart_node"></canvas></div>
+  <center><b>X axis: Elapsed time</b><br><b>%s</b></center>
+ </body>
+<html>
+`,
+		title,
+		strings.Join(labels, ","),
+		jobLabel(a), strings.Join(dataA, ","),
+		jobLabel(b), strings.Join(dataB, ","),
+		title,
+		htmlCaptions[quant],
+	)
+}
+
+func jobLabel(r *resampled) string {
+	return fmt.Sprintf("%d on %s", r.job.Samples[0].Job, r.job.Host.CanonicalName())
+}
+
+func pick(second bool, a, b float64) float64 {
+	if second {
+		return b
+	}
+	return a
+}
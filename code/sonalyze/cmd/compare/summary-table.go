@@ -0,0 +1,176 @@
+// DO NOT EDIT.  Generated from summary.go by generate-table
+
+package compare
+
+import (
+	"cmp"
+	"fmt"
+	"go-utils/gpuset"
+	"io"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+var (
+	_ = cmp.Compare(0, 0)
+	_ fmt.Formatter
+	_ = io.SeekStart
+	_ = UstrEmpty
+	_ gpuset.GpuSet
+)
+
+// MT: Constant after initialization; immutable
+var summaryFormatters = map[string]Formatter[*summaryRecord]{
+	"Metric": {
+		Fmt: func(d *summaryRecord, ctx PrintMods) string {
+			return FormatString((d.Metric), ctx)
+		},
+		Xtract: func(d *summaryRecord) any {
+			return d.Metric
+		},
+		Help: "(string) The quantity compared: elapsed, cpu, res, gpu, gpumem, io",
+	},
+	"Unit": {
+		Fmt: func(d *summaryRecord, ctx PrintMods) string {
+			return FormatString((d.Unit), ctx)
+		},
+		Xtract: func(d *summaryRecord) any {
+			return d.Unit
+		},
+		Help: "(string) The unit of the quantity",
+	},
+	"AvgA": {
+		Fmt: func(d *summaryRecord, ctx PrintMods) string {
+			return FormatFloat64((d.AvgA), ctx)
+		},
+		Xtract: func(d *summaryRecord) any {
+			return d.AvgA
+		},
+		Help: "(float64) Average value for the first job over its elapsed time",
+	},
+	"AvgB": {
+		Fmt: func(d *summaryRecord, ctx PrintMods) string {
+			return FormatFloat64((d.AvgB), ctx)
+		},
+		Xtract: func(d *summaryRecord) any {
+			return d.AvgB
+		},
+		Help: "(float64) Average value for the second job over its elapsed time",
+	},
+	"AvgChangePct": {
+		Fmt: func(d *summaryRecord, ctx PrintMods) string {
+			return FormatFloat64((d.AvgChangePct), ctx)
+		},
+		Xtract: func(d *summaryRecord) any {
+			return d.AvgChangePct
+		},
+		Help: "(float64) Change of the average from the first job to the second, in percent",
+	},
+	"PeakA": {
+		Fmt: func(d *summaryRecord, ctx PrintMods) string {
+			return FormatFloat64((d.PeakA), ctx)
+		},
+		Xtract: func(d *summaryRecord) any {
+			return d.PeakA
+		},
+		Help: "(float64) Peak value for the first job",
+	},
+	"PeakB": {
+		Fmt: func(d *summaryRecord, ctx PrintMods) string {
+			return FormatFloat64((d.PeakB), ctx)
+		},
+		Xtract: func(d *summaryRecord) any {
+			return d.PeakB
+		},
+		Help: "(float64) Peak value for the second job",
+	},
+	"PeakChangePct": {
+		Fmt: func(d *summaryRecord, ctx PrintMods) string {
+			return FormatFloat64((d.PeakChangePct), ctx)
+		},
+		Xtract: func(d *summaryRecord) any {
+			return d.PeakChangePct
+		},
+		Help: "(float64) Change of the peak from the first job to the second, in percent",
+	},
+}
+
+func init() {
+	DefAlias(summaryFormatters, "Metric", "metric")
+	DefAlias(summaryFormatters, "Unit", "unit")
+	DefAlias(summaryFormatters, "AvgA", "avg-a")
+	DefAlias(summaryFormatters, "AvgB", "avg-b")
+	DefAlias(summaryFormatters, "AvgChangePct", "avg-change")
+	DefAlias(summaryFormatters, "PeakA", "peak-a")
+	DefAlias(summaryFormatters, "PeakB", "peak-b")
+	DefAlias(summaryFormatters, "PeakChangePct", "peak-change")
+}
+
+// MT: Constant after initialization; immutable
+var summaryPredicates = map[string]Predicate[*summaryRecord]{
+	"Metric": Predicate[*summaryRecord]{
+		Compare: func(d *summaryRecord, v any) int {
+			return cmp.Compare((d.Metric), v.(string))
+		},
+	},
+	"Unit": Predicate[*summaryRecord]{
+		Compare: func(d *summaryRecord, v any) int {
+			return cmp.Compare((d.Unit), v.(string))
+		},
+	},
+	"AvgA": Predicate[*summaryRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *summaryRecord, v any) int {
+			return cmp.Compare((d.AvgA), v.(float64))
+		},
+	},
+	"AvgB": Predicate[*summaryRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *summaryRecord, v any) int {
+			return cmp.Compare((d.AvgB), v.(float64))
+		},
+	},
+	"AvgChangePct": Predicate[*summaryRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *summaryRecord, v any) int {
+			return cmp.Compare((d.AvgChangePct), v.(float64))
+		},
+	},
+	"PeakA": Predicate[*summaryRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *summaryRecord, v any) int {
+			return cmp.Compare((d.PeakA), v.(float64))
+		},
+	},
+	"PeakB": Predicate[*summaryRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *summaryRecord, v any) int {
+			return cmp.Compare((d.PeakB), v.(float64))
+		},
+	},
+	"PeakChangePct": Predicate[*summaryRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *summaryRecord, v any) int {
+			return cmp.Compare((d.PeakChangePct), v.(float64))
+		},
+	},
+}
+
+type summaryRecord struct {
+	Metric        string
+	Unit          string
+	AvgA          float64
+	AvgB          float64
+	AvgChangePct  float64
+	PeakA         float64
+	PeakB         float64
+	PeakChangePct float64
+}
+
+// MT: Constant after initialization; immutable
+var summaryAliases = map[string][]string{
+	"default": []string{"metric", "unit", "avg-a", "avg-b", "avg-change", "peak-a", "peak-b", "peak-change"},
+	"Default": []string{"Metric", "Unit", "AvgA", "AvgB", "AvgChangePct", "PeakA", "PeakB", "PeakChangePct"},
+}
+
+const summaryDefaultFields = "default"
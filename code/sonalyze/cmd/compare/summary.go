@@ -0,0 +1,33 @@
+package compare
+
+// The summary of the differences between the jobs, one record per quantity.
+
+//go:generate ../../../generate-table/generate-table -o summary-table.go summary.go
+
+/*TABLE summary
+
+package compare
+
+%%
+
+FIELDS *summaryRecord
+
+ Metric        string  alias:"metric"      desc:"The quantity compared: elapsed, cpu, res, gpu, gpumem, io"
+ Unit          string  alias:"unit"        desc:"The unit of the quantity"
+ AvgA          float64 alias:"avg-a"       desc:"Average value for the first job over its elapsed time"
+ AvgB          float64 alias:"avg-b"       desc:"Average value for the second job over its elapsed time"
+ AvgChangePct  float64 alias:"avg-change"  desc:"Change of the average from the first job to the second, in percent"
+ PeakA         float64 alias:"peak-a"      desc:"Peak value for the first job"
+ PeakB         float64 alias:"peak-b"      desc:"Peak value for the second job"
+ PeakChangePct float64 alias:"peak-change" desc:"Change of the peak from the first job to the second, in percent"
+
+GENERATE summaryRecord
+
+ALIASES
+
+  default metric,unit,avg-a,avg-b,avg-change,peak-a,peak-b,peak-change
+  Default Metric,Unit,AvgA,AvgB,AvgChangePct,PeakA,PeakB,PeakChangePct
+
+DEFAULTS default
+
+ELBAT*/
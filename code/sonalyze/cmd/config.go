@@ -12,7 +12,7 @@ import (
 // definition for each host.  No config at all is a fatal error.  No config for a host means we
 // remove the host from the set, we return the modified set.
 //
-// Over time this may become more complicated, as the config becomes time-dependent.
+// The config is time-dependent, and a host is checked at the time of the first record of its stream.
 func EnsureConfigForInputStreams(
 	cdp *config.ConfigDataProvider,
 	streams sample.InputStreamSet,
@@ -0,0 +1,238 @@
+// DO NOT EDIT.  Generated from coverage.go by generate-table
+
+package coverage
+
+import (
+	"cmp"
+	"fmt"
+	"go-utils/gpuset"
+	"io"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+var (
+	_ = cmp.Compare(0, 0)
+	_ fmt.Formatter
+	_ = io.SeekStart
+	_ = UstrEmpty
+	_ gpuset.GpuSet
+)
+
+// MT: Constant after initialization; immutable
+var coverageFormatters = map[string]Formatter[*coverageRecord]{
+	"Date": {
+		Fmt: func(d *coverageRecord, ctx PrintMods) string {
+			return FormatDateValue((d.Date), ctx)
+		},
+		Xtract: func(d *coverageRecord) any {
+			return d.Date
+		},
+		Help: "(DateValue) The day, in the -tz time zone",
+	},
+	"Hostname": {
+		Fmt: func(d *coverageRecord, ctx PrintMods) string {
+			return FormatString((d.Hostname), ctx)
+		},
+		Xtract: func(d *coverageRecord) any {
+			return d.Hostname
+		},
+		Help: "(string) Name of the node, blank for cluster-level data (sacct, cluzter)",
+	},
+	"Type": {
+		Fmt: func(d *coverageRecord, ctx PrintMods) string {
+			return FormatString((d.Type), ctx)
+		},
+		Xtract: func(d *coverageRecord) any {
+			return d.Type
+		},
+		Help: "(string) Data type: sample, sysinfo, sacct, cluzter, gpu, or disk",
+	},
+	"Records": {
+		Fmt: func(d *coverageRecord, ctx PrintMods) string {
+			return FormatInt((d.Records), ctx)
+		},
+		Xtract: func(d *coverageRecord) any {
+			return d.Records
+		},
+		Help: "(int) Number of records with a timestamp on the day",
+	},
+	"Interval": {
+		Fmt: func(d *coverageRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.Interval), ctx)
+		},
+		Xtract: func(d *coverageRecord) any {
+			return d.Interval
+		},
+		Help: "(DurationValue) Sampling interval observed for the host and type across the time window",
+	},
+	"Gaps": {
+		Fmt: func(d *coverageRecord, ctx PrintMods) string {
+			return FormatInt((d.Gaps), ctx)
+		},
+		Xtract: func(d *coverageRecord) any {
+			return d.Gaps
+		},
+		Help: "(int) Number of gaps that start on the day",
+	},
+	"Down": {
+		Fmt: func(d *coverageRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.Down), ctx)
+		},
+		Xtract: func(d *coverageRecord) any {
+			return d.Down
+		},
+		Help: "(DurationValue) Time on the day in gaps during which the node rebooted",
+	},
+	"Missing": {
+		Fmt: func(d *coverageRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.Missing), ctx)
+		},
+		Xtract: func(d *coverageRecord) any {
+			return d.Missing
+		},
+		Help: "(DurationValue) Time on the day in gaps during which the node did not reboot",
+	},
+	"Unknown": {
+		Fmt: func(d *coverageRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.Unknown), ctx)
+		},
+		Xtract: func(d *coverageRecord) any {
+			return d.Unknown
+		},
+		Help: "(DurationValue) Time on the day in gaps with no boot information",
+	},
+}
+
+func init() {
+	DefAlias(coverageFormatters, "Date", "date")
+	DefAlias(coverageFormatters, "Hostname", "host")
+	DefAlias(coverageFormatters, "Type", "type")
+	DefAlias(coverageFormatters, "Records", "records")
+	DefAlias(coverageFormatters, "Interval", "interval")
+	DefAlias(coverageFormatters, "Gaps", "gaps")
+	DefAlias(coverageFormatters, "Down", "down")
+	DefAlias(coverageFormatters, "Missing", "missing")
+	DefAlias(coverageFormatters, "Unknown", "unknown")
+}
+
+// MT: Constant after initialization; immutable
+var coveragePredicates = map[string]Predicate[*coverageRecord]{
+	"Date": Predicate[*coverageRecord]{
+		Convert: CvtString2DateValue,
+		Compare: func(d *coverageRecord, v any) int {
+			return cmp.Compare((d.Date), v.(DateValue))
+		},
+	},
+	"Hostname": Predicate[*coverageRecord]{
+		Compare: func(d *coverageRecord, v any) int {
+			return cmp.Compare((d.Hostname), v.(string))
+		},
+	},
+	"Type": Predicate[*coverageRecord]{
+		Compare: func(d *coverageRecord, v any) int {
+			return cmp.Compare((d.Type), v.(string))
+		},
+	},
+	"Records": Predicate[*coverageRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *coverageRecord, v any) int {
+			return cmp.Compare((d.Records), v.(int))
+		},
+	},
+	"Interval": Predicate[*coverageRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *coverageRecord, v any) int {
+			return cmp.Compare((d.Interval), v.(DurationValue))
+		},
+	},
+	"Gaps": Predicate[*coverageRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *coverageRecord, v any) int {
+			return cmp.Compare((d.Gaps), v.(int))
+		},
+	},
+	"Down": Predicate[*coverageRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *coverageRecord, v any) int {
+			return cmp.Compare((d.Down), v.(DurationValue))
+		},
+	},
+	"Missing": Predicate[*coverageRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *coverageRecord, v any) int {
+			return cmp.Compare((d.Missing), v.(DurationValue))
+		},
+	},
+	"Unknown": Predicate[*coverageRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *coverageRecord, v any) int {
+			return cmp.Compare((d.Unknown), v.(DurationValue))
+		},
+	},
+}
+
+type coverageRecord struct {
+	Date     DateValue
+	Hostname string
+	Type     string
+	Records  int
+	Interval DurationValue
+	Gaps     int
+	Down     DurationValue
+	Missing  DurationValue
+	Unknown  DurationValue
+}
+
+func (c *CoverageCommand) Summary(out io.Writer) {
+	fmt.Fprint(out, `Experimental: Report where data are present and where they are missing.
+
+For each host and data type there is one output record per day in the time
+window, with the number of records that day.  Days without records are
+included for every host and type that has some records in the window.  The
+data types are:
+
+  sample   process samples, including heartbeats
+  sysinfo  node configuration
+  sacct    Slurm job records (cluster-level)
+  cluzter  Slurm node and partition state (cluster-level)
+  gpu      GPU samples
+  disk     disk samples
+
+The sampling interval is the median time between consecutive distinct
+timestamps, and a gap is a time between consecutive timestamps that is longer
+than -gap-factor times the interval.  The time from the start of the window to
+the first timestamp and from the last timestamp to the end of the window (or
+now, if that is earlier) are gaps too if they are that long.  A gap is counted
+on the day it starts, and its time is divided among the days it spans.
+
+The boot times in the node samples are used to tell downtime from missing
+data.  A gap during which the node booted is downtime, a gap during which the
+node did not boot is missing data, and a gap for which there is no boot
+information is unknown.  Cluster-level data are only reported when no -host
+is given, and their gaps are always unknown.
+
+Older sacct records do not carry the time they were collected, and for those
+the job's end time is used instead.  The interval and gaps for such data are
+therefore approximate.
+
+Use -gaps to list the gaps individually instead, see -gaps -fmt help.
+`)
+}
+
+const coverageHelp = `
+coverage
+  Print record counts, the observed sampling interval, and gaps per host, data
+  type, and day.  Output records are sorted by host, type, and date.  The
+  default format is 'fixed'.
+`
+
+// MT: Constant after initialization; immutable
+var coverageAliases = map[string][]string{
+	"default": []string{"date", "host", "type", "records", "interval", "gaps", "down", "missing", "unknown"},
+	"Default": []string{"Date", "Hostname", "Type", "Records", "Interval", "Gaps", "Down", "Missing", "Unknown"},
+	"all":     []string{"default"},
+	"All":     []string{"Default"},
+}
+
+const coverageDefaultFields = "default"
@@ -0,0 +1,156 @@
+// Report data coverage per host and per data type: how many records there are per day, the
+// sampling interval observed in the data, and where the data have gaps.
+
+package coverage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	. "sonalyze/cmd"
+	. "sonalyze/table"
+)
+
+//go:generate ../../../generate-table/generate-table -o coverage-table.go coverage.go
+
+/*TABLE coverage
+
+package coverage
+
+%%
+
+FIELDS *coverageRecord
+
+ Date     DateValue     alias:"date"     desc:"The day, in the -tz time zone"
+ Hostname string        alias:"host"     desc:"Name of the node, blank for cluster-level data (sacct, cluzter)"
+ Type     string        alias:"type"     desc:"Data type: sample, sysinfo, sacct, cluzter, gpu, or disk"
+ Records  int           alias:"records"  desc:"Number of records with a timestamp on the day"
+ Interval DurationValue alias:"interval" desc:"Sampling interval observed for the host and type across the time window"
+ Gaps     int           alias:"gaps"     desc:"Number of gaps that start on the day"
+ Down     DurationValue alias:"down"     desc:"Time on the day in gaps during which the node rebooted"
+ Missing  DurationValue alias:"missing"  desc:"Time on the day in gaps during which the node did not reboot"
+ Unknown  DurationValue alias:"unknown"  desc:"Time on the day in gaps with no boot information"
+
+GENERATE coverageRecord
+
+SUMMARY CoverageCommand
+
+Experimental: Report where data are present and where they are missing.
+
+For each host and data type there is one output record per day in the time
+window, with the number of records that day.  Days without records are
+included for every host and type that has some records in the window.  The
+data types are:
+
+  sample   process samples, including heartbeats
+  sysinfo  node configuration
+  sacct    Slurm job records (cluster-level)
+  cluzter  Slurm node and partition state (cluster-level)
+  gpu      GPU samples
+  disk     disk samples
+
+The sampling interval is the median time between consecutive distinct
+timestamps, and a gap is a time between consecutive timestamps that is longer
+than -gap-factor times the interval.  The time from the start of the window to
+the first timestamp and from the last timestamp to the end of the window (or
+now, if that is earlier) are gaps too if they are that long.  A gap is counted
+on the day it starts, and its time is divided among the days it spans.
+
+The boot times in the node samples are used to tell downtime from missing
+data.  A gap during which the node booted is downtime, a gap during which the
+node did not boot is missing data, and a gap for which there is no boot
+information is unknown.  Cluster-level data are only reported when no -host
+is given, and their gaps are always unknown.
+
+Older sacct records do not carry the time they were collected, and for those
+the job's end time is used instead.  The interval and gaps for such data are
+therefore approximate.
+
+Use -gaps to list the gaps individually instead, see -gaps -fmt help.
+
+HELP
+
+  Print record counts, the observed sampling interval, and gaps per host, data
+  type, and day.  Output records are sorted by host, type, and date.  The
+  default format is 'fixed'.
+
+ALIASES
+
+  default  date,host,type,records,interval,gaps,down,missing,unknown
+  Default  Date,Hostname,Type,Records,Interval,Gaps,Down,Missing,Unknown
+  all      default
+  All      Default
+
+DEFAULTS default
+
+ELBAT*/
+
+var dataTypes = []string{"sample", "sysinfo", "sacct", "cluzter", "gpu", "disk"}
+
+type CoverageCommand struct /* implements SimpleCommand */ {
+	HostAnalysisArgs
+	FormatArgs
+
+	Type      []string
+	GapFactor float64
+	Gaps      bool
+}
+
+var _ = SimpleCommand((*CoverageCommand)(nil))
+
+func (cc *CoverageCommand) Add(fs *CLI) {
+	cc.HostAnalysisArgs.Add(fs)
+	cc.FormatArgs.Add(fs)
+
+	fs.Group("application-control")
+	fs.Var(NewRepeatableString(&cc.Type), "type",
+		"Report on these data `types,...`: sample, sysinfo, sacct, cluzter, gpu, disk [default: all]")
+	fs.Float64Var(&cc.GapFactor, "gap-factor", 3,
+		"A gap is a time between records longer than this `factor` times the sampling interval")
+
+	fs.Group("printing")
+	fs.BoolVar(&cc.Gaps, "gaps", false, "List the gaps instead of the per-day coverage")
+}
+
+func (cc *CoverageCommand) ReifyForRemote(x *ArgReifier) error {
+	e1 := errors.Join(
+		cc.HostAnalysisArgs.ReifyForRemote(x),
+		cc.FormatArgs.ReifyForRemote(x),
+	)
+	x.RepeatableString("type", cc.Type)
+	x.Float64("gap-factor", cc.GapFactor)
+	x.Bool("gaps", cc.Gaps)
+	return e1
+}
+
+func (cc *CoverageCommand) MaybeFormatHelp() *FormatHelp {
+	if cc.Gaps {
+		return StandardFormatHelp(cc.Fmt, gapHelp, gapFormatters, gapAliases, gapDefaultFields)
+	}
+	return StandardFormatHelp(cc.Fmt, coverageHelp, coverageFormatters, coverageAliases, coverageDefaultFields)
+}
+
+func (cc *CoverageCommand) Validate() error {
+	var e1, e2, e3 error
+	if len(cc.Type) == 0 {
+		cc.Type = dataTypes
+	}
+	for _, t := range cc.Type {
+		if !slices.Contains(dataTypes, t) {
+			e1 = errors.Join(e1,
+				fmt.Errorf("Bad -type value %s, must be sample, sysinfo, sacct, cluzter, gpu, or disk", t))
+		}
+	}
+	if cc.GapFactor <= 1 {
+		e2 = errors.New("-gap-factor must be greater than 1")
+	}
+	if cc.Gaps {
+		e3 = ValidateFormatArgs(
+			&cc.FormatArgs, gapDefaultFields, gapFormatters, gapAliases, DefaultFixed)
+	} else {
+		e3 = ValidateFormatArgs(
+			&cc.FormatArgs, coverageDefaultFields, coverageFormatters, coverageAliases, DefaultFixed)
+	}
+	return errors.Join(cc.HostAnalysisArgs.Validate(), e1, e2, e3)
+}
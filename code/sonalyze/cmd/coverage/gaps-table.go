@@ -0,0 +1,188 @@
+// DO NOT EDIT.  Generated from gaps.go by generate-table
+
+package coverage
+
+import (
+	"cmp"
+	"fmt"
+	"go-utils/gpuset"
+	"io"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+var (
+	_ = cmp.Compare(0, 0)
+	_ fmt.Formatter
+	_ = io.SeekStart
+	_ = UstrEmpty
+	_ gpuset.GpuSet
+)
+
+// MT: Constant after initialization; immutable
+var gapFormatters = map[string]Formatter[*gapRecord]{
+	"Hostname": {
+		Fmt: func(d *gapRecord, ctx PrintMods) string {
+			return FormatString((d.Hostname), ctx)
+		},
+		Xtract: func(d *gapRecord) any {
+			return d.Hostname
+		},
+		Help: "(string) Name of the node, blank for cluster-level data (sacct, cluzter)",
+	},
+	"Type": {
+		Fmt: func(d *gapRecord, ctx PrintMods) string {
+			return FormatString((d.Type), ctx)
+		},
+		Xtract: func(d *gapRecord) any {
+			return d.Type
+		},
+		Help: "(string) Data type: sample, sysinfo, sacct, cluzter, gpu, or disk",
+	},
+	"Start": {
+		Fmt: func(d *gapRecord, ctx PrintMods) string {
+			return FormatDateTimeValue((d.Start), ctx)
+		},
+		Xtract: func(d *gapRecord) any {
+			return d.Start
+		},
+		Help: "(DateTimeValue) Timestamp of the last record before the gap, or the start of the window",
+	},
+	"End": {
+		Fmt: func(d *gapRecord, ctx PrintMods) string {
+			return FormatDateTimeValue((d.End), ctx)
+		},
+		Xtract: func(d *gapRecord) any {
+			return d.End
+		},
+		Help: "(DateTimeValue) Timestamp of the first record after the gap, or the end of the window",
+	},
+	"Duration": {
+		Fmt: func(d *gapRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.Duration), ctx)
+		},
+		Xtract: func(d *gapRecord) any {
+			return d.Duration
+		},
+		Help: "(DurationValue) Length of the gap",
+	},
+	"Interval": {
+		Fmt: func(d *gapRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.Interval), ctx)
+		},
+		Xtract: func(d *gapRecord) any {
+			return d.Interval
+		},
+		Help: "(DurationValue) Sampling interval observed for the host and type across the time window",
+	},
+	"Cause": {
+		Fmt: func(d *gapRecord, ctx PrintMods) string {
+			return FormatString((d.Cause), ctx)
+		},
+		Xtract: func(d *gapRecord) any {
+			return d.Cause
+		},
+		Help: "(string) down, missing, or unknown",
+	},
+	"Boot": {
+		Fmt: func(d *gapRecord, ctx PrintMods) string {
+			return FormatDateTimeValueOrBlank((d.Boot), ctx)
+		},
+		Xtract: func(d *gapRecord) any {
+			return d.Boot
+		},
+		Help: "(DateTimeValue) Boot time of the node during the gap, if cause is down",
+	},
+}
+
+func init() {
+	DefAlias(gapFormatters, "Hostname", "host")
+	DefAlias(gapFormatters, "Type", "type")
+	DefAlias(gapFormatters, "Start", "start")
+	DefAlias(gapFormatters, "End", "end")
+	DefAlias(gapFormatters, "Duration", "duration")
+	DefAlias(gapFormatters, "Interval", "interval")
+	DefAlias(gapFormatters, "Cause", "cause")
+	DefAlias(gapFormatters, "Boot", "boot")
+}
+
+// MT: Constant after initialization; immutable
+var gapPredicates = map[string]Predicate[*gapRecord]{
+	"Hostname": Predicate[*gapRecord]{
+		Compare: func(d *gapRecord, v any) int {
+			return cmp.Compare((d.Hostname), v.(string))
+		},
+	},
+	"Type": Predicate[*gapRecord]{
+		Compare: func(d *gapRecord, v any) int {
+			return cmp.Compare((d.Type), v.(string))
+		},
+	},
+	"Start": Predicate[*gapRecord]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *gapRecord, v any) int {
+			return cmp.Compare((d.Start), v.(DateTimeValue))
+		},
+	},
+	"End": Predicate[*gapRecord]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *gapRecord, v any) int {
+			return cmp.Compare((d.End), v.(DateTimeValue))
+		},
+	},
+	"Duration": Predicate[*gapRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *gapRecord, v any) int {
+			return cmp.Compare((d.Duration), v.(DurationValue))
+		},
+	},
+	"Interval": Predicate[*gapRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *gapRecord, v any) int {
+			return cmp.Compare((d.Interval), v.(DurationValue))
+		},
+	},
+	"Cause": Predicate[*gapRecord]{
+		Compare: func(d *gapRecord, v any) int {
+			return cmp.Compare((d.Cause), v.(string))
+		},
+	},
+	"Boot": Predicate[*gapRecord]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *gapRecord, v any) int {
+			return cmp.Compare((d.Boot), v.(DateTimeValueOrBlank))
+		},
+	},
+}
+
+type gapRecord struct {
+	Hostname string
+	Type     string
+	Start    DateTimeValue
+	End      DateTimeValue
+	Duration DurationValue
+	Interval DurationValue
+	Cause    string
+	Boot     DateTimeValueOrBlank
+}
+
+const gapHelp = `
+gap
+  One record per gap in the data, sorted by host, type, and start time.  The
+  cause is "down" if the node booted during the gap, "missing" if the node
+  samples show that it did not, and "unknown" if there is no boot information
+  for the node after the gap or the data are cluster-level.  Gaps at the
+  start and end of the window have the window's start and end as the
+  start and end time, respectively (or now, for a window that ends in the
+  future).
+`
+
+// MT: Constant after initialization; immutable
+var gapAliases = map[string][]string{
+	"default": []string{"host", "type", "start", "end", "duration", "cause"},
+	"Default": []string{"Hostname", "Type", "Start", "End", "Duration", "Cause"},
+	"all":     []string{"host", "type", "start", "end", "duration", "interval", "cause", "boot"},
+	"All":     []string{"Hostname", "Type", "Start", "End", "Duration", "Interval", "Cause", "Boot"},
+}
+
+const gapDefaultFields = "default"
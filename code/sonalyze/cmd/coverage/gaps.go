@@ -0,0 +1,43 @@
+package coverage
+
+//go:generate ../../../generate-table/generate-table -o gaps-table.go gaps.go
+
+/*TABLE gap
+
+package coverage
+
+%%
+
+FIELDS *gapRecord
+
+ Hostname string               alias:"host"     desc:"Name of the node, blank for cluster-level data (sacct, cluzter)"
+ Type     string               alias:"type"     desc:"Data type: sample, sysinfo, sacct, cluzter, gpu, or disk"
+ Start    DateTimeValue        alias:"start"    desc:"Timestamp of the last record before the gap, or the start of the window"
+ End      DateTimeValue        alias:"end"      desc:"Timestamp of the first record after the gap, or the end of the window"
+ Duration DurationValue        alias:"duration" desc:"Length of the gap"
+ Interval DurationValue        alias:"interval" desc:"Sampling interval observed for the host and type across the time window"
+ Cause    string               alias:"cause"    desc:"down, missing, or unknown"
+ Boot     DateTimeValueOrBlank alias:"boot"     desc:"Boot time of the node during the gap, if cause is down"
+
+GENERATE gapRecord
+
+HELP
+
+  One record per gap in the data, sorted by host, type, and start time.  The
+  cause is "down" if the node booted during the gap, "missing" if the node
+  samples show that it did not, and "unknown" if there is no boot information
+  for the node after the gap or the data are cluster-level.  Gaps at the
+  start and end of the window have the window's start and end as the
+  start and end time, respectively (or now, for a window that ends in the
+  future).
+
+ALIASES
+
+  default  host,type,start,end,duration,cause
+  Default  Hostname,Type,Start,End,Duration,Cause
+  all      host,type,start,end,duration,interval,cause,boot
+  All      Hostname,Type,Start,End,Duration,Interval,Cause,Boot
+
+DEFAULTS default
+
+ELBAT*/
@@ -0,0 +1,389 @@
+package coverage
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	. "sonalyze/common"
+	"sonalyze/data/common"
+	"sonalyze/data/disksample"
+	"sonalyze/data/gpusample"
+	"sonalyze/data/node"
+	"sonalyze/data/nodesample"
+	"sonalyze/data/sample"
+	"sonalyze/data/slurmjob"
+	"sonalyze/data/slurmnode"
+	"sonalyze/data/slurmpart"
+	"sonalyze/db/repr"
+	"sonalyze/db/types"
+	. "sonalyze/table"
+)
+
+type timelineKey struct {
+	host     string // "" for cluster-level data
+	dataType string
+}
+
+// The timestamps of all the records of one type for one host, in no particular order and with
+// duplicates.
+type timelines map[timelineKey][]int64
+
+func (cc *CoverageCommand) Perform(meta types.Context, _ io.Reader, stdout, _ io.Writer) error {
+	host, err := common.ResolveHostQuery(meta, cc.Host, cc.FromDate, cc.ToDate)
+	if err != nil {
+		return err
+	}
+	filter := common.QueryFilter{
+		HaveFrom: cc.HaveFrom,
+		FromDate: cc.FromDate,
+		HaveTo:   cc.HaveTo,
+		ToDate:   cc.ToDate,
+		Host:     host,
+	}
+
+	tls := make(timelines)
+	for _, t := range cc.Type {
+		if (t == "sacct" || t == "cluzter") && !host.IsEmpty() {
+			continue
+		}
+		if err := cc.readTimelines(meta, filter, t, tls); err != nil {
+			return err
+		}
+	}
+	boots, err := cc.bootTimes(meta, filter)
+	if err != nil {
+		return err
+	}
+
+	var (
+		days []*coverageRecord
+		gaps []*gapRecord
+	)
+	loc := cc.TimeZone()
+	for k, times := range tls {
+		d, g := analyzeTimeline(k, times, boots[k.host], cc.GapFactor, cc.FromDate, cc.ToDate, loc)
+		days = append(days, d...)
+		gaps = append(gaps, g...)
+	}
+
+	if cc.Gaps {
+		gaps, err = ApplyQuery(cc.ParsedQuery, gapFormatters, gapPredicates, gaps)
+		if err != nil {
+			return err
+		}
+		slices.SortFunc(gaps, func(a, b *gapRecord) int {
+			if c := cmp.Compare(a.Hostname, b.Hostname); c != 0 {
+				return c
+			}
+			if c := cmp.Compare(a.Type, b.Type); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Start, b.Start)
+		})
+		FormatData(stdout, cc.PrintFields, gapFormatters, cc.PrintOpts, gaps)
+		return nil
+	}
+
+	days, err = ApplyQuery(cc.ParsedQuery, coverageFormatters, coveragePredicates, days)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(days, func(a, b *coverageRecord) int {
+		if c := cmp.Compare(a.Hostname, b.Hostname); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Date, b.Date)
+	})
+	FormatData(stdout, cc.PrintFields, coverageFormatters, cc.PrintOpts, days)
+	return nil
+}
+
+// Add the timestamps of the records of type `dataType` to `tls`.
+
+func (cc *CoverageCommand) readTimelines(
+	meta types.Context,
+	filter common.QueryFilter,
+	dataType string,
+	tls timelines,
+) error {
+	add := func(host string, t int64) {
+		k := timelineKey{host, dataType}
+		tls[k] = append(tls[k], t)
+	}
+
+	switch dataType {
+	case "sample":
+		sdp, err := sample.OpenSampleDataProvider(meta)
+		if err != nil {
+			return err
+		}
+		blobs, _, err := sdp.QueryRaw(filter.FromDate, filter.ToDate, filter.Host)
+		if err != nil {
+			return fmt.Errorf("Failed to read log records: %v", err)
+		}
+		for _, blob := range blobs {
+			for _, s := range blob {
+				add(s.Hostname.String(), s.Timestamp)
+			}
+		}
+
+	case "sysinfo":
+		ndp, err := node.OpenNodeDataProvider(meta)
+		if err != nil {
+			return err
+		}
+		records, err := ndp.Query(filter)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			if t, ok := parseTime(r.Time); ok {
+				add(r.Node, t)
+			}
+		}
+
+	case "sacct":
+		sdp, err := slurmjob.OpenSlurmjobDataProvider(meta)
+		if err != nil {
+			return err
+		}
+		jobs, err := sdp.Query(slurmjob.QueryFilter{QueryFilter: filter})
+		if err != nil {
+			return err
+		}
+		for _, j := range jobs {
+			add("", sacctTime(j.Main))
+			for _, s := range j.Steps {
+				add("", sacctTime(s))
+			}
+		}
+
+	case "cluzter":
+		ndp, err := slurmnode.OpenSlurmNodeDataProvider(meta)
+		if err != nil {
+			return err
+		}
+		nodes, err := ndp.Query(filter)
+		if err != nil {
+			return fmt.Errorf("Failed to read log records: %v", err)
+		}
+		for _, r := range nodes {
+			if t, ok := parseTime(r.Time); ok {
+				add("", t)
+			}
+		}
+		pdp, err := slurmpart.OpenSlurmPartitionDataProvider(meta)
+		if err != nil {
+			return err
+		}
+		partitions, err := pdp.Query(filter)
+		if err != nil {
+			return fmt.Errorf("Failed to read log records: %v", err)
+		}
+		for _, r := range partitions {
+			if t, ok := parseTime(r.Time); ok {
+				add("", t)
+			}
+		}
+
+	case "gpu":
+		gdp, err := gpusample.OpenGpuSampleDataProvider(meta)
+		if err != nil {
+			return err
+		}
+		streams, _, _, _, err := gdp.Query(filter.FromDate, filter.ToDate, filter.Host)
+		if err != nil {
+			return fmt.Errorf("Failed to read log records: %v", err)
+		}
+		for _, s := range streams {
+			for _, d := range s.Data {
+				add(s.Hostname.String(), d.Time)
+			}
+		}
+
+	case "disk":
+		ddp, err := disksample.OpenDiskSampleDataProvider(meta)
+		if err != nil {
+			return err
+		}
+		records, err := ddp.Query(filter)
+		if err != nil {
+			return fmt.Errorf("Failed to read log records: %v", err)
+		}
+		for _, r := range records {
+			add(r.Hostname.String(), r.Timestamp)
+		}
+
+	default:
+		panic("Unexpected data type")
+	}
+	return nil
+}
+
+// Older sacct data have no record timestamp, but the job's end time is close to the time the record
+// was collected.
+
+func sacctTime(r *repr.SacctInfo) int64 {
+	if r.Time != 0 {
+		return r.Time
+	}
+	return r.End
+}
+
+func parseTime(s string) (int64, bool) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Unix(), true
+}
+
+// Return the node samples for each host, sorted by ascending time.  These carry the boot times.
+
+func (cc *CoverageCommand) bootTimes(
+	meta types.Context,
+	filter common.QueryFilter,
+) (map[string][]*repr.NodeSample, error) {
+	nsp, err := nodesample.OpenNodeSampleDataProvider(meta)
+	if err != nil {
+		return nil, err
+	}
+	records, err := nsp.Query(filter)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read log records: %v", err)
+	}
+	boots := make(map[string][]*repr.NodeSample)
+	for _, r := range records {
+		if r.Boot != 0 {
+			h := r.Hostname.String()
+			boots[h] = append(boots[h], r)
+		}
+	}
+	for _, samples := range boots {
+		slices.SortFunc(samples, func(a, b *repr.NodeSample) int {
+			return cmp.Compare(a.Timestamp, b.Timestamp)
+		})
+	}
+	return boots, nil
+}
+
+// Compute the per-day coverage and the gaps for one timeline.  Days run from the day of `from` to
+// the day of `to`, inclusive, in `loc`.  Timestamps outside the window are ignored.  The time from
+// `from` to the first record and from the last record to `to` (or now, if earlier) are gaps too if
+// they are long enough.
+
+func analyzeTimeline(
+	k timelineKey,
+	times []int64,
+	nodeSamples []*repr.NodeSample,
+	gapFactor float64,
+	from, to time.Time,
+	loc *time.Location,
+) ([]*coverageRecord, []*gapRecord) {
+	fromT, toT := from.Unix(), to.Unix()
+	times = slices.DeleteFunc(times, func(t int64) bool {
+		return t < fromT || t > toT
+	})
+	if len(times) == 0 {
+		return nil, nil
+	}
+	slices.Sort(times)
+
+	days := make(map[int64]*coverageRecord)
+	for d := TruncateToDay(fromT, loc); d <= toT; d = AddDay(d, loc) {
+		days[d] = &coverageRecord{Date: DateValue(d), Hostname: k.host, Type: k.dataType}
+	}
+	for _, t := range times {
+		days[TruncateToDay(t, loc)].Records++
+	}
+
+	distinct := slices.Compact(times)
+	interval := medianInterval(distinct)
+	gaps := make([]*gapRecord, 0)
+	if interval > 0 {
+		threshold := int64(gapFactor * float64(interval))
+		addGap := func(start, end int64) {
+			if end-start <= threshold {
+				return
+			}
+			g := &gapRecord{
+				Hostname: k.host,
+				Type:     k.dataType,
+				Start:    DateTimeValue(start),
+				End:      DateTimeValue(end),
+				Duration: DurationValue(end - start),
+				Interval: DurationValue(interval),
+				Cause:    "unknown",
+			}
+			if k.host != "" {
+				if boot, known := bootAfter(nodeSamples, end); known {
+					if boot > start && boot <= end {
+						g.Cause = "down"
+						g.Boot = DateTimeValueOrBlank(boot)
+					} else if boot <= start {
+						g.Cause = "missing"
+					}
+				}
+			}
+			gaps = append(gaps, g)
+
+			days[TruncateToDay(start, loc)].Gaps++
+			for d := TruncateToDay(start, loc); d < end; d = AddDay(d, loc) {
+				overlap := DurationValue(min(end, AddDay(d, loc)) - max(start, d))
+				switch g.Cause {
+				case "down":
+					days[d].Down += overlap
+				case "missing":
+					days[d].Missing += overlap
+				default:
+					days[d].Unknown += overlap
+				}
+			}
+		}
+		addGap(fromT, distinct[0])
+		for i := 1; i < len(distinct); i++ {
+			addGap(distinct[i-1], distinct[i])
+		}
+		addGap(distinct[len(distinct)-1], min(toT, time.Now().Unix()))
+	}
+
+	records := make([]*coverageRecord, 0, len(days))
+	for _, d := range days {
+		d.Interval = DurationValue(interval)
+		records = append(records, d)
+	}
+	return records, gaps
+}
+
+// The boot time of the first node sample at or after `t`.  If the node booted more than once after
+// `t` then this is not informative, but the caller can tell from the boot time being after the gap.
+
+func bootAfter(nodeSamples []*repr.NodeSample, t int64) (int64, bool) {
+	ix, _ := slices.BinarySearchFunc(nodeSamples, t, func(s *repr.NodeSample, t int64) int {
+		return cmp.Compare(s.Timestamp, t)
+	})
+	if ix == len(nodeSamples) {
+		return 0, false
+	}
+	return nodeSamples[ix].Boot, true
+}
+
+// The median of the differences between consecutive timestamps, which must be sorted and distinct.
+
+func medianInterval(times []int64) int64 {
+	if len(times) < 2 {
+		return 0
+	}
+	deltas := make([]int64, len(times)-1)
+	for i := 1; i < len(times); i++ {
+		deltas[i-1] = times[i] - times[i-1]
+	}
+	slices.Sort(deltas)
+	return deltas[(len(deltas)-1)/2]
+}
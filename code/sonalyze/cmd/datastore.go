@@ -10,6 +10,7 @@ import (
 
 func OpenDataStoreFromCommand(anyCmd Command) (err error) {
 	db.SetCacheSize(anyCmd.CacheSize())
+	db.SetDiskCacheDir(anyCmd.DiskCacheDir())
 	if jd := anyCmd.JobanalyzerDir(); jd != "" {
 		err = db.OpenFullDataStore(jd, anyCmd.DatabaseURI())
 	} else if dburi := anyCmd.DatabaseURI(); dburi != "" {
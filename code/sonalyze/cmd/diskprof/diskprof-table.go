@@ -68,6 +68,24 @@ var diskprofFormatters = map[string]Formatter[*repr.DiskSample]{
 		},
 		Help: "(uint64) Minor device number",
 	},
+	"ReadsCompleted": {
+		Fmt: func(d *repr.DiskSample, ctx PrintMods) string {
+			return FormatUint64((d.ReadsCompleted), ctx)
+		},
+		Xtract: func(d *repr.DiskSample) any {
+			return d.ReadsCompleted
+		},
+		Help: "(uint64) Reads completed",
+	},
+	"SectorsRead": {
+		Fmt: func(d *repr.DiskSample, ctx PrintMods) string {
+			return FormatUint64((d.SectorsRead), ctx)
+		},
+		Xtract: func(d *repr.DiskSample) any {
+			return d.SectorsRead
+		},
+		Help: "(uint64) Sectors (512 bytes) read",
+	},
 	"MsReading": {
 		Fmt: func(d *repr.DiskSample, ctx PrintMods) string {
 			return FormatUint64((d.MsReading), ctx)
@@ -77,6 +95,24 @@ var diskprofFormatters = map[string]Formatter[*repr.DiskSample]{
 		},
 		Help: "(uint64) ms spent reading",
 	},
+	"WritesCompleted": {
+		Fmt: func(d *repr.DiskSample, ctx PrintMods) string {
+			return FormatUint64((d.WritesCompleted), ctx)
+		},
+		Xtract: func(d *repr.DiskSample) any {
+			return d.WritesCompleted
+		},
+		Help: "(uint64) Writes completed",
+	},
+	"SectorsWritten": {
+		Fmt: func(d *repr.DiskSample, ctx PrintMods) string {
+			return FormatUint64((d.SectorsWritten), ctx)
+		},
+		Xtract: func(d *repr.DiskSample) any {
+			return d.SectorsWritten
+		},
+		Help: "(uint64) Sectors (512 bytes) written",
+	},
 	"MsWriting": {
 		Fmt: func(d *repr.DiskSample, ctx PrintMods) string {
 			return FormatUint64((d.MsWriting), ctx)
@@ -86,6 +122,33 @@ var diskprofFormatters = map[string]Formatter[*repr.DiskSample]{
 		},
 		Help: "(uint64) ms spent writing",
 	},
+	"IOsInProgress": {
+		Fmt: func(d *repr.DiskSample, ctx PrintMods) string {
+			return FormatUint64((d.IOsInProgress), ctx)
+		},
+		Xtract: func(d *repr.DiskSample) any {
+			return d.IOsInProgress
+		},
+		Help: "(uint64) I/Os currently in progress",
+	},
+	"MsDoingIO": {
+		Fmt: func(d *repr.DiskSample, ctx PrintMods) string {
+			return FormatUint64((d.MsDoingIO), ctx)
+		},
+		Xtract: func(d *repr.DiskSample) any {
+			return d.MsDoingIO
+		},
+		Help: "(uint64) ms spent doing I/Os",
+	},
+	"WeightedMsDoingIO": {
+		Fmt: func(d *repr.DiskSample, ctx PrintMods) string {
+			return FormatUint64((d.WeightedMsDoingIO), ctx)
+		},
+		Xtract: func(d *repr.DiskSample) any {
+			return d.WeightedMsDoingIO
+		},
+		Help: "(uint64) Weighted ms spent doing I/Os",
+	},
 }
 
 func init() {
@@ -94,8 +157,15 @@ func init() {
 	DefAlias(diskprofFormatters, "Name", "name")
 	DefAlias(diskprofFormatters, "Major", "major")
 	DefAlias(diskprofFormatters, "Minor", "minor")
+	DefAlias(diskprofFormatters, "ReadsCompleted", "reads")
+	DefAlias(diskprofFormatters, "SectorsRead", "sectors-read")
 	DefAlias(diskprofFormatters, "MsReading", "ms-reading")
+	DefAlias(diskprofFormatters, "WritesCompleted", "writes")
+	DefAlias(diskprofFormatters, "SectorsWritten", "sectors-written")
 	DefAlias(diskprofFormatters, "MsWriting", "ms-writing")
+	DefAlias(diskprofFormatters, "IOsInProgress", "ios-in-progress")
+	DefAlias(diskprofFormatters, "MsDoingIO", "ms-doing-io")
+	DefAlias(diskprofFormatters, "WeightedMsDoingIO", "weighted-ms-doing-io")
 }
 
 // MT: Constant after initialization; immutable
@@ -130,18 +200,60 @@ var diskprofPredicates = map[string]Predicate[*repr.DiskSample]{
 			return cmp.Compare((d.Minor), v.(uint64))
 		},
 	},
+	"ReadsCompleted": Predicate[*repr.DiskSample]{
+		Convert: CvtString2Uint64,
+		Compare: func(d *repr.DiskSample, v any) int {
+			return cmp.Compare((d.ReadsCompleted), v.(uint64))
+		},
+	},
+	"SectorsRead": Predicate[*repr.DiskSample]{
+		Convert: CvtString2Uint64,
+		Compare: func(d *repr.DiskSample, v any) int {
+			return cmp.Compare((d.SectorsRead), v.(uint64))
+		},
+	},
 	"MsReading": Predicate[*repr.DiskSample]{
 		Convert: CvtString2Uint64,
 		Compare: func(d *repr.DiskSample, v any) int {
 			return cmp.Compare((d.MsReading), v.(uint64))
 		},
 	},
+	"WritesCompleted": Predicate[*repr.DiskSample]{
+		Convert: CvtString2Uint64,
+		Compare: func(d *repr.DiskSample, v any) int {
+			return cmp.Compare((d.WritesCompleted), v.(uint64))
+		},
+	},
+	"SectorsWritten": Predicate[*repr.DiskSample]{
+		Convert: CvtString2Uint64,
+		Compare: func(d *repr.DiskSample, v any) int {
+			return cmp.Compare((d.SectorsWritten), v.(uint64))
+		},
+	},
 	"MsWriting": Predicate[*repr.DiskSample]{
 		Convert: CvtString2Uint64,
 		Compare: func(d *repr.DiskSample, v any) int {
 			return cmp.Compare((d.MsWriting), v.(uint64))
 		},
 	},
+	"IOsInProgress": Predicate[*repr.DiskSample]{
+		Convert: CvtString2Uint64,
+		Compare: func(d *repr.DiskSample, v any) int {
+			return cmp.Compare((d.IOsInProgress), v.(uint64))
+		},
+	},
+	"MsDoingIO": Predicate[*repr.DiskSample]{
+		Convert: CvtString2Uint64,
+		Compare: func(d *repr.DiskSample, v any) int {
+			return cmp.Compare((d.MsDoingIO), v.(uint64))
+		},
+	},
+	"WeightedMsDoingIO": Predicate[*repr.DiskSample]{
+		Convert: CvtString2Uint64,
+		Compare: func(d *repr.DiskSample, v any) int {
+			return cmp.Compare((d.WeightedMsDoingIO), v.(uint64))
+		},
+	},
 }
 
 func (c *DiskProfCommand) Summary(out io.Writer) {
@@ -153,16 +265,16 @@ const diskprofHelp = `
 diskprof
   Extract disk profiling data from sample data and present it in primitive form.  Output
   records are sorted by node name and time.  The default format is 'fixed'.
-`
 
-func (c *DiskProfCommand) MaybeFormatHelp() *FormatHelp {
-	return StandardFormatHelp(c.Fmt, diskprofHelp, diskprofFormatters, diskprofAliases, diskprofDefaultFields)
-}
+  The counters are cumulative since the node was booted.  With -rate, the
+  rates per device between consecutive samples are printed instead, see
+  -rate -fmt help for those fields.
+`
 
 // MT: Constant after initialization; immutable
 var diskprofAliases = map[string][]string{
 	"Default": []string{"Timestamp", "Hostname", "Name", "MsReading", "MsWriting"},
-	"All":     []string{"Timestamp", "Hostname", "Name", "Major", "Minor", "MsReading", "MsWriting"},
+	"All":     []string{"Timestamp", "Hostname", "Name", "Major", "Minor", "ReadsCompleted", "SectorsRead", "MsReading", "WritesCompleted", "SectorsWritten", "MsWriting", "IOsInProgress", "MsDoingIO", "WeightedMsDoingIO"},
 }
 
 const diskprofDefaultFields = "Default"
@@ -32,22 +32,35 @@ FIELDS *repr.DiskSample
   Name             Ustr          desc:"Name of disk" alias:"name"
   Major            uint64        desc:"Major device number" alias:"major"
   Minor            uint64        desc:"Minor device number" alias:"minor"
+  ReadsCompleted   uint64        desc:"Reads completed" alias:"reads"
+  SectorsRead      uint64        desc:"Sectors (512 bytes) read" alias:"sectors-read"
   MsReading        uint64        desc:"ms spent reading" alias:"ms-reading"
+  WritesCompleted  uint64        desc:"Writes completed" alias:"writes"
+  SectorsWritten   uint64        desc:"Sectors (512 bytes) written" alias:"sectors-written"
   MsWriting        uint64        desc:"ms spent writing" alias:"ms-writing"
+  IOsInProgress    uint64        desc:"I/Os currently in progress" alias:"ios-in-progress"
+  MsDoingIO        uint64        desc:"ms spent doing I/Os" alias:"ms-doing-io"
+  WeightedMsDoingIO \
+                   uint64        desc:"Weighted ms spent doing I/Os" alias:"weighted-ms-doing-io"
 
 SUMMARY DiskProfCommand
 
   Display disk sample data
 
-HELP DiskProfCommand
+HELP
 
   Extract disk profiling data from sample data and present it in primitive form.  Output
   records are sorted by node name and time.  The default format is 'fixed'.
 
+  The counters are cumulative since the node was booted.  With -rate, the
+  rates per device between consecutive samples are printed instead, see
+  -rate -fmt help for those fields.
+
 ALIASES
 
   Default Timestamp,Hostname,Name,MsReading,MsWriting
-  All     Timestamp,Hostname,Name,Major,Minor,MsReading,MsWriting
+  All     Timestamp,Hostname,Name,Major,Minor,ReadsCompleted,SectorsRead,MsReading,WritesCompleted,\
+          SectorsWritten,MsWriting,IOsInProgress,MsDoingIO,WeightedMsDoingIO
 
 DEFAULTS Default
 
@@ -56,6 +69,7 @@ ELBAT*/
 type DiskProfCommand struct {
 	HostAnalysisArgs
 	FormatArgs
+	Rate bool
 }
 
 var _ = SimpleCommand((*DiskProfCommand)(nil))
@@ -63,17 +77,37 @@ var _ = SimpleCommand((*DiskProfCommand)(nil))
 func (nc *DiskProfCommand) Add(fs *CLI) {
 	nc.HostAnalysisArgs.Add(fs)
 	nc.FormatArgs.Add(fs)
+
+	fs.Group("printing")
+	fs.BoolVar(&nc.Rate, "rate", false,
+		"Print read/write rates, IOPS and utilization per device between consecutive samples")
 }
 
 func (nc *DiskProfCommand) ReifyForRemote(x *ArgReifier) error {
 	// As per normal, do not forward VerboseArgs.
-	return errors.Join(
+	e1 := errors.Join(
 		nc.HostAnalysisArgs.ReifyForRemote(x),
 		nc.FormatArgs.ReifyForRemote(x),
 	)
+	x.Bool("rate", nc.Rate)
+	return e1
+}
+
+func (nc *DiskProfCommand) MaybeFormatHelp() *FormatHelp {
+	if nc.Rate {
+		return StandardFormatHelp(nc.Fmt, diskrateHelp, diskrateFormatters, diskrateAliases, diskrateDefaultFields)
+	}
+	return StandardFormatHelp(nc.Fmt, diskprofHelp, diskprofFormatters, diskprofAliases, diskprofDefaultFields)
 }
 
 func (nc *DiskProfCommand) Validate() error {
+	if nc.Rate {
+		return errors.Join(
+			nc.HostAnalysisArgs.Validate(),
+			ValidateFormatArgs(
+				&nc.FormatArgs, diskrateDefaultFields, diskrateFormatters, diskrateAliases, DefaultFixed),
+		)
+	}
 	return errors.Join(
 		nc.HostAnalysisArgs.Validate(),
 		ValidateFormatArgs(
@@ -107,6 +141,10 @@ func (nc *DiskProfCommand) Perform(meta types.Context, _ io.Reader, stdout, stde
 		return fmt.Errorf("Failed to read log records: %v", err)
 	}
 
+	if nc.Rate {
+		return nc.performRate(meta, host, records, stdout)
+	}
+
 	records, err = ApplyQuery(nc.ParsedQuery, diskprofFormatters, diskprofPredicates, records)
 	if err != nil {
 		return err
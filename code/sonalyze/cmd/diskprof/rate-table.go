@@ -0,0 +1,238 @@
+// DO NOT EDIT.  Generated from rate.go by generate-table
+
+package diskprof
+
+import (
+	"cmp"
+	"fmt"
+	"go-utils/gpuset"
+	"io"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+var (
+	_ = cmp.Compare(0, 0)
+	_ fmt.Formatter
+	_ = io.SeekStart
+	_ = UstrEmpty
+	_ gpuset.GpuSet
+)
+
+// MT: Constant after initialization; immutable
+var diskrateFormatters = map[string]Formatter[*diskRate]{
+	"Timestamp": {
+		Fmt: func(d *diskRate, ctx PrintMods) string {
+			return FormatDateTimeValue((d.Timestamp), ctx)
+		},
+		Xtract: func(d *diskRate) any {
+			return d.Timestamp
+		},
+		Help: "(DateTimeValue) Full ISO timestamp of the end of the interval",
+	},
+	"Hostname": {
+		Fmt: func(d *diskRate, ctx PrintMods) string {
+			return FormatUstr((d.Hostname), ctx)
+		},
+		Xtract: func(d *diskRate) any {
+			return d.Hostname
+		},
+		Help: "(string) Name that host is known by on the cluster",
+	},
+	"Name": {
+		Fmt: func(d *diskRate, ctx PrintMods) string {
+			return FormatUstr((d.Name), ctx)
+		},
+		Xtract: func(d *diskRate) any {
+			return d.Name
+		},
+		Help: "(string) Name of disk",
+	},
+	"Major": {
+		Fmt: func(d *diskRate, ctx PrintMods) string {
+			return FormatUint64((d.Major), ctx)
+		},
+		Xtract: func(d *diskRate) any {
+			return d.Major
+		},
+		Help: "(uint64) Major device number",
+	},
+	"Minor": {
+		Fmt: func(d *diskRate, ctx PrintMods) string {
+			return FormatUint64((d.Minor), ctx)
+		},
+		Xtract: func(d *diskRate) any {
+			return d.Minor
+		},
+		Help: "(uint64) Minor device number",
+	},
+	"Interval": {
+		Fmt: func(d *diskRate, ctx PrintMods) string {
+			return FormatDurationValue((d.Interval), ctx)
+		},
+		Xtract: func(d *diskRate) any {
+			return d.Interval
+		},
+		Help: "(DurationValue) Length of the interval since the previous sample",
+	},
+	"ReadMBs": {
+		Fmt: func(d *diskRate, ctx PrintMods) string {
+			return FormatFloat64((d.ReadMBs), ctx)
+		},
+		Xtract: func(d *diskRate) any {
+			return d.ReadMBs
+		},
+		Help: "(float64) Data read in MB/s",
+	},
+	"WriteMBs": {
+		Fmt: func(d *diskRate, ctx PrintMods) string {
+			return FormatFloat64((d.WriteMBs), ctx)
+		},
+		Xtract: func(d *diskRate) any {
+			return d.WriteMBs
+		},
+		Help: "(float64) Data written in MB/s",
+	},
+	"ReadIOPS": {
+		Fmt: func(d *diskRate, ctx PrintMods) string {
+			return FormatFloat64((d.ReadIOPS), ctx)
+		},
+		Xtract: func(d *diskRate) any {
+			return d.ReadIOPS
+		},
+		Help: "(float64) Reads completed per second",
+	},
+	"WriteIOPS": {
+		Fmt: func(d *diskRate, ctx PrintMods) string {
+			return FormatFloat64((d.WriteIOPS), ctx)
+		},
+		Xtract: func(d *diskRate) any {
+			return d.WriteIOPS
+		},
+		Help: "(float64) Writes completed per second",
+	},
+	"UtilPct": {
+		Fmt: func(d *diskRate, ctx PrintMods) string {
+			return FormatFloat64((d.UtilPct), ctx)
+		},
+		Xtract: func(d *diskRate) any {
+			return d.UtilPct
+		},
+		Help: "(float64) Percentage of the interval the device was busy doing I/O",
+	},
+}
+
+func init() {
+	DefAlias(diskrateFormatters, "Timestamp", "timestamp")
+	DefAlias(diskrateFormatters, "Hostname", "host")
+	DefAlias(diskrateFormatters, "Name", "name")
+	DefAlias(diskrateFormatters, "Major", "major")
+	DefAlias(diskrateFormatters, "Minor", "minor")
+	DefAlias(diskrateFormatters, "Interval", "interval")
+	DefAlias(diskrateFormatters, "ReadMBs", "read")
+	DefAlias(diskrateFormatters, "WriteMBs", "write")
+	DefAlias(diskrateFormatters, "ReadIOPS", "read-iops")
+	DefAlias(diskrateFormatters, "WriteIOPS", "write-iops")
+	DefAlias(diskrateFormatters, "UtilPct", "util")
+}
+
+// MT: Constant after initialization; immutable
+var diskratePredicates = map[string]Predicate[*diskRate]{
+	"Timestamp": Predicate[*diskRate]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *diskRate, v any) int {
+			return cmp.Compare((d.Timestamp), v.(DateTimeValue))
+		},
+	},
+	"Hostname": Predicate[*diskRate]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *diskRate, v any) int {
+			return cmp.Compare((d.Hostname), v.(Ustr))
+		},
+	},
+	"Name": Predicate[*diskRate]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *diskRate, v any) int {
+			return cmp.Compare((d.Name), v.(Ustr))
+		},
+	},
+	"Major": Predicate[*diskRate]{
+		Convert: CvtString2Uint64,
+		Compare: func(d *diskRate, v any) int {
+			return cmp.Compare((d.Major), v.(uint64))
+		},
+	},
+	"Minor": Predicate[*diskRate]{
+		Convert: CvtString2Uint64,
+		Compare: func(d *diskRate, v any) int {
+			return cmp.Compare((d.Minor), v.(uint64))
+		},
+	},
+	"Interval": Predicate[*diskRate]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *diskRate, v any) int {
+			return cmp.Compare((d.Interval), v.(DurationValue))
+		},
+	},
+	"ReadMBs": Predicate[*diskRate]{
+		Convert: CvtString2Float64,
+		Compare: func(d *diskRate, v any) int {
+			return cmp.Compare((d.ReadMBs), v.(float64))
+		},
+	},
+	"WriteMBs": Predicate[*diskRate]{
+		Convert: CvtString2Float64,
+		Compare: func(d *diskRate, v any) int {
+			return cmp.Compare((d.WriteMBs), v.(float64))
+		},
+	},
+	"ReadIOPS": Predicate[*diskRate]{
+		Convert: CvtString2Float64,
+		Compare: func(d *diskRate, v any) int {
+			return cmp.Compare((d.ReadIOPS), v.(float64))
+		},
+	},
+	"WriteIOPS": Predicate[*diskRate]{
+		Convert: CvtString2Float64,
+		Compare: func(d *diskRate, v any) int {
+			return cmp.Compare((d.WriteIOPS), v.(float64))
+		},
+	},
+	"UtilPct": Predicate[*diskRate]{
+		Convert: CvtString2Float64,
+		Compare: func(d *diskRate, v any) int {
+			return cmp.Compare((d.UtilPct), v.(float64))
+		},
+	},
+}
+
+type diskRate struct {
+	Timestamp DateTimeValue
+	Hostname  Ustr
+	Name      Ustr
+	Major     uint64
+	Minor     uint64
+	Interval  DurationValue
+	ReadMBs   float64
+	WriteMBs  float64
+	ReadIOPS  float64
+	WriteIOPS float64
+	UtilPct   float64
+}
+
+const diskrateHelp = `
+diskrate
+  Read and write rates per device between consecutive disk samples, computed
+  from the cumulative counters.  An interval across a reboot of the node, or
+  where a counter went backwards, has no rate and is not printed.
+`
+
+// MT: Constant after initialization; immutable
+var diskrateAliases = map[string][]string{
+	"default": []string{"timestamp", "host", "name", "read", "write", "read-iops", "write-iops", "util"},
+	"Default": []string{"Timestamp", "Hostname", "Name", "ReadMBs", "WriteMBs", "ReadIOPS", "WriteIOPS", "UtilPct"},
+	"all":     []string{"timestamp", "host", "name", "major", "minor", "interval", "read", "write", "read-iops", "write-iops", "util"},
+	"All":     []string{"Timestamp", "Hostname", "Name", "Major", "Minor", "Interval", "ReadMBs", "WriteMBs", "ReadIOPS", "WriteIOPS", "UtilPct"},
+}
+
+const diskrateDefaultFields = "default"
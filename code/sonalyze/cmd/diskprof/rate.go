@@ -0,0 +1,205 @@
+package diskprof
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+
+	. "sonalyze/common"
+	"sonalyze/data/nodesample"
+	"sonalyze/db/repr"
+	"sonalyze/db/types"
+	. "sonalyze/table"
+)
+
+//go:generate ../../../generate-table/generate-table -o rate-table.go rate.go
+
+/*TABLE diskrate
+
+package diskprof
+
+%%
+
+FIELDS *diskRate
+
+  Timestamp  DateTimeValue desc:"Full ISO timestamp of the end of the interval" alias:"timestamp"
+  Hostname   Ustr          desc:"Name that host is known by on the cluster" alias:"host"
+  Name       Ustr          desc:"Name of disk" alias:"name"
+  Major      uint64        desc:"Major device number" alias:"major"
+  Minor      uint64        desc:"Minor device number" alias:"minor"
+  Interval   DurationValue desc:"Length of the interval since the previous sample" alias:"interval"
+  ReadMBs    float64       desc:"Data read in MB/s" alias:"read"
+  WriteMBs   float64       desc:"Data written in MB/s" alias:"write"
+  ReadIOPS   float64       desc:"Reads completed per second" alias:"read-iops"
+  WriteIOPS  float64       desc:"Writes completed per second" alias:"write-iops"
+  UtilPct    float64       desc:"Percentage of the interval the device was busy doing I/O" alias:"util"
+
+GENERATE diskRate
+
+HELP
+
+  Read and write rates per device between consecutive disk samples, computed
+  from the cumulative counters.  An interval across a reboot of the node, or
+  where a counter went backwards, has no rate and is not printed.
+
+ALIASES
+
+  default timestamp,host,name,read,write,read-iops,write-iops,util
+  Default Timestamp,Hostname,Name,ReadMBs,WriteMBs,ReadIOPS,WriteIOPS,UtilPct
+  all     timestamp,host,name,major,minor,interval,read,write,read-iops,write-iops,util
+  All     Timestamp,Hostname,Name,Major,Minor,Interval,ReadMBs,WriteMBs,ReadIOPS,WriteIOPS,UtilPct
+
+DEFAULTS default
+
+ELBAT*/
+
+// The counters in /proc/diskstats count sectors of 512 bytes regardless of the device.
+const sectorSize = 512
+
+func (nc *DiskProfCommand) performRate(
+	meta types.Context,
+	host Hosts,
+	records []*repr.DiskSample,
+	stdout io.Writer,
+) error {
+	boots, err := nc.bootTimes(meta, host)
+	if err != nil {
+		return err
+	}
+
+	// Partition by host and device, each partition sorted by ascending time.
+	type key struct {
+		host Ustr
+		name Ustr
+	}
+	devices := make(map[key][]*repr.DiskSample)
+	for _, r := range records {
+		k := key{r.Hostname, r.Name}
+		devices[k] = append(devices[k], r)
+	}
+
+	rates := make([]*diskRate, 0)
+	for k, samples := range devices {
+		slices.SortFunc(samples, func(a, b *repr.DiskSample) int {
+			return cmp.Compare(a.Timestamp, b.Timestamp)
+		})
+		for i := 1; i < len(samples); i++ {
+			prev, cur := samples[i-1], samples[i]
+			if rebootedBetween(boots[k.host], prev.Timestamp, cur.Timestamp) {
+				continue
+			}
+			if r := computeRate(prev, cur); r != nil {
+				rates = append(rates, r)
+			}
+		}
+	}
+
+	rates, err = ApplyQuery(nc.ParsedQuery, diskrateFormatters, diskratePredicates, rates)
+	if err != nil {
+		return err
+	}
+
+	// Sort by host name, then by ascending time, then by device name
+	slices.SortFunc(rates, func(a, b *diskRate) int {
+		if h := cmp.Compare(a.Hostname, b.Hostname); h != 0 {
+			return h
+		}
+		if t := cmp.Compare(a.Timestamp, b.Timestamp); t != 0 {
+			return t
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	FormatData(
+		stdout,
+		nc.PrintFields,
+		diskrateFormatters,
+		nc.PrintOpts,
+		rates,
+	)
+
+	return nil
+}
+
+// Return the node samples for each host, sorted by ascending time.  These carry the boot times.
+
+func (nc *DiskProfCommand) bootTimes(
+	meta types.Context,
+	host Hosts,
+) (map[Ustr][]*repr.NodeSample, error) {
+	nsp, err := nodesample.OpenNodeSampleDataProvider(meta)
+	if err != nil {
+		return nil, err
+	}
+	records, err := nsp.Query(
+		nodesample.QueryFilter{
+			HaveFrom: nc.HaveFrom,
+			FromDate: nc.FromDate,
+			HaveTo:   nc.HaveTo,
+			ToDate:   nc.ToDate,
+			Host:     host,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read log records: %v", err)
+	}
+	boots := make(map[Ustr][]*repr.NodeSample)
+	for _, r := range records {
+		boots[r.Hostname] = append(boots[r.Hostname], r)
+	}
+	for _, samples := range boots {
+		slices.SortFunc(samples, func(a, b *repr.NodeSample) int {
+			return cmp.Compare(a.Timestamp, b.Timestamp)
+		})
+	}
+	return boots, nil
+}
+
+// The node was rebooted between `from` and `to` if the node sample closest to `to` (and not after
+// it) has a boot time after `from`.  If there is no such sample, or the boot time is not known, we
+// can't tell, and then the check for counters going backwards must catch the reset.
+
+func rebootedBetween(nodeSamples []*repr.NodeSample, from, to int64) bool {
+	ix, found := slices.BinarySearchFunc(nodeSamples, to, func(s *repr.NodeSample, t int64) int {
+		return cmp.Compare(s.Timestamp, t)
+	})
+	if !found {
+		if ix == 0 {
+			return false
+		}
+		ix--
+	}
+	s := nodeSamples[ix]
+	return s.Timestamp > from && s.Boot > from
+}
+
+// Return nil if the rate can't be computed for the interval.
+
+func computeRate(prev, cur *repr.DiskSample) *diskRate {
+	dt := cur.Timestamp - prev.Timestamp
+	if dt <= 0 ||
+		cur.SectorsRead < prev.SectorsRead ||
+		cur.SectorsWritten < prev.SectorsWritten ||
+		cur.ReadsCompleted < prev.ReadsCompleted ||
+		cur.WritesCompleted < prev.WritesCompleted ||
+		cur.MsDoingIO < prev.MsDoingIO {
+		return nil
+	}
+	secs := float64(dt)
+	return &diskRate{
+		Timestamp: DateTimeValue(cur.Timestamp),
+		Hostname:  cur.Hostname,
+		Name:      cur.Name,
+		Major:     cur.Major,
+		Minor:     cur.Minor,
+		Interval:  DurationValue(dt),
+		ReadMBs:   RoundTo(float64((cur.SectorsRead-prev.SectorsRead)*sectorSize)/(1024*1024)/secs, 2),
+		WriteMBs:  RoundTo(float64((cur.SectorsWritten-prev.SectorsWritten)*sectorSize)/(1024*1024)/secs, 2),
+		ReadIOPS:  RoundTo(float64(cur.ReadsCompleted-prev.ReadsCompleted)/secs, 2),
+		WriteIOPS: RoundTo(float64(cur.WritesCompleted-prev.WritesCompleted)/secs, 2),
+		// The busy time can exceed the interval slightly because the two are not read at the same
+		// instant.
+		UtilPct: RoundTo(min(float64(cur.MsDoingIO-prev.MsDoingIO)/(secs*10), 100), 2),
+	}
+}
@@ -0,0 +1,100 @@
+// Report changes in node and card configuration over time.
+
+package drift
+
+import (
+	"errors"
+
+	. "sonalyze/cmd"
+	. "sonalyze/table"
+)
+
+//go:generate ../../../generate-table/generate-table -o drift-table.go drift.go
+
+/*TABLE drift
+
+package drift
+
+%%
+
+FIELDS *driftRecord
+
+ Time     string alias:"time" desc:"Full ISO timestamp of the sysinfo reading that shows the change"
+ Hostname string alias:"host" desc:"Name of the node"
+ What     string alias:"what" desc:"What changed, see summary"
+ Card     string alias:"card" desc:"UUID of the card that changed, blank for node properties"
+ Old      string alias:"old"  desc:"Previous value, or the cluster config's value for config-* changes"
+ New      string alias:"new"  desc:"New value, or the observed value for config-* changes"
+
+GENERATE driftRecord
+
+SUMMARY DriftCommand
+
+Experimental: Report changes in the configuration of nodes and their cards.
+
+The sysinfo records for each node are compared in time order, and there is
+one output record for every change between consecutive records.  The first
+record in the time window for a node is the baseline and is not reported.
+The "what" field is one of:
+
+  memory       main memory, in GiB
+  cores        sockets x cores-per-socket x threads-per-core
+  cpu-model    CPU model
+  os-release   OS release (kernel version)
+  card-added   a card with a new UUID appeared on the node
+  card-removed a card disappeared from the node
+  card-index, card-address, card-model, card-driver, card-firmware,
+  card-memory (KiB), card-power-limit
+               a property of a card that stayed on the node
+
+Additionally, if there is a static cluster configuration file then the newest
+sysinfo record in the window for each node is compared to the configuration
+for the node at that time, and mismatches are reported as config-missing (the
+node is not in the config), config-cores, config-mem, config-gpus and
+config-gpumem.
+
+Use this to notice that a node has lost a DIMM, that a card has been swapped,
+or that the cluster configuration is out of date.
+
+HELP DriftCommand
+
+  Print changes in node and card configuration over time.  Output records are
+  sorted by host, time, and what changed.  The default format is 'fixed'.
+
+ALIASES
+
+  default  time,host,what,card,old,new
+  Default  Time,Hostname,What,Card,Old,New
+  all      default
+  All      Default
+
+DEFAULTS default
+
+ELBAT*/
+
+type DriftCommand struct /* implements SimpleCommand */ {
+	HostAnalysisArgs
+	FormatArgs
+}
+
+var _ = SimpleCommand((*DriftCommand)(nil))
+
+func (dc *DriftCommand) Add(fs *CLI) {
+	dc.HostAnalysisArgs.Add(fs)
+	dc.FormatArgs.Add(fs)
+}
+
+func (dc *DriftCommand) Validate() error {
+	return errors.Join(
+		dc.HostAnalysisArgs.Validate(),
+		ValidateFormatArgs(
+			&dc.FormatArgs, driftDefaultFields, driftFormatters, driftAliases, DefaultFixed),
+	)
+}
+
+func (dc *DriftCommand) ReifyForRemote(x *ArgReifier) error {
+	return errors.Join(
+		dc.HostAnalysisArgs.ReifyForRemote(x),
+		dc.FormatArgs.ReifyForRemote(x),
+	)
+}
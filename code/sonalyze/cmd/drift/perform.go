@@ -0,0 +1,225 @@
+package drift
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	. "sonalyze/common"
+	"sonalyze/data/card"
+	"sonalyze/data/common"
+	"sonalyze/data/node"
+	"sonalyze/db/repr"
+	"sonalyze/db/types"
+	. "sonalyze/table"
+)
+
+// One sysinfo reading for a node, with its cards keyed by UUID.
+type snapshot struct {
+	node  *repr.SysinfoNodeData
+	cards map[string]*repr.SysinfoCardData
+}
+
+func (dc *DriftCommand) Perform(meta types.Context, _ io.Reader, stdout, _ io.Writer) error {
+	host, err := common.ResolveHostQuery(meta, dc.Host, dc.FromDate, dc.ToDate)
+	if err != nil {
+		return err
+	}
+	filter := common.QueryFilter{
+		HaveFrom: dc.HaveFrom,
+		FromDate: dc.FromDate,
+		HaveTo:   dc.HaveTo,
+		ToDate:   dc.ToDate,
+		Host:     host,
+	}
+
+	ndp, err := node.OpenNodeDataProvider(meta)
+	if err != nil {
+		return err
+	}
+	nodes, err := ndp.Query(filter)
+	if err != nil {
+		return err
+	}
+	cdp, err := card.OpenCardDataProvider(meta)
+	if err != nil {
+		return err
+	}
+	cards, err := cdp.Query(filter)
+	if err != nil {
+		return err
+	}
+
+	// Join the cards to the node readings they belong to.  Cards and nodes from the same sysinfo
+	// record have identical timestamps, so a card without a node reading comes from a damaged record.
+	snapshots := make(map[string]*snapshot)
+	for _, n := range nodes {
+		snapshots[n.Time+"|"+n.Node] = &snapshot{node: n, cards: make(map[string]*repr.SysinfoCardData)}
+	}
+	for _, c := range cards {
+		if s := snapshots[c.Time+"|"+c.Node]; s != nil {
+			s.cards[cardKey(c)] = c
+		} else if Verbose {
+			Log.Infof("Dropping card %s on %s at %s: no node reading at that time", cardKey(c), c.Node, c.Time)
+		}
+	}
+	byHost := make(map[string][]*snapshot)
+	for _, s := range snapshots {
+		byHost[s.node.Node] = append(byHost[s.node.Node], s)
+	}
+
+	records := make([]*driftRecord, 0)
+	for _, timeline := range byHost {
+		slices.SortFunc(timeline, func(a, b *snapshot) int {
+			return cmp.Compare(parseTime(a.node.Time), parseTime(b.node.Time))
+		})
+		for i := 1; i < len(timeline); i++ {
+			records = append(records, diffSnapshots(timeline[i-1], timeline[i])...)
+		}
+		if meta.HaveConfig() {
+			records = append(records, diffConfig(meta, timeline[len(timeline)-1])...)
+		}
+	}
+
+	records, err = ApplyQuery(dc.ParsedQuery, driftFormatters, driftPredicates, records)
+	if err != nil {
+		return err
+	}
+
+	slices.SortStableFunc(records, func(a, b *driftRecord) int {
+		if c := cmp.Compare(a.Hostname, b.Hostname); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(parseTime(a.Time), parseTime(b.Time)); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.What, b.What); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Card, b.Card)
+	})
+
+	FormatData(
+		stdout,
+		dc.PrintFields,
+		driftFormatters,
+		dc.PrintOpts,
+		records,
+	)
+
+	return nil
+}
+
+// Cards without a UUID are identified by their index, which is the best we can do.
+
+func cardKey(c *repr.SysinfoCardData) string {
+	if c.UUID != "" {
+		return c.UUID
+	}
+	return "index:" + strconv.FormatUint(c.Index, 10)
+}
+
+func parseTime(s string) int64 {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+func memGB(kb uint64) int {
+	return int(math.Round(float64(kb) / (1024 * 1024)))
+}
+
+func coresString(n *repr.SysinfoNodeData) string {
+	return fmt.Sprintf("%dx%dx%d", n.Sockets, n.CoresPerSocket, n.ThreadsPerCore)
+}
+
+func diffSnapshots(prev, cur *snapshot) []*driftRecord {
+	records := make([]*driftRecord, 0)
+	change := func(what, card, o, n string) {
+		if o != n {
+			records = append(records, &driftRecord{
+				Time:     cur.node.Time,
+				Hostname: cur.node.Node,
+				What:     what,
+				Card:     card,
+				Old:      o,
+				New:      n,
+			})
+		}
+	}
+
+	p, c := prev.node, cur.node
+	change("memory", "", strconv.Itoa(memGB(p.Memory)), strconv.Itoa(memGB(c.Memory)))
+	change("cores", "", coresString(p), coresString(c))
+	change("cpu-model", "", p.CpuModel, c.CpuModel)
+	change("os-release", "", p.OsRelease, c.OsRelease)
+
+	for key, pc := range prev.cards {
+		cc := cur.cards[key]
+		if cc == nil {
+			change("card-removed", key, pc.Model, "")
+			continue
+		}
+		change("card-index", key, fmtUint(pc.Index), fmtUint(cc.Index))
+		change("card-address", key, pc.Address, cc.Address)
+		change("card-model", key, pc.Model, cc.Model)
+		change("card-driver", key, pc.Driver, cc.Driver)
+		change("card-firmware", key, pc.Firmware, cc.Firmware)
+		change("card-memory", key, fmtUint(pc.Memory), fmtUint(cc.Memory))
+		change("card-power-limit", key, fmtUint(pc.PowerLimit), fmtUint(cc.PowerLimit))
+	}
+	for key, cc := range cur.cards {
+		if prev.cards[key] == nil {
+			change("card-added", key, "", cc.Model)
+		}
+	}
+	return records
+}
+
+// Compare the reading to the static config for the node at the time of the reading, using the same
+// computations as the config data provider uses to synthesize node configurations.
+
+func diffConfig(meta types.Context, s *snapshot) []*driftRecord {
+	records := make([]*driftRecord, 0)
+	mismatch := func(what string, o, n int) {
+		if o != n {
+			records = append(records, &driftRecord{
+				Time:     s.node.Time,
+				Hostname: s.node.Node,
+				What:     what,
+				Old:      strconv.Itoa(o),
+				New:      strconv.Itoa(n),
+			})
+		}
+	}
+
+	n := s.node
+	cfg := meta.Config().LookupHostByTime(n.Node, parseTime(n.Time))
+	if cfg == nil {
+		records = append(records, &driftRecord{
+			Time:     n.Time,
+			Hostname: n.Node,
+			What:     "config-missing",
+		})
+		return records
+	}
+	var cardMemKB uint64
+	for _, c := range s.cards {
+		cardMemKB += c.Memory
+	}
+	mismatch("config-cores", cfg.CpuCores, int(n.Sockets*n.CoresPerSocket*n.ThreadsPerCore))
+	mismatch("config-mem", cfg.MemGB, memGB(n.Memory))
+	mismatch("config-gpus", cfg.GpuCards, len(s.cards))
+	mismatch("config-gpumem", cfg.GpuMemGB, memGB(cardMemKB))
+	return records
+}
+
+func fmtUint(n uint64) string {
+	return strconv.FormatUint(n, 10)
+}
@@ -0,0 +1,505 @@
+// DO NOT EDIT.  Generated from efficiency.go by generate-table
+
+package efficiency
+
+import (
+	"cmp"
+	"fmt"
+	"go-utils/gpuset"
+	"io"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+var (
+	_ = cmp.Compare(0, 0)
+	_ fmt.Formatter
+	_ = io.SeekStart
+	_ = UstrEmpty
+	_ gpuset.GpuSet
+)
+
+// MT: Constant after initialization; immutable
+var efficiencyFormatters = map[string]Formatter[*efficiencyRecord]{
+	"JobID": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatIntOrEmpty((d.JobID), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.JobID
+		},
+		Help: "(int) Primary job ID (blank for rollups)",
+	},
+	"User": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatUstr((d.User), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.User
+		},
+		Help: "(string) Job's user (blank for account rollups)",
+	},
+	"Account": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatUstr((d.Account), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.Account
+		},
+		Help: "(string) Job's account (blank for user rollups)",
+	},
+	"Jobs": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatInt((d.Jobs), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.Jobs
+		},
+		Help: "(int) Number of jobs in the record",
+	},
+	"Elapsed": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.Elapsed), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.Elapsed
+		},
+		Help: "(DurationValue) Elapsed time of the job (sum for rollups)",
+	},
+	"TimeLimit": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.TimeLimit), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.TimeLimit
+		},
+		Help: "(DurationValue) Requested time limit (sum for rollups)",
+	},
+	"ReqCores": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatInt((d.ReqCores), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.ReqCores
+		},
+		Help: "(int) Requested CPU cores for the whole job (sum for rollups)",
+	},
+	"CpuAvgPct": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.CpuAvgPct), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.CpuAvgPct
+		},
+		Help: "(int) Average CPU utilization in percent (100% = 1 core) (time-weighted for rollups)",
+	},
+	"CpuPeakPct": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.CpuPeakPct), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.CpuPeakPct
+		},
+		Help: "(int) Peak CPU utilization in percent (100% = 1 core) (max for rollups)",
+	},
+	"ReqMemGB": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatInt((d.ReqMemGB), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.ReqMemGB
+		},
+		Help: "(int) Requested memory in GB for the whole job (sum for rollups)",
+	},
+	"ResPeakGB": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.ResPeakGB), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.ResPeakGB
+		},
+		Help: "(int) Peak resident memory in GB (max for rollups)",
+	},
+	"ReqGpus": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatInt((d.ReqGpus), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.ReqGpus
+		},
+		Help: "(int) Requested GPU cards (sum for rollups)",
+	},
+	"GpuAvgPct": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.GpuAvgPct), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.GpuAvgPct
+		},
+		Help: "(int) Average GPU utilization in percent (100% = 1 card) (time-weighted for rollups)",
+	},
+	"GpuPeakPct": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.GpuPeakPct), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.GpuPeakPct
+		},
+		Help: "(int) Peak GPU utilization in percent (100% = 1 card) (max for rollups)",
+	},
+	"GpuMemPeakGB": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.GpuMemPeakGB), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.GpuMemPeakGB
+		},
+		Help: "(int) Peak GPU memory in GB (max for rollups)",
+	},
+	"CoreHours": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.CoreHours), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.CoreHours
+		},
+		Help: "(int) Requested core-hours (req-cores * elapsed)",
+	},
+	"WastedCoreHours": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.WastedCoreHours), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.WastedCoreHours
+		},
+		Help: "(int) Requested core-hours that were not used",
+	},
+	"GpuHours": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.GpuHours), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.GpuHours
+		},
+		Help: "(int) Requested GPU-hours (req-gpus * elapsed)",
+	},
+	"WastedGpuHours": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.WastedGpuHours), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.WastedGpuHours
+		},
+		Help: "(int) Requested GPU-hours that were not used",
+	},
+	"CpuWastePct": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.CpuWastePct), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.CpuWastePct
+		},
+		Help: "(int) Percent of requested core-hours that were not used",
+	},
+	"MemWastePct": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.MemWastePct), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.MemWastePct
+		},
+		Help: "(int) Percent of requested memory (GB-hours) above the peak resident memory",
+	},
+	"GpuWastePct": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.GpuWastePct), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.GpuWastePct
+		},
+		Help: "(int) Percent of requested GPU-hours that were not used",
+	},
+	"TimeWastePct": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.TimeWastePct), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.TimeWastePct
+		},
+		Help: "(int) Percent of the time limit that was not used",
+	},
+	"RecCores": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatIntOrEmpty((d.RecCores), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.RecCores
+		},
+		Help: "(int) Recommended CPU cores (blank for rollups)",
+	},
+	"RecMemGB": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatIntOrEmpty((d.RecMemGB), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.RecMemGB
+		},
+		Help: "(int) Recommended memory in GB (blank for rollups)",
+	},
+	"RecGpus": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatIntOrEmpty((d.RecGpus), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.RecGpus
+		},
+		Help: "(int) Recommended GPU cards (blank for none and for rollups)",
+	},
+	"RecTimeLimit": {
+		Fmt: func(d *efficiencyRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.RecTimeLimit), ctx)
+		},
+		Xtract: func(d *efficiencyRecord) any {
+			return d.RecTimeLimit
+		},
+		Help: "(DurationValue) Recommended time limit (zero for rollups)",
+	},
+}
+
+func init() {
+	DefAlias(efficiencyFormatters, "JobID", "job")
+	DefAlias(efficiencyFormatters, "User", "user")
+	DefAlias(efficiencyFormatters, "Account", "account")
+	DefAlias(efficiencyFormatters, "Jobs", "jobs")
+	DefAlias(efficiencyFormatters, "Elapsed", "elapsed")
+	DefAlias(efficiencyFormatters, "TimeLimit", "time-limit")
+	DefAlias(efficiencyFormatters, "ReqCores", "req-cores")
+	DefAlias(efficiencyFormatters, "CpuAvgPct", "cpu-avg")
+	DefAlias(efficiencyFormatters, "CpuPeakPct", "cpu-peak")
+	DefAlias(efficiencyFormatters, "ReqMemGB", "req-mem")
+	DefAlias(efficiencyFormatters, "ResPeakGB", "res-peak")
+	DefAlias(efficiencyFormatters, "ReqGpus", "req-gpus")
+	DefAlias(efficiencyFormatters, "GpuAvgPct", "gpu-avg")
+	DefAlias(efficiencyFormatters, "GpuPeakPct", "gpu-peak")
+	DefAlias(efficiencyFormatters, "GpuMemPeakGB", "gpumem-peak")
+	DefAlias(efficiencyFormatters, "CoreHours", "core-hours")
+	DefAlias(efficiencyFormatters, "WastedCoreHours", "wasted-core-hours")
+	DefAlias(efficiencyFormatters, "GpuHours", "gpu-hours")
+	DefAlias(efficiencyFormatters, "WastedGpuHours", "wasted-gpu-hours")
+	DefAlias(efficiencyFormatters, "CpuWastePct", "cpu-waste")
+	DefAlias(efficiencyFormatters, "MemWastePct", "mem-waste")
+	DefAlias(efficiencyFormatters, "GpuWastePct", "gpu-waste")
+	DefAlias(efficiencyFormatters, "TimeWastePct", "time-waste")
+	DefAlias(efficiencyFormatters, "RecCores", "rec-cores")
+	DefAlias(efficiencyFormatters, "RecMemGB", "rec-mem")
+	DefAlias(efficiencyFormatters, "RecGpus", "rec-gpus")
+	DefAlias(efficiencyFormatters, "RecTimeLimit", "rec-time")
+}
+
+// MT: Constant after initialization; immutable
+var efficiencyPredicates = map[string]Predicate[*efficiencyRecord]{
+	"JobID": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.JobID), v.(IntOrEmpty))
+		},
+	},
+	"User": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.User), v.(Ustr))
+		},
+	},
+	"Account": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.Account), v.(Ustr))
+		},
+	},
+	"Jobs": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.Jobs), v.(int))
+		},
+	},
+	"Elapsed": Predicate[*efficiencyRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.Elapsed), v.(DurationValue))
+		},
+	},
+	"TimeLimit": Predicate[*efficiencyRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.TimeLimit), v.(DurationValue))
+		},
+	},
+	"ReqCores": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.ReqCores), v.(int))
+		},
+	},
+	"CpuAvgPct": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.CpuAvgPct), v.(F64Ceil))
+		},
+	},
+	"CpuPeakPct": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.CpuPeakPct), v.(F64Ceil))
+		},
+	},
+	"ReqMemGB": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.ReqMemGB), v.(int))
+		},
+	},
+	"ResPeakGB": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.ResPeakGB), v.(F64Ceil))
+		},
+	},
+	"ReqGpus": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.ReqGpus), v.(int))
+		},
+	},
+	"GpuAvgPct": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.GpuAvgPct), v.(F64Ceil))
+		},
+	},
+	"GpuPeakPct": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.GpuPeakPct), v.(F64Ceil))
+		},
+	},
+	"GpuMemPeakGB": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.GpuMemPeakGB), v.(F64Ceil))
+		},
+	},
+	"CoreHours": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.CoreHours), v.(F64Ceil))
+		},
+	},
+	"WastedCoreHours": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.WastedCoreHours), v.(F64Ceil))
+		},
+	},
+	"GpuHours": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.GpuHours), v.(F64Ceil))
+		},
+	},
+	"WastedGpuHours": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.WastedGpuHours), v.(F64Ceil))
+		},
+	},
+	"CpuWastePct": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.CpuWastePct), v.(F64Ceil))
+		},
+	},
+	"MemWastePct": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.MemWastePct), v.(F64Ceil))
+		},
+	},
+	"GpuWastePct": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.GpuWastePct), v.(F64Ceil))
+		},
+	},
+	"TimeWastePct": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.TimeWastePct), v.(F64Ceil))
+		},
+	},
+	"RecCores": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.RecCores), v.(IntOrEmpty))
+		},
+	},
+	"RecMemGB": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.RecMemGB), v.(IntOrEmpty))
+		},
+	},
+	"RecGpus": Predicate[*efficiencyRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.RecGpus), v.(IntOrEmpty))
+		},
+	},
+	"RecTimeLimit": Predicate[*efficiencyRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *efficiencyRecord, v any) int {
+			return cmp.Compare((d.RecTimeLimit), v.(DurationValue))
+		},
+	},
+}
+
+func (c *EfficiencyCommand) Summary(out io.Writer) {
+	fmt.Fprint(out, `Compare the resources requested by Slurm jobs with the resources they used.
+
+Each job's Slurm sacct data (requested cores, memory, GPUs, and time
+limit) are joined with the usage computed from the job's samples (CPU
+and GPU utilization, resident memory, and GPU memory), and the waste
+is computed as the fraction of the request that was not used.  Jobs
+that have only sacct data or only sample data are not reported.
+
+For each job, a recommended request is computed from the peak use plus
+some headroom (see -headroom).  The recommendations are only as good as
+the samples: short jobs and jobs with sparse samples can have their
+peaks underestimated.
+
+With -rollup, the jobs are aggregated per user or per account, and the
+waste is computed from the totals across the jobs.
+`)
+}
+
+const efficiencyHelp = `
+efficiency
+  Compare requested and used resources for Slurm jobs.  Output records are
+  sorted by job ID, or by user or account name for rollups.  The default format
+  is 'fixed'.
+`
+
+func (c *EfficiencyCommand) MaybeFormatHelp() *FormatHelp {
+	return StandardFormatHelp(c.Fmt, efficiencyHelp, efficiencyFormatters, efficiencyAliases, efficiencyDefaultFields)
+}
+
+// MT: Constant after initialization; immutable
+var efficiencyAliases = map[string][]string{
+	"default": []string{"job", "user", "account", "elapsed", "req-cores", "cpu-avg", "cpu-waste", "req-mem", "res-peak", "mem-waste", "req-gpus", "gpu-avg", "gpu-waste", "time-waste", "rec-cores", "rec-mem", "rec-gpus", "rec-time"},
+	"Default": []string{"JobID", "User", "Account", "Elapsed", "ReqCores", "CpuAvgPct", "CpuWastePct", "ReqMemGB", "ResPeakGB", "MemWastePct", "ReqGpus", "GpuAvgPct", "GpuWastePct", "TimeWastePct", "RecCores", "RecMemGB", "RecGpus", "RecTimeLimit"},
+	"rollup":  []string{"user", "account", "jobs", "core-hours", "wasted-core-hours", "cpu-waste", "mem-waste", "gpu-hours", "wasted-gpu-hours", "gpu-waste", "time-waste"},
+	"Rollup":  []string{"User", "Account", "Jobs", "CoreHours", "WastedCoreHours", "CpuWastePct", "MemWastePct", "GpuHours", "WastedGpuHours", "GpuWastePct", "TimeWastePct"},
+	"all":     []string{"job", "user", "account", "jobs", "elapsed", "time-limit", "req-cores", "cpu-avg", "cpu-peak", "req-mem", "res-peak", "req-gpus", "gpu-avg", "gpu-peak", "gpumem-peak", "core-hours", "wasted-core-hours", "gpu-hours", "wasted-gpu-hours", "cpu-waste", "mem-waste", "gpu-waste", "time-waste", "rec-cores", "rec-mem", "rec-gpus", "rec-time"},
+	"All":     []string{"JobID", "User", "Account", "Jobs", "Elapsed", "TimeLimit", "ReqCores", "CpuAvgPct", "CpuPeakPct", "ReqMemGB", "ResPeakGB", "ReqGpus", "GpuAvgPct", "GpuPeakPct", "GpuMemPeakGB", "CoreHours", "WastedCoreHours", "GpuHours", "WastedGpuHours", "CpuWastePct", "MemWastePct", "GpuWastePct", "TimeWastePct", "RecCores", "RecMemGB", "RecGpus", "RecTimeLimit"},
+}
+
+const efficiencyDefaultFields = "default"
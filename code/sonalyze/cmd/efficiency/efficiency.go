@@ -0,0 +1,190 @@
+// Compare the resources requested by Slurm jobs with the resources the jobs actually used, and
+// recommend better requests.
+
+package efficiency
+
+import (
+	"errors"
+	"fmt"
+
+	. "sonalyze/cmd"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+//go:generate ../../../generate-table/generate-table -o efficiency-table.go efficiency.go
+
+/*TABLE efficiency
+
+package efficiency
+
+%%
+
+FIELDS *efficiencyRecord
+
+ JobID        IntOrEmpty    alias:"job"         desc:"Primary job ID (blank for rollups)"
+ User         Ustr          alias:"user"        desc:"Job's user (blank for account rollups)"
+ Account      Ustr          alias:"account"     desc:"Job's account (blank for user rollups)"
+ Jobs         int           alias:"jobs"        desc:"Number of jobs in the record"
+ Elapsed      DurationValue alias:"elapsed"     desc:"Elapsed time of the job (sum for rollups)"
+ TimeLimit    DurationValue alias:"time-limit"  desc:"Requested time limit (sum for rollups)"
+ ReqCores     int           alias:"req-cores"   desc:"Requested CPU cores for the whole job (sum for rollups)"
+ CpuAvgPct    F64Ceil       alias:"cpu-avg"     desc:"Average CPU utilization in percent (100% = 1 core) (time-weighted for rollups)"
+ CpuPeakPct   F64Ceil       alias:"cpu-peak"    desc:"Peak CPU utilization in percent (100% = 1 core) (max for rollups)"
+ ReqMemGB     int           alias:"req-mem"     desc:"Requested memory in GB for the whole job (sum for rollups)"
+ ResPeakGB    F64Ceil       alias:"res-peak"    desc:"Peak resident memory in GB (max for rollups)"
+ ReqGpus      int           alias:"req-gpus"    desc:"Requested GPU cards (sum for rollups)"
+ GpuAvgPct    F64Ceil       alias:"gpu-avg"     desc:"Average GPU utilization in percent (100% = 1 card) (time-weighted for rollups)"
+ GpuPeakPct   F64Ceil       alias:"gpu-peak"    desc:"Peak GPU utilization in percent (100% = 1 card) (max for rollups)"
+ GpuMemPeakGB F64Ceil       alias:"gpumem-peak" desc:"Peak GPU memory in GB (max for rollups)"
+ CoreHours    F64Ceil       alias:"core-hours"  desc:"Requested core-hours (req-cores * elapsed)"
+ WastedCoreHours \
+              F64Ceil       alias:"wasted-core-hours" desc:"Requested core-hours that were not used"
+ GpuHours     F64Ceil       alias:"gpu-hours"   desc:"Requested GPU-hours (req-gpus * elapsed)"
+ WastedGpuHours \
+              F64Ceil       alias:"wasted-gpu-hours" desc:"Requested GPU-hours that were not used"
+ CpuWastePct  F64Ceil       alias:"cpu-waste"   desc:"Percent of requested core-hours that were not used"
+ MemWastePct  F64Ceil       alias:"mem-waste"   desc:"Percent of requested memory (GB-hours) above the peak resident memory"
+ GpuWastePct  F64Ceil       alias:"gpu-waste"   desc:"Percent of requested GPU-hours that were not used"
+ TimeWastePct F64Ceil       alias:"time-waste"  desc:"Percent of the time limit that was not used"
+ RecCores     IntOrEmpty    alias:"rec-cores"   desc:"Recommended CPU cores (blank for rollups)"
+ RecMemGB     IntOrEmpty    alias:"rec-mem"     desc:"Recommended memory in GB (blank for rollups)"
+ RecGpus      IntOrEmpty    alias:"rec-gpus"    desc:"Recommended GPU cards (blank for none and for rollups)"
+ RecTimeLimit DurationValue alias:"rec-time"    desc:"Recommended time limit (zero for rollups)"
+
+SUMMARY EfficiencyCommand
+
+Compare the resources requested by Slurm jobs with the resources they used.
+
+Each job's Slurm sacct data (requested cores, memory, GPUs, and time
+limit) are joined with the usage computed from the job's samples (CPU
+and GPU utilization, resident memory, and GPU memory), and the waste
+is computed as the fraction of the request that was not used.  Jobs
+that have only sacct data or only sample data are not reported.
+
+For each job, a recommended request is computed from the peak use plus
+some headroom (see -headroom).  The recommendations are only as good as
+the samples: short jobs and jobs with sparse samples can have their
+peaks underestimated.
+
+With -rollup, the jobs are aggregated per user or per account, and the
+waste is computed from the totals across the jobs.
+
+HELP EfficiencyCommand
+
+  Compare requested and used resources for Slurm jobs.  Output records are
+  sorted by job ID, or by user or account name for rollups.  The default format
+  is 'fixed'.
+
+ALIASES
+
+  default  job,user,account,elapsed,req-cores,cpu-avg,cpu-waste,req-mem,res-peak,mem-waste,\
+           req-gpus,gpu-avg,gpu-waste,time-waste,rec-cores,rec-mem,rec-gpus,rec-time
+  Default  JobID,User,Account,Elapsed,ReqCores,CpuAvgPct,CpuWastePct,ReqMemGB,ResPeakGB,MemWastePct,\
+           ReqGpus,GpuAvgPct,GpuWastePct,TimeWastePct,RecCores,RecMemGB,RecGpus,RecTimeLimit
+  rollup   user,account,jobs,core-hours,wasted-core-hours,cpu-waste,mem-waste,gpu-hours,\
+           wasted-gpu-hours,gpu-waste,time-waste
+  Rollup   User,Account,Jobs,CoreHours,WastedCoreHours,CpuWastePct,MemWastePct,GpuHours,\
+           WastedGpuHours,GpuWastePct,TimeWastePct
+  all      job,user,account,jobs,elapsed,time-limit,req-cores,cpu-avg,cpu-peak,req-mem,res-peak,\
+           req-gpus,gpu-avg,gpu-peak,gpumem-peak,core-hours,wasted-core-hours,gpu-hours,\
+           wasted-gpu-hours,cpu-waste,mem-waste,gpu-waste,time-waste,rec-cores,rec-mem,rec-gpus,rec-time
+  All      JobID,User,Account,Jobs,Elapsed,TimeLimit,ReqCores,CpuAvgPct,CpuPeakPct,ReqMemGB,ResPeakGB,\
+           ReqGpus,GpuAvgPct,GpuPeakPct,GpuMemPeakGB,CoreHours,WastedCoreHours,GpuHours,\
+           WastedGpuHours,CpuWastePct,MemWastePct,GpuWastePct,TimeWastePct,RecCores,RecMemGB,RecGpus,\
+           RecTimeLimit
+
+DEFAULTS default
+
+ELBAT*/
+
+type EfficiencyCommand struct /* implements SampleAnalysisCommand */ {
+	SampleAnalysisArgs
+	FormatArgs
+
+	// Filter args
+	Account       []string
+	Partition     []string
+	State         []string
+	MinRuntimeSec int64
+
+	// Processing args
+	Rollup   string
+	Headroom uint
+
+	// Internal / working storage
+	minRuntimeStr string
+}
+
+var _ = SampleAnalysisCommand((*EfficiencyCommand)(nil))
+
+func (ec *EfficiencyCommand) Add(fs *CLI) {
+	ec.SampleAnalysisArgs.Add(fs)
+	ec.FormatArgs.Add(fs)
+
+	fs.Group("job-filter")
+	fs.Var(NewRepeatableString(&ec.Account), "account",
+		"Select only jobs where the `Account` equals this string (repeatable) [default: all]")
+	fs.Var(NewRepeatableString(&ec.Partition), "partition",
+		"Select only jobs where the `Partition` equals this string (repeatable) [default: all]")
+	fs.Var(NewRepeatableString(&ec.State), "state",
+		"Select only jobs where the `State` equals this string (repeatable) [default: all]")
+	fs.StringVar(&ec.minRuntimeStr, "min-runtime", "",
+		"Select only jobs with at least this much runtime, format `WwDdHhMm`, all parts\n"+
+			"optional [default: 0m]")
+
+	fs.Group("aggregation")
+	fs.StringVar(&ec.Rollup, "rollup", "",
+		"Aggregate the jobs per `user` or per `account` [default: no aggregation]")
+	fs.UintVar(&ec.Headroom, "headroom", 20,
+		"Add this much headroom, in `percent`, to the observed peaks for the recommended request")
+}
+
+func (ec *EfficiencyCommand) ReifyForRemote(x *ArgReifier) error {
+	e1 := errors.Join(
+		ec.SampleAnalysisArgs.ReifyForRemote(x),
+		ec.FormatArgs.ReifyForRemote(x),
+	)
+	x.RepeatableString("account", ec.Account)
+	x.RepeatableString("partition", ec.Partition)
+	x.RepeatableString("state", ec.State)
+	x.String("min-runtime", ec.minRuntimeStr)
+	x.String("rollup", ec.Rollup)
+	x.UintUnchecked("headroom", ec.Headroom)
+	return e1
+}
+
+func (ec *EfficiencyCommand) Validate() error {
+	var e1, e2, e3 error
+	switch ec.Rollup {
+	case "", "user", "account":
+	default:
+		e1 = fmt.Errorf("Bad -rollup value %s, must be user or account", ec.Rollup)
+	}
+	defaultFields := efficiencyDefaultFields
+	if ec.Rollup != "" {
+		defaultFields = "rollup"
+	}
+	e2 = errors.Join(
+		ec.SampleAnalysisArgs.Validate(),
+		ValidateFormatArgs(
+			&ec.FormatArgs, defaultFields, efficiencyFormatters, efficiencyAliases, DefaultFixed),
+	)
+	if ec.minRuntimeStr != "" {
+		ec.MinRuntimeSec, e3 = DurationToSeconds("-min-runtime", ec.minRuntimeStr)
+	}
+	return errors.Join(e1, e2, e3)
+}
+
+func (ec *EfficiencyCommand) DefaultRecordFilters() (
+	allUsers, skipSystemUsers, excludeSystemCommands, excludeHeartbeat bool,
+) {
+	// The report is mostly useful across users, so select all users by default.
+	allUsers, skipSystemUsers, determined := ec.RecordFilterArgs.DefaultUserFilters()
+	if !determined {
+		allUsers, skipSystemUsers = true, true
+	}
+	excludeSystemCommands = true
+	excludeHeartbeat = true
+	return
+}
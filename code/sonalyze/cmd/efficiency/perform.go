@@ -0,0 +1,310 @@
+package efficiency
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sonalyze/cmd/jobs"
+	. "sonalyze/common"
+	"sonalyze/data/common"
+	"sonalyze/data/config"
+	"sonalyze/data/sample"
+	"sonalyze/data/slurmjob"
+	"sonalyze/db/types"
+	. "sonalyze/table"
+)
+
+type efficiencyRecord struct {
+	JobID           IntOrEmpty
+	User            Ustr
+	Account         Ustr
+	Jobs            int
+	Elapsed         DurationValue
+	TimeLimit       DurationValue
+	ReqCores        int
+	CpuAvgPct       F64Ceil
+	CpuPeakPct      F64Ceil
+	ReqMemGB        int
+	ResPeakGB       F64Ceil
+	ReqGpus         int
+	GpuAvgPct       F64Ceil
+	GpuPeakPct      F64Ceil
+	GpuMemPeakGB    F64Ceil
+	CoreHours       F64Ceil
+	WastedCoreHours F64Ceil
+	GpuHours        F64Ceil
+	WastedGpuHours  F64Ceil
+	CpuWastePct     F64Ceil
+	MemWastePct     F64Ceil
+	GpuWastePct     F64Ceil
+	TimeWastePct    F64Ceil
+	RecCores        IntOrEmpty
+	RecMemGB        IntOrEmpty
+	RecGpus         IntOrEmpty
+	RecTimeLimit    DurationValue
+
+	// Totals, for computing the waste in rollups
+	reqMemGBHours  float64
+	usedMemGBHours float64
+}
+
+func (ec *EfficiencyCommand) Perform(
+	out io.Writer,
+	meta types.Context,
+	filter sample.QueryFilter,
+	hosts Hosts,
+	recordFilter *sample.SampleFilter,
+) error {
+	sdp, err := sample.OpenSampleDataProvider(meta)
+	if err != nil {
+		return err
+	}
+	streams, bounds, read, dropped, err :=
+		sdp.Query(
+			filter.FromDate,
+			filter.ToDate,
+			hosts,
+			recordFilter,
+			false,
+		)
+	if err != nil {
+		return fmt.Errorf("Failed to read log records: %v", err)
+	}
+	if Verbose {
+		Log.Infof("%d records read + %d dropped\n", read, dropped)
+	}
+
+	// Only Slurm jobs (epoch zero) can be joined with sacct data, and they are merged across nodes.
+	for k, v := range streams {
+		if (*v)[0].Epoch != 0 {
+			delete(streams, k)
+		}
+	}
+	merged, _ := sample.MergeByJob(streams, bounds)
+	cdp := config.MaybeOpenConfigDataProvider(meta)
+	usage := make(map[uint32]*jobs.JobUsage)
+	for _, job := range merged {
+		if job.Samples[0].Job != 0 {
+			u := jobs.AggregateJobUsage(cdp, job)
+			usage[job.Samples[0].Job] = &u
+		}
+	}
+	if Verbose {
+		Log.Infof("Jobs constructed by merging: %d", len(usage))
+	}
+
+	slurmJobs, err := ec.querySlurmJobs(meta, usage)
+	if err != nil {
+		return err
+	}
+
+	records := make([]*efficiencyRecord, 0, len(slurmJobs))
+	for _, j := range slurmJobs {
+		if u := usage[j.Id]; u != nil {
+			records = append(records, ec.jobRecord(j, u))
+		}
+	}
+	if Verbose {
+		Log.Infof("Jobs with both sample and sacct data: %d", len(records))
+	}
+
+	switch ec.Rollup {
+	case "user":
+		records = rollup(records, func(r *efficiencyRecord) *efficiencyRecord {
+			return &efficiencyRecord{User: r.User}
+		})
+	case "account":
+		records = rollup(records, func(r *efficiencyRecord) *efficiencyRecord {
+			return &efficiencyRecord{Account: r.Account}
+		})
+	}
+
+	records, err = ApplyQuery(ec.ParsedQuery, efficiencyFormatters, efficiencyPredicates, records)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(records, func(a, b *efficiencyRecord) int {
+		c := cmp.Compare(a.JobID, b.JobID)
+		if c == 0 {
+			c = cmp.Compare(a.User.String(), b.User.String())
+		}
+		if c == 0 {
+			c = cmp.Compare(a.Account.String(), b.Account.String())
+		}
+		return c
+	})
+	FormatData(
+		out,
+		ec.PrintFields,
+		efficiencyFormatters,
+		ec.PrintOpts,
+		records,
+	)
+	return nil
+}
+
+func (ec *EfficiencyCommand) querySlurmJobs(
+	meta types.Context,
+	usage map[uint32]*jobs.JobUsage,
+) ([]*slurmjob.SlurmJob, error) {
+	jdp, err := slurmjob.OpenSlurmjobDataProvider(meta)
+	if err != nil {
+		return nil, err
+	}
+	jobIds := make([]uint32, 0, len(usage))
+	for id := range usage {
+		jobIds = append(jobIds, id)
+	}
+	if len(jobIds) == 0 {
+		return nil, nil
+	}
+	return jdp.Query(
+		slurmjob.QueryFilter{
+			QueryFilter: common.QueryFilter{
+				HaveFrom: ec.HaveFrom,
+				FromDate: ec.FromDate,
+				HaveTo:   ec.HaveTo,
+				ToDate:   ec.ToDate,
+			},
+			Account:    ec.Account,
+			Partition:  ec.Partition,
+			State:      ec.State,
+			Job:        jobIds,
+			MinRuntime: ec.MinRuntimeSec,
+		},
+	)
+}
+
+func (ec *EfficiencyCommand) jobRecord(j *slurmjob.SlurmJob, u *jobs.JobUsage) *efficiencyRecord {
+	elapsed := int64(j.Main.ElapsedRaw)
+	if elapsed == 0 {
+		// Still running, or no data from Slurm.
+		elapsed = u.DurationSec
+	}
+	hours := float64(elapsed) / 3600
+	reqCores := int(j.Main.TotalCPUs())
+	reqMemGB := float64(j.Main.TotalReqMem()) * KBToGB
+	reqGpus := requestedGpus(j.Main.ReqGPUS.String())
+	headroom := 1 + float64(ec.Headroom)/100
+
+	r := &efficiencyRecord{
+		JobID:          IntOrEmpty(j.Id),
+		User:           j.Main.User,
+		Account:        j.Main.Account,
+		Jobs:           1,
+		Elapsed:        DurationValue(elapsed),
+		TimeLimit:      DurationValue(j.Main.TimelimitRaw),
+		ReqCores:       reqCores,
+		CpuAvgPct:      F64Ceil(u.CpuPctAvg),
+		CpuPeakPct:     F64Ceil(u.CpuPctPeak),
+		ReqMemGB:       int(math.Round(reqMemGB)),
+		ResPeakGB:      F64Ceil(u.RssAnonGBPeak),
+		ReqGpus:        reqGpus,
+		GpuAvgPct:      F64Ceil(u.GpuPctAvg),
+		GpuPeakPct:     F64Ceil(u.GpuPctPeak),
+		GpuMemPeakGB:   F64Ceil(u.GpuGBPeak),
+		CoreHours:      F64Ceil(float64(reqCores) * hours),
+		GpuHours:       F64Ceil(float64(reqGpus) * hours),
+		reqMemGBHours:  reqMemGB * hours,
+		usedMemGBHours: min(u.RssAnonGBPeak, reqMemGB) * hours,
+		RecCores:       IntOrEmpty(max(1, int(math.Ceil(u.CpuPctPeak/100*headroom)))),
+		RecMemGB:       IntOrEmpty(max(1, int(math.Ceil(u.RssAnonGBPeak*headroom)))),
+		RecTimeLimit:   DurationValue(math.Ceil(float64(elapsed)*headroom/60) * 60),
+	}
+	if u.GpuPctPeak > 0 {
+		r.RecGpus = IntOrEmpty(max(1, int(math.Ceil(u.GpuPctPeak/100*headroom))))
+	}
+	r.WastedCoreHours = F64Ceil(max(0, float64(r.CoreHours)-u.CpuPctAvg/100*hours))
+	r.WastedGpuHours = F64Ceil(max(0, float64(r.GpuHours)-u.GpuPctAvg/100*hours))
+	computeWaste(r)
+	return r
+}
+
+// Aggregate the records by the key constructed by `newKey`, which returns a fresh record with only
+// the key fields set.  Sums are summed, peaks are maxed, averages are weighted by elapsed time.
+
+func rollup(
+	records []*efficiencyRecord,
+	newKey func(*efficiencyRecord) *efficiencyRecord,
+) []*efficiencyRecord {
+	byKey := make(map[[2]Ustr]*efficiencyRecord)
+	for _, r := range records {
+		key := newKey(r)
+		k := [2]Ustr{key.User, key.Account}
+		acc := byKey[k]
+		if acc == nil {
+			acc = key
+			byKey[k] = acc
+		}
+		oldElapsed, newElapsed := float64(acc.Elapsed), float64(acc.Elapsed+r.Elapsed)
+		if newElapsed > 0 {
+			acc.CpuAvgPct = F64Ceil(
+				(float64(acc.CpuAvgPct)*oldElapsed + float64(r.CpuAvgPct)*float64(r.Elapsed)) / newElapsed)
+			acc.GpuAvgPct = F64Ceil(
+				(float64(acc.GpuAvgPct)*oldElapsed + float64(r.GpuAvgPct)*float64(r.Elapsed)) / newElapsed)
+		}
+		acc.Jobs += r.Jobs
+		acc.Elapsed += r.Elapsed
+		acc.TimeLimit += r.TimeLimit
+		acc.ReqCores += r.ReqCores
+		acc.CpuPeakPct = max(acc.CpuPeakPct, r.CpuPeakPct)
+		acc.ReqMemGB += r.ReqMemGB
+		acc.ResPeakGB = max(acc.ResPeakGB, r.ResPeakGB)
+		acc.ReqGpus += r.ReqGpus
+		acc.GpuPeakPct = max(acc.GpuPeakPct, r.GpuPeakPct)
+		acc.GpuMemPeakGB = max(acc.GpuMemPeakGB, r.GpuMemPeakGB)
+		acc.CoreHours += r.CoreHours
+		acc.WastedCoreHours += r.WastedCoreHours
+		acc.GpuHours += r.GpuHours
+		acc.WastedGpuHours += r.WastedGpuHours
+		acc.reqMemGBHours += r.reqMemGBHours
+		acc.usedMemGBHours += r.usedMemGBHours
+	}
+	result := make([]*efficiencyRecord, 0, len(byKey))
+	for _, r := range byKey {
+		computeWaste(r)
+		result = append(result, r)
+	}
+	return result
+}
+
+func computeWaste(r *efficiencyRecord) {
+	if r.CoreHours > 0 {
+		r.CpuWastePct = 100 * r.WastedCoreHours / r.CoreHours
+	}
+	if r.reqMemGBHours > 0 {
+		r.MemWastePct = F64Ceil(100 * (r.reqMemGBHours - r.usedMemGBHours) / r.reqMemGBHours)
+	}
+	if r.GpuHours > 0 {
+		r.GpuWastePct = 100 * r.WastedGpuHours / r.GpuHours
+	}
+	if r.TimeLimit > 0 {
+		r.TimeWastePct = F64Ceil(max(0, 100*float64(r.TimeLimit-r.Elapsed)/float64(r.TimeLimit)))
+	}
+}
+
+// The ReqGPUS field is a comma-separated list of model=n and/or *=n, where *=n is the total if
+// present.  Return the total, or the sum of the per-model counts if there is no total.
+
+func requestedGpus(s string) int {
+	var sum int
+	if s == "" {
+		return 0
+	}
+	for _, req := range strings.Split(s, ",") {
+		if model, count, found := strings.Cut(req, "="); found {
+			if k, err := strconv.Atoi(count); err == nil {
+				if model == "*" {
+					return k
+				}
+				sum += k
+			}
+		}
+	}
+	return sum
+}
@@ -0,0 +1,255 @@
+// DO NOT EDIT.  Generated from energy.go by generate-table
+
+package energy
+
+import (
+	"cmp"
+	"fmt"
+	"go-utils/gpuset"
+	"io"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+var (
+	_ = cmp.Compare(0, 0)
+	_ fmt.Formatter
+	_ = io.SeekStart
+	_ = UstrEmpty
+	_ gpuset.GpuSet
+)
+
+// MT: Constant after initialization; immutable
+var energyFormatters = map[string]Formatter[*energyRecord]{
+	"JobID": {
+		Fmt: func(d *energyRecord, ctx PrintMods) string {
+			return FormatIntOrEmpty((d.JobID), ctx)
+		},
+		Xtract: func(d *energyRecord) any {
+			return d.JobID
+		},
+		Help: "(int) Job ID (with -by job)",
+	},
+	"User": {
+		Fmt: func(d *energyRecord, ctx PrintMods) string {
+			return FormatUstr((d.User), ctx)
+		},
+		Xtract: func(d *energyRecord) any {
+			return d.User
+		},
+		Help: "(string) Job's user (with -by job or -by user)",
+	},
+	"Account": {
+		Fmt: func(d *energyRecord, ctx PrintMods) string {
+			return FormatUstr((d.Account), ctx)
+		},
+		Xtract: func(d *energyRecord) any {
+			return d.Account
+		},
+		Help: "(string) Job's Slurm account, blank for non-Slurm jobs (with -by job or -by account)",
+	},
+	"Hosts": {
+		Fmt: func(d *energyRecord, ctx PrintMods) string {
+			return FormatHostnames((d.Hosts), ctx)
+		},
+		Xtract: func(d *energyRecord) any {
+			return d.Hosts
+		},
+		Help: "(Hostnames) Node(s) where the energy was used",
+	},
+	"Jobs": {
+		Fmt: func(d *energyRecord, ctx PrintMods) string {
+			return FormatInt((d.Jobs), ctx)
+		},
+		Xtract: func(d *energyRecord) any {
+			return d.Jobs
+		},
+		Help: "(int) Number of jobs in the record",
+	},
+	"Start": {
+		Fmt: func(d *energyRecord, ctx PrintMods) string {
+			return FormatDateTimeValueOrBlank((d.Start), ctx)
+		},
+		Xtract: func(d *energyRecord) any {
+			return d.Start
+		},
+		Help: "(DateTimeValue) Time of the first GPU sample attributed to the record (blank with -by node)",
+	},
+	"End": {
+		Fmt: func(d *energyRecord, ctx PrintMods) string {
+			return FormatDateTimeValueOrBlank((d.End), ctx)
+		},
+		Xtract: func(d *energyRecord) any {
+			return d.End
+		},
+		Help: "(DateTimeValue) End of the last GPU sample interval attributed to the record (blank with -by node)",
+	},
+	"CardHours": {
+		Fmt: func(d *energyRecord, ctx PrintMods) string {
+			return FormatFloat64((d.CardHours), ctx)
+		},
+		Xtract: func(d *energyRecord) any {
+			return d.CardHours
+		},
+		Help: "(float64) GPU card-hours attributed to the record (cards shared by jobs are split evenly)",
+	},
+	"EnergyKWh": {
+		Fmt: func(d *energyRecord, ctx PrintMods) string {
+			return FormatFloat64((d.EnergyKWh), ctx)
+		},
+		Xtract: func(d *energyRecord) any {
+			return d.EnergyKWh
+		},
+		Help: "(float64) GPU energy in kWh attributed to jobs",
+	},
+	"IdleKWh": {
+		Fmt: func(d *energyRecord, ctx PrintMods) string {
+			return FormatFloat64((d.IdleKWh), ctx)
+		},
+		Xtract: func(d *energyRecord) any {
+			return d.IdleKWh
+		},
+		Help: "(float64) GPU energy in kWh not attributed to any job (with -by node)",
+	},
+	"TotalKWh": {
+		Fmt: func(d *energyRecord, ctx PrintMods) string {
+			return FormatFloat64((d.TotalKWh), ctx)
+		},
+		Xtract: func(d *energyRecord) any {
+			return d.TotalKWh
+		},
+		Help: "(float64) Total GPU energy in kWh (with -by node)",
+	},
+}
+
+func init() {
+	DefAlias(energyFormatters, "JobID", "job")
+	DefAlias(energyFormatters, "User", "user")
+	DefAlias(energyFormatters, "Account", "account")
+	DefAlias(energyFormatters, "Hosts", "host")
+	DefAlias(energyFormatters, "Hosts", "hosts")
+	DefAlias(energyFormatters, "Jobs", "jobs")
+	DefAlias(energyFormatters, "Start", "start")
+	DefAlias(energyFormatters, "End", "end")
+	DefAlias(energyFormatters, "CardHours", "card-hours")
+	DefAlias(energyFormatters, "EnergyKWh", "kwh")
+	DefAlias(energyFormatters, "IdleKWh", "idle-kwh")
+	DefAlias(energyFormatters, "TotalKWh", "total-kwh")
+}
+
+// MT: Constant after initialization; immutable
+var energyPredicates = map[string]Predicate[*energyRecord]{
+	"JobID": Predicate[*energyRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *energyRecord, v any) int {
+			return cmp.Compare((d.JobID), v.(IntOrEmpty))
+		},
+	},
+	"User": Predicate[*energyRecord]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *energyRecord, v any) int {
+			return cmp.Compare((d.User), v.(Ustr))
+		},
+	},
+	"Account": Predicate[*energyRecord]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *energyRecord, v any) int {
+			return cmp.Compare((d.Account), v.(Ustr))
+		},
+	},
+	"Hosts": Predicate[*energyRecord]{
+		Convert: CvtString2Hostnames,
+		SetCompare: func(d *energyRecord, v any, op int) bool {
+			return SetCompareHostnames((d.Hosts), v.(*Hostnames), op)
+		},
+	},
+	"Jobs": Predicate[*energyRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *energyRecord, v any) int {
+			return cmp.Compare((d.Jobs), v.(int))
+		},
+	},
+	"Start": Predicate[*energyRecord]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *energyRecord, v any) int {
+			return cmp.Compare((d.Start), v.(DateTimeValueOrBlank))
+		},
+	},
+	"End": Predicate[*energyRecord]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *energyRecord, v any) int {
+			return cmp.Compare((d.End), v.(DateTimeValueOrBlank))
+		},
+	},
+	"CardHours": Predicate[*energyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *energyRecord, v any) int {
+			return cmp.Compare((d.CardHours), v.(float64))
+		},
+	},
+	"EnergyKWh": Predicate[*energyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *energyRecord, v any) int {
+			return cmp.Compare((d.EnergyKWh), v.(float64))
+		},
+	},
+	"IdleKWh": Predicate[*energyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *energyRecord, v any) int {
+			return cmp.Compare((d.IdleKWh), v.(float64))
+		},
+	},
+	"TotalKWh": Predicate[*energyRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *energyRecord, v any) int {
+			return cmp.Compare((d.TotalKWh), v.(float64))
+		},
+	},
+}
+
+func (c *EnergyCommand) Summary(out io.Writer) {
+	fmt.Fprint(out, `Experimental: Attribute GPU energy to jobs, users, and accounts.
+
+The power draw of each GPU card is integrated over time from the GPU
+samples: the power at each sample is taken to last until the next
+sample on the same node, unless the next sample is more than 30 minutes
+away, in which case the data are considered missing for the interval.
+
+The energy of a card in an interval is attributed to the job(s) whose
+processes used the card index at the start of the interval, split
+evenly if several jobs used the card.  The process samples nearest in
+time to the GPU sample are used, if they are within a minute of it.  Energy that is not attributed to
+any job is idle energy for the node.
+
+With -by job (the default) the energy is reported per job, with -by
+user and -by account it is summed per user or Slurm account, and with
+-by node the attributed, idle, and total energy is reported per node.
+`)
+}
+
+const energyHelp = `
+energy
+  Attribute GPU energy to jobs, users, and accounts, and compute idle energy per
+  node.  Output records are sorted by the grouping key.  The default format is
+  'fixed'.
+`
+
+func (c *EnergyCommand) MaybeFormatHelp() *FormatHelp {
+	return StandardFormatHelp(c.Fmt, energyHelp, energyFormatters, energyAliases, energyDefaultFields)
+}
+
+// MT: Constant after initialization; immutable
+var energyAliases = map[string][]string{
+	"default":   []string{"job", "user", "account", "host", "start", "end", "card-hours", "kwh"},
+	"Default":   []string{"JobID", "User", "Account", "Hosts", "Start", "End", "CardHours", "EnergyKWh"},
+	"byuser":    []string{"user", "jobs", "card-hours", "kwh"},
+	"ByUser":    []string{"User", "Jobs", "CardHours", "EnergyKWh"},
+	"byaccount": []string{"account", "jobs", "card-hours", "kwh"},
+	"ByAccount": []string{"Account", "Jobs", "CardHours", "EnergyKWh"},
+	"bynode":    []string{"host", "jobs", "card-hours", "kwh", "idle-kwh", "total-kwh"},
+	"ByNode":    []string{"Hosts", "Jobs", "CardHours", "EnergyKWh", "IdleKWh", "TotalKWh"},
+	"all":       []string{"job", "user", "account", "host", "jobs", "start", "end", "card-hours", "kwh", "idle-kwh", "total-kwh"},
+	"All":       []string{"JobID", "User", "Account", "Hosts", "Jobs", "Start", "End", "CardHours", "EnergyKWh", "IdleKWh", "TotalKWh"},
+}
+
+const energyDefaultFields = "default"
@@ -0,0 +1,120 @@
+// Attribute GPU energy to jobs, users, and accounts, and compute the idle energy per node.
+
+package energy
+
+import (
+	"errors"
+	"fmt"
+
+	. "sonalyze/cmd"
+	. "sonalyze/table"
+)
+
+//go:generate ../../../generate-table/generate-table -o energy-table.go energy.go
+
+/*TABLE energy
+
+package energy
+
+%%
+
+FIELDS *energyRecord
+
+ JobID     IntOrEmpty           alias:"job"        desc:"Job ID (with -by job)"
+ User      Ustr                 alias:"user"       desc:"Job's user (with -by job or -by user)"
+ Account   Ustr                 alias:"account"    desc:"Job's Slurm account, blank for non-Slurm jobs (with -by job or -by account)"
+ Hosts     *Hostnames           alias:"host,hosts" desc:"Node(s) where the energy was used"
+ Jobs      int                  alias:"jobs"       desc:"Number of jobs in the record"
+ Start     DateTimeValueOrBlank alias:"start"      desc:"Time of the first GPU sample attributed to the record (blank with -by node)"
+ End       DateTimeValueOrBlank alias:"end"        desc:"End of the last GPU sample interval attributed to the record (blank with -by node)"
+ CardHours float64              alias:"card-hours" desc:"GPU card-hours attributed to the record (cards shared by jobs are split evenly)"
+ EnergyKWh float64              alias:"kwh"        desc:"GPU energy in kWh attributed to jobs"
+ IdleKWh   float64              alias:"idle-kwh"   desc:"GPU energy in kWh not attributed to any job (with -by node)"
+ TotalKWh  float64              alias:"total-kwh"  desc:"Total GPU energy in kWh (with -by node)"
+
+SUMMARY EnergyCommand
+
+Experimental: Attribute GPU energy to jobs, users, and accounts.
+
+The power draw of each GPU card is integrated over time from the GPU
+samples: the power at each sample is taken to last until the next
+sample on the same node, unless the next sample is more than 30 minutes
+away, in which case the data are considered missing for the interval.
+
+The energy of a card in an interval is attributed to the job(s) whose
+processes used the card index at the start of the interval, split
+evenly if several jobs used the card.  The process samples nearest in
+time to the GPU sample are used, if they are within a minute of it.  Energy that is not attributed to
+any job is idle energy for the node.
+
+With -by job (the default) the energy is reported per job, with -by
+user and -by account it is summed per user or Slurm account, and with
+-by node the attributed, idle, and total energy is reported per node.
+
+HELP EnergyCommand
+
+  Attribute GPU energy to jobs, users, and accounts, and compute idle energy per
+  node.  Output records are sorted by the grouping key.  The default format is
+  'fixed'.
+
+ALIASES
+
+  default   job,user,account,host,start,end,card-hours,kwh
+  Default   JobID,User,Account,Hosts,Start,End,CardHours,EnergyKWh
+  byuser    user,jobs,card-hours,kwh
+  ByUser    User,Jobs,CardHours,EnergyKWh
+  byaccount account,jobs,card-hours,kwh
+  ByAccount Account,Jobs,CardHours,EnergyKWh
+  bynode    host,jobs,card-hours,kwh,idle-kwh,total-kwh
+  ByNode    Hosts,Jobs,CardHours,EnergyKWh,IdleKWh,TotalKWh
+  all       job,user,account,host,jobs,start,end,card-hours,kwh,idle-kwh,total-kwh
+  All       JobID,User,Account,Hosts,Jobs,Start,End,CardHours,EnergyKWh,IdleKWh,TotalKWh
+
+DEFAULTS default
+
+ELBAT*/
+
+type EnergyCommand struct /* implements AnalysisCommand */ {
+	HostAnalysisArgs
+	FormatArgs
+
+	By string
+}
+
+var _ = AnalysisCommand((*EnergyCommand)(nil))
+var _ = SimpleCommand((*EnergyCommand)(nil))
+
+func (ec *EnergyCommand) Add(fs *CLI) {
+	ec.HostAnalysisArgs.Add(fs)
+	ec.FormatArgs.Add(fs)
+
+	fs.Group("aggregation")
+	fs.StringVar(&ec.By, "by", "job",
+		"Report the energy per `key`: job, user, account, node")
+}
+
+func (ec *EnergyCommand) ReifyForRemote(x *ArgReifier) error {
+	x.String("by", ec.By)
+	return errors.Join(
+		ec.HostAnalysisArgs.ReifyForRemote(x),
+		ec.FormatArgs.ReifyForRemote(x),
+	)
+}
+
+func (ec *EnergyCommand) Validate() error {
+	var e1 error
+	defaultFields := energyDefaultFields
+	switch ec.By {
+	case "job":
+	case "user", "account", "node":
+		defaultFields = "by" + ec.By
+	default:
+		e1 = fmt.Errorf("Bad -by value %s, must be job, user, account, or node", ec.By)
+	}
+	return errors.Join(
+		e1,
+		ec.HostAnalysisArgs.Validate(),
+		ValidateFormatArgs(
+			&ec.FormatArgs, defaultFields, energyFormatters, energyAliases, DefaultFixed),
+	)
+}
@@ -0,0 +1,368 @@
+package energy
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+
+	"go-utils/gpuset"
+
+	. "sonalyze/common"
+	"sonalyze/data/common"
+	"sonalyze/data/gpusample"
+	"sonalyze/data/sample"
+	"sonalyze/data/slurmjob"
+	"sonalyze/db/types"
+	. "sonalyze/table"
+)
+
+// If two GPU samples on a node are further apart than this then the data for the interval are
+// considered missing, and no energy is integrated for the interval.
+const maxIntervalSec = 30 * 60
+
+// Process samples and GPU samples are not taken at exactly the same time.  A GPU sample is joined
+// with the process samples nearest in time on the node, if they are within this distance.
+const joinToleranceSec = 60
+
+const joulesPerKWh = 3600 * 1000
+
+type energyRecord struct {
+	JobID     IntOrEmpty
+	User      Ustr
+	Account   Ustr
+	Hosts     *Hostnames
+	Jobs      int
+	Start     DateTimeValueOrBlank
+	End       DateTimeValueOrBlank
+	CardHours float64
+	EnergyKWh float64
+	IdleKWh   float64
+	TotalKWh  float64
+
+	// Raw sums, rounded for presentation when the records are complete
+	cardSecs       float64
+	energyJoules   float64
+	idleJoules     float64
+	slurmJob       bool
+	hostnamesAdded map[Ustr]bool
+}
+
+// Jobs are identified by job ID and user, and for non-Slurm jobs (nonzero epoch), which have
+// node-local job IDs, also by host.
+
+type jobKey struct {
+	job  uint32
+	user Ustr
+	host Ustr
+}
+
+type gpuUse struct {
+	job  jobKey
+	gpus gpuset.GpuSet
+}
+
+// Process GPU use per timestamp on one host, sorted by ascending time.
+type hostUse []useAt
+
+type useAt struct {
+	time  int64
+	users []gpuUse
+}
+
+// The GPU use nearest in time to `t`, or nil if there is none within the tolerance.
+
+func (h hostUse) nearest(t int64) []gpuUse {
+	ix, _ := slices.BinarySearchFunc(h, t, func(u useAt, t int64) int {
+		return cmp.Compare(u.time, t)
+	})
+	best, bestDist := -1, int64(joinToleranceSec)
+	for _, i := range []int{ix - 1, ix} {
+		if i >= 0 && i < len(h) {
+			if d := max(h[i].time-t, t-h[i].time); d <= bestDist {
+				best, bestDist = i, d
+			}
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	return h[best].users
+}
+
+func (ec *EnergyCommand) Perform(meta types.Context, _ io.Reader, stdout, _ io.Writer) error {
+	host, err := common.ResolveHostQuery(meta, ec.Host, ec.FromDate, ec.ToDate)
+	if err != nil {
+		return err
+	}
+	use, err := ec.readGpuUse(meta, host)
+	if err != nil {
+		return err
+	}
+
+	gsd, err := gpusample.OpenGpuSampleDataProvider(meta)
+	if err != nil {
+		return err
+	}
+	gpuStreams, _, read, dropped, err := gsd.Query(ec.FromDate, ec.ToDate, host)
+	if err != nil {
+		return fmt.Errorf("Failed to read GPU records: %v", err)
+	}
+	if Verbose {
+		Log.Infof("%d GPU records read + %d dropped\n", read, dropped)
+	}
+
+	jobs := make(map[jobKey]*energyRecord)
+	nodes := make(map[Ustr]*energyRecord)
+	for hostname, stream := range gpuStreams {
+		node := newRecord()
+		node.addHost(hostname)
+		nodes[hostname] = node
+		hostUse := use[hostname]
+		for i := 0; i+1 < len(stream.Data); i++ {
+			t := stream.Data[i].Time
+			dt := stream.Data[i+1].Time - t
+			if dt > maxIntervalSec {
+				continue
+			}
+			users := hostUse.nearest(t)
+			for _, card := range stream.Data[i].Decoded {
+				joules := float64(card.Power) * float64(dt)
+				node.energyJoules += joules
+				sharing := make([]jobKey, 0)
+				for _, u := range users {
+					if !u.gpus.IsUnknown() && u.gpus.IsSet(int(card.Index)) {
+						sharing = append(sharing, u.job)
+					}
+				}
+				if len(sharing) == 0 {
+					node.idleJoules += joules
+					continue
+				}
+				share := 1 / float64(len(sharing))
+				node.cardSecs += float64(dt) * share
+				for _, k := range sharing {
+					r := jobs[k]
+					if r == nil {
+						r = newRecord()
+						r.JobID = IntOrEmpty(k.job)
+						r.User = k.user
+						r.Jobs = 1
+						r.Start = t
+						r.slurmJob = k.host == UstrEmpty
+						jobs[k] = r
+					}
+					r.addHost(hostname)
+					r.End = t + dt
+					r.cardSecs += float64(dt) * share
+					r.energyJoules += joules * share
+				}
+			}
+		}
+	}
+	if Verbose {
+		Log.Infof("%d jobs used GPUs on %d nodes", len(jobs), len(nodes))
+	}
+
+	err = ec.addAccounts(meta, jobs)
+	if err != nil {
+		return err
+	}
+
+	var records []*energyRecord
+	switch ec.By {
+	case "job":
+		records = recordValues(jobs)
+	case "user":
+		records = rollup(jobs, func(r *energyRecord) *energyRecord {
+			return &energyRecord{User: r.User}
+		})
+	case "account":
+		records = rollup(jobs, func(r *energyRecord) *energyRecord {
+			return &energyRecord{Account: r.Account}
+		})
+	case "node":
+		for _, r := range jobs {
+			for h := range r.hostnamesAdded {
+				nodes[h].Jobs++
+			}
+		}
+		records = recordValues(nodes)
+	}
+	for _, r := range records {
+		r.CardHours = RoundTo(r.cardSecs/3600, 2)
+		r.EnergyKWh = RoundTo((r.energyJoules-r.idleJoules)/joulesPerKWh, 3)
+		if ec.By == "node" {
+			r.IdleKWh = RoundTo(r.idleJoules/joulesPerKWh, 3)
+			r.TotalKWh = RoundTo(r.energyJoules/joulesPerKWh, 3)
+		}
+	}
+
+	records, err = ApplyQuery(ec.ParsedQuery, energyFormatters, energyPredicates, records)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(records, func(a, b *energyRecord) int {
+		return cmp.Or(
+			cmp.Compare(a.JobID, b.JobID),
+			cmp.Compare(a.User.String(), b.User.String()),
+			cmp.Compare(a.Account.String(), b.Account.String()),
+			cmp.Compare(a.Hosts.FormatFull(), b.Hosts.FormatFull()),
+		)
+	})
+	FormatData(
+		stdout,
+		ec.PrintFields,
+		energyFormatters,
+		ec.PrintOpts,
+		records,
+	)
+	return nil
+}
+
+// Read the process samples and record which jobs used which GPUs at which times on which hosts.
+// All users' processes are included, or GPUs used by excluded processes would appear idle.
+
+func (ec *EnergyCommand) readGpuUse(meta types.Context, host Hosts) (map[Ustr]hostUse, error) {
+	sdp, err := sample.OpenSampleDataProvider(meta)
+	if err != nil {
+		return nil, err
+	}
+	sampleBlobs, dropped, err := sdp.QueryRaw(ec.FromDate, ec.ToDate, host)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read log records: %v", err)
+	}
+	if Verbose {
+		Log.Infof("%d sample blobs read + %d dropped\n", len(sampleBlobs), dropped)
+	}
+	byTime := make(map[Ustr]map[int64][]gpuUse)
+	for _, samples := range sampleBlobs {
+		for _, s := range samples {
+			if s.Gpus.IsEmpty() || s.Gpus.IsUnknown() {
+				continue
+			}
+			k := jobKey{job: s.Job, user: s.User}
+			if s.Epoch != 0 || s.Job == 0 {
+				k.host = s.Hostname
+			}
+			h := byTime[s.Hostname]
+			if h == nil {
+				h = make(map[int64][]gpuUse)
+				byTime[s.Hostname] = h
+			}
+			users := h[s.Timestamp]
+			if ix := slices.IndexFunc(users, func(u gpuUse) bool { return u.job == k }); ix >= 0 {
+				users[ix].gpus = gpuset.UnionGpuSets(users[ix].gpus, s.Gpus)
+			} else {
+				h[s.Timestamp] = append(users, gpuUse{k, s.Gpus})
+			}
+		}
+	}
+	use := make(map[Ustr]hostUse)
+	for hostname, h := range byTime {
+		u := make(hostUse, 0, len(h))
+		for t, users := range h {
+			u = append(u, useAt{t, users})
+		}
+		slices.SortFunc(u, func(a, b useAt) int {
+			return cmp.Compare(a.time, b.time)
+		})
+		use[hostname] = u
+	}
+	return use, nil
+}
+
+// Fill in the accounts of Slurm jobs from the sacct data, if there are any.
+
+func (ec *EnergyCommand) addAccounts(meta types.Context, jobs map[jobKey]*energyRecord) error {
+	jobIds := make([]uint32, 0)
+	for k, r := range jobs {
+		if r.slurmJob && k.job != 0 {
+			jobIds = append(jobIds, k.job)
+		}
+	}
+	if len(jobIds) == 0 {
+		return nil
+	}
+	jdp, err := slurmjob.OpenSlurmjobDataProvider(meta)
+	if err != nil {
+		return err
+	}
+	slurmJobs, err := jdp.Query(
+		slurmjob.QueryFilter{
+			QueryFilter: common.QueryFilter{
+				HaveFrom: ec.HaveFrom,
+				FromDate: ec.FromDate,
+				HaveTo:   ec.HaveTo,
+				ToDate:   ec.ToDate,
+			},
+			Job: jobIds,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	accounts := make(map[uint32]Ustr)
+	for _, j := range slurmJobs {
+		accounts[j.Id] = j.Main.Account
+	}
+	for k, r := range jobs {
+		if r.slurmJob {
+			r.Account = accounts[k.job]
+		}
+	}
+	return nil
+}
+
+// Aggregate the job records by the key constructed by `newKey`, which returns a fresh record with
+// only the key fields set.
+
+func rollup(
+	jobs map[jobKey]*energyRecord,
+	newKey func(*energyRecord) *energyRecord,
+) []*energyRecord {
+	byKey := make(map[[2]Ustr]*energyRecord)
+	for _, r := range jobs {
+		key := newKey(r)
+		k := [2]Ustr{key.User, key.Account}
+		acc := byKey[k]
+		if acc == nil {
+			acc = newRecord()
+			acc.User = key.User
+			acc.Account = key.Account
+			acc.Start = r.Start
+			byKey[k] = acc
+		}
+		for h := range r.hostnamesAdded {
+			acc.addHost(h)
+		}
+		acc.Jobs += r.Jobs
+		acc.Start = min(acc.Start, r.Start)
+		acc.End = max(acc.End, r.End)
+		acc.cardSecs += r.cardSecs
+		acc.energyJoules += r.energyJoules
+	}
+	return recordValues(byKey)
+}
+
+func newRecord() *energyRecord {
+	return &energyRecord{
+		Hosts:          NewHostnames(),
+		hostnamesAdded: make(map[Ustr]bool),
+	}
+}
+
+func (r *energyRecord) addHost(hostname Ustr) {
+	if !r.hostnamesAdded[hostname] {
+		r.hostnamesAdded[hostname] = true
+		r.Hosts.AddSingle(hostname.String())
+	}
+}
+
+func recordValues[K comparable](m map[K]*energyRecord) []*energyRecord {
+	records := make([]*energyRecord, 0, len(m))
+	for _, r := range m {
+		records = append(records, r)
+	}
+	return records
+}
@@ -0,0 +1,200 @@
+// DO NOT EDIT.  Generated from fsck.go by generate-table
+
+package fsck
+
+import (
+	"cmp"
+	"fmt"
+	"go-utils/gpuset"
+	"io"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+var (
+	_ = cmp.Compare(0, 0)
+	_ fmt.Formatter
+	_ = io.SeekStart
+	_ = UstrEmpty
+	_ gpuset.GpuSet
+)
+
+// MT: Constant after initialization; immutable
+var fsckFormatters = map[string]Formatter[*fsckItem]{
+	"Directory": {
+		Fmt: func(d *fsckItem, ctx PrintMods) string {
+			return FormatString((d.Directory), ctx)
+		},
+		Xtract: func(d *fsckItem) any {
+			return d.Directory
+		},
+		Help: "(string) Day directory (yyyy/mm/dd) of the file, relative to the data directory",
+	},
+	"File": {
+		Fmt: func(d *fsckItem, ctx PrintMods) string {
+			return FormatString((d.File), ctx)
+		},
+		Xtract: func(d *fsckItem) any {
+			return d.File
+		},
+		Help: "(string) Name of the file within the directory",
+	},
+	"Problem": {
+		Fmt: func(d *fsckItem, ctx PrintMods) string {
+			return FormatString((d.Problem), ctx)
+		},
+		Xtract: func(d *fsckItem) any {
+			return d.Problem
+		},
+		Help: "(string) Problem: parse-error, soft-errors, misplaced, duplicates, unknown-file, name-clash",
+	},
+	"Count": {
+		Fmt: func(d *fsckItem, ctx PrintMods) string {
+			return FormatInt((d.Count), ctx)
+		},
+		Xtract: func(d *fsckItem) any {
+			return d.Count
+		},
+		Help: "(int) Number of records (or errors) affected",
+	},
+	"Detail": {
+		Fmt: func(d *fsckItem, ctx PrintMods) string {
+			return FormatString((d.Detail), ctx)
+		},
+		Xtract: func(d *fsckItem) any {
+			return d.Detail
+		},
+		Help: "(string) Explanation of the problem",
+	},
+	"Bad": {
+		Fmt: func(d *fsckItem, ctx PrintMods) string {
+			return FormatBool((d.Bad), ctx)
+		},
+		Xtract: func(d *fsckItem) any {
+			return d.Bad
+		},
+		Help: "(bool) True if the file is unusable or will be misinterpreted",
+	},
+	"Quarantined": {
+		Fmt: func(d *fsckItem, ctx PrintMods) string {
+			return FormatBool((d.Quarantined), ctx)
+		},
+		Xtract: func(d *fsckItem) any {
+			return d.Quarantined
+		},
+		Help: "(bool) True if the file was moved to the quarantine directory",
+	},
+	"Path": {
+		Fmt: func(d *fsckItem, ctx PrintMods) string {
+			return FormatString((d.Path), ctx)
+		},
+		Xtract: func(d *fsckItem) any {
+			return d.Path
+		},
+		Help: "(string) Full path of the file",
+	},
+}
+
+func init() {
+	DefAlias(fsckFormatters, "Directory", "dir")
+	DefAlias(fsckFormatters, "File", "file")
+	DefAlias(fsckFormatters, "Problem", "problem")
+	DefAlias(fsckFormatters, "Count", "count")
+	DefAlias(fsckFormatters, "Detail", "detail")
+	DefAlias(fsckFormatters, "Bad", "bad")
+	DefAlias(fsckFormatters, "Quarantined", "quarantined")
+	DefAlias(fsckFormatters, "Path", "path")
+}
+
+// MT: Constant after initialization; immutable
+var fsckPredicates = map[string]Predicate[*fsckItem]{
+	"Directory": Predicate[*fsckItem]{
+		Compare: func(d *fsckItem, v any) int {
+			return cmp.Compare((d.Directory), v.(string))
+		},
+	},
+	"File": Predicate[*fsckItem]{
+		Compare: func(d *fsckItem, v any) int {
+			return cmp.Compare((d.File), v.(string))
+		},
+	},
+	"Problem": Predicate[*fsckItem]{
+		Compare: func(d *fsckItem, v any) int {
+			return cmp.Compare((d.Problem), v.(string))
+		},
+	},
+	"Count": Predicate[*fsckItem]{
+		Convert: CvtString2Int,
+		Compare: func(d *fsckItem, v any) int {
+			return cmp.Compare((d.Count), v.(int))
+		},
+	},
+	"Detail": Predicate[*fsckItem]{
+		Compare: func(d *fsckItem, v any) int {
+			return cmp.Compare((d.Detail), v.(string))
+		},
+	},
+	"Bad": Predicate[*fsckItem]{
+		Convert: CvtString2Bool,
+		Compare: func(d *fsckItem, v any) int {
+			return CompareBool((d.Bad), v.(bool))
+		},
+	},
+	"Quarantined": Predicate[*fsckItem]{
+		Convert: CvtString2Bool,
+		Compare: func(d *fsckItem, v any) int {
+			return CompareBool((d.Quarantined), v.(bool))
+		},
+	},
+	"Path": Predicate[*fsckItem]{
+		Compare: func(d *fsckItem, v any) int {
+			return cmp.Compare((d.Path), v.(string))
+		},
+	},
+}
+
+type fsckItem struct {
+	Directory   string
+	File        string
+	Problem     string
+	Count       int
+	Detail      string
+	Bad         bool
+	Quarantined bool
+	Path        string
+}
+
+func (c *FsckCommand) Summary(out io.Writer) {
+	fmt.Fprint(out, `Check the integrity of the data files in a directory-tree data store.
+
+Every file in the date range is parsed, and problems are reported per
+file: parse errors (eg truncated JSON from a crash), records that were
+dropped or damaged during parsing, records whose timestamps fall outside
+the day of the file's directory, duplicate records, and files whose
+names are unknown or forbidden in the tree (including slurm-sacct.csv
+files that hold sample data for a host called "slurm-sacct").
+
+Bad files can optionally be moved to a quarantine directory.
+`)
+}
+
+const fsckHelp = `
+fsck
+  Check data files in a local directory tree for integrity.  Output records are
+  sorted by file name.  The default format is 'fixed'.  This command can't be run
+  against a remote server or a database.
+`
+
+func (c *FsckCommand) MaybeFormatHelp() *FormatHelp {
+	return StandardFormatHelp(c.Fmt, fsckHelp, fsckFormatters, fsckAliases, fsckDefaultFields)
+}
+
+// MT: Constant after initialization; immutable
+var fsckAliases = map[string][]string{
+	"default": []string{"dir", "file", "problem", "count", "detail"},
+	"Default": []string{"Directory", "File", "Problem", "Count", "Detail"},
+	"all":     []string{"dir", "file", "problem", "count", "detail", "bad", "quarantined", "path"},
+	"All":     []string{"Directory", "File", "Problem", "Count", "Detail", "Bad", "Quarantined", "Path"},
+}
+
+const fsckDefaultFields = "default"
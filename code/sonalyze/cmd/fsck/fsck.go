@@ -0,0 +1,159 @@
+// Check the integrity of a directory-tree data store, and optionally move bad files out of the way.
+
+package fsck
+
+import (
+	"errors"
+	"io"
+
+	. "sonalyze/cmd"
+	. "sonalyze/common"
+	"sonalyze/db/filedb"
+	"sonalyze/db/types"
+	. "sonalyze/table"
+)
+
+//go:generate ../../../generate-table/generate-table -o fsck-table.go fsck.go
+
+/*TABLE fsck
+
+package fsck
+
+%%
+
+FIELDS *fsckItem
+
+ Directory   string alias:"dir"         desc:"Day directory (yyyy/mm/dd) of the file, relative to the data directory"
+ File        string alias:"file"        desc:"Name of the file within the directory"
+ Problem     string alias:"problem"     desc:"Problem: parse-error, soft-errors, misplaced, duplicates, unknown-file, name-clash"
+ Count       int    alias:"count"       desc:"Number of records (or errors) affected"
+ Detail      string alias:"detail"      desc:"Explanation of the problem"
+ Bad         bool   alias:"bad"         desc:"True if the file is unusable or will be misinterpreted"
+ Quarantined bool   alias:"quarantined" desc:"True if the file was moved to the quarantine directory"
+ Path        string alias:"path"        desc:"Full path of the file"
+
+GENERATE fsckItem
+
+SUMMARY FsckCommand
+
+Check the integrity of the data files in a directory-tree data store.
+
+Every file in the date range is parsed, and problems are reported per
+file: parse errors (eg truncated JSON from a crash), records that were
+dropped or damaged during parsing, records whose timestamps fall outside
+the day of the file's directory, duplicate records, and files whose
+names are unknown or forbidden in the tree (including slurm-sacct.csv
+files that hold sample data for a host called "slurm-sacct").
+
+Bad files can optionally be moved to a quarantine directory.
+
+HELP FsckCommand
+
+  Check data files in a local directory tree for integrity.  Output records are
+  sorted by file name.  The default format is 'fixed'.  This command can't be run
+  against a remote server or a database.
+
+ALIASES
+
+  default  dir,file,problem,count,detail
+  Default  Directory,File,Problem,Count,Detail
+  all      dir,file,problem,count,detail,bad,quarantined,path
+  All      Directory,File,Problem,Count,Detail,Bad,Quarantined,Path
+
+DEFAULTS default
+
+ELBAT*/
+
+type FsckCommand struct /* implements SimpleCommand */ {
+	DevArgs
+	SourceArgs
+	QueryArgs
+	VerboseArgs
+	FormatArgs
+
+	Slack      uint
+	Quarantine string
+}
+
+var _ = SimpleCommand((*FsckCommand)(nil))
+
+func (fc *FsckCommand) Add(fs *CLI) {
+	fc.DevArgs.Add(fs)
+	fc.SourceArgs.Add(fs, 1)
+	fc.QueryArgs.Add(fs)
+	fc.VerboseArgs.Add(fs)
+	fc.FormatArgs.Add(fs)
+
+	fs.Group("operation-selection")
+	fs.UintVar(&fc.Slack, "slack", 0,
+		"Accept record timestamps this many `minutes` outside the day of the directory [default: 0]")
+	fs.StringVar(&fc.Quarantine, "quarantine", "",
+		"Move bad files to this `directory`, keeping their relative paths")
+}
+
+func (fc *FsckCommand) ReifyForRemote(x *ArgReifier) error {
+	return errors.New("The fsck command can't be run remotely")
+}
+
+func (fc *FsckCommand) Validate() error {
+	return errors.Join(
+		fc.DevArgs.Validate(),
+		fc.SourceArgs.Validate(),
+		fc.QueryArgs.Validate(),
+		fc.VerboseArgs.Validate(),
+		ValidateFormatArgs(
+			&fc.FormatArgs, fsckDefaultFields, fsckFormatters, fsckAliases, DefaultFixed),
+	)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////
+//
+// Processing
+
+func (fc *FsckCommand) Perform(meta types.Context, _ io.Reader, stdout, stderr io.Writer) error {
+	dataDir := meta.DataDir()
+	if dataDir == "" {
+		return errors.New("The fsck command requires a directory tree data store")
+	}
+
+	findings, err := filedb.FsckDirectoryTree(dataDir, fc.FromDate, fc.ToDate, int64(fc.Slack)*60)
+	if err != nil {
+		return err
+	}
+
+	// There can be several findings for a bad file, it must be moved only once.
+	quarantined := make(map[string]bool)
+	items := make([]*fsckItem, 0, len(findings))
+	for _, f := range findings {
+		name := f.Fullname.String()
+		if fc.Quarantine != "" && f.Problem.IsBad() && !quarantined[name] {
+			err := filedb.QuarantineFile(fc.Quarantine, f.Fullname)
+			if err != nil {
+				return err
+			}
+			if Verbose {
+				Log.Infof("Quarantined %s", name)
+			}
+			quarantined[name] = true
+		}
+		items = append(items, &fsckItem{
+			Directory: f.Dirname,
+			File:      f.Basename,
+			Problem:   f.Problem.String(),
+			Count:     f.Count,
+			Detail:    f.Detail,
+			Bad:       f.Problem.IsBad(),
+			Path:      name,
+		})
+	}
+	for _, item := range items {
+		item.Quarantined = quarantined[item.Path]
+	}
+
+	items, err = ApplyQuery(fc.ParsedQuery, fsckFormatters, fsckPredicates, items)
+	if err != nil {
+		return err
+	}
+	FormatData(stdout, fc.PrintFields, fsckFormatters, fc.PrintOpts, items)
+	return nil
+}
@@ -0,0 +1,311 @@
+// DO NOT EDIT.  Generated from idle.go by generate-table
+
+package idle
+
+import (
+	"cmp"
+	"fmt"
+	"go-utils/gpuset"
+	"io"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+var (
+	_ = cmp.Compare(0, 0)
+	_ fmt.Formatter
+	_ = io.SeekStart
+	_ = UstrEmpty
+	_ gpuset.GpuSet
+)
+
+// MT: Constant after initialization; immutable
+var idleFormatters = map[string]Formatter[*idleRecord]{
+	"JobID": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatInt((d.JobID), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.JobID
+		},
+		Help: "(int) Job ID",
+	},
+	"User": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatUstr((d.User), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.User
+		},
+		Help: "(string) Job's user",
+	},
+	"Hosts": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatHostnames((d.Hosts), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.Hosts
+		},
+		Help: "(Hostnames) Node(s) running the job",
+	},
+	"Start": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatDateTimeValue((d.Start), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.Start
+		},
+		Help: "(DateTimeValue) Time of the job's first sample",
+	},
+	"IdleSince": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatDateTimeValue((d.IdleSince), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.IdleSince
+		},
+		Help: "(DateTimeValue) Time of the job's last sample with activity",
+	},
+	"End": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatDateTimeValue((d.End), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.End
+		},
+		Help: "(DateTimeValue) Time of the job's last sample (the latest sample for its nodes)",
+	},
+	"Duration": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.Duration), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.Duration
+		},
+		Help: "(DurationValue) Time from first to last sample",
+	},
+	"IdleDuration": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.IdleDuration), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.IdleDuration
+		},
+		Help: "(DurationValue) Time from the last sample with activity to the last sample",
+	},
+	"CpuAvgPct": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.CpuAvgPct), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.CpuAvgPct
+		},
+		Help: "(int) Average CPU utilization in the idle window in percent (100% = 1 core)",
+	},
+	"GpuAvgPct": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.GpuAvgPct), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.GpuAvgPct
+		},
+		Help: "(int) Average GPU utilization in the idle window in percent (100% = 1 card)",
+	},
+	"IoAvgKBs": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.IoAvgKBs), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.IoAvgKBs
+		},
+		Help: "(int) Average I/O (read + written) in the idle window in KB/s",
+	},
+	"Gpus": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatGpuSet((d.Gpus), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.Gpus
+		},
+		Help: "(GpuSet) GPU cards held by the job in the idle window",
+	},
+	"NumGpus": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatInt((d.NumGpus), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.NumGpus
+		},
+		Help: "(int) Number of GPU cards held by the job in the idle window",
+	},
+	"ResidentGB": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.ResidentGB), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.ResidentGB
+		},
+		Help: "(int) Resident memory held at the last sample, in GB",
+	},
+	"GpuMemGB": {
+		Fmt: func(d *idleRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.GpuMemGB), ctx)
+		},
+		Xtract: func(d *idleRecord) any {
+			return d.GpuMemGB
+		},
+		Help: "(int) GPU memory held at the last sample, in GB",
+	},
+}
+
+func init() {
+	DefAlias(idleFormatters, "JobID", "job")
+	DefAlias(idleFormatters, "User", "user")
+	DefAlias(idleFormatters, "Hosts", "host")
+	DefAlias(idleFormatters, "Hosts", "hosts")
+	DefAlias(idleFormatters, "Start", "start")
+	DefAlias(idleFormatters, "IdleSince", "idle-since")
+	DefAlias(idleFormatters, "End", "end")
+	DefAlias(idleFormatters, "Duration", "duration")
+	DefAlias(idleFormatters, "IdleDuration", "idle")
+	DefAlias(idleFormatters, "CpuAvgPct", "cpu-avg")
+	DefAlias(idleFormatters, "GpuAvgPct", "gpu-avg")
+	DefAlias(idleFormatters, "IoAvgKBs", "io-avg")
+	DefAlias(idleFormatters, "Gpus", "gpus")
+	DefAlias(idleFormatters, "NumGpus", "num-gpus")
+	DefAlias(idleFormatters, "ResidentGB", "res")
+	DefAlias(idleFormatters, "GpuMemGB", "gpumem")
+}
+
+// MT: Constant after initialization; immutable
+var idlePredicates = map[string]Predicate[*idleRecord]{
+	"JobID": Predicate[*idleRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.JobID), v.(int))
+		},
+	},
+	"User": Predicate[*idleRecord]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.User), v.(Ustr))
+		},
+	},
+	"Hosts": Predicate[*idleRecord]{
+		Convert: CvtString2Hostnames,
+		SetCompare: func(d *idleRecord, v any, op int) bool {
+			return SetCompareHostnames((d.Hosts), v.(*Hostnames), op)
+		},
+	},
+	"Start": Predicate[*idleRecord]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.Start), v.(DateTimeValue))
+		},
+	},
+	"IdleSince": Predicate[*idleRecord]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.IdleSince), v.(DateTimeValue))
+		},
+	},
+	"End": Predicate[*idleRecord]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.End), v.(DateTimeValue))
+		},
+	},
+	"Duration": Predicate[*idleRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.Duration), v.(DurationValue))
+		},
+	},
+	"IdleDuration": Predicate[*idleRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.IdleDuration), v.(DurationValue))
+		},
+	},
+	"CpuAvgPct": Predicate[*idleRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.CpuAvgPct), v.(F64Ceil))
+		},
+	},
+	"GpuAvgPct": Predicate[*idleRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.GpuAvgPct), v.(F64Ceil))
+		},
+	},
+	"IoAvgKBs": Predicate[*idleRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.IoAvgKBs), v.(F64Ceil))
+		},
+	},
+	"Gpus": Predicate[*idleRecord]{
+		Convert: CvtString2GpuSet,
+		SetCompare: func(d *idleRecord, v any, op int) bool {
+			return SetCompareGpuSets((d.Gpus), v.(gpuset.GpuSet), op)
+		},
+	},
+	"NumGpus": Predicate[*idleRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.NumGpus), v.(int))
+		},
+	},
+	"ResidentGB": Predicate[*idleRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.ResidentGB), v.(F64Ceil))
+		},
+	},
+	"GpuMemGB": Predicate[*idleRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *idleRecord, v any) int {
+			return cmp.Compare((d.GpuMemGB), v.(F64Ceil))
+		},
+	},
+}
+
+func (c *IdleCommand) Summary(out io.Writer) {
+	fmt.Fprint(out, `Find still-running jobs that have made no progress for a while.
+
+A job is idle from the time of its last sample with activity until its
+last sample, provided its last sample is also the latest sample for its
+nodes (that is, the job is still running at the end of the time window).
+A sample has no activity if the CPU utilization, GPU utilization, and
+I/O rate since the previous sample are all at or below the thresholds.
+
+Jobs that hold GPUs but have no GPU activity, and jobs that are stuck in
+a deadlock with no CPU activity, are typical idle jobs.  Use -check to
+select which of the resources must be inactive: for example, -check gpu
+finds jobs that hold GPUs they do not use, even if they use the CPU
+(jobs without GPUs are not reported in that case).
+
+Slurm jobs are merged across nodes; other jobs are per node.
+`)
+}
+
+const idleHelp = `
+idle
+  Find still-running jobs that have been idle for at least some time.  Output
+  records are sorted by decreasing idle time.  The default format is 'fixed'.
+`
+
+func (c *IdleCommand) MaybeFormatHelp() *FormatHelp {
+	return StandardFormatHelp(c.Fmt, idleHelp, idleFormatters, idleAliases, idleDefaultFields)
+}
+
+// MT: Constant after initialization; immutable
+var idleAliases = map[string][]string{
+	"default": []string{"job", "user", "host", "idle-since", "idle", "cpu-avg", "gpu-avg", "io-avg", "gpus", "res", "gpumem"},
+	"Default": []string{"JobID", "User", "Hosts", "IdleSince", "IdleDuration", "CpuAvgPct", "GpuAvgPct", "IoAvgKBs", "Gpus", "ResidentGB", "GpuMemGB"},
+	"all":     []string{"job", "user", "host", "start", "idle-since", "end", "duration", "idle", "cpu-avg", "gpu-avg", "io-avg", "gpus", "num-gpus", "res", "gpumem"},
+	"All":     []string{"JobID", "User", "Hosts", "Start", "IdleSince", "End", "Duration", "IdleDuration", "CpuAvgPct", "GpuAvgPct", "IoAvgKBs", "Gpus", "NumGpus", "ResidentGB", "GpuMemGB"},
+}
+
+const idleDefaultFields = "default"
@@ -0,0 +1,157 @@
+// Find still-running jobs that have made no progress for a while.
+
+package idle
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	. "sonalyze/cmd"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+//go:generate ../../../generate-table/generate-table -o idle-table.go idle.go
+
+/*TABLE idle
+
+package idle
+
+%%
+
+FIELDS *idleRecord
+
+ JobID        int           alias:"job"        desc:"Job ID"
+ User         Ustr          alias:"user"       desc:"Job's user"
+ Hosts        *Hostnames    alias:"host,hosts" desc:"Node(s) running the job"
+ Start        DateTimeValue alias:"start"      desc:"Time of the job's first sample"
+ IdleSince    DateTimeValue alias:"idle-since" desc:"Time of the job's last sample with activity"
+ End          DateTimeValue alias:"end"        desc:"Time of the job's last sample (the latest sample for its nodes)"
+ Duration     DurationValue alias:"duration"   desc:"Time from first to last sample"
+ IdleDuration DurationValue alias:"idle"       desc:"Time from the last sample with activity to the last sample"
+ CpuAvgPct    F64Ceil       alias:"cpu-avg"    desc:"Average CPU utilization in the idle window in percent (100% = 1 core)"
+ GpuAvgPct    F64Ceil       alias:"gpu-avg"    desc:"Average GPU utilization in the idle window in percent (100% = 1 card)"
+ IoAvgKBs     F64Ceil       alias:"io-avg"     desc:"Average I/O (read + written) in the idle window in KB/s"
+ Gpus         gpuset.GpuSet alias:"gpus"       desc:"GPU cards held by the job in the idle window"
+ NumGpus      int           alias:"num-gpus"   desc:"Number of GPU cards held by the job in the idle window"
+ ResidentGB   F64Ceil       alias:"res"        desc:"Resident memory held at the last sample, in GB"
+ GpuMemGB     F64Ceil       alias:"gpumem"     desc:"GPU memory held at the last sample, in GB"
+
+SUMMARY IdleCommand
+
+Find still-running jobs that have made no progress for a while.
+
+A job is idle from the time of its last sample with activity until its
+last sample, provided its last sample is also the latest sample for its
+nodes (that is, the job is still running at the end of the time window).
+A sample has no activity if the CPU utilization, GPU utilization, and
+I/O rate since the previous sample are all at or below the thresholds.
+
+Jobs that hold GPUs but have no GPU activity, and jobs that are stuck in
+a deadlock with no CPU activity, are typical idle jobs.  Use -check to
+select which of the resources must be inactive: for example, -check gpu
+finds jobs that hold GPUs they do not use, even if they use the CPU
+(jobs without GPUs are not reported in that case).
+
+Slurm jobs are merged across nodes; other jobs are per node.
+
+HELP IdleCommand
+
+  Find still-running jobs that have been idle for at least some time.  Output
+  records are sorted by decreasing idle time.  The default format is 'fixed'.
+
+ALIASES
+
+  default job,user,host,idle-since,idle,cpu-avg,gpu-avg,io-avg,gpus,res,gpumem
+  Default JobID,User,Hosts,IdleSince,IdleDuration,CpuAvgPct,GpuAvgPct,IoAvgKBs,Gpus,ResidentGB,\
+          GpuMemGB
+  all     job,user,host,start,idle-since,end,duration,idle,cpu-avg,gpu-avg,io-avg,gpus,num-gpus,res,\
+          gpumem
+  All     JobID,User,Hosts,Start,IdleSince,End,Duration,IdleDuration,CpuAvgPct,GpuAvgPct,IoAvgKBs,\
+          Gpus,NumGpus,ResidentGB,GpuMemGB
+
+DEFAULTS default
+
+ELBAT*/
+
+type IdleCommand struct /* implements SampleAnalysisCommand */ {
+	SampleAnalysisArgs
+	FormatArgs
+
+	// Thresholds
+	MinIdleSec int64
+	MaxCpuPct  float64
+	MaxGpuPct  float64
+	MaxIoKBs   float64
+	Check      []string
+
+	// Internal / working storage
+	minIdleStr string
+}
+
+var _ = SampleAnalysisCommand((*IdleCommand)(nil))
+
+var checkable = []string{"cpu", "gpu", "io"}
+
+func (ic *IdleCommand) Add(fs *CLI) {
+	ic.SampleAnalysisArgs.Add(fs)
+	ic.FormatArgs.Add(fs)
+
+	fs.Group("job-filter")
+	fs.StringVar(&ic.minIdleStr, "min-idle", "2h",
+		"Select jobs that have been idle at least this long, format `WwDdHhMm`, all parts optional")
+	fs.Float64Var(&ic.MaxCpuPct, "max-cpu", 5,
+		"A sample has no CPU activity if CPU utilization is at most this `percent` (100% = 1 core)")
+	fs.Float64Var(&ic.MaxGpuPct, "max-gpu", 5,
+		"A sample has no GPU activity if GPU utilization is at most this `percent` (100% = 1 card)")
+	fs.Float64Var(&ic.MaxIoKBs, "max-io", 1,
+		"A sample has no I/O activity if the I/O rate is at most this `KB/s`")
+	fs.Var(NewRepeatableString(&ic.Check), "check",
+		"Require no activity for these `resources,...`: cpu, gpu, io [default: cpu,gpu,io]")
+}
+
+func (ic *IdleCommand) ReifyForRemote(x *ArgReifier) error {
+	e1 := errors.Join(
+		ic.SampleAnalysisArgs.ReifyForRemote(x),
+		ic.FormatArgs.ReifyForRemote(x),
+	)
+	x.String("min-idle", ic.minIdleStr)
+	x.Float64("max-cpu", ic.MaxCpuPct)
+	x.Float64("max-gpu", ic.MaxGpuPct)
+	x.Float64("max-io", ic.MaxIoKBs)
+	x.RepeatableString("check", ic.Check)
+	return e1
+}
+
+func (ic *IdleCommand) Validate() error {
+	var e1, e2, e3 error
+	ic.MinIdleSec, e1 = DurationToSeconds("-min-idle", ic.minIdleStr)
+	if len(ic.Check) == 0 {
+		ic.Check = checkable
+	}
+	for _, c := range ic.Check {
+		if !slices.Contains(checkable, c) {
+			e2 = errors.Join(e2, fmt.Errorf("Bad -check value %s, must be cpu, gpu, or io", c))
+		}
+	}
+	e3 = errors.Join(
+		ic.SampleAnalysisArgs.Validate(),
+		ValidateFormatArgs(
+			&ic.FormatArgs, idleDefaultFields, idleFormatters, idleAliases, DefaultFixed),
+	)
+	return errors.Join(e1, e2, e3)
+}
+
+func (ic *IdleCommand) DefaultRecordFilters() (
+	allUsers, skipSystemUsers, excludeSystemCommands, excludeHeartbeat bool,
+) {
+	// Idle jobs are mostly interesting across users, so select all users by default.
+	allUsers, skipSystemUsers, determined := ic.RecordFilterArgs.DefaultUserFilters()
+	if !determined {
+		allUsers, skipSystemUsers = true, true
+	}
+	excludeSystemCommands = true
+	excludeHeartbeat = true
+	return
+}
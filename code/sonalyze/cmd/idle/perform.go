@@ -0,0 +1,182 @@
+package idle
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+
+	"go-utils/gpuset"
+
+	. "sonalyze/common"
+	"sonalyze/data/sample"
+	"sonalyze/db/types"
+	. "sonalyze/table"
+)
+
+type idleRecord struct {
+	JobID        int
+	User         Ustr
+	Hosts        *Hostnames
+	Start        DateTimeValue
+	IdleSince    DateTimeValue
+	End          DateTimeValue
+	Duration     DurationValue
+	IdleDuration DurationValue
+	CpuAvgPct    F64Ceil
+	GpuAvgPct    F64Ceil
+	IoAvgKBs     F64Ceil
+	Gpus         gpuset.GpuSet
+	NumGpus      int
+	ResidentGB   F64Ceil
+	GpuMemGB     F64Ceil
+}
+
+func (ic *IdleCommand) Perform(
+	out io.Writer,
+	meta types.Context,
+	filter sample.QueryFilter,
+	hosts Hosts,
+	recordFilter *sample.SampleFilter,
+) error {
+	sdp, err := sample.OpenSampleDataProvider(meta)
+	if err != nil {
+		return err
+	}
+	streams, bounds, read, dropped, err :=
+		sdp.Query(
+			filter.FromDate,
+			filter.ToDate,
+			hosts,
+			recordFilter,
+			true,
+		)
+	if err != nil {
+		return fmt.Errorf("Failed to read log records: %v", err)
+	}
+	if Verbose {
+		Log.Infof("%d records read + %d dropped\n", read, dropped)
+	}
+
+	jobs, bounds := sample.MergeSlurmByJob(streams, bounds)
+	if Verbose {
+		Log.Infof("Jobs constructed by merging: %d", len(jobs))
+	}
+
+	records := make([]*idleRecord, 0)
+	for _, job := range jobs {
+		// Only jobs that are live at the end can be stuck, the others have terminated.
+		samples := job.Samples
+		bound, found := bounds[job.Host.CanonicalNameUstr()]
+		if !found || samples[len(samples)-1].Timestamp != bound.Latest {
+			continue
+		}
+		if r := ic.idleWindow(job); r != nil {
+			records = append(records, r)
+		}
+	}
+	if Verbose {
+		Log.Infof("Idle jobs: %d", len(records))
+	}
+
+	records, err = ApplyQuery(ic.ParsedQuery, idleFormatters, idlePredicates, records)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(records, func(a, b *idleRecord) int {
+		return cmp.Or(
+			cmp.Compare(b.IdleDuration, a.IdleDuration),
+			cmp.Compare(a.JobID, b.JobID),
+		)
+	})
+	FormatData(
+		out,
+		ic.PrintFields,
+		idleFormatters,
+		ic.PrintOpts,
+		records,
+	)
+	return nil
+}
+
+// Find the trailing run of samples without activity and return a record for it if it is long
+// enough.  The utilization in a sample is for the interval since the previous sample, so the idle
+// window starts at the sample before the run, and the first sample can't be part of the run.
+
+func (ic *IdleCommand) idleWindow(job sample.MergedJob) *idleRecord {
+	samples := job.Samples
+	last := len(samples) - 1
+	checkCpu := slices.Contains(ic.Check, "cpu")
+	checkGpu := slices.Contains(ic.Check, "gpu")
+	checkIo := slices.Contains(ic.Check, "io")
+	first := last + 1
+	for i := last; i > 0; i-- {
+		s, prev := samples[i], samples[i-1]
+		if checkCpu && float64(s.CpuUtilPct) > ic.MaxCpuPct {
+			break
+		}
+		if checkGpu && float64(s.GpuPct) > ic.MaxGpuPct {
+			break
+		}
+		if checkIo {
+			// A decreasing total means processes exited, which is activity of a kind.
+			rate, ok := ioRate(prev, s)
+			if !ok || rate > ic.MaxIoKBs {
+				break
+			}
+		}
+		first = i
+	}
+	if first > last {
+		return nil
+	}
+	idleSince := samples[first-1].Timestamp
+	end := samples[last].Timestamp
+	if end-idleSince < ic.MinIdleSec {
+		return nil
+	}
+
+	var cpuSum, gpuSum float64
+	gpus := gpuset.EmptyGpuSet()
+	for _, s := range samples[first:] {
+		cpuSum += float64(s.CpuUtilPct)
+		gpuSum += float64(s.GpuPct)
+		gpus = gpuset.UnionGpuSets(gpus, s.Gpus)
+	}
+	if !checkCpu && !checkIo && (gpus.IsEmpty() || gpus.IsUnknown()) {
+		// Only GPU activity was checked, and the job holds no GPUs.
+		return nil
+	}
+	n := float64(last - first + 1)
+	ioTotal := int64(samples[last].DataReadKB+samples[last].DataWrittenKB) -
+		int64(samples[first-1].DataReadKB+samples[first-1].DataWrittenKB)
+	r := &idleRecord{
+		JobID:        int(samples[0].Job),
+		User:         samples[0].User,
+		Hosts:        NewHostnamesFromHosts(job.Host),
+		Start:        samples[0].Timestamp,
+		IdleSince:    idleSince,
+		End:          end,
+		Duration:     end - samples[0].Timestamp,
+		IdleDuration: end - idleSince,
+		CpuAvgPct:    cpuSum / n,
+		GpuAvgPct:    gpuSum / n,
+		IoAvgKBs:     float64(max(0, ioTotal)) / float64(end-idleSince),
+		Gpus:         gpus,
+		ResidentGB:   float64(samples[last].RssAnonKB) * KBToGB,
+		GpuMemGB:     float64(samples[last].GpuKB) * KBToGB,
+	}
+	if !gpus.IsUnknown() {
+		r.NumGpus = gpus.Size()
+	}
+	return r
+}
+
+func ioRate(prev, s sample.Sample) (float64, bool) {
+	before := prev.DataReadKB + prev.DataWrittenKB
+	after := s.DataReadKB + s.DataWrittenKB
+	if after < before {
+		return 0, false
+	}
+	return float64(after-before) / float64(s.Timestamp-prev.Timestamp), true
+}
@@ -323,6 +323,224 @@ var jobsFormatters = map[string]Formatter[*jobSummary]{
 		},
 		Help: "(int) Peak number of active threads summed across all processes",
 	},
+	"CpuTwAvgPct": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kCpuPctTwAvg]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kCpuPctTwAvg]
+		},
+		Help: "(int) Time-weighted average CPU utilization in percent (100% = 1 core)",
+	},
+	"CpuP50Pct": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kCpuPctP50]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kCpuPctP50]
+		},
+		Help: "(int) Time-weighted median CPU utilization in percent (100% = 1 core)",
+	},
+	"CpuP90Pct": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kCpuPctP90]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kCpuPctP90]
+		},
+		Help: "(int) Time-weighted 90th percentile CPU utilization in percent (100% = 1 core)",
+	},
+	"CpuP99Pct": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kCpuPctP99]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kCpuPctP99]
+		},
+		Help: "(int) Time-weighted 99th percentile CPU utilization in percent (100% = 1 core)",
+	},
+	"MemTwAvgGB": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kCpuGBTwAvg]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kCpuGBTwAvg]
+		},
+		Help: "(int) Time-weighted average main virtual memory utilization in GB",
+	},
+	"MemP50GB": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kCpuGBP50]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kCpuGBP50]
+		},
+		Help: "(int) Time-weighted median main virtual memory utilization in GB",
+	},
+	"MemP90GB": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kCpuGBP90]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kCpuGBP90]
+		},
+		Help: "(int) Time-weighted 90th percentile main virtual memory utilization in GB",
+	},
+	"MemP99GB": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kCpuGBP99]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kCpuGBP99]
+		},
+		Help: "(int) Time-weighted 99th percentile main virtual memory utilization in GB",
+	},
+	"ResidentMemTwAvgGB": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kRssAnonGBTwAvg]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kRssAnonGBTwAvg]
+		},
+		Help: "(int) Time-weighted average main resident memory utilization in GB",
+	},
+	"ResidentMemP50GB": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kRssAnonGBP50]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kRssAnonGBP50]
+		},
+		Help: "(int) Time-weighted median main resident memory utilization in GB",
+	},
+	"ResidentMemP90GB": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kRssAnonGBP90]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kRssAnonGBP90]
+		},
+		Help: "(int) Time-weighted 90th percentile main resident memory utilization in GB",
+	},
+	"ResidentMemP99GB": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kRssAnonGBP99]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kRssAnonGBP99]
+		},
+		Help: "(int) Time-weighted 99th percentile main resident memory utilization in GB",
+	},
+	"GpuTwAvgPct": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kGpuPctTwAvg]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kGpuPctTwAvg]
+		},
+		Help: "(int) Time-weighted average GPU utilization in percent (100% = 1 card)",
+	},
+	"GpuP50Pct": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kGpuPctP50]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kGpuPctP50]
+		},
+		Help: "(int) Time-weighted median GPU utilization in percent (100% = 1 card)",
+	},
+	"GpuP90Pct": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kGpuPctP90]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kGpuPctP90]
+		},
+		Help: "(int) Time-weighted 90th percentile GPU utilization in percent (100% = 1 card)",
+	},
+	"GpuP99Pct": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kGpuPctP99]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kGpuPctP99]
+		},
+		Help: "(int) Time-weighted 99th percentile GPU utilization in percent (100% = 1 card)",
+	},
+	"GpuMemTwAvgGB": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kGpuGBTwAvg]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kGpuGBTwAvg]
+		},
+		Help: "(int) Time-weighted average resident GPU memory utilization in GB",
+	},
+	"GpuMemP50GB": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kGpuGBP50]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kGpuGBP50]
+		},
+		Help: "(int) Time-weighted median resident GPU memory utilization in GB",
+	},
+	"GpuMemP90GB": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kGpuGBP90]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kGpuGBP90]
+		},
+		Help: "(int) Time-weighted 90th percentile resident GPU memory utilization in GB",
+	},
+	"GpuMemP99GB": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatF64Ceil((d.computed[kGpuGBP99]), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.computed[kGpuGBP99]
+		},
+		Help: "(int) Time-weighted 99th percentile resident GPU memory utilization in GB",
+	},
+	"MemGrowthGBPerHour": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatFloat64((d.MemGrowthGBPerHour), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.MemGrowthGBPerHour
+		},
+		Help: "(float64) Trend of main resident memory utilization in GB per hour (least-squares fit over the samples)",
+	},
+	"ProjectedOomTime": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatDateTimeValueOrBlank((d.ProjectedOomTime), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.ProjectedOomTime
+		},
+		Help:        "(DateTimeValue) Time at which the main resident memory trend reaches the node's memory, blank if not growing",
+		NeedsConfig: true,
+	},
+	"GpuMemGrowthGBPerHour": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatFloat64((d.GpuMemGrowthGBPerHour), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.GpuMemGrowthGBPerHour
+		},
+		Help: "(float64) Trend of GPU memory utilization in GB per hour (least-squares fit over the samples)",
+	},
+	"ProjectedGpuOomTime": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatDateTimeValueOrBlank((d.ProjectedGpuOomTime), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.ProjectedGpuOomTime
+		},
+		Help:        "(DateTimeValue) Time at which the GPU memory trend reaches the memory of the job's cards, blank if not growing",
+		NeedsConfig: true,
+	},
 	"Gpus": {
 		Fmt: func(d *jobSummary, ctx PrintMods) string {
 			return FormatGpuSet((d.Gpus), ctx)
@@ -413,6 +631,42 @@ var jobsFormatters = map[string]Formatter[*jobSummary]{
 		},
 		Help: "(uint64) Total read traffic",
 	},
+	"ReadMBsAvg": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatFloat64((d.ReadMBsAvg), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.ReadMBsAvg
+		},
+		Help: "(float64) Average rate of data read in MB/s",
+	},
+	"ReadMBsPeak": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatFloat64((d.ReadMBsPeak), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.ReadMBsPeak
+		},
+		Help: "(float64) Peak rate of data read in MB/s between two samples",
+	},
+	"WriteMBsAvg": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatFloat64((d.WriteMBsAvg), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.WriteMBsAvg
+		},
+		Help: "(float64) Average rate of data written in MB/s",
+	},
+	"WriteMBsPeak": {
+		Fmt: func(d *jobSummary, ctx PrintMods) string {
+			return FormatFloat64((d.WriteMBsPeak), ctx)
+		},
+		Xtract: func(d *jobSummary) any {
+			return d.WriteMBsPeak
+		},
+		Help: "(float64) Peak rate of data written in MB/s between two samples",
+	},
 	"SomeGpu": {
 		Fmt: func(d *jobSummary, ctx PrintMods) string {
 			return FormatBool((d.computedFlags&kUsesGpu != 0), ctx)
@@ -1050,6 +1304,30 @@ func init() {
 	DefAlias(jobsFormatters, "OccupiedRelativeGpuMemPeakPct", "sgpumem-peak")
 	DefAlias(jobsFormatters, "ThreadAvg", "thread-avg")
 	DefAlias(jobsFormatters, "ThreadPeak", "thread-peak")
+	DefAlias(jobsFormatters, "CpuTwAvgPct", "cpu-twavg")
+	DefAlias(jobsFormatters, "CpuP50Pct", "cpu-p50")
+	DefAlias(jobsFormatters, "CpuP90Pct", "cpu-p90")
+	DefAlias(jobsFormatters, "CpuP99Pct", "cpu-p99")
+	DefAlias(jobsFormatters, "MemTwAvgGB", "mem-twavg")
+	DefAlias(jobsFormatters, "MemP50GB", "mem-p50")
+	DefAlias(jobsFormatters, "MemP90GB", "mem-p90")
+	DefAlias(jobsFormatters, "MemP99GB", "mem-p99")
+	DefAlias(jobsFormatters, "ResidentMemTwAvgGB", "res-twavg")
+	DefAlias(jobsFormatters, "ResidentMemP50GB", "res-p50")
+	DefAlias(jobsFormatters, "ResidentMemP90GB", "res-p90")
+	DefAlias(jobsFormatters, "ResidentMemP99GB", "res-p99")
+	DefAlias(jobsFormatters, "GpuTwAvgPct", "gpu-twavg")
+	DefAlias(jobsFormatters, "GpuP50Pct", "gpu-p50")
+	DefAlias(jobsFormatters, "GpuP90Pct", "gpu-p90")
+	DefAlias(jobsFormatters, "GpuP99Pct", "gpu-p99")
+	DefAlias(jobsFormatters, "GpuMemTwAvgGB", "gpumem-twavg")
+	DefAlias(jobsFormatters, "GpuMemP50GB", "gpumem-p50")
+	DefAlias(jobsFormatters, "GpuMemP90GB", "gpumem-p90")
+	DefAlias(jobsFormatters, "GpuMemP99GB", "gpumem-p99")
+	DefAlias(jobsFormatters, "MemGrowthGBPerHour", "res-growth")
+	DefAlias(jobsFormatters, "ProjectedOomTime", "oom-time")
+	DefAlias(jobsFormatters, "GpuMemGrowthGBPerHour", "gpumem-growth")
+	DefAlias(jobsFormatters, "ProjectedGpuOomTime", "gpu-oom-time")
 	DefAlias(jobsFormatters, "Gpus", "gpus")
 	DefAlias(jobsFormatters, "GpuFail", "gpufail")
 	DefAlias(jobsFormatters, "Cmd", "cmd")
@@ -1061,6 +1339,10 @@ func init() {
 	DefAlias(jobsFormatters, "GpuTime", "gputime")
 	DefAlias(jobsFormatters, "ReadGB", "read")
 	DefAlias(jobsFormatters, "WrittenGB", "written")
+	DefAlias(jobsFormatters, "ReadMBsAvg", "read-avg")
+	DefAlias(jobsFormatters, "ReadMBsPeak", "read-peak")
+	DefAlias(jobsFormatters, "WriteMBsAvg", "write-avg")
+	DefAlias(jobsFormatters, "WriteMBsPeak", "write-peak")
 }
 
 // MT: Constant after initialization; immutable
@@ -1256,6 +1538,150 @@ var jobsPredicates = map[string]Predicate[*jobSummary]{
 			return cmp.Compare((d.computed[kThreadPeak]), v.(F64Ceil))
 		},
 	},
+	"CpuTwAvgPct": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kCpuPctTwAvg]), v.(F64Ceil))
+		},
+	},
+	"CpuP50Pct": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kCpuPctP50]), v.(F64Ceil))
+		},
+	},
+	"CpuP90Pct": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kCpuPctP90]), v.(F64Ceil))
+		},
+	},
+	"CpuP99Pct": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kCpuPctP99]), v.(F64Ceil))
+		},
+	},
+	"MemTwAvgGB": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kCpuGBTwAvg]), v.(F64Ceil))
+		},
+	},
+	"MemP50GB": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kCpuGBP50]), v.(F64Ceil))
+		},
+	},
+	"MemP90GB": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kCpuGBP90]), v.(F64Ceil))
+		},
+	},
+	"MemP99GB": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kCpuGBP99]), v.(F64Ceil))
+		},
+	},
+	"ResidentMemTwAvgGB": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kRssAnonGBTwAvg]), v.(F64Ceil))
+		},
+	},
+	"ResidentMemP50GB": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kRssAnonGBP50]), v.(F64Ceil))
+		},
+	},
+	"ResidentMemP90GB": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kRssAnonGBP90]), v.(F64Ceil))
+		},
+	},
+	"ResidentMemP99GB": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kRssAnonGBP99]), v.(F64Ceil))
+		},
+	},
+	"GpuTwAvgPct": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kGpuPctTwAvg]), v.(F64Ceil))
+		},
+	},
+	"GpuP50Pct": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kGpuPctP50]), v.(F64Ceil))
+		},
+	},
+	"GpuP90Pct": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kGpuPctP90]), v.(F64Ceil))
+		},
+	},
+	"GpuP99Pct": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kGpuPctP99]), v.(F64Ceil))
+		},
+	},
+	"GpuMemTwAvgGB": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kGpuGBTwAvg]), v.(F64Ceil))
+		},
+	},
+	"GpuMemP50GB": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kGpuGBP50]), v.(F64Ceil))
+		},
+	},
+	"GpuMemP90GB": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kGpuGBP90]), v.(F64Ceil))
+		},
+	},
+	"GpuMemP99GB": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.computed[kGpuGBP99]), v.(F64Ceil))
+		},
+	},
+	"MemGrowthGBPerHour": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.MemGrowthGBPerHour), v.(float64))
+		},
+	},
+	"ProjectedOomTime": Predicate[*jobSummary]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.ProjectedOomTime), v.(DateTimeValueOrBlank))
+		},
+	},
+	"GpuMemGrowthGBPerHour": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.GpuMemGrowthGBPerHour), v.(float64))
+		},
+	},
+	"ProjectedGpuOomTime": Predicate[*jobSummary]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.ProjectedGpuOomTime), v.(DateTimeValueOrBlank))
+		},
+	},
 	"Gpus": Predicate[*jobSummary]{
 		Convert: CvtString2GpuSet,
 		SetCompare: func(d *jobSummary, v any, op int) bool {
@@ -1315,6 +1741,30 @@ var jobsPredicates = map[string]Predicate[*jobSummary]{
 			return cmp.Compare((d.u64[uWrittenGBTotal]), v.(uint64))
 		},
 	},
+	"ReadMBsAvg": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.ReadMBsAvg), v.(float64))
+		},
+	},
+	"ReadMBsPeak": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.ReadMBsPeak), v.(float64))
+		},
+	},
+	"WriteMBsAvg": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.WriteMBsAvg), v.(float64))
+		},
+	},
+	"WriteMBsPeak": Predicate[*jobSummary]{
+		Convert: CvtString2Float64,
+		Compare: func(d *jobSummary, v any) int {
+			return cmp.Compare((d.WriteMBsPeak), v.(float64))
+		},
+	},
 	"SomeGpu": Predicate[*jobSummary]{
 		Convert: CvtString2Bool,
 		Compare: func(d *jobSummary, v any) int {
@@ -1717,7 +2167,7 @@ func (c *JobsCommand) MaybeFormatHelp() *FormatHelp {
 
 // MT: Constant after initialization; immutable
 var jobsAliases = map[string][]string{
-	"all":                    []string{"jobm", "job", "user", "duration", "duration/sec", "start", "start/sec", "end", "end/sec", "cpu-avg", "cpu-peak", "rcpu-avg", "rcpu-peak", "mem-avg", "mem-peak", "rmem-avg", "rmem-peak", "res-avg", "res-peak", "rres-avg", "rres-peak", "gpu-avg", "gpu-peak", "rgpu-avg", "rgpu-peak", "sgpu-avg", "sgpu-peak", "gpumem-avg", "gpumem-peak", "rgpumem-avg", "rgpumem-peak", "sgpumem-avg", "sgpumem-peak", "thread-avg", "thread-peak", "gpus", "gpufail", "cmd", "host", "now", "now/sec", "classification", "cputime/sec", "cputime", "gputime/sec", "gputime"},
+	"all":                    []string{"jobm", "job", "user", "duration", "duration/sec", "start", "start/sec", "end", "end/sec", "cpu-avg", "cpu-peak", "rcpu-avg", "rcpu-peak", "mem-avg", "mem-peak", "rmem-avg", "rmem-peak", "res-avg", "res-peak", "rres-avg", "rres-peak", "gpu-avg", "gpu-peak", "rgpu-avg", "rgpu-peak", "sgpu-avg", "sgpu-peak", "gpumem-avg", "gpumem-peak", "rgpumem-avg", "rgpumem-peak", "sgpumem-avg", "sgpumem-peak", "thread-avg", "thread-peak", "cpu-twavg", "cpu-p50", "cpu-p90", "cpu-p99", "mem-twavg", "mem-p50", "mem-p90", "mem-p99", "res-twavg", "res-p50", "res-p90", "res-p99", "gpu-twavg", "gpu-p50", "gpu-p90", "gpu-p99", "gpumem-twavg", "gpumem-p50", "gpumem-p90", "gpumem-p99", "res-growth", "oom-time", "gpumem-growth", "gpu-oom-time", "read-avg", "read-peak", "write-avg", "write-peak", "gpus", "gpufail", "cmd", "host", "now", "now/sec", "classification", "cputime/sec", "cputime", "gputime/sec", "gputime"},
 	"std":                    []string{"jobm", "user", "duration", "host"},
 	"cpu":                    []string{"cpu-avg", "cpu-peak"},
 	"rcpu":                   []string{"rcpu-avg", "rcpu-peak"},
@@ -1732,7 +2182,15 @@ var jobsAliases = map[string][]string{
 	"rgpumem":                []string{"rgpumem-avg", "rgpumem-peak"},
 	"sgpumem":                []string{"sgpumem-avg", "sgpumem-peak"},
 	"threads":                []string{"thread-avg", "thread-peak"},
-	"All":                    []string{"JobAndMark", "Job", "User", "Duration", "Duration/sec", "Start", "Start/sec", "End", "End/sec", "CpuAvgPct", "CpuPeakPct", "RelativeCpuAvgPct", "RelativeCpuPeakPct", "MemAvgGB", "MemPeakGB", "RelativeMemAvgPct", "RelativeMemPeakPct", "ResidentMemAvgGB", "ResidentMemPeakGB", "RelativeResidentMemAvgPct", "RelativeResidentMemPeakPct", "GpuAvgPct", "GpuPeakPct", "RelativeGpuAvgPct", "RelativeGpuPeakPct", "OccupiedRelativeGpuAvgPct", "OccupiedRelativeGpuPeakPct", "GpuMemAvgGB", "GpuMemPeakGB", "RelativeGpuMemAvgPct", "RelativeGpuMemPeakPct", "OccupiedRelativeGpuMemAvgPct", "OccupiedRelativeGpuMemPeakPct", "ThreadAvg", "ThreadPeak", "Gpus", "GpuFail", "Cmd", "Hosts", "Now", "Now/sec", "Classification", "CpuTime/sec", "CpuTime", "GpuTime/sec", "GpuTime", "SomeGpu", "NoGpu", "Running", "Completed", "Zombie", "Primordial", "BornLater"},
+	"twavg":                  []string{"cpu-twavg", "mem-twavg", "res-twavg", "gpu-twavg", "gpumem-twavg"},
+	"cpupct":                 []string{"cpu-p50", "cpu-p90", "cpu-p99"},
+	"mempct":                 []string{"mem-p50", "mem-p90", "mem-p99"},
+	"respct":                 []string{"res-p50", "res-p90", "res-p99"},
+	"gpupct":                 []string{"gpu-p50", "gpu-p90", "gpu-p99"},
+	"gpumempct":              []string{"gpumem-p50", "gpumem-p90", "gpumem-p99"},
+	"growth":                 []string{"res-growth", "oom-time", "gpumem-growth", "gpu-oom-time"},
+	"io":                     []string{"read-avg", "read-peak", "write-avg", "write-peak"},
+	"All":                    []string{"JobAndMark", "Job", "User", "Duration", "Duration/sec", "Start", "Start/sec", "End", "End/sec", "CpuAvgPct", "CpuPeakPct", "RelativeCpuAvgPct", "RelativeCpuPeakPct", "MemAvgGB", "MemPeakGB", "RelativeMemAvgPct", "RelativeMemPeakPct", "ResidentMemAvgGB", "ResidentMemPeakGB", "RelativeResidentMemAvgPct", "RelativeResidentMemPeakPct", "GpuAvgPct", "GpuPeakPct", "RelativeGpuAvgPct", "RelativeGpuPeakPct", "OccupiedRelativeGpuAvgPct", "OccupiedRelativeGpuPeakPct", "GpuMemAvgGB", "GpuMemPeakGB", "RelativeGpuMemAvgPct", "RelativeGpuMemPeakPct", "OccupiedRelativeGpuMemAvgPct", "OccupiedRelativeGpuMemPeakPct", "ThreadAvg", "ThreadPeak", "CpuTwAvgPct", "CpuP50Pct", "CpuP90Pct", "CpuP99Pct", "MemTwAvgGB", "MemP50GB", "MemP90GB", "MemP99GB", "ResidentMemTwAvgGB", "ResidentMemP50GB", "ResidentMemP90GB", "ResidentMemP99GB", "GpuTwAvgPct", "GpuP50Pct", "GpuP90Pct", "GpuP99Pct", "GpuMemTwAvgGB", "GpuMemP50GB", "GpuMemP90GB", "GpuMemP99GB", "MemGrowthGBPerHour", "ProjectedOomTime", "GpuMemGrowthGBPerHour", "ProjectedGpuOomTime", "ReadMBsAvg", "ReadMBsPeak", "WriteMBsAvg", "WriteMBsPeak", "Gpus", "GpuFail", "Cmd", "Hosts", "Now", "Now/sec", "Classification", "CpuTime/sec", "CpuTime", "GpuTime/sec", "GpuTime", "SomeGpu", "NoGpu", "Running", "Completed", "Zombie", "Primordial", "BornLater"},
 	"Std":                    []string{"JobAndMark", "User", "Duration", "Hosts"},
 	"Cpu":                    []string{"CpuAvgPct", "CpuPeakPct"},
 	"RelativeCpu":            []string{"RelativeCpuAvgPct", "RelativeCpuPeakPct"},
@@ -1747,6 +2205,14 @@ var jobsAliases = map[string][]string{
 	"RelativeGpuMem":         []string{"RelativeGpuMemAvgPct", "RelativeGpuMemPeakPct"},
 	"OccupiedRelativeGpuMem": []string{"OccupiedRelativeGpuMemAvgPct", "OccupiedRelativeGpuMemPeakPct"},
 	"Threads":                []string{"ThreadAvg", "ThreadPeak"},
+	"TwAvg":                  []string{"CpuTwAvgPct", "MemTwAvgGB", "ResidentMemTwAvgGB", "GpuTwAvgPct", "GpuMemTwAvgGB"},
+	"CpuPct":                 []string{"CpuP50Pct", "CpuP90Pct", "CpuP99Pct"},
+	"MemPct":                 []string{"MemP50GB", "MemP90GB", "MemP99GB"},
+	"ResidentMemPct":         []string{"ResidentMemP50GB", "ResidentMemP90GB", "ResidentMemP99GB"},
+	"GpuPct":                 []string{"GpuP50Pct", "GpuP90Pct", "GpuP99Pct"},
+	"GpuMemPct":              []string{"GpuMemP50GB", "GpuMemP90GB", "GpuMemP99GB"},
+	"Growth":                 []string{"MemGrowthGBPerHour", "ProjectedOomTime", "GpuMemGrowthGBPerHour", "ProjectedGpuOomTime"},
+	"Io":                     []string{"ReadMBsAvg", "ReadMBsPeak", "WriteMBsAvg", "WriteMBsPeak"},
 	"default":                []string{"std", "cpu", "mem", "gpu", "gpumem", "cmd"},
 	"Default":                []string{"Std", "Cpu", "Mem", "Gpu", "GpuMem", "Cmd"},
 }
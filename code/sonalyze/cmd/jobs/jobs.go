@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"errors"
+	"fmt"
 
 	. "sonalyze/cmd"
 	. "sonalyze/common"
@@ -22,7 +23,7 @@ type uintArg struct {
 const bigValue = 100000000
 
 // MT: Constant after initialization; immutable
-var uintArgs = []uintArg{
+var uintArgs = append([]uintArg{
 	uintArg{
 		"Select only jobs with at least this many samples [default: 1]",
 		1,
@@ -226,6 +227,48 @@ var uintArgs = []uintArg{
 		kRgpuGBPeak,
 		true,
 	},
+}, statUintArgs()...)
+
+// Filters on the time-weighted statistics are regular enough to be generated: min and max filters
+// for the utilization, and min filters for the memory, as for the average and peak filters above.
+func statUintArgs() []uintArg {
+	var args []uintArg
+	for _, q := range []struct {
+		name, text string
+		ix         int
+		withMax    bool
+	}{
+		{"cpu", "CPU use (100=1 full CPU)", kCpuPctTwAvg, true},
+		{"mem", "virtual memory use (GB)", kCpuGBTwAvg, false},
+		{"res", "resident memory use (GB)", kRssAnonGBTwAvg, false},
+		{"gpu", "GPU use (100=1 full GPU card)", kGpuPctTwAvg, true},
+		{"gpumem", "GPU memory use (GB)", kGpuGBTwAvg, false},
+	} {
+		for i, stat := range []struct{ name, text string }{
+			{"twavg", "time-weighted average"},
+			{"p50", "time-weighted median"},
+			{"p90", "time-weighted 90th percentile"},
+			{"p99", "time-weighted 99th percentile"},
+		} {
+			args = append(args, uintArg{
+				fmt.Sprintf("Select only jobs with at least this much %s %s", stat.text, q.text),
+				0,
+				fmt.Sprintf("min-%s-%s", q.name, stat.name),
+				q.ix + i,
+				false,
+			})
+			if q.withMax {
+				args = append(args, uintArg{
+					fmt.Sprintf("Select only jobs with at most this much %s %s", stat.text, q.text),
+					bigValue,
+					fmt.Sprintf("max-%s-%s", q.name, stat.name),
+					q.ix + i,
+					false,
+				})
+			}
+		}
+	}
+	return args
 }
 
 type JobsCommand struct /* implements SampleAnalysisCommand */ {
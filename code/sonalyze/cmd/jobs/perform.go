@@ -51,6 +51,28 @@ const (
 	kSgpuGBPeak            // Peak GPU memory utilization ditto
 	kThreadAvg             // Average number of active threads (summed across all processes)
 	kThreadPeak            // Peak number of active threads (ditto)
+
+	// Time-weighted averages and percentiles, see weighSamples()
+	kCpuPctTwAvg
+	kCpuPctP50
+	kCpuPctP90
+	kCpuPctP99
+	kCpuGBTwAvg
+	kCpuGBP50
+	kCpuGBP90
+	kCpuGBP99
+	kRssAnonGBTwAvg
+	kRssAnonGBP50
+	kRssAnonGBP90
+	kRssAnonGBP99
+	kGpuPctTwAvg
+	kGpuPctP50
+	kGpuPctP90
+	kGpuPctP99
+	kGpuGBTwAvg
+	kGpuGBP50
+	kGpuGBP90
+	kGpuGBP99
 	numF64Fields
 )
 
@@ -79,8 +101,6 @@ const (
 	kIsZombie                       // Command contains <defunct> or user starts with _zombie_
 )
 
-const kb2gb = 1.0 / (1024 * 1024)
-
 // Package for results from aggregation and summation.
 type jobSummary struct {
 	jobAggregate
@@ -109,15 +129,30 @@ type jobSummary struct {
 // from the recorded percentage figure, otherwise kRgpuGB* are derived from the recorded absolute
 // figures.  If a system config is not present then all fields will represent the recorded values
 // (kRgpuKB * the recorded percentages).
+//
+// The memory growth rates are the slopes of least-squares fits of the memory utilization over the
+// samples, in GiB per hour, rounded to two decimals.  The projected OOM times are the times at which
+// the fitted lines reach the available memory; they are zero if the memory is not growing or if
+// there is no system config.
+//
+// The I/O rates are in MB/s, rounded to two decimals, see ioRates().
 type jobAggregate struct {
-	GpuFail     int
-	Gpus        gpuset.GpuSet
-	computed    [numF64Fields]float64
-	u64         [numU64Fields]uint64
-	IsZombie    bool
-	InContainer bool
-	Cmd         string
-	Hosts       *Hostnames
+	GpuFail               int
+	Gpus                  gpuset.GpuSet
+	MemGrowthGBPerHour    float64
+	GpuMemGrowthGBPerHour float64
+	ProjectedOomTime      DateTimeValueOrBlank
+	ProjectedGpuOomTime   DateTimeValueOrBlank
+	ReadMBsAvg            float64
+	ReadMBsPeak           float64
+	WriteMBsAvg           float64
+	WriteMBsPeak          float64
+	computed              [numF64Fields]float64
+	u64                   [numU64Fields]uint64
+	IsZombie              bool
+	InContainer           bool
+	Cmd                   string
+	Hosts                 *Hostnames
 }
 
 func (jc *JobsCommand) Perform(
@@ -195,7 +230,7 @@ func (jc *JobsCommand) summarizeAndFilterJobs(
 	if jc.MergeAll {
 		jobs, bounds = sample.MergeByJob(streams, bounds)
 	} else if !jc.MergeNone {
-		jobs, bounds = mergeAcrossSomeNodes(streams, bounds)
+		jobs, bounds = sample.MergeSlurmByJob(streams, bounds)
 	} else {
 		jobs = sample.MergeByHostAndJob(streams)
 	}
@@ -386,9 +421,13 @@ func aggregateSingleJobFromSonarData(
 		dataReadGB, dataWrittenGB        uint64
 		dataReadKB, dataWrittenKB        uint64
 		vmSizeKBPeak, residentSizeKBPeak uint64
+		rssAnonGBTrend, gpuGBTrend       LinearFit
 	)
 
 	for _, s := range job {
+		hours := float64(s.Timestamp-job[0].Timestamp) / 3600
+		rssAnonGBTrend.Add(hours, float64(s.RssAnonKB)*KBToGB)
+		gpuGBTrend.Add(hours, float64(s.GpuKB)*KBToGB)
 		gpus = gpuset.UnionGpuSets(gpus, s.Gpus)
 		gpuFail = sample.MergeGpuFail(gpuFail, s.GpuFail)
 		cpuPctAvg += float64(s.CpuUtilPct)
@@ -396,12 +435,12 @@ func aggregateSingleJobFromSonarData(
 		gpuPctAvg += float64(s.GpuPct)
 		gpuPctPeak = max(gpuPctPeak, float64(s.GpuPct))
 		cpuTime += s.CpuTimeSec
-		cpuGBAvg += float64(s.CpuKB) * kb2gb
-		cpuGBPeak = max(cpuGBPeak, float64(s.CpuKB)*kb2gb)
-		rssAnonGBAvg += float64(s.RssAnonKB) * kb2gb
-		rssAnonGBPeak = max(rssAnonGBPeak, float64(s.RssAnonKB)*kb2gb)
-		gpuGBAvg += float64(s.GpuKB) * kb2gb
-		gpuGBPeak = max(gpuGBPeak, float64(s.GpuKB)*kb2gb)
+		cpuGBAvg += float64(s.CpuKB) * KBToGB
+		cpuGBPeak = max(cpuGBPeak, float64(s.CpuKB)*KBToGB)
+		rssAnonGBAvg += float64(s.RssAnonKB) * KBToGB
+		rssAnonGBPeak = max(rssAnonGBPeak, float64(s.RssAnonKB)*KBToGB)
+		gpuGBAvg += float64(s.GpuKB) * KBToGB
+		gpuGBPeak = max(gpuGBPeak, float64(s.GpuKB)*KBToGB)
 		threadAvg += s.NumThreads
 		threadPeak = max(threadPeak, s.NumThreads)
 		inContainer = inContainer || s.InContainer
@@ -419,6 +458,9 @@ func aggregateSingleJobFromSonarData(
 		}
 	}
 	usesGpu := !gpus.IsEmpty()
+	rssAnonGBBase, rssAnonGBGrowth, _ := rssAnonGBTrend.Fit()
+	gpuGBBase, gpuGBGrowth, _ := gpuGBTrend.Fit()
+	var projectedOomTime, projectedGpuOomTime int64
 
 	if sys := cdp.LookupMergedHostByTime(host, job[0].Timestamp); sys != nil {
 		// Quantities can be zero in surprising ways, so always guard divisions
@@ -431,6 +473,7 @@ func aggregateSingleJobFromSonarData(
 			rCpuGBPeak = (cpuGBPeak * 100) / memory
 			rRssAnonGBAvg = (rssAnonGBAvg * 100) / memory
 			rRssAnonGBPeak = (rssAnonGBPeak * 100) / memory
+			projectedOomTime = projectTrend(job, rssAnonGBBase, rssAnonGBGrowth, memory)
 		}
 		if gpuCards := float64(sys.GpuCards); gpuCards > 0 {
 			rGpuPctAvg = gpuPctAvg / gpuCards
@@ -441,6 +484,11 @@ func aggregateSingleJobFromSonarData(
 			// job, so we need not look to sys.GpuMemPct here.
 			rGpuGBAvg = (gpuGBAvg * 100) / gpuMemory
 			rGpuGBPeak = (gpuGBPeak * 100) / gpuMemory
+			if usesGpu && !gpus.IsUnknown() && sys.GpuCards > 0 {
+				// Only the memory on the job's cards is available to it.
+				gpuMemory = float64(gpus.Size()) * (gpuMemory / float64(sys.GpuCards))
+			}
+			projectedGpuOomTime = projectTrend(job, gpuGBBase, gpuGBGrowth, gpuMemory)
 		}
 		if usesGpu && !gpus.IsUnknown() {
 			nCards := float64(gpus.Size())
@@ -475,14 +523,24 @@ func aggregateSingleJobFromSonarData(
 	if fb.needHosts {
 		hosts = NewHostnamesFromHosts(host)
 	}
+	readMBsAvg, readMBsPeak := ioRates(job, func(s sample.Sample) uint64 { return s.DataReadKB })
+	writeMBsAvg, writeMBsPeak := ioRates(job, func(s sample.Sample) uint64 { return s.DataWrittenKB })
 	n := float64(len(job))
 	a := jobAggregate{
-		Gpus:        gpus,
-		GpuFail:     int(gpuFail),
-		Cmd:         cmd,
-		Hosts:       hosts,
-		IsZombie:    isZombie,
-		InContainer: inContainer,
+		Gpus:                  gpus,
+		GpuFail:               int(gpuFail),
+		MemGrowthGBPerHour:    RoundTo(rssAnonGBGrowth, 2),
+		GpuMemGrowthGBPerHour: RoundTo(gpuGBGrowth, 2),
+		ProjectedOomTime:      projectedOomTime,
+		ProjectedGpuOomTime:   projectedGpuOomTime,
+		ReadMBsAvg:            RoundTo(readMBsAvg, 2),
+		ReadMBsPeak:           RoundTo(readMBsPeak, 2),
+		WriteMBsAvg:           RoundTo(writeMBsAvg, 2),
+		WriteMBsPeak:          RoundTo(writeMBsPeak, 2),
+		Cmd:                   cmd,
+		Hosts:                 hosts,
+		IsZombie:              isZombie,
+		InContainer:           inContainer,
 	}
 	a.computed[kCpuPctAvg] = cpuPctAvg / n
 	a.computed[kCpuPctPeak] = cpuPctPeak
@@ -516,6 +574,27 @@ func aggregateSingleJobFromSonarData(
 	a.computed[kThreadAvg] = float64(threadAvg) / n
 	a.computed[kThreadPeak] = float64(threadPeak)
 
+	weights := weighSamples(job)
+	for _, q := range []struct {
+		ix    int
+		value func(s sample.Sample) float64
+	}{
+		{kCpuPctTwAvg, func(s sample.Sample) float64 { return float64(s.CpuUtilPct) }},
+		{kCpuGBTwAvg, func(s sample.Sample) float64 { return float64(s.CpuKB) * KBToGB }},
+		{kRssAnonGBTwAvg, func(s sample.Sample) float64 { return float64(s.RssAnonKB) * KBToGB }},
+		{kGpuPctTwAvg, func(s sample.Sample) float64 { return float64(s.GpuPct) }},
+		{kGpuGBTwAvg, func(s sample.Sample) float64 { return float64(s.GpuKB) * KBToGB }},
+	} {
+		values := make([]float64, len(job))
+		for i, s := range job {
+			values[i] = q.value(s)
+		}
+		a.computed[q.ix] = WeightedMean(values, weights)
+		a.computed[q.ix+1] = WeightedPercentile(values, weights, 50)
+		a.computed[q.ix+2] = WeightedPercentile(values, weights, 90)
+		a.computed[q.ix+3] = WeightedPercentile(values, weights, 99)
+	}
+
 	a.u64[uReadGBTotal] = dataReadGB
 	a.u64[uReadKBTotal] = dataReadKB
 	a.u64[uWrittenGBTotal] = dataWrittenGB
@@ -527,6 +606,90 @@ func aggregateSingleJobFromSonarData(
 	return a
 }
 
+// Usage figures for a job whose samples have been merged across its nodes, for commands that join
+// the sample data with other per-job data.  These are computed by the same aggregation as the jobs
+// command uses and are not rounded.
+type JobUsage struct {
+	CpuPctAvg     float64 // Average CPU utilization, 1 core == 100%
+	CpuPctPeak    float64 // Peak CPU utilization ditto
+	RssAnonGBPeak float64 // Peak resident main memory utilization, GiB
+	GpuPctAvg     float64 // Average GPU utilization, 1 card == 100%
+	GpuPctPeak    float64 // Peak GPU utilization ditto
+	GpuGBPeak     float64 // Peak GPU memory utilization, GiB
+	DurationSec   int64   // Time from first to last sample
+}
+
+func AggregateJobUsage(cdp *config.ConfigDataProvider, job sample.MergedJob) JobUsage {
+	samples := job.Samples
+	a := aggregateSingleJobFromSonarData(cdp, job.Host, samples, flagBag{})
+	return JobUsage{
+		CpuPctAvg:     a.computed[kCpuPctAvg],
+		CpuPctPeak:    a.computed[kCpuPctPeak],
+		RssAnonGBPeak: a.computed[kRssAnonGBPeak],
+		GpuPctAvg:     a.computed[kGpuPctAvg],
+		GpuPctPeak:    a.computed[kGpuPctPeak],
+		GpuGBPeak:     a.computed[kGpuGBPeak],
+		DurationSec:   samples[len(samples)-1].Timestamp - samples[0].Timestamp,
+	}
+}
+
+// The samples are not evenly spaced in time, so for the time-weighted statistics each sample is
+// weighted by the length of the interval it covers, that is, the time since the previous sample.
+// The first sample is given the weight of the second, or 1 if it is alone.
+func weighSamples(job []sample.Sample) []float64 {
+	weights := make([]float64, len(job))
+	for i := 1; i < len(job); i++ {
+		weights[i] = float64(job[i].Timestamp - job[i-1].Timestamp)
+	}
+	if len(job) > 1 {
+		weights[0] = weights[1]
+	} else {
+		weights[0] = 1
+	}
+	return weights
+}
+
+// The I/O counters in the samples are cumulative (summed across the job's processes), so the rates
+// are computed from the differences between consecutive samples.  The counters go backwards when a
+// process exits, and the intervals where that happens are skipped, as the true traffic in them is
+// unknown.  The average is the traffic over the time covered by the remaining intervals; the peak
+// is the highest rate in any interval.
+func ioRates(job []sample.Sample, counterKB func(s sample.Sample) uint64) (avgMBs, peakMBs float64) {
+	var totalKB, totalSec float64
+	for i := 1; i < len(job); i++ {
+		dt := job[i].Timestamp - job[i-1].Timestamp
+		before, after := counterKB(job[i-1]), counterKB(job[i])
+		if dt <= 0 || after < before {
+			continue
+		}
+		kb := float64(after - before)
+		totalKB += kb
+		totalSec += float64(dt)
+		peakMBs = max(peakMBs, kb/1024/float64(dt))
+	}
+	if totalSec > 0 {
+		avgMBs = totalKB / 1024 / totalSec
+	}
+	return
+}
+
+// Given the fitted memory trend base + growth*hours for the job, where hours are relative to the
+// first sample, return the time at which the trend reaches the limit, or zero if the trend is not
+// growing.  If the trend is already past the limit then return the time of the last sample.
+func projectTrend(job []sample.Sample, base, growth, limit float64) int64 {
+	if growth <= 0 || limit <= 0 {
+		return 0
+	}
+	first := job[0].Timestamp
+	last := job[len(job)-1].Timestamp
+	hours := (limit - base) / growth
+	if hours > float64(math.MaxInt32) {
+		// Effectively never, and avoid overflow
+		return 0
+	}
+	return max(last, first+int64(hours*3600))
+}
+
 // The synthesis is imperfect, and would be so even if the Slurm documentation were better.
 func synthesizeSacctDataFromSonarData(
 	summaries []*jobSummary,
@@ -731,36 +894,6 @@ func (jc *JobsCommand) joinSacctData(
 	return discarded
 }
 
-// Merge mergeable streams as if by --merge-all; the remaining streams are merged as if by
-// --merge-none, and the two sets of merged jobs are combined into one set.
-
-func mergeAcrossSomeNodes(
-	streams sample.InputStreamSet,
-	bounds Timebounds,
-) ([]sample.MergedJob, Timebounds) {
-	mergeable := make(sample.InputStreamSet)
-	mBounds := make(Timebounds)
-	solo := make(sample.InputStreamSet)
-	sBounds := make(Timebounds)
-	for k, v := range streams {
-		bound := bounds[k.Host]
-		if (*v)[0].Epoch == 0 {
-			mBounds[k.Host] = bound
-			mergeable[k] = v
-		} else {
-			sBounds[k.Host] = bound
-			solo[k] = v
-		}
-	}
-	mergedJobs, mergedBounds := sample.MergeByJob(mergeable, mBounds)
-	otherJobs := sample.MergeByHostAndJob(solo)
-	mergedJobs = append(mergedJobs, otherJobs...)
-	for k, v := range sBounds {
-		mergedBounds[k] = v
-	}
-	return mergedJobs, mergedBounds
-}
-
 // Container for computations we would prefer not to do but will need to do if certain names are
 // used for printing or in queries.
 
@@ -71,6 +71,58 @@ FIELDS *jobSummary
                                    field:"computed[kThreadAvg]" alias:"thread-avg"
   ThreadPeak         F64Ceil       desc:"Peak number of active threads summed across all processes" \
                                    field:"computed[kThreadPeak]" alias:"thread-peak"
+  CpuTwAvgPct        F64Ceil       desc:"Time-weighted average CPU utilization in percent (100% = 1 core)" \
+                                   field:"computed[kCpuPctTwAvg]" alias:"cpu-twavg"
+  CpuP50Pct          F64Ceil       desc:"Time-weighted median CPU utilization in percent (100% = 1 core)" \
+                                   field:"computed[kCpuPctP50]" alias:"cpu-p50"
+  CpuP90Pct          F64Ceil       desc:"Time-weighted 90th percentile CPU utilization in percent (100% = 1 core)" \
+                                   field:"computed[kCpuPctP90]" alias:"cpu-p90"
+  CpuP99Pct          F64Ceil       desc:"Time-weighted 99th percentile CPU utilization in percent (100% = 1 core)" \
+                                   field:"computed[kCpuPctP99]" alias:"cpu-p99"
+  MemTwAvgGB         F64Ceil       desc:"Time-weighted average main virtual memory utilization in GB" \
+                                   field:"computed[kCpuGBTwAvg]" alias:"mem-twavg"
+  MemP50GB           F64Ceil       desc:"Time-weighted median main virtual memory utilization in GB" \
+                                   field:"computed[kCpuGBP50]" alias:"mem-p50"
+  MemP90GB           F64Ceil       desc:"Time-weighted 90th percentile main virtual memory utilization in GB" \
+                                   field:"computed[kCpuGBP90]" alias:"mem-p90"
+  MemP99GB           F64Ceil       desc:"Time-weighted 99th percentile main virtual memory utilization in GB" \
+                                   field:"computed[kCpuGBP99]" alias:"mem-p99"
+  ResidentMemTwAvgGB F64Ceil       desc:"Time-weighted average main resident memory utilization in GB" \
+                                   field:"computed[kRssAnonGBTwAvg]" alias:"res-twavg"
+  ResidentMemP50GB   F64Ceil       desc:"Time-weighted median main resident memory utilization in GB" \
+                                   field:"computed[kRssAnonGBP50]" alias:"res-p50"
+  ResidentMemP90GB   F64Ceil       desc:"Time-weighted 90th percentile main resident memory utilization in GB" \
+                                   field:"computed[kRssAnonGBP90]" alias:"res-p90"
+  ResidentMemP99GB   F64Ceil       desc:"Time-weighted 99th percentile main resident memory utilization in GB" \
+                                   field:"computed[kRssAnonGBP99]" alias:"res-p99"
+  GpuTwAvgPct        F64Ceil       desc:"Time-weighted average GPU utilization in percent (100% = 1 card)" \
+                                   field:"computed[kGpuPctTwAvg]" alias:"gpu-twavg"
+  GpuP50Pct          F64Ceil       desc:"Time-weighted median GPU utilization in percent (100% = 1 card)" \
+                                   field:"computed[kGpuPctP50]" alias:"gpu-p50"
+  GpuP90Pct          F64Ceil       desc:"Time-weighted 90th percentile GPU utilization in percent (100% = 1 card)" \
+                                   field:"computed[kGpuPctP90]" alias:"gpu-p90"
+  GpuP99Pct          F64Ceil       desc:"Time-weighted 99th percentile GPU utilization in percent (100% = 1 card)" \
+                                   field:"computed[kGpuPctP99]" alias:"gpu-p99"
+  GpuMemTwAvgGB      F64Ceil       desc:"Time-weighted average resident GPU memory utilization in GB" \
+                                   field:"computed[kGpuGBTwAvg]" alias:"gpumem-twavg"
+  GpuMemP50GB        F64Ceil       desc:"Time-weighted median resident GPU memory utilization in GB" \
+                                   field:"computed[kGpuGBP50]" alias:"gpumem-p50"
+  GpuMemP90GB        F64Ceil       desc:"Time-weighted 90th percentile resident GPU memory utilization in GB" \
+                                   field:"computed[kGpuGBP90]" alias:"gpumem-p90"
+  GpuMemP99GB        F64Ceil       desc:"Time-weighted 99th percentile resident GPU memory utilization in GB" \
+                                   field:"computed[kGpuGBP99]" alias:"gpumem-p99"
+  MemGrowthGBPerHour float64       desc:"Trend of main resident memory utilization in GB per hour (least-squares fit over the samples)" \
+                                   alias:"res-growth"
+  ProjectedOomTime   DateTimeValueOrBlank \
+                                   desc:"Time at which the main resident memory trend reaches the node's memory, blank if not growing" \
+                                   config:"true" alias:"oom-time"
+  GpuMemGrowthGBPerHour \
+                     float64       desc:"Trend of GPU memory utilization in GB per hour (least-squares fit over the samples)" \
+                                   alias:"gpumem-growth"
+  ProjectedGpuOomTime \
+                     DateTimeValueOrBlank \
+                                   desc:"Time at which the GPU memory trend reaches the memory of the job's cards, blank if not growing" \
+                                   config:"true" alias:"gpu-oom-time"
   Gpus               gpuset.GpuSet desc:"GPU device numbers used by the job, 'none' if none or 'unknown' in error states" alias:"gpus"
   GpuFail            int           desc:"Flag indicating GPU status (0=Ok, 1=Failing)" alias:"gpufail"
   Cmd                string        desc:"The commands invoking the processes of the job" alias:"cmd"
@@ -81,6 +133,10 @@ FIELDS *jobSummary
   GpuTime            DurationValue desc:"Total GPU time of the job across all cards" alias:"gputime"
   ReadGB             uint64        desc:"Total read traffic" field:"u64[uReadGBTotal]" alias:"read"
   WrittenGB          uint64        desc:"Total read traffic" field:"u64[uWrittenGBTotal]" alias:"written"
+  ReadMBsAvg         float64       desc:"Average rate of data read in MB/s" alias:"read-avg"
+  ReadMBsPeak        float64       desc:"Peak rate of data read in MB/s between two samples" alias:"read-peak"
+  WriteMBsAvg        float64       desc:"Average rate of data written in MB/s" alias:"write-avg"
+  WriteMBsPeak       float64       desc:"Peak rate of data written in MB/s between two samples" alias:"write-peak"
 
   # The expressions extracting bit flags happen to work for well-understood reasons, but this is
   # brittle and works in Go only because the operator precedence is right (in C it would not work).
@@ -186,7 +242,10 @@ ALIASES
   all         jobm,job,user,duration,duration/sec,start,start/sec,end,end/sec,cpu-avg,cpu-peak,rcpu-avg,\
               rcpu-peak,mem-avg,mem-peak,rmem-avg,rmem-peak,res-avg,res-peak,rres-avg,rres-peak,gpu-avg,\
               gpu-peak,rgpu-avg,rgpu-peak,sgpu-avg,sgpu-peak,gpumem-avg,gpumem-peak,rgpumem-avg,rgpumem-peak,\
-              sgpumem-avg,sgpumem-peak,thread-avg,thread-peak,gpus,gpufail,cmd,host,now,now/sec,classification,\
+              sgpumem-avg,sgpumem-peak,thread-avg,thread-peak,cpu-twavg,cpu-p50,cpu-p90,cpu-p99,mem-twavg,\
+              mem-p50,mem-p90,mem-p99,res-twavg,res-p50,res-p90,res-p99,gpu-twavg,gpu-p50,gpu-p90,gpu-p99,\
+              gpumem-twavg,gpumem-p50,gpumem-p90,gpumem-p99,res-growth,oom-time,gpumem-growth,gpu-oom-time,\
+              read-avg,read-peak,write-avg,write-peak,gpus,gpufail,cmd,host,now,now/sec,classification,\
               cputime/sec,cputime,gputime/sec,gputime
   std         jobm,user,duration,host
   cpu         cpu-avg,cpu-peak
@@ -202,12 +261,25 @@ ALIASES
   rgpumem     rgpumem-avg,rgpumem-peak
   sgpumem     sgpumem-avg,sgpumem-peak
   threads     thread-avg,thread-peak
+  twavg       cpu-twavg,mem-twavg,res-twavg,gpu-twavg,gpumem-twavg
+  cpupct      cpu-p50,cpu-p90,cpu-p99
+  mempct      mem-p50,mem-p90,mem-p99
+  respct      res-p50,res-p90,res-p99
+  gpupct      gpu-p50,gpu-p90,gpu-p99
+  gpumempct   gpumem-p50,gpumem-p90,gpumem-p99
+  growth      res-growth,oom-time,gpumem-growth,gpu-oom-time
+  io          read-avg,read-peak,write-avg,write-peak
   All         JobAndMark,Job,User,Duration,Duration/sec,Start,Start/sec,End,End/sec,CpuAvgPct,CpuPeakPct,\
               RelativeCpuAvgPct,RelativeCpuPeakPct,MemAvgGB,MemPeakGB,RelativeMemAvgPct,RelativeMemPeakPct,\
               ResidentMemAvgGB,ResidentMemPeakGB,RelativeResidentMemAvgPct,RelativeResidentMemPeakPct,\
               GpuAvgPct,GpuPeakPct,RelativeGpuAvgPct,RelativeGpuPeakPct,OccupiedRelativeGpuAvgPct,\
               OccupiedRelativeGpuPeakPct,GpuMemAvgGB,GpuMemPeakGB,RelativeGpuMemAvgPct,\
               RelativeGpuMemPeakPct,OccupiedRelativeGpuMemAvgPct,OccupiedRelativeGpuMemPeakPct,ThreadAvg,ThreadPeak,\
+              CpuTwAvgPct,CpuP50Pct,CpuP90Pct,CpuP99Pct,MemTwAvgGB,MemP50GB,MemP90GB,MemP99GB,\
+              ResidentMemTwAvgGB,ResidentMemP50GB,ResidentMemP90GB,ResidentMemP99GB,\
+              GpuTwAvgPct,GpuP50Pct,GpuP90Pct,GpuP99Pct,GpuMemTwAvgGB,GpuMemP50GB,GpuMemP90GB,GpuMemP99GB,\
+              MemGrowthGBPerHour,ProjectedOomTime,GpuMemGrowthGBPerHour,ProjectedGpuOomTime,\
+              ReadMBsAvg,ReadMBsPeak,WriteMBsAvg,WriteMBsPeak,\
               Gpus,GpuFail,Cmd,Hosts,Now,Now/sec,Classification,CpuTime/sec,CpuTime,GpuTime/sec,GpuTime,\
               SomeGpu,NoGpu,Running,Completed,Zombie,Primordial,BornLater
   Std         JobAndMark,User,Duration,Hosts
@@ -228,6 +300,15 @@ ALIASES
   OccupiedRelativeGpuMem \
               OccupiedRelativeGpuMemAvgPct,OccupiedRelativeGpuMemPeakPct
   Threads     ThreadAvg,ThreadPeak
+  TwAvg       CpuTwAvgPct,MemTwAvgGB,ResidentMemTwAvgGB,GpuTwAvgPct,GpuMemTwAvgGB
+  CpuPct      CpuP50Pct,CpuP90Pct,CpuP99Pct
+  MemPct      MemP50GB,MemP90GB,MemP99GB
+  ResidentMemPct \
+              ResidentMemP50GB,ResidentMemP90GB,ResidentMemP99GB
+  GpuPct      GpuP50Pct,GpuP90Pct,GpuP99Pct
+  GpuMemPct   GpuMemP50GB,GpuMemP90GB,GpuMemP99GB
+  Growth      MemGrowthGBPerHour,ProjectedOomTime,GpuMemGrowthGBPerHour,ProjectedGpuOomTime
+  Io          ReadMBsAvg,ReadMBsPeak,WriteMBsAvg,WriteMBsPeak
 
   default     std,cpu,mem,gpu,gpumem,cmd
   Default     Std,Cpu,Mem,Gpu,GpuMem,Cmd
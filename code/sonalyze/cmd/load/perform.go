@@ -69,20 +69,21 @@ func (lc *LoadCommand) Perform(
 
 	// Bucket the data, if applicable
 	if lc.bucketing != bNone {
+		loc := lc.TimeZone()
 		newStreams := make([]sample.MergedJob, 0)
 		for _, s := range mergedStreams {
 			var newS sample.MergedJob
 			switch lc.bucketing {
 			case bHalfHourly:
-				newS = sample.FoldSamplesHalfHourly(s.Samples, s.Host)
+				newS = sample.FoldSamplesHalfHourly(s.Samples, s.Host, loc)
 			case bHourly:
-				newS = sample.FoldSamplesHourly(s.Samples, s.Host)
+				newS = sample.FoldSamplesHourly(s.Samples, s.Host, loc)
 			case bHalfDaily:
-				newS = sample.FoldSamplesHalfDaily(s.Samples, s.Host)
+				newS = sample.FoldSamplesHalfDaily(s.Samples, s.Host, loc)
 			case bDaily:
-				newS = sample.FoldSamplesDaily(s.Samples, s.Host)
+				newS = sample.FoldSamplesDaily(s.Samples, s.Host, loc)
 			case bWeekly:
-				newS = sample.FoldSamplesWeekly(s.Samples, s.Host)
+				newS = sample.FoldSamplesWeekly(s.Samples, s.Host, loc)
 			default:
 				panic("Unexpected case")
 			}
@@ -159,8 +160,8 @@ func (lc *LoadCommand) Perform(
 }
 
 func (lc *LoadCommand) insertMissingRecords(ss sample.SampleStream, fromIncl, toIncl int64) sample.SampleStream {
-	var trunc func(int64) int64
-	var step func(int64) int64
+	var trunc func(int64, *time.Location) int64
+	var step func(int64, *time.Location) int64
 	switch lc.bucketing {
 	case bHalfHourly:
 		trunc = TruncateToHalfHour
@@ -180,24 +181,25 @@ func (lc *LoadCommand) insertMissingRecords(ss sample.SampleStream, fromIncl, to
 	default:
 		panic("Unexpected case")
 	}
+	loc := lc.TimeZone()
 	host := ss[0].Hostname
-	t := trunc(fromIncl)
+	t := trunc(fromIncl, loc)
 	result := make(sample.SampleStream, 0)
 
 	for _, s := range ss {
 		for t < s.Timestamp {
 			newS := sample.Sample{Sample: &repr.Sample{Timestamp: t, Hostname: host}}
 			result = append(result, newS)
-			t = step(t)
+			t = step(t, loc)
 		}
 		result = append(result, s)
-		t = step(t)
+		t = step(t, loc)
 	}
-	ending := trunc(toIncl)
+	ending := trunc(toIncl, loc)
 	for t <= ending {
 		newS := sample.Sample{Sample: &repr.Sample{Timestamp: t, Hostname: host}}
 		result = append(result, newS)
-		t = step(t)
+		t = step(t, loc)
 	}
 	return result
 }
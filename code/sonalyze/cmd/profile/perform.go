@@ -148,7 +148,11 @@ func (pc *ProfileCommand) Perform(
 			if indices[i] < len(p) {
 				r := p[indices[i]]
 				if roundToMinute(r.Timestamp) == currentTime {
-					m.set(currentTime, pif.indexFor(r), newProfDatum(r, pc.Max))
+					var prev *repr.Sample
+					if indices[i] > 0 {
+						prev = p[indices[i]-1].Sample
+					}
+					m.set(currentTime, pif.indexFor(r), newProfDatum(r, prev, pc.Max))
 					indices[i]++
 					if indices[i] == len(p) {
 						nonempty--
@@ -175,6 +179,7 @@ func (pc *ProfileCommand) Perform(
 				var count int
 				var cpuUtilPct, gpuPct float32
 				var cpuKB, gpuKB, rssAnonKB uint64
+				var readMBs, writeMBs float64
 				var base *repr.Sample
 				for _, rn := range myrowNames {
 					if probe := m.get(rn, cn); probe != nil {
@@ -184,6 +189,8 @@ func (pc *ProfileCommand) Perform(
 						cpuKB += probe.cpuKB
 						gpuKB += probe.gpuKB
 						rssAnonKB += probe.rssAnonKB
+						readMBs += probe.readMBs
+						writeMBs += probe.writeMBs
 						base = probe.s
 					}
 				}
@@ -194,6 +201,8 @@ func (pc *ProfileCommand) Perform(
 						cpuKB:      cpuKB / uint64(count),
 						gpuKB:      gpuKB / uint64(count),
 						rssAnonKB:  rssAnonKB / uint64(count),
+						readMBs:    readMBs / float64(count),
+						writeMBs:   writeMBs / float64(count),
 						s:          base,
 					}
 					m2.set(newTime, cn, avg)
@@ -317,16 +326,21 @@ type profIndex struct {
 	col processIndex
 }
 
+// The I/O rates are for the interval since the previous sample of the process (or rolled-up
+// processes), they are zero for the first sample and if the counters went backwards.
+
 type profDatum struct {
 	cpuUtilPct float32
 	gpuPct     float32
 	cpuKB      uint64
 	gpuKB      uint64
 	rssAnonKB  uint64
+	readMBs    float64
+	writeMBs   float64
 	s          *repr.Sample
 }
 
-func newProfDatum(r sample.Sample, max float64) *profDatum {
+func newProfDatum(r sample.Sample, prev *repr.Sample, max float64) *profDatum {
 	var v profDatum
 	v.cpuUtilPct = r.CpuUtilPct
 	v.gpuPct = r.GpuPct
@@ -334,6 +348,15 @@ func newProfDatum(r sample.Sample, max float64) *profDatum {
 	v.gpuKB = r.GpuKB
 	v.rssAnonKB = r.RssAnonKB
 	v.s = r.Sample
+	if prev != nil && r.Timestamp > prev.Timestamp {
+		secs := float64(r.Timestamp - prev.Timestamp)
+		if r.DataReadKB >= prev.DataReadKB {
+			v.readMBs = float64(r.DataReadKB-prev.DataReadKB) / 1024 / secs
+		}
+		if r.DataWrittenKB >= prev.DataWrittenKB {
+			v.writeMBs = float64(r.DataWrittenKB-prev.DataWrittenKB) / 1024 / secs
+		}
+	}
 
 	if max != 0 {
 		// Clamping is a hack but it works.
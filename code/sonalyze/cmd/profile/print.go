@@ -10,6 +10,8 @@
 //   res: the res_gb field
 //   gpu: the gpu_pct field
 //   gpumem: the gpumem_gb field
+//   read: the data read in MB/s since the previous sample, derived from data_read_kb
+//   write: the data written in MB/s since the previous sample, derived from data_written_kb
 //   nproc: the rolledup field + 1, this is not printed if every process has rolledup=0
 //   command: the command field
 //
@@ -17,7 +19,7 @@
 // Output formats:
 //
 // For HTML, CSV and AWK output the "fields" list must have a single string from the set
-// "cpu","mem","res","gpu","gpumem".  This is the per-process value we print, along with a
+// "cpu","mem","res","gpu","gpumem","read","write".  This is the per-process value we print, along with a
 // timestamp.  But the layout differs among these three formats:
 //
 //  - For csv, each row has a timestamp and then one data field for each process at that time, that
@@ -55,7 +57,9 @@ import (
 	"math"
 	"slices"
 	"strings"
+	"time"
 
+	. "sonalyze/common"
 	"sonalyze/data/sample"
 	. "sonalyze/table"
 )
@@ -133,7 +137,7 @@ func (pc *ProfileCommand) printProfile(
 			data,
 		)
 	} else if pc.PrintOpts.Json {
-		formatJson(out, m, processes, pif, pc.testNoMemory)
+		formatJson(out, m, processes, pif, pc.testNoMemory, pc.TimeZone())
 	} else {
 		panic("Unknown print format")
 	}
@@ -160,7 +164,7 @@ func (pc *ProfileCommand) collectHtml(
 
 	labels = make([]string, len(rowNames))
 	for i, rn := range rowNames {
-		labels[i] = "\"" + formatTime(rn) + "\""
+		labels[i] = "\"" + formatTime(rn, pc.TimeZone()) + "\""
 	}
 
 	// Iterate by processes rather than m.cols() since process order is what we care about.
@@ -235,7 +239,7 @@ func (pc *ProfileCommand) collectCsvOrAwk(
 	}
 
 	for y, rn := range rowNames {
-		matrix[y][0] = formatTime(rn)
+		matrix[y][0] = formatTime(rn, pc.TimeZone())
 		for x, p := range processes {
 			pid := pif.indexFor(p[0])
 			entry := m.get(rn, pid)
@@ -271,6 +275,8 @@ FIELDS *fixedLine
  ResidentMemGB int                  alias:"res,rss" desc:"Main resident memory usage in GiB"
  Gpu           int                  alias:"gpu"     desc:"GPU utilization in percent, 100% = 1 card (except for HTML)"
  GpuMemGB      int                  alias:"gpumem"  desc:"GPU resident memory usage in GiB (across all cards)"
+ ReadMBs       float64              alias:"read"    desc:"Data read in MB/s since the previous sample"
+ WriteMBs      float64              alias:"write"   desc:"Data written in MB/s since the previous sample"
  Command       Ustr                 alias:"cmd"     desc:"Name of executable starting the process"
  NumProcs      IntOrEmpty           alias:"nproc"   desc:"Number of rolled-up processes, blank for zero"
 
@@ -338,6 +344,8 @@ func (pc *ProfileCommand) collectFixed(
 					ResidentMemGB: int(math.Round(float64(entry.rssAnonKB) / (1024 * 1024))),
 					Gpu:           int(math.Round(float64(entry.gpuPct))),
 					GpuMemGB:      int(math.Round(float64(entry.gpuKB) / (1024 * 1024))),
+					ReadMBs:       RoundTo(entry.readMBs, 2),
+					WriteMBs:      RoundTo(entry.writeMBs, 2),
 					Command:       entry.s.Cmd,
 					NumProcs:      IntOrEmpty(numprocs),
 				})
@@ -384,6 +392,12 @@ func lookupSingleFormatter(
 		case "gpumem", "GpuMemGB":
 			formatter = formatGpuMem
 			n++
+		case "read", "ReadMBs":
+			formatter = formatRead
+			n++
+		case "write", "WriteMBs":
+			formatter = formatWrite
+			n++
 		default:
 			err = fmt.Errorf("Not a printable field for this output format: %s", f.Name)
 			return
@@ -427,6 +441,14 @@ func formatGpuMem(s *profDatum) string {
 	return fmt.Sprint(math.Round(float64(s.gpuKB) / (1024 * 1024)))
 }
 
+func formatRead(s *profDatum) string {
+	return fmt.Sprint(RoundTo(s.readMBs, 2))
+}
+
+func formatWrite(s *profDatum) string {
+	return fmt.Sprint(RoundTo(s.writeMBs, 2))
+}
+
 var htmlCaptions = map[string]string{
 	"cpu":           "Y axis: Number of CPU cores (1.0 = 1 core at 100%)",
 	"CpuUtilPct":    "Y axis: Number of CPU cores (1.0 = 1 core at 100%)",
@@ -439,6 +461,10 @@ var htmlCaptions = map[string]string{
 	"Gpu":           "Y axis: Number of GPU cards in use (1.0 = 1 card at 100%)",
 	"gpumem":        "Y axis: Real GPU memory in GB",
 	"GpuMemGB":      "Y axis: Real GPU memory in GB",
+	"read":          "Y axis: Data read in MB/s",
+	"ReadMBs":       "Y axis: Data read in MB/s",
+	"write":         "Y axis: Data written in MB/s",
+	"WriteMBs":      "Y axis: Data written in MB/s",
 }
 
 func formatHtml(
@@ -500,17 +526,22 @@ func formatJson(
 	processes []sample.SampleStream,
 	pif *processIndexFactory,
 	noMemory bool,
+	loc *time.Location,
 ) {
+	// The I/O rates were added later and are omitted when zero, so that consumers of the older
+	// output see the same shape for jobs without I/O.
 	type jsonPoint struct {
-		Command    string `json:"command"`
-		Host       string `json:"host,omitempty"`
-		Pid        uint32 `json:"pid"`
-		CpuUtilPct int    `json:"cpu"`
-		CpuGB      uint64 `json:"mem"`
-		RssAnonGB  uint64 `json:"res"`
-		GpuPct     int    `json:"gpu"`
-		GpuMemGB   uint64 `json:"gpumem"`
-		Nproc      int    `json:"nproc"`
+		Command    string  `json:"command"`
+		Host       string  `json:"host,omitempty"`
+		Pid        uint32  `json:"pid"`
+		CpuUtilPct int     `json:"cpu"`
+		CpuGB      uint64  `json:"mem"`
+		RssAnonGB  uint64  `json:"res"`
+		GpuPct     int     `json:"gpu"`
+		GpuMemGB   uint64  `json:"gpumem"`
+		ReadMBs    float64 `json:"read,omitempty"`
+		WriteMBs   float64 `json:"write,omitempty"`
+		Nproc      int     `json:"nproc"`
 	}
 	type jsonJob struct {
 		Time   string      `json:"time"`
@@ -549,11 +580,13 @@ func formatJson(
 				RssAnonGB:  uint64(math.Round(float64(entry.rssAnonKB) / (1024 * 1024))),
 				GpuPct:     int(math.Round(float64(entry.gpuPct))),
 				GpuMemGB:   gpuGB,
+				ReadMBs:    RoundTo(entry.readMBs, 2),
+				WriteMBs:   RoundTo(entry.writeMBs, 2),
 				Nproc:      int(entry.s.Rolledup) + 1,
 			})
 		}
 		objects = append(objects, jsonJob{
-			Time:   formatTime(rn),
+			Time:   formatTime(rn, loc),
 			Job:    e.s.Job,
 			Points: points,
 		})
@@ -566,6 +599,6 @@ func formatJson(
 	}
 }
 
-func formatTime(t int64) string {
-	return FormatYyyyMmDdHhMmUtc(t)
+func formatTime(t int64, loc *time.Location) string {
+	return FormatYyyyMmDdHhMm(t, loc)
 }
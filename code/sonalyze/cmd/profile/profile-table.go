@@ -84,6 +84,24 @@ var profileFormatters = map[string]Formatter[*fixedLine]{
 		},
 		Help: "(int) GPU resident memory usage in GiB (across all cards)",
 	},
+	"ReadMBs": {
+		Fmt: func(d *fixedLine, ctx PrintMods) string {
+			return FormatFloat64((d.ReadMBs), ctx)
+		},
+		Xtract: func(d *fixedLine) any {
+			return d.ReadMBs
+		},
+		Help: "(float64) Data read in MB/s since the previous sample",
+	},
+	"WriteMBs": {
+		Fmt: func(d *fixedLine, ctx PrintMods) string {
+			return FormatFloat64((d.WriteMBs), ctx)
+		},
+		Xtract: func(d *fixedLine) any {
+			return d.WriteMBs
+		},
+		Help: "(float64) Data written in MB/s since the previous sample",
+	},
 	"Command": {
 		Fmt: func(d *fixedLine, ctx PrintMods) string {
 			return FormatUstr((d.Command), ctx)
@@ -113,6 +131,8 @@ func init() {
 	DefAlias(profileFormatters, "ResidentMemGB", "rss")
 	DefAlias(profileFormatters, "Gpu", "gpu")
 	DefAlias(profileFormatters, "GpuMemGB", "gpumem")
+	DefAlias(profileFormatters, "ReadMBs", "read")
+	DefAlias(profileFormatters, "WriteMBs", "write")
 	DefAlias(profileFormatters, "Command", "cmd")
 	DefAlias(profileFormatters, "NumProcs", "nproc")
 }
@@ -161,6 +181,18 @@ var profilePredicates = map[string]Predicate[*fixedLine]{
 			return cmp.Compare((d.GpuMemGB), v.(int))
 		},
 	},
+	"ReadMBs": Predicate[*fixedLine]{
+		Convert: CvtString2Float64,
+		Compare: func(d *fixedLine, v any) int {
+			return cmp.Compare((d.ReadMBs), v.(float64))
+		},
+	},
+	"WriteMBs": Predicate[*fixedLine]{
+		Convert: CvtString2Float64,
+		Compare: func(d *fixedLine, v any) int {
+			return cmp.Compare((d.WriteMBs), v.(float64))
+		},
+	},
 	"Command": Predicate[*fixedLine]{
 		Convert: CvtString2Ustr,
 		Compare: func(d *fixedLine, v any) int {
@@ -183,6 +215,8 @@ type fixedLine struct {
 	ResidentMemGB int
 	Gpu           int
 	GpuMemGB      int
+	ReadMBs       float64
+	WriteMBs      float64
 	Command       Ustr
 	NumProcs      IntOrEmpty
 }
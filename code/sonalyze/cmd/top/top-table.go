@@ -0,0 +1,168 @@
+// DO NOT EDIT.  Generated from top.go by generate-table
+
+package top
+
+import (
+	"cmp"
+	"fmt"
+	"go-utils/gpuset"
+	"io"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+var (
+	_ = cmp.Compare(0, 0)
+	_ fmt.Formatter
+	_ = io.SeekStart
+	_ = UstrEmpty
+	_ gpuset.GpuSet
+)
+
+// MT: Constant after initialization; immutable
+var topFormatters = map[string]Formatter[*coreRecord]{
+	"Timestamp": {
+		Fmt: func(d *coreRecord, ctx PrintMods) string {
+			return FormatDateTimeValue((d.Timestamp), ctx)
+		},
+		Xtract: func(d *coreRecord) any {
+			return d.Timestamp
+		},
+		Help: "(DateTimeValue) Time of the end of the interval",
+	},
+	"Hostname": {
+		Fmt: func(d *coreRecord, ctx PrintMods) string {
+			return FormatUstr((d.Hostname), ctx)
+		},
+		Xtract: func(d *coreRecord) any {
+			return d.Hostname
+		},
+		Help: "(string) Name of the node",
+	},
+	"Core": {
+		Fmt: func(d *coreRecord, ctx PrintMods) string {
+			return FormatInt((d.Core), ctx)
+		},
+		Xtract: func(d *coreRecord) any {
+			return d.Core
+		},
+		Help: "(int) Index of the core on the node",
+	},
+	"UtilPct": {
+		Fmt: func(d *coreRecord, ctx PrintMods) string {
+			return FormatF64Ceil((d.UtilPct), ctx)
+		},
+		Xtract: func(d *coreRecord) any {
+			return d.UtilPct
+		},
+		Help: "(int) Utilization of the core during the interval in percent",
+	},
+	"Mark": {
+		Fmt: func(d *coreRecord, ctx PrintMods) string {
+			return FormatString((d.Mark), ctx)
+		},
+		Xtract: func(d *coreRecord) any {
+			return d.Mark
+		},
+		Help: "(string) Busyness mark for the interval: '.', 'o' or 'O' (see summary)",
+	},
+}
+
+func init() {
+	DefAlias(topFormatters, "Timestamp", "time")
+	DefAlias(topFormatters, "Hostname", "host")
+	DefAlias(topFormatters, "Core", "core")
+	DefAlias(topFormatters, "UtilPct", "util")
+	DefAlias(topFormatters, "Mark", "mark")
+}
+
+// MT: Constant after initialization; immutable
+var topPredicates = map[string]Predicate[*coreRecord]{
+	"Timestamp": Predicate[*coreRecord]{
+		Convert: CvtString2DateTimeValue,
+		Compare: func(d *coreRecord, v any) int {
+			return cmp.Compare((d.Timestamp), v.(DateTimeValue))
+		},
+	},
+	"Hostname": Predicate[*coreRecord]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *coreRecord, v any) int {
+			return cmp.Compare((d.Hostname), v.(Ustr))
+		},
+	},
+	"Core": Predicate[*coreRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *coreRecord, v any) int {
+			return cmp.Compare((d.Core), v.(int))
+		},
+	},
+	"UtilPct": Predicate[*coreRecord]{
+		Convert: CvtString2Float64,
+		Compare: func(d *coreRecord, v any) int {
+			return cmp.Compare((d.UtilPct), v.(F64Ceil))
+		},
+	},
+	"Mark": Predicate[*coreRecord]{
+		Compare: func(d *coreRecord, v any) int {
+			return cmp.Compare((d.Mark), v.(string))
+		},
+	},
+}
+
+type coreRecord struct {
+	Timestamp DateTimeValue
+	Hostname  Ustr
+	Core      int
+	UtilPct   F64Ceil
+	Mark      string
+}
+
+func (c *TopCommand) Summary(out io.Writer) {
+	fmt.Fprint(out, `EXPERIMENTAL: Print per-cpu load across time for one or more nodes.
+
+The default output format is text, like this:
+
+HOST: NodeName
+  Time ...oO..O...o...o...
+  Time ...oO..O...O...o...
+  Time ...oO..O.......O...
+  ...
+
+where the characters represent CPUs in order and there is a mark indicating
+how busy the cpu was during the previous time interval.  The mark is "."
+for "not busy" (< 10% utilization), "o" for somewhat busy (< 25%), and "O"
+for busy.  Cores removed by -q are shown as blanks.
+
+With an explicit -fmt there is one record per core per time interval, with the
+utilization of the core in percent.
+
+With -job, only the nodes of the job and the time intervals overlapping the
+job's lifetime are shown.  These are taken from the Slurm data for the job if
+available, otherwise from the sample data.  The Slurm data also give the number
+of CPUs for the job, and the job's share of those on each node, assuming an
+even spread, is the number of cores shown per node.  Slurm does not record
+which cores were allocated, so the cores shown are the ones that were busiest
+during the job's lifetime.  Without Slurm data, all the cores of the job's
+nodes are shown.
+
+The output is sorted by node name, then by time, then by core.
+`)
+}
+
+const topHelp = `
+top
+  Print per-core utilization across time for one or more nodes.  The default
+  format is 'text'.
+`
+
+func (c *TopCommand) MaybeFormatHelp() *FormatHelp {
+	return StandardFormatHelp(c.Fmt, topHelp, topFormatters, topAliases, topDefaultFields)
+}
+
+// MT: Constant after initialization; immutable
+var topAliases = map[string][]string{
+	"default": []string{"time", "host", "core", "util", "mark"},
+	"Default": []string{"Timestamp", "Hostname", "Core", "UtilPct", "Mark"},
+}
+
+const topDefaultFields = "default"
@@ -1,77 +1,188 @@
 // Produce a per-node timeline of core busyness.
 //
-// See summary.txt for info.
-//
 // TODO:
 //
-// CSV, awk, json not implemented but coming.  In this case it will be a record with
-// nodename,time,string where the string is as above.
-//
 // In addition to the "text" format there will probably be a "log" format (values 0, 1, 2, 3
-// corresponding to 0-12.5%, 12.5%-25%, 25%-50%, 50%-100%), a true-percent format, an absolute-value
-// format, and a diff-since-previous format.
-//
-// Long-term I see the possibility of asking slurm about node allocation for a job and being able to
-// filter these data by job#.
+// corresponding to 0-12.5%, 12.5%-25%, 25%-50%, 50%-100%) and a diff-since-previous format.
 
 package top
 
 import (
 	"bufio"
+	"bytes"
 	"cmp"
-	_ "embed"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"slices"
 
-	umaps "go-utils/maps"
+	"go-utils/hostglob"
 
 	. "sonalyze/cmd"
 	. "sonalyze/common"
 	"sonalyze/data/common"
 	"sonalyze/data/cpusample"
+	"sonalyze/data/sample"
+	"sonalyze/data/slurmjob"
+	"sonalyze/db/repr"
 	"sonalyze/db/types"
 	. "sonalyze/table"
 )
 
+//go:generate ../../../generate-table/generate-table -o top-table.go top.go
+
+/*TABLE top
+
+package top
+
+%%
+
+FIELDS *coreRecord
+
+ Timestamp DateTimeValue alias:"time" desc:"Time of the end of the interval"
+ Hostname  Ustr          alias:"host" desc:"Name of the node"
+ Core      int           alias:"core" desc:"Index of the core on the node"
+ UtilPct   F64Ceil       alias:"util" desc:"Utilization of the core during the interval in percent"
+ Mark      string        alias:"mark" desc:"Busyness mark for the interval: '.', 'o' or 'O' (see summary)"
+
+GENERATE coreRecord
+
+SUMMARY TopCommand
+
+EXPERIMENTAL: Print per-cpu load across time for one or more nodes.
+
+The default output format is text, like this:
+
+HOST: NodeName
+  Time ...oO..O...o...o...
+  Time ...oO..O...O...o...
+  Time ...oO..O.......O...
+  ...
+
+where the characters represent CPUs in order and there is a mark indicating
+how busy the cpu was during the previous time interval.  The mark is "."
+for "not busy" (< 10% utilization), "o" for somewhat busy (< 25%), and "O"
+for busy.  Cores removed by -q are shown as blanks.
+
+With an explicit -fmt there is one record per core per time interval, with the
+utilization of the core in percent.
+
+With -job, only the nodes of the job and the time intervals overlapping the
+job's lifetime are shown.  These are taken from the Slurm data for the job if
+available, otherwise from the sample data.  The Slurm data also give the number
+of CPUs for the job, and the job's share of those on each node, assuming an
+even spread, is the number of cores shown per node.  Slurm does not record
+which cores were allocated, so the cores shown are the ones that were busiest
+during the job's lifetime.  Without Slurm data, all the cores of the job's
+nodes are shown.
+
+The output is sorted by node name, then by time, then by core.
+
+HELP TopCommand
+
+  Print per-core utilization across time for one or more nodes.  The default
+  format is 'text'.
+
+ALIASES
+
+  default time,host,core,util,mark
+  Default Timestamp,Hostname,Core,UtilPct,Mark
+
+DEFAULTS default
+
+ELBAT*/
+
 type TopCommand struct /* implements AnalysisCommand */ {
 	HostAnalysisArgs
+	FormatArgs
+
+	Job uint
+
+	// Synthesized and other
+	textOutput bool
 }
 
 var _ = AnalysisCommand((*TopCommand)(nil))
 var _ = SimpleCommand((*TopCommand)(nil))
 
-//go:embed summary.txt
-var summary string
-
-func (tc *TopCommand) Summary(out io.Writer) {
-	fmt.Fprint(out, summary)
-}
-
 func (tc *TopCommand) Add(fs *CLI) {
 	tc.HostAnalysisArgs.Add(fs)
+	tc.FormatArgs.Add(fs)
+
+	fs.Group("record-filter")
+	fs.UintVar(&tc.Job, "job", 0, "Show only the nodes and the lifetime of this job `id`")
 }
 
 func (tc *TopCommand) Validate() error {
-	return tc.HostAnalysisArgs.Validate()
-}
+	// FormatArgs are handled specially below
+	e1 := tc.HostAnalysisArgs.Validate()
 
-func (tc *TopCommand) ReifyForRemote(x *ArgReifier) error {
-	return tc.HostAnalysisArgs.ReifyForRemote(x)
+	// As for `profile`, the text output is handled on the side.  It is the default if there is no
+	// -fmt at all.
+	var others map[string]bool
+	var e2 error
+	tc.PrintFields, others, e2 = ParseFormatSpec(topDefaultFields, tc.Fmt, topFormatters, topAliases)
+	if e2 == nil && len(tc.PrintFields) == 0 {
+		e2 = errors.New("No valid output fields were selected in format string")
+	}
+	tc.textOutput = tc.Fmt == "" || others["text"]
+
+	def := DefaultFixed
+	if tc.textOutput {
+		def = DefaultNone
+	}
+	tc.PrintOpts = StandardFormatOptions(others, def)
+
+	var e3 error
+	if tc.textOutput && !tc.PrintOpts.IsDefaultFormat() {
+		e3 = errors.New("Multiple output formats requested")
+	}
+
+	return errors.Join(e1, e2, e3)
 }
 
-func (tc *TopCommand) MaybeFormatHelp() *FormatHelp {
-	// FIXME, but currently no format options at all
-	return nil
+func (tc *TopCommand) ReifyForRemote(x *ArgReifier) error {
+	e1 := errors.Join(
+		tc.HostAnalysisArgs.ReifyForRemote(x),
+		tc.FormatArgs.ReifyForRemote(x),
+	)
+	x.Uint("job", tc.Job)
+	return e1
 }
 
 func (tc *TopCommand) Perform(meta types.Context, stdin io.Reader, stdout, stderr io.Writer) error {
 	cdp, err := cpusample.OpenCpuSampleDataProvider(meta)
+	if err != nil {
+		return err
+	}
 
 	host, err := common.ResolveHostQuery(meta, tc.Host, tc.FromDate, tc.ToDate)
 	if err != nil {
 		return err
 	}
+
+	// The job's nodes narrow the host set further; the job's lifetime filters the intervals below.
+	var jobHosts *hostglob.HostGlobber
+	var jobStart, jobEnd int64
+	var jobCpus int
+	if tc.Job != 0 {
+		var nodes []string
+		nodes, jobStart, jobEnd, jobCpus, err = tc.findJob(meta, host)
+		if err != nil {
+			return err
+		}
+		jobHosts, err = hostglob.NewGlobber(true, nodes)
+		if err != nil {
+			return err
+		}
+		if Verbose {
+			Log.Infof(
+				"Job %d: nodes %v, from %d to %d, %d cpus per node",
+				tc.Job, nodes, jobStart, jobEnd, jobCpus)
+		}
+	}
+
 	// TODO: Use standard query interface with standard QueryFilter here.
 	streams, _, read, dropped, err :=
 		cdp.Query(
@@ -87,40 +198,254 @@ func (tc *TopCommand) Perform(meta types.Context, stdin io.Reader, stdout, stder
 		UstrStats(stderr, false)
 	}
 
-	hostStreams := umaps.Values(streams)
-	slices.SortFunc(hostStreams, func(a, b *cpusample.CpuSamplesByHost) int {
+	hosts := make([]*cpusample.CpuSamplesByHost, 0, len(streams))
+	for _, v := range streams {
+		if jobHosts == nil || jobHosts.Match(v.Hostname.String()) {
+			hosts = append(hosts, v)
+		}
+	}
+	slices.SortFunc(hosts, func(a, b *cpusample.CpuSamplesByHost) int {
 		return cmp.Compare(a.Hostname.String(), b.Hostname.String())
 	})
+	inJob := func(prev, cur int64) bool {
+		return jobHosts == nil || cur > jobStart && (jobEnd == 0 || prev < jobEnd)
+	}
 
-	// Ad-hoc fixed-format output for now
-
-	buf := bufio.NewWriter(stdout)
-	defer buf.Flush()
-	for _, v := range hostStreams {
-		if len(v.Data) > 0 {
-			buf.WriteString("HOST: ")
-			buf.WriteString(v.Hostname.String())
-			buf.WriteByte('\n')
-
-			for i := 1; i < len(v.Data); i++ {
-				tdiff := float64(v.Data[i].Time - v.Data[i-1].Time)
-				buf.WriteString("  ")
-				buf.WriteString(FormatYyyyMmDdHhMmUtc(v.Data[i].Time))
-				buf.WriteByte(' ')
-				for j := range v.Data[i].Decoded {
-					n := float64(v.Data[i].Decoded[j]-v.Data[i-1].Decoded[j]) / tdiff
-					if n >= 0.25 {
-						buf.WriteByte('O')
-					} else if n >= 0.10 {
-						buf.WriteByte('o')
-					} else {
-						buf.WriteByte('.')
+	if tc.textOutput {
+		// The text output can be large, so the marks are computed directly and the records are
+		// only materialized one at a time for the query.
+		var queryNeg func(*coreRecord) bool
+		if tc.ParsedQuery != nil {
+			queryNeg, err = CompileQueryNeg(topFormatters, topPredicates, tc.ParsedQuery)
+			if err != nil {
+				return fmt.Errorf("Could not compile query: %v", err)
+			}
+		}
+		buf := bufio.NewWriter(stdout)
+		defer buf.Flush()
+		for _, v := range hosts {
+			cores := tc.jobCores(v, jobCpus, inJob)
+			header := false
+			var line []byte
+			forEachInterval(v, inJob, func(cur int64, util []float64) {
+				line = line[:0]
+				for j, n := range util {
+					m := byte(' ')
+					if cores == nil || cores[j] {
+						m = mark(n)[0]
+						if queryNeg != nil {
+							r := newCoreRecord(cur, v.Hostname, j, n)
+							if queryNeg(&r) {
+								m = ' '
+							}
+						}
 					}
+					line = append(line, m)
+				}
+				line = bytes.TrimRight(line, " ")
+				if len(line) == 0 {
+					return
 				}
+				if !header {
+					buf.WriteString("HOST: ")
+					buf.WriteString(v.Hostname.String())
+					buf.WriteByte('\n')
+					header = true
+				}
+				buf.WriteString("  ")
+				buf.WriteString(FormatYyyyMmDdHhMm(cur, tc.TimeZone()))
+				buf.WriteByte(' ')
+				buf.Write(line)
 				buf.WriteByte('\n')
-			}
+			})
 		}
+		return nil
+	}
+
+	records := make([]*coreRecord, 0)
+	for _, v := range hosts {
+		cores := tc.jobCores(v, jobCpus, inJob)
+		forEachInterval(v, inJob, func(cur int64, util []float64) {
+			for j, n := range util {
+				if cores == nil || cores[j] {
+					r := newCoreRecord(cur, v.Hostname, j, n)
+					records = append(records, &r)
+				}
+			}
+		})
 	}
 
+	records, err = ApplyQuery(tc.ParsedQuery, topFormatters, topPredicates, records)
+	if err != nil {
+		return err
+	}
+
+	// The records were generated in order of host, time, and core.
+	FormatData(
+		stdout,
+		tc.PrintFields,
+		topFormatters,
+		tc.PrintOpts,
+		records,
+	)
+
 	return nil
 }
+
+func newCoreRecord(t int64, hostname Ustr, core int, util float64) coreRecord {
+	return coreRecord{
+		Timestamp: DateTimeValue(t),
+		Hostname:  hostname,
+		Core:      core,
+		UtilPct:   F64Ceil(math.Round(util * 100)),
+		Mark:      mark(util),
+	}
+}
+
+// Call `f` with the end time and the per-core utilization (1.0 == fully busy) of each interval
+// between consecutive samples for the host that is accepted by `include`.  The utilization slice
+// is reused between calls.
+
+func forEachInterval(
+	v *cpusample.CpuSamplesByHost,
+	include func(prev, cur int64) bool,
+	f func(cur int64, util []float64),
+) {
+	var util []float64
+	for i := 1; i < len(v.Data); i++ {
+		prev, cur := v.Data[i-1].Time, v.Data[i].Time
+		if !include(prev, cur) {
+			continue
+		}
+		tdiff := float64(cur - prev)
+		util = util[:0]
+		for j := range v.Data[i].Decoded {
+			// The counters are reset when the node reboots, and the interval is then meaningless.
+			var n float64
+			if j < len(v.Data[i-1].Decoded) && v.Data[i].Decoded[j] >= v.Data[i-1].Decoded[j] {
+				n = float64(v.Data[i].Decoded[j]-v.Data[i-1].Decoded[j]) / tdiff
+			}
+			util = append(util, n)
+		}
+		f(cur, util)
+	}
+}
+
+// Slurm records how many CPUs a job has but not which ones, so for a job that does not have the
+// whole node, the cores shown are the `cpus` cores on the node that were busiest during the job's
+// lifetime.  Returns nil if all the cores are to be shown.
+
+func (tc *TopCommand) jobCores(
+	v *cpusample.CpuSamplesByHost,
+	cpus int,
+	inJob func(prev, cur int64) bool,
+) []bool {
+	if cpus == 0 {
+		return nil
+	}
+	var busy []float64
+	forEachInterval(v, inJob, func(_ int64, util []float64) {
+		for len(busy) < len(util) {
+			busy = append(busy, 0)
+		}
+		for j, n := range util {
+			busy[j] += n
+		}
+	})
+	if cpus >= len(busy) {
+		return nil
+	}
+	order := make([]int, len(busy))
+	for j := range order {
+		order[j] = j
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(busy[b], busy[a])
+	})
+	cores := make([]bool, len(busy))
+	for _, j := range order[:cpus] {
+		cores[j] = true
+	}
+	return cores
+}
+
+func mark(n float64) string {
+	if n >= 0.25 {
+		return "O"
+	}
+	if n >= 0.10 {
+		return "o"
+	}
+	return "."
+}
+
+// Find the job's nodes, its lifetime, and the number of CPUs it has on each node, preferably from
+// the Slurm data, otherwise from the samples.  The end time is zero if the job is still running
+// according to Slurm.  The number of CPUs is zero if it is not known.
+
+func (tc *TopCommand) findJob(
+	meta types.Context,
+	host Hosts,
+) (nodes []string, start, end int64, cpus int, err error) {
+	if sdp, e := slurmjob.OpenSlurmjobDataProvider(meta); e == nil {
+		jobs, e := sdp.Query(
+			slurmjob.QueryFilter{
+				QueryFilter: common.QueryFilter{
+					HaveFrom: tc.HaveFrom,
+					FromDate: tc.FromDate,
+					HaveTo:   tc.HaveTo,
+					ToDate:   tc.ToDate,
+				},
+				Job: []uint32{uint32(tc.Job)},
+			},
+		)
+		if e == nil && len(jobs) > 0 && jobs[0].Main.NodeList != UstrEmpty {
+			main := jobs[0].Main
+			nodes, err = hostglob.SplitMultiPattern(main.NodeList.String())
+			if err == nil {
+				cpus = cpusPerNode(main, len(nodes))
+			}
+			return nodes, main.Start, main.End, cpus, err
+		}
+	}
+
+	sdp, err := sample.OpenSampleDataProvider(meta)
+	if err != nil {
+		return
+	}
+	blobs, _, err := sdp.QueryRaw(tc.FromDate, tc.ToDate, host)
+	if err != nil {
+		err = fmt.Errorf("Failed to read log records: %v", err)
+		return
+	}
+	seen := make(map[Ustr]bool)
+	for _, blob := range blobs {
+		for _, s := range blob {
+			if s.Job != uint32(tc.Job) {
+				continue
+			}
+			if !seen[s.Hostname] {
+				seen[s.Hostname] = true
+				nodes = append(nodes, s.Hostname.String())
+			}
+			if start == 0 || s.Timestamp < start {
+				start = s.Timestamp
+			}
+			end = max(end, s.Timestamp)
+		}
+	}
+	if len(nodes) == 0 {
+		err = fmt.Errorf("Job %d not found", tc.Job)
+	}
+	return
+}
+
+// The job's CPUs are a total for the job, and the allocation is assumed to be even across the nodes.
+
+func cpusPerNode(main *repr.SacctInfo, numNodes int) int {
+	total := int(main.TotalCPUs())
+	if numNodes == 0 || total == 0 {
+		return 0
+	}
+	return (total + numNodes - 1) / numNodes
+}
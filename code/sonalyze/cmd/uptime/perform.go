@@ -1,19 +1,30 @@
 // Given a stretch of time - a set of Samples - when a host was up, the status of its GPUs can be
-// determined by looking at the records' gpu_status fields.
+// determined by looking at the records' gpu_status fields, and the status of the individual cards
+// by looking at the GPU samples' failing fields.
 //
 // In addition to the Samples, we take as inputs the `from` and `to` timestamps defining the time
 // window of interest.  A host is up at the start if its first Sample is within the gap-threshold
 // of the `from` time, and ditto it is up at the end for its last Sample close to the `to` time.
-// The gap-threshold is computed from the sampling interval provided as an argument to the program.
+// The gap-threshold is computed from the sampling interval provided as an argument to the program,
+// or if there is no such argument, from the sampling interval observed in the host's Samples.
 //
-// The output has five fields: device, host, state, start, end where
+// Newer data carry the node's boot time in the node samples.  When the boot time is known, the
+// host went down between two Samples if and only if it booted between them, and it came up again
+// at the boot time.  A gap in the Samples that is not spanned by a boot is missing data, not
+// downtime.  The gap-threshold is only used for hosts and times for which there is no boot time.
 //
-//  - device is `host` or `gpu`
+// The output has six fields: device, host, state, start, end, reboots where (reboots is only printed
+// when asked for by name)
+//
+//  - device is `host`, `gpu`, or `gpuN` for card index N
 //  - host is the name of the host (FQDN probably)
 //  - state is `up` or `down`
 //  - start is the inclusive start of the window when the device was in the given state, on the form
 //    YYYY-MM-DD HH:MM (the same form used elsewhere)
 //  - end is the exclusive end of the window, ditto
+//  - reboots is the number of distinct boot times in the node samples that fall within a `host`
+//    `down` window, 0 when no boot time is known.  The node samples are normally taken at the same
+//    times as the Samples, and then only the last boot in the window is seen and this is 0 or 1
 //
 // `start` and `end` of hosts are computed so that windows overlap: the `end` of one record will
 // equal the `start` of the next.  This is fine, and helps clients display the data.  `start` and
@@ -29,11 +40,6 @@
 // - For nodes/hosts that don't have GPUs it would be nice not to print any GPU information.
 //   We should be able to use the config data to drive that.
 //
-// - (Speculative) At the moment, the gpu_status is per-host, not per-card, because that's all sonar
-//   is able to discern.  When that changes, the device field will be generalized so that its value
-//   may be `gpu0`, `gpu1`, etc.  Most likely records for these will be in addition to the records
-//   for plain `gpu`, which will plausibly retain its existing semantics.
-//
 // - (Speculative) As gpu_status is an enum it can take on other values than up or down; thus when
 //   we improve state detection, the representation here of that value may change, or there may be
 //   additional fields.
@@ -51,8 +57,12 @@ import (
 	uslices "go-utils/slices"
 
 	. "sonalyze/common"
+	"sonalyze/data/common"
 	"sonalyze/data/config"
+	"sonalyze/data/gpusample"
+	"sonalyze/data/nodesample"
 	"sonalyze/data/sample"
+	"sonalyze/db/repr"
 	"sonalyze/db/types"
 	. "sonalyze/table"
 )
@@ -62,6 +72,12 @@ type window struct {
 	start, end int // inclusive indices in `samples`
 }
 
+// A host "up" window, with the time range reported for it.
+type upWindow struct {
+	window
+	from, to int64
+}
+
 func (uc *UptimeCommand) Perform(
 	out io.Writer,
 	meta types.Context,
@@ -102,7 +118,58 @@ func (uc *UptimeCommand) Perform(
 		Log.Infof("%d streams", len(streams))
 		Log.Infof("%d records after hack", len(samples))
 	}
-	return uc.printReports(out, uc.computeReports(samples, bounds, meta, hosts))
+
+	boots, err := uc.bootTimes(meta, filter, hosts)
+	if err != nil {
+		return err
+	}
+	gdp, err := gpusample.OpenGpuSampleDataProvider(meta)
+	if err != nil {
+		return err
+	}
+	gpus, _, _, _, err := gdp.Query(filter.FromDate, filter.ToDate, hosts)
+	if err != nil {
+		return fmt.Errorf("Failed to read log records: %v", err)
+	}
+
+	return uc.printReports(out, uc.computeReports(samples, bounds, meta, hosts, boots, gpus))
+}
+
+// Return the node samples that have a boot time for each host, sorted by ascending time.
+
+func (uc *UptimeCommand) bootTimes(
+	meta types.Context,
+	filter sample.QueryFilter,
+	hosts Hosts,
+) (map[Ustr][]*repr.NodeSample, error) {
+	nsp, err := nodesample.OpenNodeSampleDataProvider(meta)
+	if err != nil {
+		return nil, err
+	}
+	records, err := nsp.Query(
+		common.QueryFilter{
+			HaveFrom: filter.HaveFrom,
+			FromDate: filter.FromDate,
+			HaveTo:   filter.HaveTo,
+			ToDate:   filter.ToDate,
+			Host:     hosts,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read log records: %v", err)
+	}
+	boots := make(map[Ustr][]*repr.NodeSample)
+	for _, r := range records {
+		if r.Boot != 0 {
+			boots[r.Hostname] = append(boots[r.Hostname], r)
+		}
+	}
+	for _, samples := range boots {
+		slices.SortFunc(samples, func(a, b *repr.NodeSample) int {
+			return cmp.Compare(a.Timestamp, b.Timestamp)
+		})
+	}
+	return boots, nil
 }
 
 // Compute up/down reports for all selected hosts within the time window.  The result will not be
@@ -113,6 +180,8 @@ func (uc *UptimeCommand) computeReports(
 	bounds Timebounds,
 	meta types.Context,
 	hostGlobber Hosts,
+	boots map[Ustr][]*repr.NodeSample,
+	gpus gpusample.GpuSamplesByHostSet,
 ) []*UptimeLine {
 	reports := make([]*UptimeLine, 0)
 	fromIncl, toIncl := uc.InterpretFromToWithBounds(bounds)
@@ -126,15 +195,29 @@ func (uc *UptimeCommand) computeReports(
 
 	uc.computeAlwaysDown(&reports, samples, meta, hostGlobber, fromIncl, toIncl)
 
-	hostUpWindows := make([]window, 0)
-	cutoff := int64(uc.Interval) * 60 * 2
+	hostUpWindows := make([]upWindow, 0)
 	for _, w := range uc.computeHostWindows(samples, hostGlobber, fromIncl, toIncl) {
 		hostFirst := samples[w.start]
 		hostLast := samples[w.end]
+		nodeSamples := boots[hostFirst.Hostname]
+		cutoff := uc.gapCutoff(samples[w.start : w.end+1])
 
 		// If the host is down at the start, push out a record saying so.  Then we start in the "up"
-		// state always.
-		if !(hostFirst.Timestamp-fromIncl <= cutoff) {
+		// state always.  If the boot time is known then the host came up when it booted, and it was
+		// up at the start if it booted before that.
+		upStart := hostFirst.Timestamp
+		downAtStart := !(hostFirst.Timestamp-fromIncl <= cutoff)
+		reboots := 0
+		if boot, known := bootTime(nodeSamples, hostFirst.Timestamp); known {
+			downAtStart = boot > fromIncl
+			if downAtStart {
+				upStart = boot
+				reboots = countBoots(nodeSamples, fromIncl, hostFirst.Timestamp)
+			} else {
+				upStart = fromIncl
+			}
+		}
+		if downAtStart {
 			if Verbose {
 				Log.Infof("  Down at start")
 			}
@@ -144,12 +227,14 @@ func (uc *UptimeCommand) computeReports(
 					Hostname: hostFirst.Hostname.String(),
 					State:    "down",
 					Start:    DateTimeValue(fromIncl),
-					End:      DateTimeValue(hostFirst.Timestamp),
+					End:      DateTimeValue(upStart),
+					Reboots:  reboots,
 				})
 			}
 		}
 
-		// If the host is down at the end, push out a record saying so.
+		// If the host is down at the end, push out a record saying so.  There is no boot time after
+		// the last sample, so this always uses the gap threshold.
 		if !(toIncl-hostLast.Timestamp <= cutoff) {
 			if Verbose {
 				Log.Infof("  Down at end")
@@ -174,7 +259,13 @@ func (uc *UptimeCommand) computeReports(
 
 			// We're in an "up" window, scan to its end.
 			j := windowStart + 1
-			for j <= w.end && samples[j].Timestamp-prevTimestamp <= cutoff {
+			var boot int64
+			for j <= w.end {
+				var down bool
+				down, boot = wentDown(nodeSamples, cutoff, prevTimestamp, samples[j].Timestamp)
+				if down {
+					break
+				}
 				prevTimestamp = samples[j].Timestamp
 				j++
 			}
@@ -190,31 +281,42 @@ func (uc *UptimeCommand) computeReports(
 					Device:   "host",
 					Hostname: hostFirst.Hostname.String(),
 					State:    "up",
-					Start:    DateTimeValue(samples[windowStart].Timestamp),
+					Start:    DateTimeValue(upStart),
 					End:      DateTimeValue(samples[j-1].Timestamp),
 				})
 			}
 
 			// Record this window, we'll need it for the GPU scans later.  (The scans could happen
 			// here, but it just makes the code unreadable.)
-			hostUpWindows = append(hostUpWindows, window{windowStart, j - 1})
+			hostUpWindows = append(
+				hostUpWindows,
+				upWindow{window{windowStart, j - 1}, upStart, samples[j-1].Timestamp},
+			)
 
 			if j > w.end {
 				break
 			}
 
 			// System went down in the window.  The window in which it is down is entirely between
-			// these two records.  The fact that there is a following record means it came up again.
+			// these two records, or between the first record and the boot time if that is known.
+			// The fact that there is a following record means it came up again.
 			if Verbose {
 				Log.Infof("  Down window %d..%d inclusive\n", j-1, j)
 			}
+			upStart = samples[j].Timestamp
+			reboots = 0
+			if boot != 0 {
+				upStart = boot
+				reboots = countBoots(nodeSamples, prevTimestamp, samples[j].Timestamp)
+			}
 			if !uc.OnlyUp {
 				reports = append(reports, &UptimeLine{
 					Device:   "host",
 					Hostname: hostFirst.Hostname.String(),
 					State:    "down",
 					Start:    DateTimeValue(prevTimestamp),
-					End:      DateTimeValue(samples[j].Timestamp),
+					End:      DateTimeValue(upStart),
+					Reboots:  reboots,
 				})
 			}
 
@@ -245,11 +347,150 @@ func (uc *UptimeCommand) computeReports(
 				})
 			}
 		}
+
+		// And the status of the individual cards, for data that have it.
+		uc.computeCardReports(&reports, gpus[samples[w.start].Hostname], w.from, w.to)
 	}
 
 	return reports
 }
 
+// The gap threshold is twice the sampling interval.  If no interval was given, use the median time
+// between the host's distinct sample times.  The samples are sorted by ascending time.
+
+func (uc *UptimeCommand) gapCutoff(samples sample.SampleStream) int64 {
+	if uc.Interval > 0 {
+		return int64(uc.Interval) * 60 * 2
+	}
+	deltas := make([]int64, 0)
+	for i := 1; i < len(samples); i++ {
+		if d := samples[i].Timestamp - samples[i-1].Timestamp; d > 0 {
+			deltas = append(deltas, d)
+		}
+	}
+	if len(deltas) == 0 {
+		return 0
+	}
+	slices.Sort(deltas)
+	return deltas[(len(deltas)-1)/2] * 2
+}
+
+// The host went down between two consecutive samples at times `prev` and `cur` if its boot time at
+// `cur` shows that it booted after `prev`, and then the boot time is also returned.  If the boot
+// time shows that it did not, the host stayed up even if there is a long gap between the samples.
+// Otherwise, it went down if the gap is longer than the cutoff.
+
+func wentDown(nodeSamples []*repr.NodeSample, cutoff, prev, cur int64) (bool, int64) {
+	if cur == prev {
+		return false, 0
+	}
+	if boot, known := bootTime(nodeSamples, cur); known {
+		if boot > prev {
+			return true, boot
+		}
+		return false, 0
+	}
+	return cur-prev > cutoff, 0
+}
+
+// The boot time of the node as of time `t`, from the first node sample at or after `t`.  If that
+// sample's boot time is after `t` then the node has booted again since `t` and we can't tell.
+
+func bootTime(nodeSamples []*repr.NodeSample, t int64) (int64, bool) {
+	ix, _ := slices.BinarySearchFunc(nodeSamples, t, func(s *repr.NodeSample, t int64) int {
+		return cmp.Compare(s.Timestamp, t)
+	})
+	if ix == len(nodeSamples) || nodeSamples[ix].Boot > t {
+		return 0, false
+	}
+	return nodeSamples[ix].Boot, true
+}
+
+// The number of distinct boot times after `prev` in the node samples from `prev` up to and including
+// the first node sample at or after `cur`, ie, the number of times the node is known to have booted
+// between the Samples at `prev` and `cur`.
+
+func countBoots(nodeSamples []*repr.NodeSample, prev, cur int64) int {
+	ix, _ := slices.BinarySearchFunc(nodeSamples, prev, func(s *repr.NodeSample, t int64) int {
+		return cmp.Compare(s.Timestamp, t)
+	})
+	n := 0
+	var last int64
+	for ; ix < len(nodeSamples); ix++ {
+		s := nodeSamples[ix]
+		if s.Boot > prev && s.Boot != last {
+			n++
+			last = s.Boot
+		}
+		if s.Timestamp >= cur {
+			break
+		}
+	}
+	return n
+}
+
+// Compute up/down windows for each card of the host within the host's up window [start, end], from
+// the card's failing state in the GPU samples.  As for the host-level GPU status, the end of one
+// window is the start of the next.  Samples that do not carry the failing state are ignored.
+
+func (uc *UptimeCommand) computeCardReports(
+	reports *[]*UptimeLine,
+	gpuSamples *gpusample.GpuSamplesByHost,
+	start, end int64,
+) {
+	if gpuSamples == nil {
+		return
+	}
+	type cardWindow struct {
+		failing    bool
+		start, end int64
+	}
+	emit := func(index uint64, cw *cardWindow) {
+		updown := "up"
+		if cw.failing {
+			updown = "down"
+		}
+		if !(updown == "up" && uc.OnlyDown) && !(updown == "down" && uc.OnlyUp) {
+			*reports = append(*reports, &UptimeLine{
+				Device:   fmt.Sprintf("gpu%d", index),
+				Hostname: gpuSamples.Hostname.String(),
+				State:    updown,
+				Start:    DateTimeValue(cw.start),
+				End:      DateTimeValue(cw.end),
+			})
+		}
+	}
+
+	cards := make(map[uint64]*cardWindow)
+	first, _ := slices.BinarySearchFunc(gpuSamples.Data, start, func(s gpusample.GpuSamples, t int64) int {
+		return cmp.Compare(s.Time, t)
+	})
+	for _, d := range gpuSamples.Data[first:] {
+		if d.Time > end {
+			break
+		}
+		for _, g := range d.Decoded {
+			if (g.Attr & repr.GpuHasFailing) == 0 {
+				continue
+			}
+			failing := g.Failing != 0
+			cw := cards[g.Index]
+			if cw == nil {
+				cards[g.Index] = &cardWindow{failing, d.Time, d.Time}
+				continue
+			}
+			cw.end = d.Time
+			if failing != cw.failing {
+				emit(g.Index, cw)
+				*cw = cardWindow{failing, d.Time, d.Time}
+			}
+		}
+	}
+	for index, cw := range cards {
+		emit(index, cw)
+	}
+}
+
 // Return a sequence of windows: each window pertains to a stretch of records for a single host
 // starting no earlier than fromIncl and ending no later than toIncl.
 //
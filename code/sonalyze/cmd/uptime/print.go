@@ -37,11 +37,12 @@ package uptime
 
 FIELDS *UptimeLine
 
- Device   string        alias:"device" desc:"Device type: 'host' or 'gpu'"
- Hostname string        alias:"host"   desc:"Host name for the device"
- State    string        alias:"state"  desc:"Device state: 'up' or 'down'"
- Start    DateTimeValue alias:"start"  desc:"Start time of 'up' or 'down' window"
- End      DateTimeValue alias:"end"    desc:"End time of 'up' or 'down' window"
+ Device   string        alias:"device"  desc:"Device type: 'host', 'gpu', or 'gpuN' for card index N"
+ Hostname string        alias:"host"    desc:"Host name for the device"
+ State    string        alias:"state"   desc:"Device state: 'up' or 'down'"
+ Start    DateTimeValue alias:"start"   desc:"Start time of 'up' or 'down' window"
+ End      DateTimeValue alias:"end"     desc:"End time of 'up' or 'down' window"
+ Reboots  int           alias:"reboots" desc:"Number of distinct boot times seen in a host 'down' window, 0 if none are known"
 
 GENERATE UptimeLine
 
@@ -54,12 +55,26 @@ order, hosts before devices on the host.  Periods where the node/device is
 up or down are both printed, but one can select one or the other with
 "-only-up" and "-only-down".
 
-The "-interval" switch must be specified and should be the interval in
-minutes for samples on the nodes in question.
+Newer data record the time the node was booted.  For these, a node was down
+between two samples if and only if it booted between them, and the "down"
+window ends at the boot time.  A gap in the samples without a boot is
+missing data and the node is considered up.
+
+For older data, and at the end of the timeline, a node was down if there is
+a gap in the samples longer than twice the sampling interval.  The
+"-interval" switch should be the interval in minutes for samples on the
+nodes in question.  If it is not given, the median time between the node's
+samples is used.
 
 A host or device is up at the start of the timeline if its first Sample is
-within a small factor of the interval of the "from" time, and ditto it is
-up at the end for its last Sample close to the "to" time.
+within a small factor of the interval of the "from" time (or its boot time
+is before the "from" time), and ditto it is up at the end for its last
+Sample close to the "to" time.
+
+The status of the GPUs is reported for the host as a whole ("gpu") and,
+when the GPU samples carry it, for each card ("gpu0", "gpu1", ...).
+
+The "reboots" field is not part of "all" and must be requested by name.
 
 HELP UptimeCommand
 
@@ -89,11 +104,7 @@ func (uc *UptimeCommand) printReports(out io.Writer, reports []*UptimeLine) erro
 			c = cmp.Compare(a.Start, b.Start)
 			if c == 0 {
 				if a.Device != b.Device {
-					if a.Device == "host" {
-						c = -1
-					} else {
-						c = 1
-					}
+					c = compareDevices(a.Device, b.Device)
 				}
 				if c == 0 {
 					c = cmp.Compare(a.End, b.End)
@@ -114,3 +125,18 @@ func (uc *UptimeCommand) printReports(out io.Writer, reports []*UptimeLine) erro
 	)
 	return nil
 }
+
+// "host" sorts first, then "gpu", then the cards in index order.
+
+func compareDevices(a, b string) int {
+	if a == "host" {
+		return -1
+	}
+	if b == "host" {
+		return 1
+	}
+	if c := cmp.Compare(len(a), len(b)); c != 0 {
+		return c
+	}
+	return cmp.Compare(a, b)
+}
@@ -28,7 +28,7 @@ var uptimeFormatters = map[string]Formatter[*UptimeLine]{
 		Xtract: func(d *UptimeLine) any {
 			return d.Device
 		},
-		Help: "(string) Device type: 'host' or 'gpu'",
+		Help: "(string) Device type: 'host', 'gpu', or 'gpuN' for card index N",
 	},
 	"Hostname": {
 		Fmt: func(d *UptimeLine, ctx PrintMods) string {
@@ -66,6 +66,15 @@ var uptimeFormatters = map[string]Formatter[*UptimeLine]{
 		},
 		Help: "(DateTimeValue) End time of 'up' or 'down' window",
 	},
+	"Reboots": {
+		Fmt: func(d *UptimeLine, ctx PrintMods) string {
+			return FormatInt((d.Reboots), ctx)
+		},
+		Xtract: func(d *UptimeLine) any {
+			return d.Reboots
+		},
+		Help: "(int) Number of distinct boot times seen in a host 'down' window, 0 if none are known",
+	},
 }
 
 func init() {
@@ -74,6 +83,7 @@ func init() {
 	DefAlias(uptimeFormatters, "State", "state")
 	DefAlias(uptimeFormatters, "Start", "start")
 	DefAlias(uptimeFormatters, "End", "end")
+	DefAlias(uptimeFormatters, "Reboots", "reboots")
 }
 
 // MT: Constant after initialization; immutable
@@ -105,6 +115,12 @@ var uptimePredicates = map[string]Predicate[*UptimeLine]{
 			return cmp.Compare((d.End), v.(DateTimeValue))
 		},
 	},
+	"Reboots": Predicate[*UptimeLine]{
+		Convert: CvtString2Int,
+		Compare: func(d *UptimeLine, v any) int {
+			return cmp.Compare((d.Reboots), v.(int))
+		},
+	},
 }
 
 type UptimeLine struct {
@@ -113,6 +129,7 @@ type UptimeLine struct {
 	State    string
 	Start    DateTimeValue
 	End      DateTimeValue
+	Reboots  int
 }
 
 func (c *UptimeCommand) Summary(out io.Writer) {
@@ -123,12 +140,26 @@ order, hosts before devices on the host.  Periods where the node/device is
 up or down are both printed, but one can select one or the other with
 "-only-up" and "-only-down".
 
-The "-interval" switch must be specified and should be the interval in
-minutes for samples on the nodes in question.
+Newer data record the time the node was booted.  For these, a node was down
+between two samples if and only if it booted between them, and the "down"
+window ends at the boot time.  A gap in the samples without a boot is
+missing data and the node is considered up.
+
+For older data, and at the end of the timeline, a node was down if there is
+a gap in the samples longer than twice the sampling interval.  The
+"-interval" switch should be the interval in minutes for samples on the
+nodes in question.  If it is not given, the median time between the node's
+samples is used.
 
 A host or device is up at the start of the timeline if its first Sample is
-within a small factor of the interval of the "from" time, and ditto it is
-up at the end for its last Sample close to the "to" time.
+within a small factor of the interval of the "from" time (or its boot time
+is before the "from" time), and ditto it is up at the end for its last
+Sample close to the "to" time.
+
+The status of the GPUs is reported for the host as a whole ("gpu") and,
+when the GPU samples carry it, for each card ("gpu0", "gpu1", ...).
+
+The "reboots" field is not part of "all" and must be requested by name.
 `)
 }
 
@@ -1,8 +1,9 @@
 // Compute uptime for a host or a host's GPUs.
 //
 // Given a list of Samples, including heartbeat records, the uptime for each host can be computed
-// by looking at gaps in the timeline of observations for the host.  If a gap exceeds the threshold
-// for the gap, we assume the system was down.
+// by looking at boot times and gaps in the timeline of observations for the host.  If the host
+// booted between two observations, or there is no boot time and the gap exceeds the threshold for
+// the gap, we assume the system was down.
 
 package uptime
 
@@ -30,7 +31,7 @@ func (uc *UptimeCommand) Add(fs *CLI) {
 
 	fs.Group("application-control")
 	fs.UintVar(&uc.Interval, "interval", 0,
-		"The maximum sampling `interval` in minutes (before any randomization) seen in the data (required)")
+		"The maximum sampling `interval` in minutes (before any randomization) seen in the data [default: observed]")
 
 	fs.Group("printing")
 	fs.BoolVar(&uc.OnlyUp, "only-up", false, "Show only times when systems are up")
@@ -49,17 +50,14 @@ func (uc *UptimeCommand) ReifyForRemote(x *ArgReifier) error {
 }
 
 func (uc *UptimeCommand) Validate() error {
-	var e1, e3, e4, e5 error
+	var e1, e4, e5 error
 	e1 = uc.SampleAnalysisArgs.Validate()
-	if uc.Interval == 0 {
-		e3 = errors.New("-interval is required")
-	}
 	if uc.OnlyUp && uc.OnlyDown {
 		e4 = errors.New("Nonsensical -only-up AND -only-down")
 	}
 	e5 = ValidateFormatArgs(
 		&uc.FormatArgs, uptimeDefaultFields, uptimeFormatters, uptimeAliases, DefaultFixed)
-	return errors.Join(e1, e3, e4, e5)
+	return errors.Join(e1, e4, e5)
 }
 
 func (uc *UptimeCommand) DefaultRecordFilters() (
@@ -0,0 +1,244 @@
+package waittime
+
+import (
+	"cmp"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	. "sonalyze/common"
+	"sonalyze/data/common"
+	"sonalyze/data/slurmjob"
+	"sonalyze/db/types"
+	. "sonalyze/table"
+)
+
+type waittimeRecord struct {
+	Period      DateValue
+	Partition   Ustr
+	GpuType     Ustr
+	SizeClass   Ustr
+	Jobs        int
+	Mean        DurationValue
+	Min         DurationValue
+	P50         DurationValue
+	P90         DurationValue
+	P99         DurationValue
+	Max         DurationValue
+	Wait0h      int
+	Wait1h      int
+	Wait2h      int
+	Wait3h      int
+	Wait4to11h  int
+	Wait12to23h int
+	Wait1to6d   int
+	Wait7d      int
+
+	// For sorting the size classes in size order
+	sizeIndex int
+}
+
+// Upper bounds (inclusive) on requested cores for the size classes; the last class is open.  Jobs
+// whose core count is not recorded are in the class unknownSize, which sorts after these.
+
+var sizeClasses = []struct {
+	maxCores uint32
+	name     string
+}{
+	{1, "1"},
+	{4, "2-4"},
+	{16, "5-16"},
+	{64, "17-64"},
+	{256, "65-256"},
+	{0, "257+"},
+}
+
+const unknownSize = "unknown"
+
+type groupKey struct {
+	period    int64
+	partition Ustr
+	gpuType   Ustr
+	sizeIndex int
+}
+
+func (wc *WaittimeCommand) Perform(meta types.Context, _ io.Reader, stdout, _ io.Writer) error {
+	sdp, err := slurmjob.OpenSlurmjobDataProvider(meta)
+	if err != nil {
+		return err
+	}
+	host, err := common.ResolveHostQuery(meta, wc.Host, wc.FromDate, wc.ToDate)
+	if err != nil {
+		return err
+	}
+	jobs, err := sdp.Query(
+		slurmjob.QueryFilter{
+			QueryFilter: common.QueryFilter{
+				HaveFrom: wc.HaveFrom,
+				FromDate: wc.FromDate,
+				HaveTo:   wc.HaveTo,
+				ToDate:   wc.ToDate,
+				Host:     host,
+			},
+			State:     wc.State,
+			User:      wc.User,
+			Account:   wc.Account,
+			Partition: wc.Partition,
+			GpuType:   wc.GpuType,
+			SomeGPU:   wc.SomeGPU,
+			NoGPU:     wc.NoGPU,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	var truncate func(int64, *time.Location) int64
+	switch wc.Bucket {
+	case "day":
+		truncate = TruncateToDay
+	case "week":
+		truncate = TruncateToWeek
+	case "month":
+		truncate = TruncateToMonth
+	}
+	byPartition := slices.Contains(wc.By, "partition")
+	byGpuType := slices.Contains(wc.By, "gpu-type")
+	bySize := slices.Contains(wc.By, "size")
+	loc := wc.TimeZone()
+
+	// Group the wait times.  Jobs that never started have no wait time.
+
+	groups := make(map[groupKey][]int64)
+	var notStarted int
+	for _, j := range jobs {
+		if j.Main.Start == 0 || j.Main.Start < j.Main.Submit {
+			notStarted++
+			continue
+		}
+		var k groupKey
+		if truncate != nil {
+			k.period = truncate(j.Main.Submit, loc)
+		}
+		if byPartition {
+			k.partition = j.Main.Partition
+		}
+		if byGpuType {
+			k.gpuType = gpuType(j.Main.ReqGPUS)
+		}
+		if bySize {
+			k.sizeIndex = sizeClass(j.Main.TotalCPUs())
+		} else {
+			k.sizeIndex = -1
+		}
+		groups[k] = append(groups[k], j.Main.Start-j.Main.Submit)
+	}
+	if Verbose {
+		Log.Infof("%d jobs, %d never started, %d groups", len(jobs), notStarted, len(groups))
+	}
+
+	records := make([]*waittimeRecord, 0, len(groups))
+	for k, waits := range groups {
+		records = append(records, newRecord(k, waits))
+	}
+
+	records, err = ApplyQuery(wc.ParsedQuery, waittimeFormatters, waittimePredicates, records)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(records, func(a, b *waittimeRecord) int {
+		return cmp.Or(
+			cmp.Compare(a.Period, b.Period),
+			cmp.Compare(a.Partition.String(), b.Partition.String()),
+			cmp.Compare(a.GpuType.String(), b.GpuType.String()),
+			cmp.Compare(a.sizeIndex, b.sizeIndex),
+		)
+	})
+	FormatData(
+		stdout,
+		wc.PrintFields,
+		waittimeFormatters,
+		wc.PrintOpts,
+		records,
+	)
+	return nil
+}
+
+func newRecord(k groupKey, waits []int64) *waittimeRecord {
+	slices.Sort(waits)
+	r := &waittimeRecord{
+		Period:    k.period,
+		Partition: k.partition,
+		GpuType:   k.gpuType,
+		Jobs:      len(waits),
+		Min:       waits[0],
+		P50:       Percentile(waits, 50),
+		P90:       Percentile(waits, 90),
+		P99:       Percentile(waits, 99),
+		Max:       waits[len(waits)-1],
+		sizeIndex: k.sizeIndex,
+	}
+	switch {
+	case k.sizeIndex == len(sizeClasses):
+		r.SizeClass = StringToUstr(unknownSize)
+	case k.sizeIndex >= 0:
+		r.SizeClass = StringToUstr(sizeClasses[k.sizeIndex].name)
+	}
+	var sum int64
+	for _, w := range waits {
+		sum += w
+		switch hours := w / 3600; {
+		case hours == 0:
+			r.Wait0h++
+		case hours == 1:
+			r.Wait1h++
+		case hours == 2:
+			r.Wait2h++
+		case hours == 3:
+			r.Wait3h++
+		case hours < 12:
+			r.Wait4to11h++
+		case hours < 24:
+			r.Wait12to23h++
+		case hours < 24*7:
+			r.Wait1to6d++
+		default:
+			r.Wait7d++
+		}
+	}
+	r.Mean = sum / int64(len(waits))
+	return r
+}
+
+func sizeClass(cores uint32) int {
+	if cores == 0 {
+		return len(sizeClasses)
+	}
+	for i, c := range sizeClasses[:len(sizeClasses)-1] {
+		if cores <= c.maxCores {
+			return i
+		}
+	}
+	return len(sizeClasses) - 1
+}
+
+// The ReqGPUS field is a comma-separated list of model=n and/or *=n.  The GPU type is the list of
+// models, or * if no model was requested, or blank if no GPUs were requested.
+
+func gpuType(reqGpus Ustr) Ustr {
+	if reqGpus == UstrEmpty {
+		return UstrEmpty
+	}
+	models := make([]string, 0)
+	for _, req := range strings.Split(reqGpus.String(), ",") {
+		if model, _, _ := strings.Cut(req, "="); model != "*" {
+			models = append(models, model)
+		}
+	}
+	if len(models) == 0 {
+		return StringToUstr("*")
+	}
+	slices.Sort(models)
+	return StringToUstr(strings.Join(models, ","))
+}
@@ -0,0 +1,380 @@
+// DO NOT EDIT.  Generated from waittime.go by generate-table
+
+package waittime
+
+import (
+	"cmp"
+	"fmt"
+	"go-utils/gpuset"
+	"io"
+	. "sonalyze/common"
+	. "sonalyze/table"
+)
+
+var (
+	_ = cmp.Compare(0, 0)
+	_ fmt.Formatter
+	_ = io.SeekStart
+	_ = UstrEmpty
+	_ gpuset.GpuSet
+)
+
+// MT: Constant after initialization; immutable
+var waittimeFormatters = map[string]Formatter[*waittimeRecord]{
+	"Period": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatDateValue((d.Period), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Period
+		},
+		Help: "(DateValue) Start of the time bucket the jobs were submitted in (with -bucket)",
+	},
+	"Partition": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatUstr((d.Partition), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Partition
+		},
+		Help: "(string) Requested partition (with -by partition)",
+	},
+	"GpuType": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatUstr((d.GpuType), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.GpuType
+		},
+		Help: "(string) Requested GPU models, * for any model, blank for no GPUs (with -by gpu-type)",
+	},
+	"SizeClass": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatUstr((d.SizeClass), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.SizeClass
+		},
+		Help: "(string) Class of requested cores for the whole job (with -by size)",
+	},
+	"Jobs": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatInt((d.Jobs), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Jobs
+		},
+		Help: "(int) Number of jobs in the group",
+	},
+	"Mean": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.Mean), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Mean
+		},
+		Help: "(DurationValue) Mean wait time",
+	},
+	"Min": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.Min), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Min
+		},
+		Help: "(DurationValue) Minimum wait time",
+	},
+	"P50": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.P50), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.P50
+		},
+		Help: "(DurationValue) Median wait time",
+	},
+	"P90": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.P90), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.P90
+		},
+		Help: "(DurationValue) 90th percentile wait time",
+	},
+	"P99": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.P99), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.P99
+		},
+		Help: "(DurationValue) 99th percentile wait time",
+	},
+	"Max": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatDurationValue((d.Max), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Max
+		},
+		Help: "(DurationValue) Maximum wait time",
+	},
+	"Wait0h": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatInt((d.Wait0h), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Wait0h
+		},
+		Help: "(int) Number of jobs that waited less than 1h",
+	},
+	"Wait1h": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatInt((d.Wait1h), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Wait1h
+		},
+		Help: "(int) Number of jobs that waited at least 1h but less than 2h",
+	},
+	"Wait2h": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatInt((d.Wait2h), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Wait2h
+		},
+		Help: "(int) Number of jobs that waited at least 2h but less than 3h",
+	},
+	"Wait3h": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatInt((d.Wait3h), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Wait3h
+		},
+		Help: "(int) Number of jobs that waited at least 3h but less than 4h",
+	},
+	"Wait4to11h": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatInt((d.Wait4to11h), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Wait4to11h
+		},
+		Help: "(int) Number of jobs that waited at least 4h but less than 12h",
+	},
+	"Wait12to23h": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatInt((d.Wait12to23h), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Wait12to23h
+		},
+		Help: "(int) Number of jobs that waited at least 12h but less than 1d",
+	},
+	"Wait1to6d": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatInt((d.Wait1to6d), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Wait1to6d
+		},
+		Help: "(int) Number of jobs that waited at least 1d but less than 7d",
+	},
+	"Wait7d": {
+		Fmt: func(d *waittimeRecord, ctx PrintMods) string {
+			return FormatInt((d.Wait7d), ctx)
+		},
+		Xtract: func(d *waittimeRecord) any {
+			return d.Wait7d
+		},
+		Help: "(int) Number of jobs that waited 7d or more",
+	},
+}
+
+func init() {
+	DefAlias(waittimeFormatters, "Period", "period")
+	DefAlias(waittimeFormatters, "Partition", "partition")
+	DefAlias(waittimeFormatters, "GpuType", "gpu-type")
+	DefAlias(waittimeFormatters, "SizeClass", "size")
+	DefAlias(waittimeFormatters, "Jobs", "jobs")
+	DefAlias(waittimeFormatters, "Mean", "mean")
+	DefAlias(waittimeFormatters, "Min", "min")
+	DefAlias(waittimeFormatters, "P50", "p50")
+	DefAlias(waittimeFormatters, "P90", "p90")
+	DefAlias(waittimeFormatters, "P99", "p99")
+	DefAlias(waittimeFormatters, "Max", "max")
+	DefAlias(waittimeFormatters, "Wait0h", "w0h")
+	DefAlias(waittimeFormatters, "Wait1h", "w1h")
+	DefAlias(waittimeFormatters, "Wait2h", "w2h")
+	DefAlias(waittimeFormatters, "Wait3h", "w3h")
+	DefAlias(waittimeFormatters, "Wait4to11h", "w4to11h")
+	DefAlias(waittimeFormatters, "Wait12to23h", "w12to23h")
+	DefAlias(waittimeFormatters, "Wait1to6d", "w1to6d")
+	DefAlias(waittimeFormatters, "Wait7d", "w7d")
+}
+
+// MT: Constant after initialization; immutable
+var waittimePredicates = map[string]Predicate[*waittimeRecord]{
+	"Period": Predicate[*waittimeRecord]{
+		Convert: CvtString2DateValue,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Period), v.(DateValue))
+		},
+	},
+	"Partition": Predicate[*waittimeRecord]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Partition), v.(Ustr))
+		},
+	},
+	"GpuType": Predicate[*waittimeRecord]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.GpuType), v.(Ustr))
+		},
+	},
+	"SizeClass": Predicate[*waittimeRecord]{
+		Convert: CvtString2Ustr,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.SizeClass), v.(Ustr))
+		},
+	},
+	"Jobs": Predicate[*waittimeRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Jobs), v.(int))
+		},
+	},
+	"Mean": Predicate[*waittimeRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Mean), v.(DurationValue))
+		},
+	},
+	"Min": Predicate[*waittimeRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Min), v.(DurationValue))
+		},
+	},
+	"P50": Predicate[*waittimeRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.P50), v.(DurationValue))
+		},
+	},
+	"P90": Predicate[*waittimeRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.P90), v.(DurationValue))
+		},
+	},
+	"P99": Predicate[*waittimeRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.P99), v.(DurationValue))
+		},
+	},
+	"Max": Predicate[*waittimeRecord]{
+		Convert: CvtString2DurationValue,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Max), v.(DurationValue))
+		},
+	},
+	"Wait0h": Predicate[*waittimeRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Wait0h), v.(int))
+		},
+	},
+	"Wait1h": Predicate[*waittimeRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Wait1h), v.(int))
+		},
+	},
+	"Wait2h": Predicate[*waittimeRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Wait2h), v.(int))
+		},
+	},
+	"Wait3h": Predicate[*waittimeRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Wait3h), v.(int))
+		},
+	},
+	"Wait4to11h": Predicate[*waittimeRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Wait4to11h), v.(int))
+		},
+	},
+	"Wait12to23h": Predicate[*waittimeRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Wait12to23h), v.(int))
+		},
+	},
+	"Wait1to6d": Predicate[*waittimeRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Wait1to6d), v.(int))
+		},
+	},
+	"Wait7d": Predicate[*waittimeRecord]{
+		Convert: CvtString2Int,
+		Compare: func(d *waittimeRecord, v any) int {
+			return cmp.Compare((d.Wait7d), v.(int))
+		},
+	},
+}
+
+func (c *WaittimeCommand) Summary(out io.Writer) {
+	fmt.Fprint(out, `Compute the distribution of queue wait times for Slurm jobs.
+
+The wait time of a job is the time from when it was submitted to when
+it started, as recorded in the Slurm sacct data.  Jobs that never
+started are not included.
+
+The jobs are grouped by the keys selected with -by (partition, GPU
+type, and requested size class) and, with -bucket, by the day, week,
+or month in which they were submitted.  For each group, the percentiles
+of the wait time and a histogram of the wait times are computed.  For
+example, to see how long A100 jobs waited per week in April 2025:
+
+  sonalyze waittime -from 2025-04-01 -to 2025-04-30 -gpu-type a100 -by gpu-type -bucket week
+
+The size classes are by requested cores for the whole job: 1, 2-4, 5-16,
+17-64, 65-256, and 257+.  Jobs for which the number of cores was not
+recorded are in the class "unknown".
+`)
+}
+
+const waittimeHelp = `
+waittime
+  Compute the distribution of queue wait times for Slurm jobs.  Output records
+  are sorted by period and then by the group keys.  The default format is 'fixed'.
+`
+
+func (c *WaittimeCommand) MaybeFormatHelp() *FormatHelp {
+	return StandardFormatHelp(c.Fmt, waittimeHelp, waittimeFormatters, waittimeAliases, waittimeDefaultFields)
+}
+
+// MT: Constant after initialization; immutable
+var waittimeAliases = map[string][]string{
+	"default":   []string{"partition", "gpu-type", "size", "jobs", "mean", "p50", "p90", "p99", "max"},
+	"Default":   []string{"Partition", "GpuType", "SizeClass", "Jobs", "Mean", "P50", "P90", "P99", "Max"},
+	"bucketed":  []string{"period", "partition", "gpu-type", "size", "jobs", "mean", "p50", "p90", "p99", "max"},
+	"Bucketed":  []string{"Period", "Partition", "GpuType", "SizeClass", "Jobs", "Mean", "P50", "P90", "P99", "Max"},
+	"histogram": []string{"partition", "gpu-type", "size", "jobs", "w0h", "w1h", "w2h", "w3h", "w4to11h", "w12to23h", "w1to6d", "w7d"},
+	"Histogram": []string{"Partition", "GpuType", "SizeClass", "Jobs", "Wait0h", "Wait1h", "Wait2h", "Wait3h", "Wait4to11h", "Wait12to23h", "Wait1to6d", "Wait7d"},
+	"all":       []string{"period", "partition", "gpu-type", "size", "jobs", "mean", "min", "p50", "p90", "p99", "max", "w0h", "w1h", "w2h", "w3h", "w4to11h", "w12to23h", "w1to6d", "w7d"},
+	"All":       []string{"Period", "Partition", "GpuType", "SizeClass", "Jobs", "Mean", "Min", "P50", "P90", "P99", "Max", "Wait0h", "Wait1h", "Wait2h", "Wait3h", "Wait4to11h", "Wait12to23h", "Wait1to6d", "Wait7d"},
+}
+
+const waittimeDefaultFields = "default"
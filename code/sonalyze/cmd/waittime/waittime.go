@@ -0,0 +1,178 @@
+// Compute the distribution of queue wait times (Start - Submit) for Slurm jobs from the sacct data.
+
+package waittime
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	. "sonalyze/cmd"
+	. "sonalyze/table"
+)
+
+//go:generate ../../../generate-table/generate-table -o waittime-table.go waittime.go
+
+/*TABLE waittime
+
+package waittime
+
+%%
+
+FIELDS *waittimeRecord
+
+ Period      DateValue     alias:"period"    desc:"Start of the time bucket the jobs were submitted in (with -bucket)"
+ Partition   Ustr          alias:"partition" desc:"Requested partition (with -by partition)"
+ GpuType     Ustr          alias:"gpu-type"  desc:"Requested GPU models, * for any model, blank for no GPUs (with -by gpu-type)"
+ SizeClass   Ustr          alias:"size"      desc:"Class of requested cores for the whole job (with -by size)"
+ Jobs        int           alias:"jobs"      desc:"Number of jobs in the group"
+ Mean        DurationValue alias:"mean"      desc:"Mean wait time"
+ Min         DurationValue alias:"min"       desc:"Minimum wait time"
+ P50         DurationValue alias:"p50"       desc:"Median wait time"
+ P90         DurationValue alias:"p90"       desc:"90th percentile wait time"
+ P99         DurationValue alias:"p99"       desc:"99th percentile wait time"
+ Max         DurationValue alias:"max"       desc:"Maximum wait time"
+ Wait0h      int           alias:"w0h"       desc:"Number of jobs that waited less than 1h"
+ Wait1h      int           alias:"w1h"       desc:"Number of jobs that waited at least 1h but less than 2h"
+ Wait2h      int           alias:"w2h"       desc:"Number of jobs that waited at least 2h but less than 3h"
+ Wait3h      int           alias:"w3h"       desc:"Number of jobs that waited at least 3h but less than 4h"
+ Wait4to11h  int           alias:"w4to11h"   desc:"Number of jobs that waited at least 4h but less than 12h"
+ Wait12to23h int           alias:"w12to23h"  desc:"Number of jobs that waited at least 12h but less than 1d"
+ Wait1to6d   int           alias:"w1to6d"    desc:"Number of jobs that waited at least 1d but less than 7d"
+ Wait7d      int           alias:"w7d"       desc:"Number of jobs that waited 7d or more"
+
+SUMMARY WaittimeCommand
+
+Compute the distribution of queue wait times for Slurm jobs.
+
+The wait time of a job is the time from when it was submitted to when
+it started, as recorded in the Slurm sacct data.  Jobs that never
+started are not included.
+
+The jobs are grouped by the keys selected with -by (partition, GPU
+type, and requested size class) and, with -bucket, by the day, week,
+or month in which they were submitted.  For each group, the percentiles
+of the wait time and a histogram of the wait times are computed.  For
+example, to see how long A100 jobs waited per week in April 2025:
+
+  sonalyze waittime -from 2025-04-01 -to 2025-04-30 -gpu-type a100 -by gpu-type -bucket week
+
+The size classes are by requested cores for the whole job: 1, 2-4, 5-16,
+17-64, 65-256, and 257+.  Jobs for which the number of cores was not
+recorded are in the class "unknown".
+
+HELP WaittimeCommand
+
+  Compute the distribution of queue wait times for Slurm jobs.  Output records
+  are sorted by period and then by the group keys.  The default format is 'fixed'.
+
+ALIASES
+
+  default   partition,gpu-type,size,jobs,mean,p50,p90,p99,max
+  Default   Partition,GpuType,SizeClass,Jobs,Mean,P50,P90,P99,Max
+  bucketed  period,partition,gpu-type,size,jobs,mean,p50,p90,p99,max
+  Bucketed  Period,Partition,GpuType,SizeClass,Jobs,Mean,P50,P90,P99,Max
+  histogram partition,gpu-type,size,jobs,w0h,w1h,w2h,w3h,w4to11h,w12to23h,w1to6d,w7d
+  Histogram Partition,GpuType,SizeClass,Jobs,Wait0h,Wait1h,Wait2h,Wait3h,Wait4to11h,Wait12to23h,\
+            Wait1to6d,Wait7d
+  all       period,partition,gpu-type,size,jobs,mean,min,p50,p90,p99,max,w0h,w1h,w2h,w3h,w4to11h,\
+            w12to23h,w1to6d,w7d
+  All       Period,Partition,GpuType,SizeClass,Jobs,Mean,Min,P50,P90,P99,Max,Wait0h,Wait1h,Wait2h,\
+            Wait3h,Wait4to11h,Wait12to23h,Wait1to6d,Wait7d
+
+DEFAULTS default
+
+ELBAT*/
+
+type WaittimeCommand struct /* implements AnalysisCommand */ {
+	HostAnalysisArgs
+	FormatArgs
+
+	// Selections
+	State     []string
+	User      []string
+	Account   []string
+	Partition []string
+	GpuType   []string
+	SomeGPU   bool
+	NoGPU     bool
+
+	// Grouping
+	By     []string
+	Bucket string
+}
+
+var _ = AnalysisCommand((*WaittimeCommand)(nil))
+var _ = SimpleCommand((*WaittimeCommand)(nil))
+
+var groupKeys = []string{"partition", "gpu-type", "size"}
+
+func (wc *WaittimeCommand) Add(fs *CLI) {
+	wc.HostAnalysisArgs.Add(fs)
+	wc.FormatArgs.Add(fs)
+
+	fs.Group("job-filter")
+	fs.Var(NewRepeatableString(&wc.State), "state",
+		"Select jobs with final state `state,...`: COMPLETED, CANCELLED, FAILED, TIMEOUT, etc")
+	fs.Var(NewRepeatableString(&wc.User), "user",
+		"Select jobs with user `user1,...`")
+	fs.Var(NewRepeatableString(&wc.Account), "account",
+		"Select jobs with account `account1,...`")
+	fs.Var(NewRepeatableString(&wc.Partition), "partition",
+		"Select jobs on partition `partition1,...`")
+	fs.Var(NewRepeatableString(&wc.GpuType), "gpu-type",
+		"Select jobs where the requested GPUs have this `prefix` (repeatable) [default: all]")
+	fs.BoolVar(&wc.SomeGPU, "some-gpu", false, "Select jobs that requested GPUs")
+	fs.BoolVar(&wc.NoGPU, "no-gpu", false, "Select jobs that did not request GPUs")
+
+	fs.Group("aggregation")
+	fs.Var(NewRepeatableString(&wc.By), "by",
+		"Group the jobs by `key,...`: partition, gpu-type, size [default: partition]")
+	fs.StringVar(&wc.Bucket, "bucket", "none",
+		"Also group the jobs by submit time `interval`: none, day, week, month")
+}
+
+func (wc *WaittimeCommand) ReifyForRemote(x *ArgReifier) error {
+	x.RepeatableString("state", wc.State)
+	x.RepeatableString("user", wc.User)
+	x.RepeatableString("account", wc.Account)
+	x.RepeatableString("partition", wc.Partition)
+	x.RepeatableString("gpu-type", wc.GpuType)
+	x.Bool("some-gpu", wc.SomeGPU)
+	x.Bool("no-gpu", wc.NoGPU)
+	x.RepeatableString("by", wc.By)
+	x.String("bucket", wc.Bucket)
+	return errors.Join(
+		wc.HostAnalysisArgs.ReifyForRemote(x),
+		wc.FormatArgs.ReifyForRemote(x),
+	)
+}
+
+func (wc *WaittimeCommand) Validate() error {
+	var e1, e2, e3, e4 error
+	if len(wc.By) == 0 {
+		wc.By = []string{"partition"}
+	}
+	for _, k := range wc.By {
+		if !slices.Contains(groupKeys, k) {
+			e1 = errors.Join(e1, fmt.Errorf("Bad -by value %s, must be partition, gpu-type, or size", k))
+		}
+	}
+	defaultFields := waittimeDefaultFields
+	switch wc.Bucket {
+	case "none":
+	case "day", "week", "month":
+		defaultFields = "bucketed"
+	default:
+		e2 = fmt.Errorf("Bad -bucket value %s, must be none, day, week, or month", wc.Bucket)
+	}
+	if wc.SomeGPU && wc.NoGPU {
+		e3 = errors.New("Can't use both -some-gpu and -no-gpu")
+	}
+	e4 = errors.Join(
+		wc.HostAnalysisArgs.Validate(),
+		ValidateFormatArgs(
+			&wc.FormatArgs, defaultFields, waittimeFormatters, waittimeAliases, DefaultFixed),
+	)
+	return errors.Join(e1, e2, e3, e4)
+}
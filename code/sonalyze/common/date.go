@@ -1,10 +1,9 @@
 package common
 
 import (
-	"errors"
-	"regexp"
-	"strconv"
 	"time"
+
+	gut "go-utils/time"
 )
 
 // Parse a relative date string and return the time in the UTC time zone.  The base time is folded
@@ -24,6 +23,10 @@ import (
 //  Nd (days ago)
 //  Nw (weeks ago)
 //
+// or any of the other forms accepted by go-utils/time.ParseTime: Nh and Nm offsets and combinations
+// like 2d3h, ISO timestamps with or without an offset, now-2d3h arithmetic, and calendar names
+// like yesterday and last-month, which endOfDay takes to the end of the period.
+//
 // NOTE: we're opting in to the Go semantics here: the nonexistent yyyy-09-31 is silently
 // reinterpreted as yyyy-10-01.  This differs from the Rust semantics - Chrono signals an error.
 //
@@ -32,29 +35,15 @@ import (
 // that it wouldn't be better to be uniform.
 
 func ParseRelativeDateUtc(now time.Time, s string, endOfDay bool) (time.Time, error) {
-	now = now.UTC()
-	if probe := daysRe.FindSubmatch([]byte(s)); probe != nil {
-		days, _ := strconv.ParseUint(string(probe[1]), 10, 32)
-		return now.AddDate(0, 0, -int(days)), nil
-	}
-
-	if probe := weeksRe.FindSubmatch([]byte(s)); probe != nil {
-		weeks, _ := strconv.ParseUint(string(probe[1]), 10, 32)
-		return now.AddDate(0, 0, -int(weeks)*7), nil
-	}
-
-	if probe := dateRe.FindSubmatch([]byte(s)); probe != nil {
-		yyyy, _ := strconv.ParseUint(string(probe[1]), 10, 32)
-		mm, _ := strconv.ParseUint(string(probe[2]), 10, 32)
-		dd, _ := strconv.ParseUint(string(probe[3]), 10, 32)
-		var h, m, s int
-		if endOfDay {
-			h, m, s = 23, 59, 59
-		}
-		return time.Date(int(yyyy), time.Month(mm), int(dd), h, m, s, 0, time.UTC), nil
-	}
+	return ParseRelativeDate(now, s, endOfDay, time.UTC)
+}
 
-	return now, errors.New("Bad time specification")
+// ParseRelativeDate is like ParseRelativeDateUtc but interprets the date in the time zone `loc`: a
+// YYYY-MM-DD date is the start (or end) of that day in `loc`, and Nd and Nw are calendar days and
+// weeks in `loc`, so "1d" on the day after a DST transition is 23 or 25 hours ago.  The result is
+// always UTC.
+func ParseRelativeDate(now time.Time, s string, endOfDay bool, loc *time.Location) (time.Time, error) {
+	return gut.ParseTime(now, s, endOfDay, loc)
 }
 
 // t should be UTC, the result is always UTC
@@ -68,47 +57,54 @@ func RoundupDay(t time.Time) time.Time {
 	return ThisDay(t.Add(24*time.Hour - 1*time.Second))
 }
 
-// MT: Constant after initialization; immutable (except for configuration methods).
-var dateRe = regexp.MustCompile(`^(\d\d\d\d)-(\d\d)-(\d\d)$`)
-var daysRe = regexp.MustCompile(`^(\d+)d$`)
-var weeksRe = regexp.MustCompile(`^(\d+)w$`)
+// Time buckets.  The TruncateToX functions return the start of the bucket containing t, and the
+// AddX functions take the start of a bucket to the start of the next bucket.  Buckets are aligned
+// with the wall clock in `loc`, so across DST transitions days and weeks are 23 or 25 hours
+// long (etc), and local working days line up with the buckets.  All times are unix timestamps.
 
-func TruncateToHalfHour(t int64) int64 {
-	u := time.Unix(t, 0).UTC()
-	m := 0
-	if u.Minute() >= 30 {
-		m = 30
-	}
-	return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), m, 0, 0, time.UTC).Unix()
+func TruncateToHalfHour(t int64, loc *time.Location) int64 {
+	return truncateByOffset(t, 30*60, loc)
+}
+
+func TruncateToHour(t int64, loc *time.Location) int64 {
+	return truncateByOffset(t, 60*60, loc)
 }
 
-func TruncateToHour(t int64) int64 {
-	u := time.Unix(t, 0).UTC()
-	return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), 0, 0, 0, time.UTC).Unix()
+// Wall clock hours can be ambiguous across DST transitions, so truncate the unix time adjusted by
+// the zone offset in effect at t instead.
+func truncateByOffset(t, period int64, loc *time.Location) int64 {
+	_, offset := time.Unix(t, 0).In(loc).Zone()
+	local := t + int64(offset)
+	return t - (local%period+period)%period
 }
 
-func TruncateToHalfDay(t int64) int64 {
-	u := time.Unix(t, 0).UTC()
+func TruncateToHalfDay(t int64, loc *time.Location) int64 {
+	u := time.Unix(t, 0).In(loc)
 	h := 0
 	if u.Hour() >= 12 {
 		h = 12
 	}
-	return time.Date(u.Year(), u.Month(), u.Day(), h, 0, 0, 0, time.UTC).Unix()
+	return time.Date(u.Year(), u.Month(), u.Day(), h, 0, 0, 0, loc).Unix()
 }
 
-func TruncateToDay(t int64) int64 {
-	u := time.Unix(t, 0).UTC()
-	return time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, time.UTC).Unix()
+func TruncateToDay(t int64, loc *time.Location) int64 {
+	u := time.Unix(t, 0).In(loc)
+	return time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, loc).Unix()
 }
 
-func TruncateToWeek(t int64) int64 {
+func TruncateToWeek(t int64, loc *time.Location) int64 {
 	// Week starts on monday, while t.Weekday starts on Sunday
-	u := time.Unix(t, 0).UTC()
-	u = time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, time.UTC)
+	u := time.Unix(t, 0).In(loc)
+	u = time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, loc)
 	u = u.AddDate(0, 0, -daysFromMonday(u.Weekday()))
 	return u.Unix()
 }
 
+func TruncateToMonth(t int64, loc *time.Location) int64 {
+	u := time.Unix(t, 0).In(loc)
+	return time.Date(u.Year(), u.Month(), 1, 0, 0, 0, 0, loc).Unix()
+}
+
 func daysFromMonday(m time.Weekday) int {
 	t := int(m) - int(time.Monday)
 	if t < 0 {
@@ -117,22 +113,26 @@ func daysFromMonday(m time.Weekday) int {
 	return t
 }
 
-func AddHalfHour(t int64) int64 {
+// Steps of half days, days and weeks are calendar steps in `loc`.  Hours and half hours are fixed
+// lengths of time even across DST transitions.
+
+func AddHalfHour(t int64, _ *time.Location) int64 {
 	return t + 30*60
 }
 
-func AddHour(t int64) int64 {
+func AddHour(t int64, _ *time.Location) int64 {
 	return t + 60*60
 }
 
-func AddHalfDay(t int64) int64 {
-	return t + 12*60*60
+func AddHalfDay(t int64, loc *time.Location) int64 {
+	u := time.Unix(t, 0).In(loc)
+	return time.Date(u.Year(), u.Month(), u.Day(), u.Hour()+12, u.Minute(), u.Second(), 0, loc).Unix()
 }
 
-func AddDay(t int64) int64 {
-	return t + 24*60*60
+func AddDay(t int64, loc *time.Location) int64 {
+	return time.Unix(t, 0).In(loc).AddDate(0, 0, 1).Unix()
 }
 
-func AddWeek(t int64) int64 {
-	return t + 7*24*60*60
+func AddWeek(t int64, loc *time.Location) int64 {
+	return time.Unix(t, 0).In(loc).AddDate(0, 0, 7).Unix()
 }
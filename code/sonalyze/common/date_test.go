@@ -57,34 +57,58 @@ func TestParse(t *testing.T) {
 	}
 }
 
+// The richer forms are tested in go-utils/time, just check that they get through.
+func TestParseRicher(t *testing.T) {
+	now := time.Date(2025, 3, 5, 14, 30, 0, 0, time.UTC)
+	x, err := ParseRelativeDateUtc(now, "now-2d3h", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !x.Equal(time.Date(2025, 3, 3, 11, 30, 0, 0, time.UTC)) {
+		t.Fatal("now-2d3h")
+	}
+
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err = ParseRelativeDate(now, "yesterday", true, oslo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Location() != time.UTC || !x.Equal(time.Date(2025, 3, 4, 22, 59, 59, 0, time.UTC)) {
+		t.Fatal("yesterday")
+	}
+}
+
 // These are feeble but at least test that something works
 func TestAdd(t *testing.T) {
 	var q time.Time
 	x, _ := ParseRelativeDateUtc(q, "2023-10-12", false)
-	z := time.Unix(AddHalfHour(x.Unix()), 0).UTC()
+	z := time.Unix(AddHalfHour(x.Unix(), time.UTC), 0).UTC()
 	if z.Hour() != 0 || z.Minute() != 30 {
 		t.Fatal("Half-hour")
 	}
-	z = time.Unix(AddHour(x.Unix()), 0).UTC()
+	z = time.Unix(AddHour(x.Unix(), time.UTC), 0).UTC()
 	if z.Hour() != 1 || z.Minute() != 0 {
 		t.Fatal("Hour")
 	}
-	z = time.Unix(AddHalfDay(x.Unix()), 0).UTC()
+	z = time.Unix(AddHalfDay(x.Unix(), time.UTC), 0).UTC()
 	if z.Hour() != 12 || z.Minute() != 0 {
 		t.Fatal("Half-day")
 	}
-	z = time.Unix(AddDay(x.Unix()), 0).UTC()
+	z = time.Unix(AddDay(x.Unix(), time.UTC), 0).UTC()
 	if z.Hour() != 0 || z.Minute() != 0 || z.Day() != 13 {
 		t.Fatal("Day")
 	}
-	z = time.Unix(AddWeek(x.Unix()), 0).UTC()
+	z = time.Unix(AddWeek(x.Unix(), time.UTC), 0).UTC()
 	if z.Hour() != 0 || z.Minute() != 0 || z.Day() != 19 {
 		t.Fatal("Day")
 	}
 }
 
 func TestTrunc(t *testing.T) {
-	z := time.Unix(TruncateToHalfHour(time.Now().Unix()), 0).UTC()
+	z := time.Unix(TruncateToHalfHour(time.Now().Unix(), time.UTC), 0).UTC()
 	if z.Second() != 0 || z.Nanosecond() != 0 {
 		t.Fatal("Fractions")
 	}
@@ -92,12 +116,12 @@ func TestTrunc(t *testing.T) {
 		t.Fatal("Half-hour")
 	}
 
-	z = time.Unix(TruncateToHour(time.Now().Unix()), 0).UTC()
+	z = time.Unix(TruncateToHour(time.Now().Unix(), time.UTC), 0).UTC()
 	if z.Second() != 0 || z.Nanosecond() != 0 || z.Minute() != 0 {
 		t.Fatal("Fractions")
 	}
 
-	z = time.Unix(TruncateToHalfDay(time.Now().Unix()), 0).UTC()
+	z = time.Unix(TruncateToHalfDay(time.Now().Unix(), time.UTC), 0).UTC()
 	if z.Second() != 0 || z.Nanosecond() != 0 || z.Minute() != 0 {
 		t.Fatal("Fractions")
 	}
@@ -105,7 +129,7 @@ func TestTrunc(t *testing.T) {
 		t.Fatal("Half-day")
 	}
 
-	z = time.Unix(TruncateToDay(time.Now().Unix()), 0).UTC()
+	z = time.Unix(TruncateToDay(time.Now().Unix(), time.UTC), 0).UTC()
 	if z.Second() != 0 || z.Nanosecond() != 0 || z.Minute() != 0 || z.Hour() != 0 {
 		t.Fatal("Fractions")
 	}
@@ -134,7 +158,7 @@ func assertTruncatesToMonday(t *testing.T, input, desired string) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	z := time.Unix(TruncateToWeek(tm.Unix()), 0).UTC()
+	z := time.Unix(TruncateToWeek(tm.Unix(), time.UTC), 0).UTC()
 	if z.Second() != 0 || z.Nanosecond() != 0 || z.Minute() != 0 || z.Hour() != 0 {
 		t.Fatal("Fractions")
 	}
@@ -146,3 +170,63 @@ func assertTruncatesToMonday(t *testing.T, input, desired string) {
 		t.Fatalf("Not the same! Desired=%s formatted=%s", desired, formatted)
 	}
 }
+
+func TestTimeZone(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// DST started at 02:00 local time on 2025-03-30, so that day has 23 hours.
+	x, err := ParseRelativeDate(time.Time{}, "2025-03-30", false, oslo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Location() != time.UTC || !x.Equal(time.Date(2025, 3, 29, 23, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Start of day %v", x)
+	}
+	y, _ := ParseRelativeDate(time.Time{}, "2025-03-30", true, oslo)
+	if !y.Equal(time.Date(2025, 3, 30, 21, 59, 59, 0, time.UTC)) {
+		t.Fatalf("End of day %v", y)
+	}
+	now := time.Date(2025, 3, 30, 12, 0, 0, 0, oslo)
+	z, _ := ParseRelativeDate(now, "1d", false, oslo)
+	if now.Sub(z) != 23*time.Hour {
+		t.Fatalf("Relative day %v", z)
+	}
+
+	noon := time.Date(2025, 3, 30, 12, 0, 0, 0, oslo).Unix()
+	day := TruncateToDay(noon, oslo)
+	if day != x.Unix() {
+		t.Fatalf("Truncate day %v", time.Unix(day, 0))
+	}
+	next := AddDay(day, oslo)
+	if next-day != 23*60*60 || next != TruncateToDay(next, oslo) {
+		t.Fatalf("Add day %v", time.Unix(next, 0))
+	}
+	if AddHalfDay(AddHalfDay(day, oslo), oslo) != next {
+		t.Fatalf("Add half day")
+	}
+	if AddHour(time.Date(2025, 3, 30, 1, 0, 0, 0, oslo).Unix(), oslo) !=
+		time.Date(2025, 3, 30, 3, 0, 0, 0, oslo).Unix() {
+		t.Fatalf("Add hour")
+	}
+	week := TruncateToWeek(noon, oslo)
+	if week != time.Date(2025, 3, 24, 0, 0, 0, 0, oslo).Unix() ||
+		AddWeek(week, oslo) != time.Date(2025, 3, 31, 0, 0, 0, 0, oslo).Unix() {
+		t.Fatalf("Week")
+	}
+	if TruncateToMonth(noon, oslo) != time.Date(2025, 3, 1, 0, 0, 0, 0, oslo).Unix() {
+		t.Fatalf("Month")
+	}
+
+	// The half-hour offset zones are aligned on local hours.
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := TruncateToHour(time.Date(2025, 3, 30, 10, 45, 0, 0, kolkata).Unix(), kolkata)
+	if h != time.Date(2025, 3, 30, 10, 0, 0, 0, kolkata).Unix() {
+		t.Fatalf("Kolkata hour %v", time.Unix(h, 0))
+	}
+}
@@ -144,6 +144,17 @@ func (h *Hosts) IsEmpty() bool {
 	return h.globber.IsEmpty()
 }
 
+// Return true if the set has patterns but they are all exclusions, so that it selects every host
+// that is not excluded.  Such a set can be matched against but not expanded.
+func (h *Hosts) OnlyExclusions() bool {
+	for _, p := range h.patterns {
+		if _, isExclusion := hostglob.CutExclusion(p); !isExclusion {
+			return false
+		}
+	}
+	return len(h.patterns) > 0
+}
+
 func (h *Hosts) Patterns() []string {
 	return h.patterns
 }
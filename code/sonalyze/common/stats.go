@@ -0,0 +1,102 @@
+package common
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// Multiply a quantity in KB by this to get GB.
+
+const KBToGB = 1.0 / (1024 * 1024)
+
+// Round x to the given number of decimal places.
+
+func RoundTo(x float64, decimals int) float64 {
+	scale := math.Pow10(decimals)
+	return math.Round(x*scale) / scale
+}
+
+// Nearest-rank percentile of a sorted nonempty slice: the smallest value such that at least `pct`
+// percent of the values are less than or equal to it.  `pct` must be in the range 0..100.
+
+func Percentile[T cmp.Ordered](sorted []T, pct int) T {
+	rank := (pct*len(sorted) + 99) / 100
+	if rank > 0 {
+		rank--
+	}
+	return sorted[rank]
+}
+
+// Least-squares linear fit y = a + b*x, accumulated one point at a time.  For precision, the x
+// values should be close to zero, eg, times relative to the first time.
+
+type LinearFit struct {
+	n, sx, sy, sxx, sxy float64
+}
+
+func (lf *LinearFit) Add(x, y float64) {
+	lf.n++
+	lf.sx += x
+	lf.sy += y
+	lf.sxx += x * x
+	lf.sxy += x * y
+}
+
+// Returns the intercept a and slope b, and false if there are fewer than two distinct x values.
+
+func (lf *LinearFit) Fit() (a, b float64, ok bool) {
+	d := lf.n*lf.sxx - lf.sx*lf.sx
+	if lf.n < 2 || d <= 0 {
+		return 0, 0, false
+	}
+	b = (lf.n*lf.sxy - lf.sx*lf.sy) / d
+	a = (lf.sy - b*lf.sx) / lf.n
+	return a, b, true
+}
+
+// Weighted mean of the values.  The slices must be nonempty and of the same length, and the weights
+// nonnegative.  If the total weight is zero, every value has weight 1.
+
+func WeightedMean(values, weights []float64) float64 {
+	var sum, total float64
+	for i, v := range values {
+		sum += v * weights[i]
+		total += weights[i]
+	}
+	if total == 0 {
+		for _, v := range values {
+			sum += v
+		}
+		total = float64(len(values))
+	}
+	return sum / total
+}
+
+// Weighted nearest-rank percentile: the smallest value such that the values less than or equal to
+// it have at least `pct` percent of the total weight.  The slices must be nonempty and of the same
+// length, and the weights nonnegative.  If the total weight is zero, every value has weight 1.
+
+func WeightedPercentile(values, weights []float64, pct int) float64 {
+	ix := make([]int, len(values))
+	var total float64
+	for i := range ix {
+		ix[i] = i
+		total += weights[i]
+	}
+	weight := func(i int) float64 { return weights[i] }
+	if total == 0 {
+		weight = func(int) float64 { return 1 }
+		total = float64(len(values))
+	}
+	slices.SortFunc(ix, func(a, b int) int { return cmp.Compare(values[a], values[b]) })
+	target := float64(pct) * total / 100
+	var sum float64
+	for _, i := range ix {
+		sum += weight(i)
+		if sum >= target {
+			return values[i]
+		}
+	}
+	return values[ix[len(ix)-1]]
+}
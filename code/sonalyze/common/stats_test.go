@@ -0,0 +1,80 @@
+package common
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRoundTo(t *testing.T) {
+	if r := RoundTo(2.345678, 2); r != 2.35 {
+		t.Errorf("RoundTo 2: got %v", r)
+	}
+	if r := RoundTo(-0.0125, 3); r != -0.013 {
+		t.Errorf("RoundTo 3: got %v", r)
+	}
+	if r := RoundTo(7.5, 0); r != 8 {
+		t.Errorf("RoundTo 0: got %v", r)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	xs := []int{15, 20, 35, 40, 50}
+	for _, c := range []struct{ pct, want int }{
+		{0, 15}, {5, 15}, {30, 20}, {40, 20}, {50, 35}, {90, 50}, {100, 50},
+	} {
+		if p := Percentile(xs, c.pct); p != c.want {
+			t.Errorf("Percentile %d: got %d want %d", c.pct, p, c.want)
+		}
+	}
+	if Percentile([]float64{3.5}, 99) != 3.5 {
+		t.Errorf("Singleton")
+	}
+}
+
+func TestLinearFit(t *testing.T) {
+	var lf LinearFit
+	if _, _, ok := lf.Fit(); ok {
+		t.Errorf("Empty fit")
+	}
+	lf.Add(1, 5)
+	lf.Add(1, 7)
+	if _, _, ok := lf.Fit(); ok {
+		t.Errorf("Degenerate fit")
+	}
+	lf = LinearFit{}
+	for _, p := range [][2]float64{{0, 1}, {1, 3.5}, {2, 4.5}, {3, 7}} {
+		lf.Add(p[0], p[1])
+	}
+	// y = 1.15 + 1.9x
+	a, b, ok := lf.Fit()
+	if !ok || math.Abs(a-1.15) > 1e-9 || math.Abs(b-1.9) > 1e-9 {
+		t.Errorf("Fit: got %v %v %v", a, b, ok)
+	}
+}
+
+func TestWeightedMean(t *testing.T) {
+	if m := WeightedMean([]float64{10, 20, 40}, []float64{1, 2, 1}); m != 22.5 {
+		t.Errorf("WeightedMean: got %v", m)
+	}
+	if m := WeightedMean([]float64{10, 20, 40}, []float64{0, 0, 0}); math.Abs(m-70.0/3) > 1e-9 {
+		t.Errorf("Zero weights: got %v", m)
+	}
+}
+
+func TestWeightedPercentile(t *testing.T) {
+	xs := []float64{40, 10, 30, 20}
+	ws := []float64{1, 1, 1, 7}
+	for _, c := range []struct {
+		pct  int
+		want float64
+	}{
+		{0, 10}, {10, 10}, {11, 20}, {50, 20}, {80, 20}, {81, 30}, {90, 30}, {99, 40}, {100, 40},
+	} {
+		if p := WeightedPercentile(xs, ws, c.pct); p != c.want {
+			t.Errorf("WeightedPercentile %d: got %v want %v", c.pct, p, c.want)
+		}
+	}
+	if p := WeightedPercentile(xs, []float64{0, 0, 0, 0}, 50); p != 20 {
+		t.Errorf("Zero weights: got %v", p)
+	}
+}
@@ -74,6 +74,17 @@ func (u Ustr) String() string {
 	return revTable[u]
 }
 
+// Ustr values are specific to the process, so a serialized Ustr is represented by its string.  These
+// methods make encoding/gob do that.
+func (u Ustr) GobEncode() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *Ustr) GobDecode(bs []byte) error {
+	*u = BytesToUstr(bs)
+	return nil
+}
+
 // Sort the ustrs ascending by their string representations.  This will not allocate new strings and
 // will not allocate at all if the sort does not.
 func UstrSortAscending(us []Ustr) {
@@ -14,43 +14,36 @@ package api0
 
 import (
 	"context"
-	"path"
-	"strings"
 
 	"github.com/danielgtaylor/huma/v2"
 
-	"go-utils/auth"
-	"sonalyze/cmd"
-	. "sonalyze/common"
 	"sonalyze/daemon/apiutil"
 )
 
 var (
-	jobanalyzerDir   string
-	databaseURI      string
-	cmdlineHandler   cmd.CommandLineHandler
-	getAuthenticator *auth.Authenticator
+	queryContext *apiutil.QueryContext
 )
 
 func SetupAPI(
 	api huma.API,
-	jobanalyzerDir_ string,
-	databaseURI_ string,
-	cmdlineHandler_ cmd.CommandLineHandler,
-	getAuthenticator_ *auth.Authenticator,
+	queryContext_ *apiutil.QueryContext,
 ) {
-	jobanalyzerDir = jobanalyzerDir_
-	databaseURI = databaseURI_
-	cmdlineHandler = cmdlineHandler_
-	getAuthenticator = getAuthenticator_
+	queryContext = queryContext_
 	grp := huma.NewGroup(api, "/api/v0")
 	// WHEN UPDATING THESE, ALSO UPDATE SWITCH IN ../../application/command.go and HELP TEXT IN THE
 	// SAME PLACE.
 	addCard(grp)
+	addCardusage(grp)
 	addCluster(grp)
+	addCompare(grp)
 	addConfig(grp)
+	addCoverage(grp)
 	addDiskprof(grp)
+	addDrift(grp)
+	addEfficiency(grp)
+	addEnergy(grp)
 	addGpu(grp)
+	addIdle(grp)
 	addJobs(grp)
 	addLoad(grp)
 	addMetadata(grp)
@@ -61,12 +54,13 @@ func SetupAPI(
 	addSample(grp)
 	addSnode(grp)
 	addSpart(grp)
+	addTop(grp)
 	addUptime(grp)
 	addVersion(grp)
+	addWaittime(grp)
 	// Omitting `add` because it was already obsolete; replaced by /api/v1/insert
 	// Omitting `parse` because that's the old name for `sample`
 	// Omitting `report` because it's obsolete, it was for dashboard-1
-	// Omitting `top` for now because it is very limited
 }
 
 // Query commands.
@@ -94,6 +88,27 @@ func addCard(api huma.API) {
 	})
 }
 
+func addCardusage(api huma.API) {
+	huma.Get(api, "/cardusage", func(
+		ctx context.Context,
+		input *struct {
+			apiutil.AuthHeader
+			SampleAnalysisParams
+			FormatParams
+			Gap string `query:"gap"`
+		},
+	) (*QueryResponse, error) {
+		return queryCommand(
+			"cardusage",
+			input.Auth,
+			append(
+				collectAll(&input.SampleAnalysisParams, &input.FormatParams),
+				collect("gap", input.Gap)...,
+			),
+		)
+	})
+}
+
 func addCluster(api huma.API) {
 	huma.Get(api, "/cluster", func(
 		ctx context.Context,
@@ -111,6 +126,28 @@ func addCluster(api huma.API) {
 	})
 }
 
+func addCompare(api huma.API) {
+	huma.Get(api, "/compare", func(
+		ctx context.Context,
+		input *struct {
+			apiutil.AuthHeader
+			SampleAnalysisParams
+			FormatParams
+			Step    string `query:"step"`
+			Summary string `query:"summary"`
+		},
+	) (*QueryResponse, error) {
+		return queryCommand(
+			"compare",
+			input.Auth,
+			append(
+				collectAll(&input.SampleAnalysisParams, &input.FormatParams),
+				collect("step", input.Step, "summary", input.Summary)...,
+			),
+		)
+	})
+}
+
 func addConfig(api huma.API) {
 	huma.Get(api, "/config", func(
 		ctx context.Context,
@@ -128,6 +165,33 @@ func addConfig(api huma.API) {
 	})
 }
 
+func addCoverage(api huma.API) {
+	huma.Get(api, "/coverage", func(
+		ctx context.Context,
+		input *struct {
+			apiutil.AuthHeader
+			HostAnalysisParams
+			FormatParams
+			Type      string `query:"type"`
+			GapFactor string `query:"gap-factor"`
+			Gaps      string `query:"gaps"`
+		},
+	) (*QueryResponse, error) {
+		return queryCommand(
+			"coverage",
+			input.Auth,
+			append(
+				collectAll(&input.HostAnalysisParams, &input.FormatParams),
+				collect(
+					"type", input.Type,
+					"gap-factor", input.GapFactor,
+					"gaps", input.Gaps,
+				)...,
+			),
+		)
+	})
+}
+
 func addDiskprof(api huma.API) {
 	huma.Get(api, "/diskprof", func(
 		ctx context.Context,
@@ -135,16 +199,91 @@ func addDiskprof(api huma.API) {
 			apiutil.AuthHeader
 			HostAnalysisParams
 			FormatParams
+			Rate string `query:"rate"`
 		},
 	) (*QueryResponse, error) {
 		return queryCommand(
 			"diskprof",
 			input.Auth,
+			append(
+				collectAll(&input.HostAnalysisParams, &input.FormatParams),
+				collect("rate", input.Rate)...,
+			),
+		)
+	})
+}
+
+func addDrift(api huma.API) {
+	huma.Get(api, "/drift", func(
+		ctx context.Context,
+		input *struct {
+			apiutil.AuthHeader
+			HostAnalysisParams
+			FormatParams
+		},
+	) (*QueryResponse, error) {
+		return queryCommand(
+			"drift",
+			input.Auth,
 			collectAll(&input.HostAnalysisParams, &input.FormatParams),
 		)
 	})
 }
 
+func addEfficiency(api huma.API) {
+	huma.Get(api, "/efficiency", func(
+		ctx context.Context,
+		input *struct {
+			apiutil.AuthHeader
+			SampleAnalysisParams
+			FormatParams
+			Account    string `query:"account"`
+			Partition  string `query:"partition"`
+			State      string `query:"state"`
+			MinRuntime string `query:"min-runtime"`
+			Rollup     string `query:"rollup"`
+			Headroom   string `query:"headroom"`
+		},
+	) (*QueryResponse, error) {
+		return queryCommand(
+			"efficiency",
+			input.Auth,
+			append(
+				collectAll(&input.SampleAnalysisParams, &input.FormatParams),
+				collect(
+					"account", input.Account,
+					"partition", input.Partition,
+					"state", input.State,
+					"min-runtime", input.MinRuntime,
+					"rollup", input.Rollup,
+					"headroom", input.Headroom,
+				)...,
+			),
+		)
+	})
+}
+
+func addEnergy(api huma.API) {
+	huma.Get(api, "/energy", func(
+		ctx context.Context,
+		input *struct {
+			apiutil.AuthHeader
+			HostAnalysisParams
+			FormatParams
+			By string `query:"by" doc:"Grouping key: job, user, account, node"`
+		},
+	) (*QueryResponse, error) {
+		return queryCommand(
+			"energy",
+			input.Auth,
+			append(
+				collectAll(&input.HostAnalysisParams, &input.FormatParams),
+				collect("by", input.By)...,
+			),
+		)
+	})
+}
+
 func addGpu(api huma.API) {
 	huma.Get(api, "/gpu", func(
 		ctx context.Context,
@@ -163,6 +302,37 @@ func addGpu(api huma.API) {
 	})
 }
 
+func addIdle(api huma.API) {
+	huma.Get(api, "/idle", func(
+		ctx context.Context,
+		input *struct {
+			apiutil.AuthHeader
+			SampleAnalysisParams
+			FormatParams
+			MinIdle string `query:"min-idle"`
+			MaxCpu  string `query:"max-cpu"`
+			MaxGpu  string `query:"max-gpu"`
+			MaxIo   string `query:"max-io"`
+			Check   string `query:"check"`
+		},
+	) (*QueryResponse, error) {
+		return queryCommand(
+			"idle",
+			input.Auth,
+			append(
+				collectAll(&input.SampleAnalysisParams, &input.FormatParams),
+				collect(
+					"min-idle", input.MinIdle,
+					"max-cpu", input.MaxCpu,
+					"max-gpu", input.MaxGpu,
+					"max-io", input.MaxIo,
+					"check", input.Check,
+				)...,
+			),
+		)
+	})
+}
+
 func addJobs(api huma.API) {
 	huma.Get(api, "/jobs", func(
 		ctx context.Context,
@@ -213,6 +383,34 @@ func addJobs(api huma.API) {
 			MinGpumemPeak  string `query:"min-gpumem-peak"`
 			MinRgpumemAvg  string `query:"min-rgpumem-avg"`
 			MinRgpumemPeak string `query:"min-rgpumem-peak"`
+			MinCpuTwavg    string `query:"min-cpu-twavg"`
+			MaxCpuTwavg    string `query:"max-cpu-twavg"`
+			MinCpuP50      string `query:"min-cpu-p50"`
+			MaxCpuP50      string `query:"max-cpu-p50"`
+			MinCpuP90      string `query:"min-cpu-p90"`
+			MaxCpuP90      string `query:"max-cpu-p90"`
+			MinCpuP99      string `query:"min-cpu-p99"`
+			MaxCpuP99      string `query:"max-cpu-p99"`
+			MinMemTwavg    string `query:"min-mem-twavg"`
+			MinMemP50      string `query:"min-mem-p50"`
+			MinMemP90      string `query:"min-mem-p90"`
+			MinMemP99      string `query:"min-mem-p99"`
+			MinResTwavg    string `query:"min-res-twavg"`
+			MinResP50      string `query:"min-res-p50"`
+			MinResP90      string `query:"min-res-p90"`
+			MinResP99      string `query:"min-res-p99"`
+			MinGpuTwavg    string `query:"min-gpu-twavg"`
+			MaxGpuTwavg    string `query:"max-gpu-twavg"`
+			MinGpuP50      string `query:"min-gpu-p50"`
+			MaxGpuP50      string `query:"max-gpu-p50"`
+			MinGpuP90      string `query:"min-gpu-p90"`
+			MaxGpuP90      string `query:"max-gpu-p90"`
+			MinGpuP99      string `query:"min-gpu-p99"`
+			MaxGpuP99      string `query:"max-gpu-p99"`
+			MinGpumemTwavg string `query:"min-gpumem-twavg"`
+			MinGpumemP50   string `query:"min-gpumem-p50"`
+			MinGpumemP90   string `query:"min-gpumem-p90"`
+			MinGpumemP99   string `query:"min-gpumem-p99"`
 			FormatParams
 		},
 	) (*QueryResponse, error) {
@@ -266,6 +464,34 @@ func addJobs(api huma.API) {
 					"min-gpumem-peak", input.MinGpumemPeak,
 					"min-rgpumem-avg", input.MinRgpumemAvg,
 					"min-rgpumem-peak", input.MinRgpumemPeak,
+					"min-cpu-twavg", input.MinCpuTwavg,
+					"max-cpu-twavg", input.MaxCpuTwavg,
+					"min-cpu-p50", input.MinCpuP50,
+					"max-cpu-p50", input.MaxCpuP50,
+					"min-cpu-p90", input.MinCpuP90,
+					"max-cpu-p90", input.MaxCpuP90,
+					"min-cpu-p99", input.MinCpuP99,
+					"max-cpu-p99", input.MaxCpuP99,
+					"min-mem-twavg", input.MinMemTwavg,
+					"min-mem-p50", input.MinMemP50,
+					"min-mem-p90", input.MinMemP90,
+					"min-mem-p99", input.MinMemP99,
+					"min-res-twavg", input.MinResTwavg,
+					"min-res-p50", input.MinResP50,
+					"min-res-p90", input.MinResP90,
+					"min-res-p99", input.MinResP99,
+					"min-gpu-twavg", input.MinGpuTwavg,
+					"max-gpu-twavg", input.MaxGpuTwavg,
+					"min-gpu-p50", input.MinGpuP50,
+					"max-gpu-p50", input.MaxGpuP50,
+					"min-gpu-p90", input.MinGpuP90,
+					"max-gpu-p90", input.MaxGpuP90,
+					"min-gpu-p99", input.MinGpuP99,
+					"max-gpu-p99", input.MaxGpuP99,
+					"min-gpumem-twavg", input.MinGpumemTwavg,
+					"min-gpumem-p50", input.MinGpumemP50,
+					"min-gpumem-p90", input.MinGpumemP90,
+					"min-gpumem-p99", input.MinGpumemP99,
 				)...,
 			),
 		)
@@ -522,6 +748,27 @@ func addSpart(api huma.API) {
 	})
 }
 
+func addTop(api huma.API) {
+	huma.Get(api, "/top", func(
+		ctx context.Context,
+		input *struct {
+			apiutil.AuthHeader
+			HostAnalysisParams
+			FormatParams
+			Job string `query:"job"`
+		},
+	) (*QueryResponse, error) {
+		return queryCommand(
+			"top",
+			input.Auth,
+			append(
+				collectAll(&input.HostAnalysisParams, &input.FormatParams),
+				collect("job", input.Job)...,
+			),
+		)
+	})
+}
+
 func addUptime(api huma.API) {
 	huma.Get(api, "/uptime", func(
 		ctx context.Context,
@@ -560,67 +807,34 @@ func addVersion(api huma.API) {
 	})
 }
 
+func addWaittime(api huma.API) {
+	huma.Get(api, "/waittime", func(
+		ctx context.Context,
+		input *struct {
+			apiutil.AuthHeader
+			HostAnalysisParams
+			FormatParams
+			WaittimeParams
+		},
+	) (*QueryResponse, error) {
+		return queryCommand(
+			"waittime",
+			input.Auth,
+			collectAll(&input.HostAnalysisParams, &input.FormatParams, &input.WaittimeParams),
+		)
+	})
+}
+
 // Run a query command.
 //
 // This must return `error` to be API compatible with Huma, but the error return is always a
 // huma.StatusError.
 
 func queryCommand(command, auth string, params []string) (*QueryResponse, error) {
-	verbose := Verbose
-	if getAuthenticator != nil {
-		user, pass := apiutil.DecodeAuth(auth)
-		if !getAuthenticator.Authenticate(user, pass) {
-			return nil, huma.Error401Unauthorized(command + ": Unknown user/pass combination")
-		}
-	}
-	// not normally what we want but handy for debugging
-	// if verbose && auth != "" {
-	// 	Log.Infof("Auth: %q", auth)
-	// }
-	if jobanalyzerDir != "" {
-		params = append(params, "-jobanalyzer-dir", jobanalyzerDir)
-	}
-	if databaseURI != "" {
-		params = append(params, "-database-uri", databaseURI)
-	}
-	// not normally what we want but handy for debugging
-	// if verbose {
-	// 	params = append(params, "-v")
-	// }
-	cmdName := "<sonalyze>"
-	if verbose {
-		Log.Infof(
-			"Command: %s %s",
-			path.Join(jobanalyzerDir, cmdName),
-			command+" "+strings.Join(params, " "),
-		)
-	}
-
-	anyCmd, _ := cmdlineHandler.ParseVerb(cmdName, command)
-	if anyCmd == nil {
-		return nil, huma.Error500InternalServerError(command + ": Unknown")
-	}
-	fs := cmd.NewCLI(command, anyCmd, cmdName, false)
-	err := cmdlineHandler.ParseArgs(command, params, anyCmd, fs)
-	if err != nil {
-		return nil, huma.Error400BadRequest(command + ": " + err.Error())
-	}
-
-	// The -cpuprofile option is ignored here, it should have forced ParseArgs to error out.
-
-	var stdoutBuf, stderrBuf strings.Builder
-	err = cmdlineHandler.HandleCommand(anyCmd, nil, &stdoutBuf, &stderrBuf)
-	// In HandleCommand, the command line parser overrides the global setting.
-	Verbose = verbose
-	stdout := stdoutBuf.String()
-	stderr := stderrBuf.String()
+	stdout, err := queryContext.RunCommand(command, auth, params)
 	if err != nil {
-		return nil, huma.Error400BadRequest(command + ": " + err.Error())
+		return nil, err
 	}
-	if stderr != "" {
-		Log.Warningf(stderr, "")
-	}
-
 	return &QueryResponse{Body: stdout}, nil
 }
 
@@ -635,16 +849,17 @@ type Collectable interface {
 }
 
 type ClusterParams struct {
-	Cluster string `query:"cluster"`
+	Cluster  string `query:"cluster"`
+	TimeZone string `query:"tz" doc:"IANA time zone name"`
 }
 
 func (x *ClusterParams) Collect() []string {
-	return collect("cluster", x.Cluster)
+	return collect("cluster", x.Cluster, "tz", x.TimeZone)
 }
 
 type SourceParams struct {
-	FromDate string `query:"from" doc:"ISO time stamp"`
-	ToDate   string `query:"to" doc:"ISO time stamp"`
+	FromDate string `query:"from" doc:"Time expression as for the -from option"`
+	ToDate   string `query:"to" doc:"Time expression as for the -to option"`
 }
 
 func (x *SourceParams) Collect() []string {
@@ -720,6 +935,34 @@ func (x *FormatParams) Collect() []string {
 	return collect("fmt", x.Fmt)
 }
 
+// The waittime parameters are shared with the v1 API.
+
+type WaittimeParams struct {
+	States     string `query:"state"`
+	Users      string `query:"user"`
+	Accounts   string `query:"account"`
+	Partitions string `query:"partition"`
+	GpuTypes   string `query:"gpu-type"`
+	SomeGPU    string `query:"some-gpu"`
+	NoGPU      string `query:"no-gpu"`
+	By         string `query:"by" doc:"Comma-separated grouping keys: partition, gpu-type, size"`
+	Bucket     string `query:"bucket" doc:"Time bucket: none, day, week, month"`
+}
+
+func (x *WaittimeParams) Collect() []string {
+	return collect(
+		"state", x.States,
+		"user", x.Users,
+		"account", x.Accounts,
+		"partition", x.Partitions,
+		"gpu-type", x.GpuTypes,
+		"some-gpu", x.SomeGPU,
+		"no-gpu", x.NoGPU,
+		"by", x.By,
+		"bucket", x.Bucket,
+	)
+}
+
 type GpuIndexParam struct {
 	Gpu string `query:"gpu"`
 }
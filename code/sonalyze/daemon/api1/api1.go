@@ -15,23 +15,27 @@ import (
 	"github.com/NordicHPC/sonar/util/formats/newfmt"
 	"github.com/danielgtaylor/huma/v2"
 
+	"sonalyze/daemon/api0"
 	"sonalyze/daemon/apiutil"
 	"sonalyze/db"
 )
 
 var (
+	queryContext      *apiutil.QueryContext
 	postAuthenticator *auth.Authenticator
 )
 
 func SetupAPI(
 	api huma.API,
+	queryContext_ *apiutil.QueryContext,
 	insertAPI bool,
 	postAuthenticator_ *auth.Authenticator,
 ) {
+	queryContext = queryContext_
 	postAuthenticator = postAuthenticator_
 	grp := huma.NewGroup(api, "/api/v1")
 
-	// v1 get apis go here, when we've implemented them
+	addWaittime(grp)
 
 	if insertAPI {
 		addInsertSysinfoData(grp)
@@ -41,6 +45,53 @@ func SetupAPI(
 	}
 }
 
+// Query commands.
+//
+// The v1 query commands run the same commands as the v0 API but with native-JSON output, and
+// return that output as a JSON value.  The `fmt` parameter is a list of fields, optionally with
+// other format options; it must not select a non-JSON output format.
+
+type QueryResponse struct {
+	Body json.RawMessage
+}
+
+func addWaittime(api huma.API) {
+	huma.Get(api, "/waittime", func(
+		ctx context.Context,
+		input *struct {
+			apiutil.AuthHeader
+			api0.HostAnalysisParams
+			Fmt string `query:"fmt" doc:"Comma-separated field names"`
+			api0.WaittimeParams
+		},
+	) (*QueryResponse, error) {
+		return queryCommand(
+			"waittime",
+			input.Auth,
+			input.Fmt,
+			append(input.HostAnalysisParams.Collect(), input.WaittimeParams.Collect()...),
+		)
+	})
+}
+
+// This must return `error` to be API compatible with Huma, but the error return is always a
+// huma.StatusError.
+
+func queryCommand(command, auth, fields string, params []string) (*QueryResponse, error) {
+	format := "native"
+	if fields != "" {
+		format += "," + fields
+	}
+	stdout, err := queryContext.RunCommand(command, auth, append(params, "-fmt="+format))
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid([]byte(stdout)) {
+		return nil, huma.Error400BadRequest(command + ": Output is not JSON, check the fmt parameter")
+	}
+	return &QueryResponse{Body: json.RawMessage(stdout)}, nil
+}
+
 // Insertion.
 //
 // Sonar does not require a specific return structure beyond the HTTP code.  Here, on successful
@@ -193,7 +244,13 @@ func insertionSetup(path, cluster, auth string) (db.AppendablePersistentDataProv
 	if hErr != nil {
 		return nil, hErr
 	}
-	ds, err := db.OpenAppendablePersistentDirectoryDB(meta)
+	var ds db.AppendablePersistentDataProvider
+	var err error
+	if meta.HaveDatabaseConnection() {
+		ds = db.OpenConnectedDB(meta)
+	} else {
+		ds, err = db.OpenAppendablePersistentDirectoryDB(meta)
+	}
 	if err != nil {
 		return nil, huma.Error500InternalServerError("insert: incompatible database")
 	}
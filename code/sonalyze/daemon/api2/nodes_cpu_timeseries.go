@@ -42,7 +42,7 @@ func handleNodesCpuTimeseries(
 		StartTimeInS  uint64 `query:"start_time_in_s" doc:"Posix timestamp"`
 		EndTimeInS    uint64 `query:"end_time_in_s" doc:"Posix timestamp"`
 		ResolutionInS uint64 `query:"resolution_in_s" doc:"Default is 300"`
-		Nodenames     string `query:"nodename" doc:"Compressed node name list"`
+		Nodenames     string `query:"nodename" doc:"Compressed node name list, with !exclusions and @groups"`
 	},
 ) (*NodesCpuTimeseriesResponse, error) {
 	prof, hErr := computeProfile(
@@ -56,7 +56,7 @@ func handleNodesInfo(
 	ctx context.Context,
 	input *struct {
 		Cluster  string `path:"cluster" example:"my.cluster.name" doc:"Name of cluster"`
-		Nodename string `query:"nodename" doc:"Compressed node name list"`
+		Nodename string `query:"nodename" doc:"Compressed node name list, with !exclusions and @groups"`
 		TimeInS  uint64 `query:"time_in_s" doc:"Posix timestamp"`
 	},
 ) (*NodesInfoResponse, error) {
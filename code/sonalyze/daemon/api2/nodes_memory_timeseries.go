@@ -37,7 +37,7 @@ func handleNodesMemoryTimeseries(
 		StartTimeInS  uint64 `query:"start_time_in_s" doc:"Posix timestamp"`
 		EndTimeInS    uint64 `query:"end_time_in_s" doc:"Posix timestamp"`
 		ResolutionInS uint64 `query:"resolution_in_s" doc:"Default is 300"`
-		Nodename      string `query:"nodename" doc:"Compressed node name list"`
+		Nodename      string `query:"nodename" doc:"Compressed node name list, with !exclusions and @groups"`
 	},
 ) (*NodesMemoryTimeseriesResponse, error) {
 	prof, hErr := computeProfile(
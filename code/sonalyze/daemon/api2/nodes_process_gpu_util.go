@@ -52,7 +52,7 @@ func handleNodesProcessGpuUtil(
 	ctx context.Context,
 	input *struct {
 		Cluster          string `path:"cluster" example:"my.cluster.name" doc:"Name of cluster"`
-		Nodename         string `query:"nodename" doc:"Compressed node name list"`
+		Nodename         string `query:"nodename" doc:"Compressed node name list, with !exclusions and @groups"`
 		ReferenceTimeInS uint64 `query:"reference_time_in_s" doc:"Center point of averaging interval"`
 		WindowInS        uint64 `query:"window_in_s" doc:"Width of averaging interval, default 300"`
 	},
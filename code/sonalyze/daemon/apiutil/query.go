@@ -0,0 +1,84 @@
+// Running query commands in-process on behalf of the GET APIs.
+
+package apiutil
+
+import (
+	"path"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"go-utils/auth"
+	"sonalyze/cmd"
+	. "sonalyze/common"
+)
+
+type QueryContext struct {
+	JobanalyzerDir   string
+	DatabaseURI      string
+	CmdlineHandler   cmd.CommandLineHandler
+	GetAuthenticator *auth.Authenticator
+}
+
+// Run a query command and return its output.
+//
+// The error return is always a huma.StatusError.
+
+func (qc *QueryContext) RunCommand(command, auth string, params []string) (string, error) {
+	verbose := Verbose
+	if qc.GetAuthenticator != nil {
+		user, pass := DecodeAuth(auth)
+		if !qc.GetAuthenticator.Authenticate(user, pass) {
+			return "", huma.Error401Unauthorized(command + ": Unknown user/pass combination")
+		}
+	}
+	// not normally what we want but handy for debugging
+	// if verbose && auth != "" {
+	// 	Log.Infof("Auth: %q", auth)
+	// }
+	if qc.JobanalyzerDir != "" {
+		params = append(params, "-jobanalyzer-dir", qc.JobanalyzerDir)
+	}
+	if qc.DatabaseURI != "" {
+		params = append(params, "-database-uri", qc.DatabaseURI)
+	}
+	// not normally what we want but handy for debugging
+	// if verbose {
+	// 	params = append(params, "-v")
+	// }
+	cmdName := "<sonalyze>"
+	if verbose {
+		Log.Infof(
+			"Command: %s %s",
+			path.Join(qc.JobanalyzerDir, cmdName),
+			command+" "+strings.Join(params, " "),
+		)
+	}
+
+	anyCmd, _ := qc.CmdlineHandler.ParseVerb(cmdName, command)
+	if anyCmd == nil {
+		return "", huma.Error500InternalServerError(command + ": Unknown")
+	}
+	fs := cmd.NewCLI(command, anyCmd, cmdName, false)
+	err := qc.CmdlineHandler.ParseArgs(command, params, anyCmd, fs)
+	if err != nil {
+		return "", huma.Error400BadRequest(command + ": " + err.Error())
+	}
+
+	// The -cpuprofile option is ignored here, it should have forced ParseArgs to error out.
+
+	var stdoutBuf, stderrBuf strings.Builder
+	err = qc.CmdlineHandler.HandleCommand(anyCmd, nil, &stdoutBuf, &stderrBuf)
+	// In HandleCommand, the command line parser overrides the global setting.
+	Verbose = verbose
+	stdout := stdoutBuf.String()
+	stderr := stderrBuf.String()
+	if err != nil {
+		return "", huma.Error400BadRequest(command + ": " + err.Error())
+	}
+	if stderr != "" {
+		Log.Warningf(stderr, "")
+	}
+
+	return stdout, nil
+}
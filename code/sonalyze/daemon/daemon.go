@@ -23,7 +23,10 @@
 // -database-uri <uri>
 //
 //  If present, this specifies a database access point.  The database is used for data access rather
-//  than the data/ subdirectory of the jobanalyzer directory.
+//  than the data/ subdirectory of the jobanalyzer directory.  A URI of the form sqlite:///path names
+//  an SQLite database file, which is created if it does not exist and can be inserted into; the
+//  clusters are those that have data in the database and those that have config files.  Any other
+//  URI names a TimescaleDB database, which is read-only.
 //
 // -analysis-auth <filename>
 // -password-file <filename>
@@ -45,6 +48,20 @@
 //   (megabytes) or nnG (gigabytes).  A sensible size *might* be about 256MB per 100 (slurm) nodes
 //   per week.
 //
+// -disk-cache <directory>
+//
+//   Also keep parsed data files in serialized form in the named directory, which is created if it
+//   does not exist.  The entries persist across restarts of the daemon, so that data files need not
+//   be parsed again after a restart.  It is always safe to remove the directory while the daemon is
+//   not running.
+//
+// -purge-horizon <days>
+//
+//   Once an hour, drop the in-memory metadata for data directories more than this many days old,
+//   so that a long-running daemon does not keep the metadata for the entire history of every
+//   cluster.  The data are not removed from disk and the metadata are read again if the data are
+//   needed.  By default nothing is dropped.
+//
 // -kafka <broker-address>
 //
 //   EXPERIMENTAL.  The daemon will attempt to ingest data over a unencrypted and unauthenticated
@@ -84,8 +101,8 @@
 // -insert
 //
 //   Enable the /api/v1/insert points in the REST API.  Normally this API is enabled only when
-//   running without a -database-uri and without -kafka (though it is not incompatible with the
-//   latter).
+//   running without a -database-uri (or with an SQLite -database-uri) and without -kafka (though it
+//   is not incompatible with the latter).
 //
 // Termination:
 //
@@ -113,6 +130,7 @@ import (
 
 	"go-utils/auth"
 	. "sonalyze/cmd"
+	"sonalyze/db"
 )
 
 const (
@@ -134,6 +152,7 @@ type DaemonCommand struct {
 	kafkaBroker   string
 	consumerGroup string
 	restAPI       string
+	purgeHorizon  uint
 	insert        bool
 	v0            bool
 	v1            bool
@@ -161,6 +180,8 @@ func (dc *DaemonCommand) Add(fs *CLI) {
 	fs.StringVar(&dc.kafkaBroker, "kafka", "", "Ingest data from this `broker` for all known clusters")
 	fs.StringVar(&dc.consumerGroup, "kafka-group", defaultKafkaGroup, "Kafka consumer `group name`")
 	fs.StringVar(&dc.restAPI, "rest-api", "", "Serve /api/v0, /api/v1 and /api/v2 on this interface:port")
+	fs.UintVar(&dc.purgeHorizon, "purge-horizon", 0,
+		"Drop in-memory metadata for data more than this many `days` old [default: never]")
 	fs.BoolVar(&dc.insert, "insert", false, "Enable the /api/v1/insert points")
 	fs.BoolVar(&dc.v0, "v0", false, "Enable the v0 API")
 	fs.BoolVar(&dc.v1, "v1", false, "Enable the v1 API")
@@ -195,8 +216,8 @@ func (dc *DaemonCommand) Validate() error {
 			return fmt.Errorf("Failed to read upload authentication file: %v", err)
 		}
 	}
-	if dc.insert && dc.DatabaseURI() != "" {
-		return fmt.Errorf("Can't have both -database-uri and -insert")
+	if dc.insert && dc.DatabaseURI() != "" && !db.IsSqliteURI(dc.DatabaseURI()) {
+		return fmt.Errorf("Can't have both -database-uri and -insert, unless the database is SQLite")
 	}
 	if dc.consumerGroup != defaultKafkaGroup && dc.kafkaBroker == "" {
 		return fmt.Errorf("Can't have -kafka-group without -kafka")
@@ -31,6 +31,8 @@ func (dc *DaemonCommand) RunDaemon(_ io.Reader, _, stderr io.Writer) error {
 	}
 	Log.SetUnderlying(logger)
 
+	db.SetPurgeHorizon(dc.purgeHorizon)
+
 	if dc.kafkaBroker != "" {
 		for _, cl := range special.AllClusters() {
 			meta := db.NewContextFromCluster(cl)
@@ -56,18 +58,19 @@ func (dc *DaemonCommand) RunDaemon(_ io.Reader, _, stderr io.Writer) error {
 
 	if dc.restAPI != "" {
 		api := apiutil.CreateAPI(dc.restAPI)
+		queryContext := &apiutil.QueryContext{
+			JobanalyzerDir:   dc.JobanalyzerDir(),
+			DatabaseURI:      dc.DatabaseURI(),
+			CmdlineHandler:   dc.cmdlineHandler,
+			GetAuthenticator: dc.getAuthenticator,
+		}
 		if dc.v0 {
-			api0.SetupAPI(
-				api,
-				dc.JobanalyzerDir(),
-				dc.DatabaseURI(),
-				dc.cmdlineHandler,
-				dc.getAuthenticator,
-			)
+			api0.SetupAPI(api, queryContext)
 		}
 		if dc.v1 {
 			api1.SetupAPI(
 				api,
+				queryContext,
 				dc.insert,
 				dc.postAuthenticator,
 			)
@@ -5,19 +5,94 @@ import (
 	"strings"
 	"time"
 
+	"go-utils/config"
+	"go-utils/hostglob"
+
 	. "sonalyze/common"
 )
 
 // If any h pattern contains * then it is resolved against the available hosts here.
+//
+// Node groups (@name) are replaced by the group's hosts from the cluster config.  If there are
+// exclusions (!pattern) and also other patterns then the patterns are expanded and the excluded
+// hosts removed, so that the resulting Hosts holds only plain names.  If there are only
+// exclusions they are kept in the Hosts, whose globber will then match every host not excluded.
 
 func ResolveHostQuery(meta any, h HostQuery, from, to time.Time) (Hosts, error) {
+	patterns, err := resolveGroups(meta, h.Patterns)
+	if err != nil {
+		return Hosts{}, err
+	}
+	if len(patterns) == 0 && len(h.Patterns) > 0 {
+		return Hosts{}, errors.New("The node groups are empty")
+	}
+
 	// FIXME: Implement this properly for wildcards by querying AvailableHosts() as in the config
 	// code and then filtering against those names.  For now, just return error if * is used.
-	for _, p := range h.Patterns {
+	// Exclusions are only ever matched against, so they may have wildcards.
+	var haveExclusions, haveInclusions bool
+	for _, p := range patterns {
+		if _, isExclusion := hostglob.CutExclusion(p); isExclusion {
+			haveExclusions = true
+			continue
+		}
+		haveInclusions = true
 		if strings.IndexByte(p, '*') != -1 {
 			return Hosts{}, errors.New("Wildcards not currently allowed: " + p)
 		}
 	}
 
-	return NewHostsFromPatterns(h.Patterns...)
+	if haveExclusions && haveInclusions {
+		names, err := hostglob.ExpandPatterns(patterns)
+		if err != nil {
+			return Hosts{}, err
+		}
+		// An empty Hosts means all hosts, which is the opposite of what was asked for.
+		if len(names) == 0 {
+			return Hosts{}, errors.New("No hosts are left after the exclusions")
+		}
+		return NewHostsFromPatterns(names...)
+	}
+
+	return NewHostsFromPatterns(patterns...)
+}
+
+type groupConfig interface {
+	HaveConfig() bool
+	Config() *config.ClusterConfig
+}
+
+func resolveGroups(meta any, patterns []string) ([]string, error) {
+	var result []string
+	for i, p := range patterns {
+		item, isExclusion := hostglob.CutExclusion(p)
+		name, isGroup := hostglob.CutGroup(item)
+		if !isGroup {
+			if result != nil {
+				result = append(result, p)
+			}
+			continue
+		}
+		if result == nil {
+			result = append(make([]string, 0, len(patterns)), patterns[:i]...)
+		}
+		var hosts []string
+		var found bool
+		if gc, ok := meta.(groupConfig); ok && gc.HaveConfig() {
+			hosts, found = gc.Config().LookupGroup(name)
+		}
+		if !found {
+			return nil, errors.New("Unknown node group: " + name)
+		}
+		for _, hn := range hosts {
+			if isExclusion {
+				hn = "!" + hn
+			}
+			result = append(result, hn)
+		}
+	}
+	if result == nil {
+		return patterns, nil
+	}
+	return result, nil
 }
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go-utils/config"
+
+	. "sonalyze/common"
+)
+
+type groupMeta struct {
+	cfg *config.ClusterConfig
+}
+
+func (m *groupMeta) HaveConfig() bool              { return true }
+func (m *groupMeta) Config() *config.ClusterConfig { return m.cfg }
+
+func TestResolveHostQuery(t *testing.T) {
+	cfg, err := config.ReadConfigFrom(strings.NewReader(
+		`{"name":"x", "nodes":[], "groups":{"a100":"gpu-[1-4]", "none":"gpu-1,!gpu-1*"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := &groupMeta{cfg}
+	resolve := func(s string) (Hosts, error) {
+		q, err := NewHostQueryFromMultiPatterns(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ResolveHostQuery(meta, q, time.Time{}, time.Time{})
+	}
+
+	h, err := resolve("gpu-[1-20],!gpu-13,!@a100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.CanonicalName() != "gpu-[5-12,14-20]" {
+		t.Fatalf("Exclusion %s", h.CanonicalName())
+	}
+
+	h, err = resolve("@a100,c1-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := h.HostnameGlobber()
+	if !g.Match("gpu-3") || !g.Match("c1-1") || g.Match("gpu-5") {
+		t.Fatal("Group")
+	}
+
+	h, err = resolve("!gpu-13")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g = h.HostnameGlobber()
+	if !h.OnlyExclusions() || g.Match("gpu-13") || !g.Match("gpu-12") {
+		t.Fatal("Only exclusions")
+	}
+
+	for _, bad := range []string{"@nope", "@none", "gpu-1,!gpu-1", "gpu-*"} {
+		if _, err := resolve(bad); err == nil {
+			t.Fatalf("Should fail: %s", bad)
+		}
+	}
+}
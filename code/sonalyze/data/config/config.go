@@ -157,7 +157,7 @@ func (cdp *ConfigDataProvider) LookupSingleHostByTime(host string, t int64) *rep
 
 	// Fallback code to old-style static node config, this will likely disappear.
 	if cdp.meta.HaveConfig() {
-		return cdp.meta.Config().LookupHost(host)
+		return cdp.meta.Config().LookupHostByTime(host, t)
 	}
 
 	return nil
@@ -197,14 +197,31 @@ func (cdb *ConfigDataProvider) AvailableHosts(fromDate, toDate time.Time) (map[s
 				nodenames[record.Node] = true
 			}
 		}
-	} else {
-		for _, node := range cdb.meta.NodesDefinedInConfigIfAny() {
-			nodenames[node.Hostname] = true
+	} else if cdb.meta.HaveConfig() {
+		for _, hostname := range cdb.meta.Config().HostsDefinedInTimeWindow(
+			fromDate.Unix(),
+			toDate.Unix()+1,
+		) {
+			nodenames[hostname] = true
 		}
 	}
 	return nodenames, nil
 }
 
+// A record from the static config is timestamped with its own timestamp if it has one, otherwise
+// with the start of its validity period, so that the newest record can be found.
+
+func staticNodeConfig(r *repr.NodeSummary) *NodeConfig {
+	nc := &NodeConfig{NodeSummary: *r}
+	if nc.Timestamp == "" {
+		nc.Timestamp = r.From
+	}
+	if t, err := time.Parse(time.RFC3339, nc.Timestamp); err == nil {
+		nc.Time = t.Unix()
+	}
+	return nc
+}
+
 type QueryFilter = common.QueryFilter
 
 type QueryArgs struct {
@@ -217,14 +234,21 @@ type QueryArgs struct {
 // time-invariant config file, that was not necessary, but now it is.
 //
 // As for all other query operators, if the host set is empty (the common case) then we find all
-// hosts that have data in the time range.
+// hosts that have data in the time range.  If the host set has only exclusions then we find all
+// those hosts that are not excluded.
 
 func (cdp *ConfigDataProvider) Query(qa QueryArgs) ([]*NodeConfig, error) {
-	if qa.Host.IsEmpty() {
+	if qa.Host.IsEmpty() || qa.Host.OnlyExclusions() {
 		hosts, err := cdp.AvailableHosts(qa.FromDate, qa.ToDate)
 		if err != nil {
 			return nil, err
 		}
+		if qa.Host.OnlyExclusions() {
+			globber := qa.Host.HostnameGlobber()
+			maps.DeleteFunc(hosts, func(hn string, _ bool) bool {
+				return !globber.Match(hn)
+			})
+		}
 		qa.Host = NewHostsFromSingle(slices.Collect(maps.Keys(hosts))...)
 	}
 
@@ -239,13 +263,16 @@ func (cdp *ConfigDataProvider) Query(qa QueryArgs) ([]*NodeConfig, error) {
 
 	records := cdp.obtainAllFromCache(qa)
 
-	// Fallback code to old-style static node config, this will likely disappear.
+	// Fallback code to old-style static node config, this will likely disappear.  A node may have
+	// several configurations in the time window if the config has a time dimension.
 	if len(records) == 0 && cdp.meta.HaveConfig() {
 		for host := range qa.Host.ExpandNames() {
-			if probe := cdp.meta.Config().LookupHost(host); probe != nil {
-				records = append(records, &NodeConfig{
-					NodeSummary: *probe,
-				})
+			for _, probe := range cdp.meta.Config().LookupHostInTimeWindow(
+				host,
+				qa.FromDate.Unix(),
+				qa.ToDate.Unix()+1,
+			) {
+				records = append(records, staticNodeConfig(probe))
 			}
 		}
 	}
@@ -13,6 +13,7 @@ package sample
 import (
 	"math"
 	"slices"
+	"time"
 
 	"go-utils/gpuset"
 	"go-utils/maps"
@@ -224,6 +225,34 @@ func MergeByJob(streams InputStreamSet, bounds Timebounds) ([]MergedJob, Timebou
 	return newStreams, newBounds
 }
 
+// Merge the streams of Slurm jobs (epoch zero), whose job IDs are cluster-wide, across nodes as by
+// MergeByJob; the remaining streams are merged per node as by MergeByHostAndJob, and the two sets of
+// merged jobs are combined into one set.
+
+func MergeSlurmByJob(streams InputStreamSet, bounds Timebounds) ([]MergedJob, Timebounds) {
+	mergeable := make(InputStreamSet)
+	mBounds := make(Timebounds)
+	solo := make(InputStreamSet)
+	sBounds := make(Timebounds)
+	for k, v := range streams {
+		bound := bounds[k.Host]
+		if (*v)[0].Epoch == 0 {
+			mBounds[k.Host] = bound
+			mergeable[k] = v
+		} else {
+			sBounds[k.Host] = bound
+			solo[k] = v
+		}
+	}
+	mergedJobs, mergedBounds := MergeByJob(mergeable, mBounds)
+	otherJobs := MergeByHostAndJob(solo)
+	mergedJobs = append(mergedJobs, otherJobs...)
+	for k, v := range sBounds {
+		mergedBounds[k] = v
+	}
+	return mergedJobs, mergedBounds
+}
+
 // Merge streams that have the same host (across jobs) into synthesized data.
 //
 // Each output stream is sorted ascending by timestamp.  No two records have exactly the same time.
@@ -696,24 +725,24 @@ func sumRecords(
 	}
 }
 
-func FoldSamplesHalfHourly(samples SampleStream, hosts Hosts) MergedJob {
-	return foldSamples(samples, hosts, TruncateToHalfHour)
+func FoldSamplesHalfHourly(samples SampleStream, hosts Hosts, loc *time.Location) MergedJob {
+	return foldSamples(samples, hosts, func(t int64) int64 { return TruncateToHalfHour(t, loc) })
 }
 
-func FoldSamplesHourly(samples SampleStream, hosts Hosts) MergedJob {
-	return foldSamples(samples, hosts, TruncateToHour)
+func FoldSamplesHourly(samples SampleStream, hosts Hosts, loc *time.Location) MergedJob {
+	return foldSamples(samples, hosts, func(t int64) int64 { return TruncateToHour(t, loc) })
 }
 
-func FoldSamplesHalfDaily(samples SampleStream, hosts Hosts) MergedJob {
-	return foldSamples(samples, hosts, TruncateToHalfDay)
+func FoldSamplesHalfDaily(samples SampleStream, hosts Hosts, loc *time.Location) MergedJob {
+	return foldSamples(samples, hosts, func(t int64) int64 { return TruncateToHalfDay(t, loc) })
 }
 
-func FoldSamplesDaily(samples SampleStream, hosts Hosts) MergedJob {
-	return foldSamples(samples, hosts, TruncateToDay)
+func FoldSamplesDaily(samples SampleStream, hosts Hosts, loc *time.Location) MergedJob {
+	return foldSamples(samples, hosts, func(t int64) int64 { return TruncateToDay(t, loc) })
 }
 
-func FoldSamplesWeekly(samples SampleStream, hosts Hosts) MergedJob {
-	return foldSamples(samples, hosts, TruncateToWeek)
+func FoldSamplesWeekly(samples SampleStream, hosts Hosts, loc *time.Location) MergedJob {
+	return foldSamples(samples, hosts, func(t int64) int64 { return TruncateToWeek(t, loc) })
 }
 
 func foldSamples(samples SampleStream, hosts Hosts, truncTime func(int64) int64) MergedJob {
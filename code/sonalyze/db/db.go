@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"os"
 	"path"
+	"strings"
 	"unicode"
 
 	"go-utils/alias"
@@ -32,6 +33,19 @@ func OpenReadOnlyDB(meta types.Context, dataType types.DataType) (DataProvider,
 	return theLog, nil
 }
 
+// Open the database that is attached to the cluster of the context, which must have a database
+// connection.
+func OpenConnectedDB(cx types.Context) AppendablePersistentDataProvider {
+	switch theDB := cx.ConnectedDB().(type) {
+	case *sqliteConnection:
+		return openSqliteDB(theDB, cx)
+	case *databaseConnection:
+		return openTimescaleDB(theDB, cx)
+	default:
+		panic("Unknown database connection type")
+	}
+}
+
 func OpenFullDataStore(jobanalyzerDir, databaseURI string) error {
 	var (
 		clusters map[string]*special.ClusterEntry
@@ -41,7 +55,15 @@ func OpenFullDataStore(jobanalyzerDir, databaseURI string) error {
 	clusters = make(map[string]*special.ClusterEntry)
 
 	if databaseURI != "" {
-		theDB, err := OpenDatabaseURI(databaseURI)
+		var theDB interface {
+			EnumerateClusters() ([]string, error)
+		}
+		var err error
+		if IsSqliteURI(databaseURI) {
+			theDB, err = OpenSqliteURI(databaseURI)
+		} else {
+			theDB, err = OpenDatabaseURI(databaseURI)
+		}
 		if err != nil {
 			return err
 		}
@@ -49,6 +71,11 @@ func OpenFullDataStore(jobanalyzerDir, databaseURI string) error {
 		if err != nil {
 			return err
 		}
+		// An SQLite database knows only the clusters it has data for.  Clusters that have config
+		// files are also defined, so that data can be inserted for them.
+		if IsSqliteURI(databaseURI) && jobanalyzerDir != "" {
+			clusterNames = append(clusterNames, configuredClusterNames(jobanalyzerDir)...)
+		}
 		for _, name := range clusterNames {
 			c := special.NewClusterEntry()
 			c.Name = name
@@ -242,6 +269,21 @@ func OpenDataStoreFromConfig(cfg *config.ClusterConfig) error {
 	return nil
 }
 
+// Return the names of the clusters that have config files in the jobanalyzer directory.
+func configuredClusterNames(jobanalyzerDir string) []string {
+	names := make([]string, 0)
+	dirEntries, err := os.ReadDir(filesys.MakeClusterConfigDirPath(jobanalyzerDir))
+	if err != nil {
+		return names
+	}
+	for _, e := range dirEntries {
+		if name, found := strings.CutSuffix(e.Name(), "-config.json"); found && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func CloseDataStore() {
 	special.ClearClusters()
 }
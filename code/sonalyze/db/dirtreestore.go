@@ -4,9 +4,13 @@
 package db
 
 import (
+	"maps"
 	"path"
+	"slices"
 	"sync"
+	"time"
 
+	. "sonalyze/common"
 	"sonalyze/db/errs"
 	"sonalyze/db/filedb"
 	"sonalyze/db/types"
@@ -14,15 +18,22 @@ import (
 
 type clusterStore struct {
 	sync.Mutex
-	cacheSize   int64
-	initialized bool
-	closed      bool
-	clusters    map[string]*filedb.PersistentCluster
+	cacheSize    int64
+	diskCacheDir string
+	purgeHorizon uint
+	initialized  bool
+	closed       bool
+	clusters     map[string]*filedb.PersistentCluster
 }
 
 // MT: Constant after initialization; thread-safe
 var gClusterStore clusterStore
 
+const (
+	// How often to look for directories to purge, when purging is enabled.
+	purgeInterval = time.Hour
+)
+
 func unsafeResetClusterStore() {
 	gClusterStore = clusterStore{
 		clusters: make(map[string]*filedb.PersistentCluster, 10),
@@ -41,6 +52,19 @@ func SetCacheSize(size int64) {
 	gClusterStore.setCacheSize(size)
 }
 
+// SetDiskCacheDir can be called to enable the on-disk cache of parsed data for the database, before
+// the first database operation is performed.
+func SetDiskCacheDir(dir string) {
+	gClusterStore.setDiskCacheDir(dir)
+}
+
+// SetPurgeHorizon can be called to make the store periodically drop its in-memory metadata for
+// directories more than `days` days old.  The metadata are recreated if the data are needed again.
+// This is for long-running processes; zero means never purge.
+func SetPurgeHorizon(days uint) {
+	gClusterStore.setPurgeHorizon(days)
+}
+
 // Open a date-keyed directory tree as a read-only persistent database.
 func OpenPersistentDirectoryDB(
 	meta types.Context,
@@ -81,12 +105,64 @@ func (s *clusterStore) setCacheSize(size int64) {
 	}
 }
 
+func (s *clusterStore) setDiskCacheDir(dir string) {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed || s.initialized {
+		return
+	}
+	s.diskCacheDir = dir
+}
+
+func (s *clusterStore) setPurgeHorizon(days uint) {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed || s.purgeHorizon != 0 || days == 0 {
+		return
+	}
+	s.purgeHorizon = days
+	go s.purgeLoop()
+}
+
+func (s *clusterStore) purgeLoop() {
+	for {
+		time.Sleep(purgeInterval)
+		if !s.purgeOnce(time.Now().UTC()) {
+			return
+		}
+	}
+}
+
+// Returns false if the store is closed.
+func (s *clusterStore) purgeOnce(now time.Time) bool {
+	s.Lock()
+	if s.closed {
+		s.Unlock()
+		return false
+	}
+	cutoff := ThisDay(now).AddDate(0, 0, -int(s.purgeHorizon))
+	clusters := slices.Collect(maps.Values(s.clusters))
+	s.Unlock()
+
+	// Clusters can be closed concurrently, but that's fine, purging will be a no-op.
+	for _, c := range clusters {
+		c.PurgeDirectoriesBefore(cutoff)
+	}
+	return true
+}
+
 func (s *clusterStore) lazyInitLocked() {
 	if !s.initialized {
 		s.initialized = true
 		if s.cacheSize > 0 {
 			filedb.CacheInit(s.cacheSize)
 		}
+		if s.diskCacheDir != "" {
+			err := filedb.DiskCacheInit(s.diskCacheDir)
+			if err != nil {
+				Log.Warningf("Disk cache disabled: %v", err)
+			}
+		}
 	}
 }
 
@@ -130,4 +206,5 @@ func (ls *clusterStore) close() {
 	for _, d := range clusters {
 		d.Close()
 	}
+	filedb.DiskCacheFlushSync()
 }
@@ -1,11 +1,13 @@
 // Jobanalyzer has a simple data base interface that provides access to individual data streams,
 // basically a trivial time series database at the moment.  The data base can be backed by either a
-// list of individual immutable files or a (mutable) directory tree, as described in filedb/.  In
-// the future, proper time series databases will be added.
+// list of individual immutable files or a (mutable) directory tree, as described in filedb/, or by
+// a database: a remote, read-only TimescaleDB (timescaledb.go) or a local, read-write SQLite file
+// (sqlitedb.go).
 //
 // Lists of files can be opened with the functions in fileliststore.go, directory trees with the
-// functions in dirtreestore.go.  For historical reasons these are referred to throughout the code
-// as "transient clusters" and "persistent clusters", respectively.
+// functions in dirtreestore.go, and databases with OpenConnectedDB in db.go.  For historical
+// reasons the first two are referred to throughout the code as "transient clusters" and "persistent
+// clusters", respectively.
 //
 // The main thread would normally `defer db.Close()` to make sure that all pending writes are done
 // when the program shuts down, if read-write databases are opened.
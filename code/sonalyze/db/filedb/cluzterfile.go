@@ -3,6 +3,7 @@
 package filedb
 
 import (
+	"encoding/gob"
 	"io"
 	"unsafe"
 
@@ -102,6 +103,28 @@ func (_ *cluzterFileReadSyncMethods) CachedSizeOfPayload(payload any) uintptr {
 	return size
 }
 
+// The on-disk form of the payload must have exported fields.
+type cluzterDiskPayload struct {
+	AttributeData []*repr.CluzterAttributes
+	PartitionData []*repr.CluzterPartitions
+	NodeData      []*repr.CluzterNodes
+}
+
+func (_ *cluzterFileReadSyncMethods) EncodePayload(enc *gob.Encoder, payload any) error {
+	data := payload.(cluzterPayloadType)
+	return enc.Encode(&cluzterDiskPayload{data.attributeData, data.partitionData, data.nodeData})
+}
+
+func (_ *cluzterFileReadSyncMethods) DecodePayload(dec *gob.Decoder) (payload any, err error) {
+	var p cluzterDiskPayload
+	err = dec.Decode(&p)
+	if err != nil {
+		return
+	}
+	payload = &cluzterData{p.AttributeData, p.PartitionData, p.NodeData}
+	return
+}
+
 func ReadCluzterAttributeDataSlice(
 	files []*LogFile,
 	reader ReadSyncMethods,
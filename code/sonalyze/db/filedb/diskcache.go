@@ -0,0 +1,224 @@
+// On-disk cache of parsed LogFile payloads.
+//
+// The in-memory cache (cache.go) is lost when the process exits, and after a restart every file
+// must be parsed again.  The disk cache, when enabled, holds the parsed payloads of cacheable files
+// in serialized form so that they can be reloaded much more cheaply than they can be re-parsed.
+//
+// - To look for a payload on disk, call lf.diskCacheReadLocked()
+// - To save a payload to disk, call lf.diskCacheWriteLocked()
+//
+// Entries are keyed by the full path name of the data file, and an entry is valid only if the size
+// and modification time of the data file are the same as when the entry was written, and if the
+// entry was written by a program with the same diskCacheVersion.  Invalid and unreadable entries
+// are ignored and are eventually overwritten.  Each entry is a gob stream holding a diskCacheHeader
+// followed by the payload as serialized by the ReadSyncMethods for the file.
+//
+// BUMP diskCacheVersion WHENEVER THE MEANING OF A CACHED DATA REPRESENTATION CHANGES, otherwise the
+// cache will return stale data across program upgrades.  (Gob copes with fields being added or
+// removed, but not with fields changing meaning.)
+//
+// Entries are written asynchronously by a background goroutine, to a temporary file that is then
+// renamed, so a reader will never see a partial entry.  If the writer falls behind, entries are
+// dropped; they will be written the next time the file is parsed.
+//
+// The cache is never trimmed.  Entries for data files that are removed from the data store linger
+// until the cache directory is cleaned up by external action; it is always safe to remove the
+// entire cache directory, though it's best to do it while the daemon is not running.
+
+package filedb
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+
+	. "sonalyze/common"
+)
+
+const (
+	diskCacheVersion = 2
+
+	// Writer queue length.  Writes are dropped when the queue is full.
+	diskCacheQueueCap = 1000
+)
+
+type diskCacheHeader struct {
+	Version    int
+	Path       string // Full name of the data file
+	Size       int64  // Size of the data file when it was parsed
+	ModTime    int64  // Modification time of the data file when it was parsed, in ns
+	SoftErrors int
+}
+
+type diskCacheWriteRequest struct {
+	header  diskCacheHeader
+	reader  ReadSyncMethods
+	payload any
+}
+
+var (
+	// MT: Atomic
+	diskCacheDir atomic.Pointer[string] // nil if the disk cache is disabled
+
+	// MT: Constant after initialization; thread-safe
+	diskCacheWrites = make(chan *diskCacheWriteRequest, diskCacheQueueCap)
+
+	// MT: Thread-safe
+	diskCachePending sync.WaitGroup
+)
+
+// Enable the disk cache, storing entries in `dir`, which is created if it does not exist.  This
+// should be called before any data are read.
+func DiskCacheInit(dir string) error {
+	err := os.MkdirAll(dir, dirPermissions)
+	if err != nil {
+		return err
+	}
+	Log.Infof("Enabling disk cache in %s", dir)
+	diskCacheDir.Store(&dir)
+	return nil
+}
+
+// Return true iff the disk cache is enabled.  This will not block.
+func DiskCacheEnabled() bool {
+	return diskCacheDir.Load() != nil
+}
+
+// Return the name of the entry for the data file.
+func diskCacheEntryName(dir, filename string) string {
+	h := sha256.Sum256([]byte(filename))
+	key := hex.EncodeToString(h[:])
+	return path.Join(dir, key[:2], key[2:]+".gob")
+}
+
+// Return the payload and soft error count from the cache entry for lf if there is a valid entry.
+// Any failure to read the entry is treated as a cache miss.
+func (lf *LogFile) diskCacheReadLocked(reader ReadSyncMethods) (bool, any, int) {
+	dir := diskCacheDir.Load()
+	if dir == nil {
+		return false, nil, 0
+	}
+	filename := lf.Fullname.String()
+	info, err := os.Stat(filename)
+	if err != nil {
+		return false, nil, 0
+	}
+	entry, err := os.Open(diskCacheEntryName(*dir, filename))
+	if err != nil {
+		return false, nil, 0
+	}
+	defer entry.Close()
+
+	dec := gob.NewDecoder(bufio.NewReader(entry))
+	var header diskCacheHeader
+	err = dec.Decode(&header)
+	if err != nil ||
+		header.Version != diskCacheVersion ||
+		header.Path != filename ||
+		header.Size != info.Size() ||
+		header.ModTime != info.ModTime().UnixNano() {
+		return false, nil, 0
+	}
+	payload, err := reader.DecodePayload(dec)
+	if err != nil {
+		Log.Warningf("Bad disk cache entry for %s: %v", filename, err)
+		return false, nil, 0
+	}
+	if Verbose {
+		Log.Infof("Disk cache hit %s", lf.Fullname)
+	}
+	return true, payload, header.SoftErrors
+}
+
+// Schedule the payload for writing to the cache.  `info` must describe the data file as it was
+// before it was read, so that a concurrent append by another process will invalidate the entry.
+func (lf *LogFile) diskCacheWriteLocked(
+	reader ReadSyncMethods,
+	payload any,
+	softErrors int,
+	info os.FileInfo,
+) {
+	if !DiskCacheEnabled() {
+		return
+	}
+	req := &diskCacheWriteRequest{
+		header: diskCacheHeader{
+			Version:    diskCacheVersion,
+			Path:       lf.Fullname.String(),
+			Size:       info.Size(),
+			ModTime:    info.ModTime().UnixNano(),
+			SoftErrors: softErrors,
+		},
+		reader:  reader,
+		payload: payload,
+	}
+	diskCachePending.Add(1)
+	select {
+	case diskCacheWrites <- req:
+	default:
+		diskCachePending.Done()
+		Log.Infof("Disk cache queue full, not caching %s", lf.Fullname)
+	}
+}
+
+// A goroutine that writes cache entries.  The payloads are shared with the in-memory cache and with
+// readers, but they are never mutated after being read, so no locks are needed.
+
+func diskCacheWriterFunc() {
+	for req := range diskCacheWrites {
+		err := writeDiskCacheEntry(*diskCacheDir.Load(), req)
+		if err != nil {
+			Log.Warningf("Failed to write disk cache entry for %s: %v", req.header.Path, err)
+		}
+	}
+}
+
+func writeDiskCacheEntry(dir string, req *diskCacheWriteRequest) (err error) {
+	defer diskCachePending.Done()
+
+	name := diskCacheEntryName(dir, req.header.Path)
+	err = os.MkdirAll(path.Dir(name), dirPermissions)
+	if err != nil {
+		return
+	}
+	f, err := os.CreateTemp(path.Dir(name), "tmp-*")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+
+	w := bufio.NewWriter(f)
+	enc := gob.NewEncoder(w)
+	err = enc.Encode(&req.header)
+	if err == nil {
+		err = req.reader.EncodePayload(enc, req.payload)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// Wait until all queued entries have been written.
+func DiskCacheFlushSync() {
+	diskCachePending.Wait()
+}
+
+func init() {
+	go Forever(diskCacheWriterFunc, os.Stderr)
+}
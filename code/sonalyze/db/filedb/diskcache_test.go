@@ -0,0 +1,107 @@
+package filedb
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+
+	. "sonalyze/common"
+	"sonalyze/db/repr"
+)
+
+func TestDiskCache(t *testing.T) {
+	err := DiskCacheInit(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer diskCacheDir.Store(nil)
+
+	// Copy the data so that we can modify them.
+	dataDir := t.TempDir()
+	for _, name := range []string{
+		"2025/04/13/0+sample-n1.cluster1.uio.no.json",
+		"2025/04/12/n1.cluster1.uio.no.csv",
+		"2025/05/03/slurm-sacct.csv",
+		"2025/05/03/0+cluzter-slurm.json",
+	} {
+		bs, err := os.ReadFile(path.Join("testdata/data/cluster1.uio.no", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.MkdirAll(path.Join(dataDir, path.Dir(name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path.Join(dataDir, name), bs, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Parse, then read back from the cache with fresh LogFiles, the results must be the same.
+	readTwice := func(dirname, basename string, attrs FileAttr, reader ReadSyncMethods) (any, any) {
+		fn := Fullname{Cluster: dataDir, Dirname: dirname, Basename: basename}
+		uf := NewUstrCache()
+		parsed, _, err := NewLogFile(fn, attrs).ReadSync(uf, reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		DiskCacheFlushSync()
+		lf := NewLogFile(fn, attrs)
+		lf.Lock()
+		hit, payload, _ := lf.diskCacheReadLocked(reader)
+		lf.Unlock()
+		if !hit {
+			t.Fatalf("Expected disk cache hit for %s", basename)
+		}
+		return parsed, reader.SelectDataFromPayload(payload)
+	}
+
+	for _, kind := range []SampleFileKind{
+		SampleFileKindSample,
+		SampleFileKindNodeSample,
+		SampleFileKindCpuSamples,
+		SampleFileKindGpuSamples,
+	} {
+		a, b := readTwice("2025/04/13", "0+sample-n1.cluster1.uio.no.json", FileSampleV0JSON,
+			NewSampleFileMethods(kind))
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("Sample data differ, kind %d", kind)
+		}
+	}
+	a, b := readTwice("2025/04/12", "n1.cluster1.uio.no.csv", FileSampleCSV,
+		NewSampleFileMethods(SampleFileKindSample))
+	if len(a.([]*repr.Sample)) == 0 || !reflect.DeepEqual(a, b) {
+		t.Errorf("CSV sample data differ")
+	}
+	a, b = readTwice("2025/05/03", "slurm-sacct.csv", FileSlurmCSV, NewSacctFileMethods())
+	if len(a.([]*repr.SacctInfo)) == 0 || !reflect.DeepEqual(a, b) {
+		t.Errorf("Sacct data differ")
+	}
+	a, b = readTwice("2025/05/03", "0+cluzter-slurm.json", FileCluzterV0JSON,
+		NewCluzterFileMethods(CluzterFileKindPartitionData))
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Cluzter data differ")
+	}
+
+	// Appending to the file invalidates the entry.
+	fn := Fullname{Cluster: dataDir, Dirname: "2025/04/12", Basename: "n1.cluster1.uio.no.csv"}
+	f, err := os.OpenFile(fn.String(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("\n")
+	f.Close()
+	// Make sure the mtime changes even with coarse timestamps.
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(fn.String(), later, later)
+	lf := NewLogFile(fn, FileSampleCSV)
+	lf.Lock()
+	hit, _, _ := lf.diskCacheReadLocked(NewSampleFileMethods(SampleFileKindSample))
+	lf.Unlock()
+	if hit {
+		t.Errorf("Expected disk cache miss after append")
+	}
+}
@@ -0,0 +1,447 @@
+// Integrity checking ("fsck") for directory-tree stores.
+//
+// FsckDirectoryTree walks the yyyy/mm/dd leaf directories of a cluster's data tree for a date range
+// and runs the parsers in db/parse on every file in them, independently of the PersistentCluster
+// and of the cache.  It reports:
+//
+//   - files that the parser gave up on (typically truncated JSON lines following a crash)
+//   - files from which records or fields were dropped during parsing
+//   - records timestamped outside the day of the directory they are in
+//   - records that are exact duplicates of other records in the same file
+//   - files and directories whose names match none of the FILE NAME SCHEMES (see doc.go)
+//   - slurm-sacct.csv files that carry sample data for a host named "slurm-sacct"
+//
+// The first, fifth and sixth of these make the file unusable (or misleading) to the database, and
+// such files are considered "bad", see FsckProblem.IsBad().  Bad files can be moved out of the tree
+// with QuarantineFile.
+//
+// Fsck is read-only and only reads files, but quarantining must not be done while some other
+// process (typically the daemon) may be appending to the tree.
+
+package filedb
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	. "sonalyze/common"
+	"sonalyze/db/parse"
+	"sonalyze/db/repr"
+)
+
+type FsckProblem int
+
+const (
+	FsckParseError FsckProblem = iota
+	FsckSoftErrors
+	FsckMisplacedRecords
+	FsckDuplicateRecords
+	FsckUnknownFile
+	FsckNameClash
+)
+
+func (p FsckProblem) String() string {
+	switch p {
+	case FsckParseError:
+		return "parse-error"
+	case FsckSoftErrors:
+		return "soft-errors"
+	case FsckMisplacedRecords:
+		return "misplaced"
+	case FsckDuplicateRecords:
+		return "duplicates"
+	case FsckUnknownFile:
+		return "unknown-file"
+	case FsckNameClash:
+		return "name-clash"
+	default:
+		panic("Unexpected")
+	}
+}
+
+// A bad file should be removed from the tree: its data are unreadable or will be misinterpreted.
+func (p FsckProblem) IsBad() bool {
+	return p == FsckParseError || p == FsckUnknownFile || p == FsckNameClash
+}
+
+type FsckFinding struct {
+	Fullname
+	Problem FsckProblem
+	Count   int    // Number of records (or errors) affected, 1 for whole-file problems
+	Detail  string // Human-readable explanation
+}
+
+// Check all files in the leaf directories of `dataDir` in the date range [fromDate, toDate].  The
+// dates should be UTC; they are rounded out to full days.  Records are misplaced if their
+// timestamps are more than `slack` seconds outside the day of their directory.  The findings are
+// sorted by path name and then by problem.
+//
+// Errors are returned only for failures to read directories.  Failures to read and parse files
+// are findings.
+
+func FsckDirectoryTree(dataDir string, fromDate, toDate time.Time, slack int64) ([]*FsckFinding, error) {
+	fromDate = ThisDay(fromDate)
+	toDate = RoundupDay(toDate)
+
+	files, findings, err := fsckScanDirectories(dataDir, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parsing is done by a private pool of workers, not by the shared parser goroutines: those
+	// work on LogFiles and aggregate the results across files, but we need per-file results.
+	var (
+		lock sync.Mutex
+		wg   sync.WaitGroup
+		work = make(chan Fullname)
+	)
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			uf := NewUstrCache()
+			for fn := range work {
+				fs := fsckFile(fn, slack, uf)
+				lock.Lock()
+				findings = append(findings, fs...)
+				lock.Unlock()
+			}
+		}()
+	}
+	for _, fn := range files {
+		work <- fn
+	}
+	close(work)
+	wg.Wait()
+
+	slices.SortFunc(findings, func(a, b *FsckFinding) int {
+		if c := cmp.Compare(a.Dirname, b.Dirname); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Basename, b.Basename); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Problem, b.Problem)
+	})
+	return findings, nil
+}
+
+// Move a file to the same relative location under `quarantineDir`, creating directories as
+// necessary.  The quarantine directory should be on the same file system as the data.
+
+func QuarantineFile(quarantineDir string, fn Fullname) error {
+	targetDir := path.Join(quarantineDir, fn.Dirname)
+	err := os.MkdirAll(targetDir, dirPermissions)
+	if err != nil {
+		return err
+	}
+	return os.Rename(fn.String(), path.Join(targetDir, fn.Basename))
+}
+
+// Enumerate the files to check.  Entries in the month directories that are not day directories,
+// and entries in the day directories that are not regular files with known names, are reported
+// immediately as findings.
+
+func fsckScanDirectories(
+	dataDir string,
+	fromDate, toDate time.Time,
+) (files []Fullname, findings []*FsckFinding, err error) {
+	files = make([]Fullname, 0)
+	findings = make([]*FsckFinding, 0)
+	seenMonths := make(map[string]bool)
+	for d := fromDate; d.Before(toDate); d = d.AddDate(0, 0, 1) {
+		month := fmt.Sprintf("%04d/%02d", d.Year(), d.Month())
+		if !seenMonths[month] {
+			seenMonths[month] = true
+			findings = append(findings, fsckScanMonth(dataDir, month)...)
+		}
+
+		dirname := dirnameFromTime(d)
+		entries, e := os.ReadDir(path.Join(dataDir, dirname))
+		if e != nil {
+			if os.IsNotExist(e) {
+				continue
+			}
+			err = e
+			return
+		}
+		for _, e := range entries {
+			fn := Fullname{Cluster: dataDir, Dirname: dirname, Basename: e.Name()}
+			if !e.Type().IsRegular() {
+				findings = append(findings, &FsckFinding{
+					Fullname: fn,
+					Problem:  FsckUnknownFile,
+					Count:    1,
+					Detail:   "Not a regular file",
+				})
+				continue
+			}
+			attr, known := classifyBasename(e.Name())
+			if !known {
+				findings = append(findings, &FsckFinding{
+					Fullname: fn,
+					Problem:  FsckUnknownFile,
+					Count:    1,
+					Detail:   "Unknown file name",
+				})
+				continue
+			}
+			if attr != 0 {
+				files = append(files, fn)
+			}
+		}
+	}
+	return
+}
+
+func fsckScanMonth(dataDir, month string) []*FsckFinding {
+	findings := make([]*FsckFinding, 0)
+	entries, err := os.ReadDir(path.Join(dataDir, month))
+	if err != nil {
+		return findings
+	}
+	var year, mon int
+	fmt.Sscanf(month, "%d/%d", &year, &mon)
+	for _, e := range entries {
+		name := e.Name()
+		day, err := strconv.Atoi(name)
+		valid := err == nil && len(name) == 2 && e.IsDir()
+		if valid {
+			t := time.Date(year, time.Month(mon), day, 0, 0, 0, 0, time.UTC)
+			valid = t.Day() == day && int(t.Month()) == mon
+		}
+		if !valid {
+			findings = append(findings, &FsckFinding{
+				Fullname: Fullname{Cluster: dataDir, Dirname: month, Basename: name},
+				Problem:  FsckUnknownFile,
+				Count:    1,
+				Detail:   "Not a day directory",
+			})
+		}
+	}
+	return findings
+}
+
+// Return the file type for the basename and true, or false if the name is not acceptable in a leaf
+// directory.  Files that are known but off-limits (see doc.go) have file type zero.
+
+func classifyBasename(basename string) (FileAttr, bool) {
+	proscribed := false
+	for _, fa := range []filesAdapter{
+		sampleFilesAdapter{},
+		sysinfoFilesAdapter{},
+		sacctFilesAdapter{},
+		cluzterFilesAdapter{},
+	} {
+		for _, glob := range fa.globs() {
+			if matched, _ := path.Match(glob, basename); matched {
+				if fa.proscribedBasename(basename) {
+					proscribed = true
+					continue
+				}
+				return fa.fileTypeFromBasename(basename), true
+			}
+		}
+	}
+	return 0, proscribed
+}
+
+// The data that are checked for a file, independent of the file type.
+
+type fsckFileData struct {
+	records    int
+	softErrors int
+	times      []int64 // Record timestamps, 0 is "unknown"
+	duplicates int
+}
+
+func fsckFile(fn Fullname, slack int64, uf *UstrCache) []*FsckFinding {
+	findings := make([]*FsckFinding, 0)
+	attr, _ := classifyBasename(fn.Basename)
+	data, err := fsckParseFile(fn, attr, uf)
+	if err != nil {
+		return append(findings, &FsckFinding{
+			Fullname: fn,
+			Problem:  FsckParseError,
+			Count:    1,
+			Detail:   err.Error(),
+		})
+	}
+
+	if data.softErrors > 0 {
+		findings = append(findings, &FsckFinding{
+			Fullname: fn,
+			Problem:  FsckSoftErrors,
+			Count:    data.softErrors,
+			Detail:   fmt.Sprintf("%d records, %d soft errors", data.records, data.softErrors),
+		})
+	}
+
+	dayStart := timeFromDirname(fn.Dirname).Unix()
+	dayEnd := dayStart + 24*60*60
+	var misplaced int
+	var earliest, latest int64
+	for _, t := range data.times {
+		if t == 0 || (t >= dayStart-slack && t < dayEnd+slack) {
+			continue
+		}
+		if misplaced == 0 {
+			earliest, latest = t, t
+		} else {
+			earliest, latest = min(earliest, t), max(latest, t)
+		}
+		misplaced++
+	}
+	if misplaced > 0 {
+		findings = append(findings, &FsckFinding{
+			Fullname: fn,
+			Problem:  FsckMisplacedRecords,
+			Count:    misplaced,
+			Detail: fmt.Sprintf(
+				"Timestamps from %s to %s",
+				time.Unix(earliest, 0).UTC().Format(time.RFC3339),
+				time.Unix(latest, 0).UTC().Format(time.RFC3339),
+			),
+		})
+	}
+
+	if data.duplicates > 0 {
+		findings = append(findings, &FsckFinding{
+			Fullname: fn,
+			Problem:  FsckDuplicateRecords,
+			Count:    data.duplicates,
+			Detail:   fmt.Sprintf("%d records, %d duplicates", data.records, data.duplicates),
+		})
+	}
+
+	// See the note about "slurm-sacct" in doc.go.  Sample records in the sacct file are dropped
+	// by the sacct parser, so parse the file as sample data too, to find them.
+	if attr == FileSlurmCSV {
+		if clashes := fsckCountSampleRecords(fn, uf); clashes > 0 {
+			findings = append(findings, &FsckFinding{
+				Fullname: fn,
+				Problem:  FsckNameClash,
+				Count:    clashes,
+				Detail:   "Sample records for a host named slurm-sacct",
+			})
+		}
+	}
+
+	return findings
+}
+
+func fsckParseFile(fn Fullname, attr FileAttr, uf *UstrCache) (data fsckFileData, err error) {
+	input, err := os.Open(fn.String())
+	if err != nil {
+		return
+	}
+	defer input.Close()
+
+	switch attr {
+	case FileSampleCSV:
+		var samples []*repr.Sample
+		samples, _, _, data.softErrors, err = parse.ParseSampleCSV(input, uf)
+		fsckSampleData(&data, samples, nil)
+	case FileSampleV0JSON:
+		var samples []*repr.Sample
+		var nodeSamples []*repr.NodeSample
+		samples, nodeSamples, _, _, _, data.softErrors, err = parse.ParseSamplesV0JSON(input, uf)
+		fsckSampleData(&data, samples, nodeSamples)
+	case FileSysinfoOldJSON, FileSysinfoV0JSON:
+		var nodeData []*repr.SysinfoNodeData
+		if attr == FileSysinfoV0JSON {
+			nodeData, _, data.softErrors, err = parse.ParseSysinfoV0JSON(input)
+		} else {
+			nodeData, _, data.softErrors, err = parse.ParseSysinfoOldJSON(input)
+		}
+		data.records = len(nodeData)
+		seen := make(map[[2]string]bool)
+		for _, d := range nodeData {
+			data.times = append(data.times, fsckParseTime(d.Time))
+			key := [2]string{d.Time, d.Node}
+			if seen[key] {
+				data.duplicates++
+			}
+			seen[key] = true
+		}
+	case FileSlurmCSV, FileSlurmV0JSON:
+		var records []*repr.SacctInfo
+		if attr == FileSlurmCSV {
+			records, data.softErrors, err = parse.ParseSlurmCSV(input, uf)
+		} else {
+			records, data.softErrors, err = parse.ParseSlurmV0JSON(input, uf)
+		}
+		data.records = len(records)
+		seen := make(map[repr.SacctInfo]bool)
+		for _, r := range records {
+			// Older sacct data have no record timestamp.
+			data.times = append(data.times, r.Time)
+			if seen[*r] {
+				data.duplicates++
+			}
+			seen[*r] = true
+		}
+	case FileCluzterV0JSON:
+		var attributes []*repr.CluzterAttributes
+		attributes, _, _, data.softErrors, err = parse.ParseCluzterV0JSON(input)
+		data.records = len(attributes)
+		seen := make(map[string]bool)
+		for _, a := range attributes {
+			data.times = append(data.times, fsckParseTime(a.Time))
+			if seen[a.Time] {
+				data.duplicates++
+			}
+			seen[a.Time] = true
+		}
+	default:
+		panic("Unexpected")
+	}
+	return
+}
+
+func fsckSampleData(data *fsckFileData, samples []*repr.Sample, nodeSamples []*repr.NodeSample) {
+	data.records = len(samples) + len(nodeSamples)
+	seen := make(map[repr.Sample]bool, len(samples))
+	for _, s := range samples {
+		data.times = append(data.times, s.Timestamp)
+		if seen[*s] {
+			data.duplicates++
+		}
+		seen[*s] = true
+	}
+	seenNode := make(map[repr.NodeSample]bool, len(nodeSamples))
+	for _, s := range nodeSamples {
+		data.times = append(data.times, s.Timestamp)
+		if seenNode[*s] {
+			data.duplicates++
+		}
+		seenNode[*s] = true
+	}
+}
+
+func fsckCountSampleRecords(fn Fullname, uf *UstrCache) int {
+	input, err := os.Open(fn.String())
+	if err != nil {
+		return 0
+	}
+	defer input.Close()
+	samples, _, _, _, _ := parse.ParseSampleCSV(input, uf)
+	return len(samples)
+}
+
+// Unparseable timestamps are treated as unknown (0), the parsers will have counted them as soft
+// errors if they matter.
+func fsckParseTime(s string) int64 {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,95 @@
+package filedb
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+const (
+	fsckSample1 = "v=0.7.0,time=2025-04-11T10:00:00+00:00,host=n1,cores=8,user=root,job=1,pid=10,cmd=x,cpu%=1,cpukib=10,cputime_sec=7\n"
+	fsckSample2 = "v=0.7.0,time=2025-04-11T10:05:00+00:00,host=n1,cores=8,user=root,job=1,pid=10,cmd=x,cpu%=1,cpukib=10,cputime_sec=8\n"
+	fsckSample3 = "v=0.7.0,time=2025-04-12T03:00:00+00:00,host=n1,cores=8,user=root,job=1,pid=10,cmd=x,cpu%=1,cpukib=10,cputime_sec=9\n"
+	fsckClash   = "v=0.7.0,time=2025-04-11T10:00:00+00:00,host=slurm-sacct,cores=8,user=root,job=1,pid=10,cmd=x,cpu%=1,cpukib=10,cputime_sec=7\n"
+)
+
+func TestFsck(t *testing.T) {
+	dataDir := t.TempDir()
+	dayDir := path.Join(dataDir, "2025/04/11")
+	err := os.MkdirAll(dayDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string) {
+		err := os.WriteFile(path.Join(dayDir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("n1.csv", fsckSample1+fsckSample2+fsckSample1+fsckSample3)
+	write("slurm-sacct.csv", fsckClash)
+	write("0+sysinfo-n1.json", `{"meta":{"producer":"sonar","version":"0.14.0"},"data":{"type":"sysi`)
+	write("junk.txt", "hi\n")
+	write("cpuhog.csv", "") // Off-limits but legal
+	err = os.MkdirAll(path.Join(dataDir, "2025/04/tmp"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2025, 4, 11, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	findings, err := FsckDirectoryTree(dataDir, from, to, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []struct {
+		basename string
+		problem  FsckProblem
+		count    int
+	}{
+		{"tmp", FsckUnknownFile, 1},
+		{"0+sysinfo-n1.json", FsckParseError, 1},
+		{"junk.txt", FsckUnknownFile, 1},
+		{"n1.csv", FsckMisplacedRecords, 1},
+		{"n1.csv", FsckDuplicateRecords, 1},
+		{"slurm-sacct.csv", FsckSoftErrors, 11},
+		{"slurm-sacct.csv", FsckNameClash, 1},
+	}
+	if len(findings) != len(expect) {
+		for _, f := range findings {
+			t.Log(f.Fullname, f.Problem, f.Count, f.Detail)
+		}
+		t.Fatalf("Expected %d findings, got %d", len(expect), len(findings))
+	}
+	for i, e := range expect {
+		f := findings[i]
+		if f.Basename != e.basename || f.Problem != e.problem || f.Count != e.count {
+			t.Errorf("Finding %d: got %s %s %d", i, f.Basename, f.Problem, f.Count)
+		}
+	}
+
+	// With slack the misplaced record is accepted.
+	findings, err = FsckDirectoryTree(dataDir, from, to, 4*60*60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range findings {
+		if f.Problem == FsckMisplacedRecords {
+			t.Errorf("Unexpected misplaced records with slack")
+		}
+	}
+
+	// Quarantine moves the file aside, relative to the tree.
+	qDir := t.TempDir()
+	err = QuarantineFile(qDir, Fullname{Cluster: dataDir, Dirname: "2025/04/11", Basename: "junk.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path.Join(qDir, "2025/04/11/junk.txt")); err != nil {
+		t.Errorf("Quarantined file not found: %v", err)
+	}
+	if _, err := os.Stat(path.Join(dayDir, "junk.txt")); err == nil {
+		t.Errorf("Quarantined file still present")
+	}
+}
@@ -12,7 +12,8 @@
 // data will not be written.
 //
 // A file may cache its data, mostly transparently - in this case, a read operation returns the
-// cached data.  See below.
+// cached data.  See below.  Independently of that, the parsed data may also be cached on disk, see
+// diskcache.go.
 //
 // The files are kept generic through the use of `any`.  We could instead have created a hierarchy
 // of interfaces and/or used generic types but that currently seems like needless complexity.
@@ -47,6 +48,7 @@ package filedb
 
 import (
 	"bufio"
+	"encoding/gob"
 	"errors"
 	"fmt"
 	"io"
@@ -89,6 +91,11 @@ type ReadSyncMethods interface {
 	// (using cap(), say).  This does not have to be super fast, it is only used in connection with
 	// I/O.  The data will tend to be in the CPU cache.
 	CachedSizeOfPayload(payload any) uintptr
+
+	// Serialize the payload for the on-disk cache, and deserialize it again.  These are only called
+	// if IsCacheable() returns true.  See diskcache.go.
+	EncodePayload(enc *gob.Encoder, payload any) error
+	DecodePayload(dec *gob.Decoder) (payload any, err error)
 }
 
 type FileAttr int
@@ -214,6 +221,14 @@ func (lf *LogFile) ReadSync(
 		}
 	}
 
+	if !gotCachedData && reader.IsCacheable() && DiskCacheEnabled() {
+		gotCachedData, payload, softErrors = lf.diskCacheReadLocked(reader)
+		if gotCachedData && CacheEnabled() {
+			size := reader.CachedSizeOfPayload(payload)
+			lf.cacheWriteLocked(&cachePayload{payload, softErrors}, int64(size))
+		}
+	}
+
 	if !gotCachedData {
 		var inputFile *os.File
 		inputFile, err = os.Open(lf.Fullname.String())
@@ -221,6 +236,13 @@ func (lf *LogFile) ReadSync(
 			return
 		}
 		defer inputFile.Close()
+		// Stat before reading so that an entry written to the disk cache is invalidated by any
+		// concurrent append.
+		var info os.FileInfo
+		info, err = inputFile.Stat()
+		if err != nil {
+			return
+		}
 		payload, softErrors, err = reader.ReadDataLocked(lf.attrs, inputFile, uf)
 		if err != nil {
 			return
@@ -229,6 +251,9 @@ func (lf *LogFile) ReadSync(
 			size := reader.CachedSizeOfPayload(payload)
 			lf.cacheWriteLocked(&cachePayload{payload, softErrors}, int64(size))
 		}
+		if reader.IsCacheable() && DiskCacheEnabled() {
+			lf.diskCacheWriteLocked(reader, payload, softErrors, info)
+		}
 	}
 
 	data = reader.SelectDataFromPayload(payload)
@@ -33,21 +33,27 @@
 // Over time (months) as the server is up, there may be a substantial amount of metadata in memory -
 // the shadow directory trees may become quite large.  For example, most queries will only very
 // rarely request files from more than a few days ago and caching really old stuff may be
-// undesirable.  In principle we would want to purge disused shadow directory tree entries.
-// However, this is hard because we require that there is only ever one LogFile per appendable file,
-// and so we need to prove, before we purge a directory, that no references exist anywhere in the
-// system to any file in the directory (or we risk the directory becoming reinstated and a new
-// LogFile created for one that is already active).  In addition, given the constraints of the date
-// range, we'd only ever be able to purge from the ends of the date range.
+// undesirable.  For saga+betzy+fram+fox there are > 2000 nodes, and one file for each per day.  Even
+// if we intern all the strings then each file structure is at least 64 bytes, and not pointer-free.
+// 2000*365*64=46MB per year, plus cached data.
 //
-// On the other hand, let's consider how much data we're talking about.  For one year there are 365
-// directories, not an important factor.  But for saga+betzy+fram+fox there are > 2000 nodes, and
-// one file for each per day.  Even if we intern all the strings (and we probably should) then each
-// file structure is at least 64 bytes, and not pointer-free.  2000*365*64=46MB.  I guess it's
-// probably affordable, for now.
+// Hence old shadow directories can be purged, see PurgeDirectoriesBefore().  This is subtle because
+// we require that there is only ever one LogFile per appendable file, and so we need to prove,
+// before we purge a directory, that no references exist anywhere in the system to any file in the
+// directory (or we risk the directory becoming reinstated and a new LogFile created for one that is
+// already active).  References to LogFiles escape the cluster lock in only two ways:
 //
-// A halfway solution, should non-purging turn out to be a problem, is to purge *everything* every
-// so often, effectively restarting the daemon.
+//  - a file with data pending is in the `dirty` set until it is flushed
+//  - an append operation drops the cluster lock before appending to the file, and for that
+//    duration it holds a reference count on the directory (persistentDir.refs)
+//
+// A directory can be purged if it has no references and none of its files are dirty.  (Reads hold
+// the cluster lock while reading.  If that changes, reads must also take a reference.)  Purged
+// files are purged from the cache, so the cache's tables will not retain them either.
+//
+// In addition, given the constraints of the date range, we can only purge from the low end of the
+// date range: fromDate is moved forward to the first directory that is retained.  If a request
+// later comes in for an older date then the older part of the tree is simply scanned again.
 
 package filedb
 
@@ -59,6 +65,7 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go-utils/hostglob"
@@ -135,6 +142,10 @@ type persistentDir struct {
 	// Path name underneath the cluster's dataDir, form "yyyy/mm/dd".
 	name string
 
+	// Number of operations that are using files in the directory without holding the cluster lock.
+	// This is incremented with the lock held.  The directory can't be purged when this is nonzero.
+	refs atomic.Int32
+
 	// All files in the directory.  If files is nil then the directory has not been scanned for
 	// those files, otherwise each map is canonical - there can be no files (of that type) in the
 	// directory that are not in this map.  There is a separate set per file because these are
@@ -505,18 +516,77 @@ func (pc *PersistentCluster) appendDataAsync(
 		return errs.ClusterClosedErr
 	}
 
-	file, err := pc.findFileByTimeLocked(timestamp, filename, fa)
+	d, file, err := pc.findFileByTimeLocked(timestamp, filename, fa)
 	if err != nil {
 		return err
 	}
 
 	pc.dirty[file] = true
 
+	// The reference prevents the directory from being purged while the lock is not held.
+	d.refs.Add(1)
+	defer d.refs.Add(-1)
+
 	shouldUnlock = false
 	pc.Unlock()
 	return file.AppendAsync(payload)
 }
 
+// Remove the shadow directories for dates before `cutoff` from the tree, along with their files,
+// unless some file in the directories is in use, see the block comment at the beginning.  Returns
+// the number of directories removed.  The cutoff should be UTC, it is rounded down to midnight.
+
+func (pc *PersistentCluster) PurgeDirectoriesBefore(cutoff time.Time) int {
+	pc.Lock()
+	defer pc.Unlock()
+	if pc.closed {
+		return 0
+	}
+
+	newFrom := ThisDay(cutoff)
+	if !pc.fromDate.Before(newFrom) {
+		return 0
+	}
+	if pc.toDate.Before(newFrom) {
+		newFrom = pc.toDate
+	}
+
+	dirtyDirs := make(map[string]bool)
+	for f := range pc.dirty {
+		dirtyDirs[f.Dirname] = true
+	}
+
+	limit := dirnameFromTime(newFrom)
+	n := 0
+	for n < len(pc.dirs) && pc.dirs[n].name < limit {
+		d := pc.dirs[n]
+		if d.refs.Load() != 0 || dirtyDirs[d.name] {
+			newFrom = timeFromDirname(d.name)
+			break
+		}
+		n++
+	}
+
+	for _, d := range pc.dirs[:n] {
+		for _, files := range []map[string]*LogFile{
+			d.sampleFiles, d.sysinfoFiles, d.sacctFiles, d.cluzterFiles,
+		} {
+			for _, f := range files {
+				f.PurgeCache("internal:directory purged")
+			}
+		}
+	}
+
+	// Copy the remaining directories so that the purged ones are not retained by the old array.
+	pc.dirs = slices.Clone(pc.dirs[n:])
+	pc.fromDate = newFrom
+
+	if n > 0 {
+		Log.Infof("Purged %d directories from %s, new start date %s", n, pc.dataDir, limit)
+	}
+	return n
+}
+
 ///////////////////////////////////////////////////////////////////////////////////////////////////
 //
 // Adapters to hide file idiosyncracies in a persistent store.
@@ -737,10 +807,10 @@ func FilenameGlobber(h Hosts, globs []string) *hostglob.HostGlobber {
 func (pc *PersistentCluster) findFileByTimeLocked(
 	timestamp, filename string,
 	fa filesAdapter,
-) (*LogFile, error) {
+) (*persistentDir, *LogFile, error) {
 	tval, err := time.Parse(time.RFC3339, timestamp)
 	if err != nil {
-		return nil, errs.BadTimestampErr
+		return nil, nil, errs.BadTimestampErr
 	}
 	// This conversion to UTC creates a detectable semantic change from the Rust code and earlier Go
 	// code.  It means that a record for eg 2024-06-03T00:00:01+02:00 will end up in the 2024/06/02
@@ -750,7 +820,7 @@ func (pc *PersistentCluster) findFileByTimeLocked(
 	tval = tval.UTC()
 	d, err := pc.ensureScannedDirectoryLocked(tval)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var f *LogFile
 	name := Fullname{
@@ -769,7 +839,7 @@ func (pc *PersistentCluster) findFileByTimeLocked(
 		f = NewLogFile(name, attrs)
 		files[filename] = f
 	}
-	return f, nil
+	return d, f, nil
 }
 
 // Return the subslice of the shadown directory slice corresponding to the date range.  For correct
@@ -914,3 +984,9 @@ func binarySearchDirs(dirs []*persistentDir, d time.Time) (int, bool) {
 func dirnameFromTime(t time.Time) string {
 	return fmt.Sprintf("%04d/%02d/%02d", t.Year(), t.Month(), t.Day())
 }
+
+func timeFromDirname(dirname string) time.Time {
+	var year, month, day int
+	fmt.Sscanf(dirname, "%d/%d/%d", &year, &month, &day)
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
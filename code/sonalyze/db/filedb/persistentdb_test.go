@@ -585,3 +585,57 @@ SlurmPartitionLoop:
 		t.Fatal("No cluzter partition data read")
 	}
 }
+
+func TestPurge(t *testing.T) {
+	dataDir := t.TempDir()
+	pc := NewPersistentCluster(dataDir, &stubMeta{dataDir: dataDir})
+	defer pc.Close()
+
+	// Create data in three old directories, and in a fourth that is not flushed and must not be
+	// purged.
+	for _, d := range []string{"2025-04-11", "2025-04-12", "2025-04-13"} {
+		err := pc.AppendSamplesAsync(FileSampleCSV, "n1", d+"T10:00:00Z", "x")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	pc.FlushAsync()
+	err := pc.AppendSamplesAsync(FileSampleCSV, "n1", "2025-04-14T10:00:00Z", "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cutoff := time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC)
+	if n := pc.PurgeDirectoriesBefore(cutoff); n != 3 {
+		t.Fatalf("Expected 3 directories purged, got %d", n)
+	}
+	if pc.fromDate != time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("Unexpected fromDate %v", pc.fromDate)
+	}
+
+	// A directory in use can't be purged either.
+	pc.FlushAsync()
+	pc.dirs[0].refs.Add(1)
+	if n := pc.PurgeDirectoriesBefore(cutoff); n != 0 {
+		t.Fatalf("Expected no directories purged, got %d", n)
+	}
+	pc.dirs[0].refs.Add(-1)
+	if n := pc.PurgeDirectoriesBefore(cutoff); n != 1 {
+		t.Fatalf("Expected 1 directory purged, got %d", n)
+	}
+	if len(pc.dirs) != 0 {
+		t.Fatalf("Expected no directories, got %d", len(pc.dirs))
+	}
+
+	// The purged directories are found again when they are needed.
+	fs, err := pc.SampleFilenames(types.DataProviderFilter{
+		FromDate: time.Date(2025, 4, 12, 0, 0, 0, 0, time.UTC),
+		ToDate:   time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fs) != 2 {
+		t.Fatalf("Expected 2 files, got %v", fs)
+	}
+}
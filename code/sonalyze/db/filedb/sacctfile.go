@@ -3,6 +3,7 @@
 package filedb
 
 import (
+	"encoding/gob"
 	"io"
 	"unsafe"
 
@@ -56,6 +57,24 @@ func (_ *sacctFileReadSyncMethods) CachedSizeOfPayload(payload any) uintptr {
 	return size
 }
 
+func (_ *sacctFileReadSyncMethods) EncodePayload(enc *gob.Encoder, payload any) error {
+	// Wrap the slice so that an empty payload can be encoded.
+	return enc.Encode(&struct{ Records sacctPayloadType }{payload.(sacctPayloadType)})
+}
+
+func (_ *sacctFileReadSyncMethods) DecodePayload(dec *gob.Decoder) (payload any, err error) {
+	var p struct{ Records sacctPayloadType }
+	err = dec.Decode(&p)
+	if err != nil {
+		return
+	}
+	if p.Records == nil {
+		p.Records = make(sacctPayloadType, 0)
+	}
+	payload = p.Records
+	return
+}
+
 func ReadSacctSlice(
 	files []*LogFile,
 	reader ReadSyncMethods,
@@ -1,6 +1,7 @@
 package filedb
 
 import (
+	"encoding/gob"
 	"io"
 	"unsafe"
 
@@ -73,6 +74,8 @@ func (sfr *sampleFileReadSyncMethods) SelectDataFromPayload(payload any) (data a
 		return payload.(samplePayloadType).samples
 	case DataNeedNodeSamples:
 		return payload.(samplePayloadType).nodeSamples
+	case DataNeedDiskSamples:
+		return payload.(samplePayloadType).diskSamples
 	case DataNeedCpuSamples:
 		return payload.(samplePayloadType).cpuSamples
 	case DataNeedGpuSamples:
@@ -128,6 +131,36 @@ func (_ *sampleFileReadSyncMethods) CachedSizeOfPayload(payload any) uintptr {
 	return size
 }
 
+// The on-disk form of the payload must have exported fields.
+type sampleDiskPayload struct {
+	Samples     []*repr.Sample
+	NodeSamples []*repr.NodeSample
+	DiskSamples []*repr.DiskSample
+	CpuSamples  []*repr.CpuSamples
+	GpuSamples  []*repr.GpuSamples
+}
+
+func (_ *sampleFileReadSyncMethods) EncodePayload(enc *gob.Encoder, payload any) error {
+	data := payload.(samplePayloadType)
+	return enc.Encode(&sampleDiskPayload{
+		data.samples,
+		data.nodeSamples,
+		data.diskSamples,
+		data.cpuSamples,
+		data.gpuSamples,
+	})
+}
+
+func (_ *sampleFileReadSyncMethods) DecodePayload(dec *gob.Decoder) (payload any, err error) {
+	var p sampleDiskPayload
+	err = dec.Decode(&p)
+	if err != nil {
+		return
+	}
+	payload = &sampleData{p.Samples, p.NodeSamples, p.DiskSamples, p.CpuSamples, p.GpuSamples}
+	return
+}
+
 func readProcessSampleSlice(
 	files []*LogFile,
 	reader ReadSyncMethods,
@@ -1,6 +1,7 @@
 package filedb
 
 import (
+	"encoding/gob"
 	"io"
 	"unsafe"
 
@@ -95,6 +96,16 @@ func (_ *sysinfoFileReadSyncMethods) CachedSizeOfPayload(payload any) uintptr {
 	return size
 }
 
+// Sysinfo data are not cacheable.
+
+func (_ *sysinfoFileReadSyncMethods) EncodePayload(_ *gob.Encoder, _ any) error {
+	panic("Unexpected")
+}
+
+func (_ *sysinfoFileReadSyncMethods) DecodePayload(_ *gob.Decoder) (any, error) {
+	panic("Unexpected")
+}
+
 func ReadSysinfoNodeDataSlice(
 	files []*LogFile,
 	reader ReadSyncMethods,
@@ -34,6 +34,10 @@ func MakeClusterDataDirPath(jobanalyzerDir string) string {
 	return path.Join(jobanalyzerDir, dataDirName)
 }
 
+func MakeClusterConfigDirPath(jobanalyzerDir string) string {
+	return path.Join(jobanalyzerDir, clusterConfigDirName)
+}
+
 func MakeClusterAliasesPath(jobanalyzerDir string) string {
 	return path.Join(jobanalyzerDir, clusterConfigDirName, clusterAliasesFilename)
 }
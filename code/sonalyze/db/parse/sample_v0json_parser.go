@@ -49,7 +49,7 @@ func ParseSamplesV0JSON(
 		timestamp := ti.Unix()
 		bo, err := time.Parse(time.RFC3339, string(data.System.Boot))
 		var boot int64
-		if err != nil {
+		if err == nil {
 			boot = bo.Unix()
 		}
 		cluster := ustrs.Alloc(string(data.Cluster))
@@ -143,7 +143,7 @@ func ParseSamplesV0JSON(
 				var gpuKib uint64
 				var gpuFail uint8
 				for _, g := range process.Gpus {
-					pgpus, _ = gpuset.Adjoin(pgpus, uint32(g.Index))
+					pgpus = gpuset.Adjoin(pgpus, uint32(g.Index))
 					gpuPct += g.GpuUtil
 					gpuMemPct += g.GpuMemoryUtil
 					gpuKib += g.GpuMemory
@@ -12,6 +12,8 @@
 package repr
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"unsafe"
 
@@ -75,6 +77,49 @@ func decodeEncodedCpuSamples(e EncodedCpuSamples) ([]byte, []uint64) {
 	}
 }
 
+// Serialization for the on-disk cache: a tag byte followed by the raw bytes or the varint-encoded
+// values.
+func (e EncodedCpuSamples) GobEncode() ([]byte, error) {
+	switch xs := e.x.(type) {
+	case []byte:
+		return append([]byte{encodedAsBytes}, xs...), nil
+	case []uint64:
+		bs := make([]byte, 1, 1+len(xs)*3)
+		bs[0] = encodedAsValues
+		for _, x := range xs {
+			bs = binary.AppendUvarint(bs, x)
+		}
+		return bs, nil
+	default:
+		return nil, errors.New("Unexpected CPU sample encoding")
+	}
+}
+
+func (e *EncodedCpuSamples) GobDecode(bs []byte) error {
+	if len(bs) == 0 {
+		return gobDecodeError
+	}
+	switch bs[0] {
+	case encodedAsBytes:
+		e.x = bytes.Clone(bs[1:])
+	case encodedAsValues:
+		xs := make([]uint64, 0, len(bs)/2)
+		bs = bs[1:]
+		for len(bs) > 0 {
+			x, n := binary.Uvarint(bs)
+			if n <= 0 {
+				return gobDecodeError
+			}
+			xs = append(xs, x)
+			bs = bs[n:]
+		}
+		e.x = xs
+	default:
+		return gobDecodeError
+	}
+	return nil
+}
+
 // Decode base-45 delta-encoded data, see Sonar documentation.
 
 const (
@@ -22,6 +22,9 @@
 package repr
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -107,6 +110,43 @@ func decodeEncodedGpuSamples(e EncodedGpuSamples) ([]byte, []PerGpuSample) {
 	}
 }
 
+// Serialization for the on-disk cache: a tag byte followed by the raw bytes or the JSON-encoded
+// values (the latter because the values are the Sonar JSON representation).
+func (e EncodedGpuSamples) GobEncode() ([]byte, error) {
+	switch xs := e.x.(type) {
+	case []byte:
+		return append([]byte{encodedAsBytes}, xs...), nil
+	case []PerGpuSample:
+		bs, err := json.Marshal(xs)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{encodedAsValues}, bs...), nil
+	default:
+		return nil, errors.New("Unexpected GPU sample encoding")
+	}
+}
+
+func (e *EncodedGpuSamples) GobDecode(bs []byte) error {
+	if len(bs) == 0 {
+		return gobDecodeError
+	}
+	switch bs[0] {
+	case encodedAsBytes:
+		e.x = bytes.Clone(bs[1:])
+	case encodedAsValues:
+		var xs []PerGpuSample
+		err := json.Unmarshal(bs[1:], &xs)
+		if err != nil {
+			return err
+		}
+		e.x = xs
+	default:
+		return gobDecodeError
+	}
+	return nil
+}
+
 // Decode GPU sample data.
 func DecodeEncodedGpuSamples(edata EncodedGpuSamples) (result []PerGpuSample, err error) {
 	data, decodedVals := decodeEncodedGpuSamples(edata)
@@ -133,6 +173,9 @@ func decodeCSVEncodedGpuSamples(data []byte) []PerGpuSample {
 		data := strings.Split(adata, "|")
 		if result == nil {
 			result = make([]PerGpuSample, len(data))
+			for i := range result {
+				result[i].SampleGpu = &newfmt.SampleGpu{Index: uint64(i)}
+			}
 		}
 		for i := 0; i < len(data); i++ {
 			switch tag {
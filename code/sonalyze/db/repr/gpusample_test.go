@@ -0,0 +1,20 @@
+package repr
+
+import (
+	"testing"
+)
+
+func TestDecodeCSVGpuSamples(t *testing.T) {
+	xs, err := DecodeEncodedGpuSamples(EncodedGpuSamplesFromBytes(
+		[]byte("fan%=27|28,perf=P8|P2,musekib=1024|2048,tempc=26|27,poww=5|200,powlimw=250|300")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(xs) != 2 {
+		t.Fatalf("Length %d", len(xs))
+	}
+	if xs[0].Index != 0 || xs[0].Fan != 27 || xs[0].Memory != 1024 || xs[0].Power != 5 ||
+		xs[1].Index != 1 || xs[1].Temperature != 27 || xs[1].Power != 200 || xs[1].PowerLimit != 300 {
+		t.Fatalf("Decoded %+v %+v", *xs[0].SampleGpu, *xs[1].SampleGpu)
+	}
+}
@@ -1,6 +1,7 @@
 package repr
 
 import (
+	"errors"
 	"unsafe"
 )
 
@@ -10,6 +11,14 @@ var (
 	StringSize  uintptr
 )
 
+// Tags for the serialized forms of the encoded sample unions, see cpusample.go and gpusample.go.
+const (
+	encodedAsBytes  = byte(0)
+	encodedAsValues = byte(1)
+)
+
+var gobDecodeError = errors.New("Could not decode serialized sample data")
+
 func init() {
 	var x *int
 	PointerSize = unsafe.Sizeof(x)
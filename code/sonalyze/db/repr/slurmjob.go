@@ -12,6 +12,8 @@
 package repr
 
 import (
+	"strconv"
+	"strings"
 	"unsafe"
 
 	. "sonalyze/common"
@@ -91,6 +93,30 @@ type SacctInfo struct {
 	Priority     uint64
 }
 
+// The number of CPUs for the job as a whole, across all its nodes.  This is ReqCPUS, which Slurm
+// reports as the total for the job, or if that is not recorded then the cpu= entry of AllocRes,
+// which is also a total.  Returns 0 if neither is known.
+
+func (s *SacctInfo) TotalCPUs() uint32 {
+	if s.ReqCPUS != 0 {
+		return s.ReqCPUS
+	}
+	for _, res := range strings.Split(s.AllocRes.String(), ",") {
+		if n, found := strings.CutPrefix(res, "cpu="); found {
+			if k, err := strconv.ParseUint(n, 10, 32); err == nil {
+				return uint32(k)
+			}
+		}
+	}
+	return 0
+}
+
+// The requested memory in KB for the job as a whole.  ReqMem is per node.
+
+func (s *SacctInfo) TotalReqMem() uint64 {
+	return s.ReqMem * uint64(max(s.ReqNodes, 1))
+}
+
 var (
 	// MT: Constant after initialization; immutable
 	SizeofSacctInfo uintptr
@@ -0,0 +1,36 @@
+package repr
+
+import (
+	"testing"
+
+	. "sonalyze/common"
+)
+
+func TestSacctInfoTotals(t *testing.T) {
+	s := SacctInfo{
+		ReqCPUS:  2,
+		ReqNodes: 2,
+		ReqMem:   8388608,
+		AllocRes: StringToUstr("billing=4,cpu=4,gres/gpu=1,node=2"),
+	}
+	if s.TotalCPUs() != 2 {
+		t.Fatalf("TotalCPUs %d", s.TotalCPUs())
+	}
+	if s.TotalReqMem() != 2*8388608 {
+		t.Fatalf("TotalReqMem %d", s.TotalReqMem())
+	}
+
+	s.ReqCPUS = 0
+	s.ReqNodes = 0
+	if s.TotalCPUs() != 4 {
+		t.Fatalf("TotalCPUs from AllocRes %d", s.TotalCPUs())
+	}
+	if s.TotalReqMem() != 8388608 {
+		t.Fatalf("TotalReqMem without nodes %d", s.TotalReqMem())
+	}
+
+	s.AllocRes = StringToUstr("")
+	if s.TotalCPUs() != 0 {
+		t.Fatalf("TotalCPUs unknown %d", s.TotalCPUs())
+	}
+}
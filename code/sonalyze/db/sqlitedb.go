@@ -0,0 +1,420 @@
+// This is a read-write interface to a single-file SQLite database, allowing a small installation to
+// have a transactional data store without running a database server.  The database is selected
+// with `-database-uri sqlite:///path/to/file.db` (or `sqlite://relative/path.db`).  The file is
+// created if it does not exist.
+//
+// The database is organized like the directory tree store: it holds the records exactly as they
+// were received, one row per record, tagged with cluster, data stream, data format, host and time.
+// Records are parsed by the same parsers as are used for files when they are read, and there is no
+// schema for the contents of the records.  This keeps the store faithful to the input and means
+// that new input formats and new fields in the input need no database migration.  The cost is that
+// reads must parse, and that the database can only filter on the tags.
+//
+// Here we read raw data from the database every time, no caching in Sonalyze, as for timescaledb.
+//
+// Insertion is synchronous, each record is committed in its own transaction when the Append method
+// returns.  Hence FlushAsync and Close have nothing to do.
+//
+// The database is opened in WAL mode so that the daemon can write while sonalyze subprocesses read,
+// and lock contention is handled by a busy timeout.
+
+package db
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	. "sonalyze/common"
+	"sonalyze/db/errs"
+	"sonalyze/db/filedb"
+	"sonalyze/db/repr"
+	"sonalyze/db/types"
+	"sonalyze/db/util"
+)
+
+const (
+	sqliteURIPrefix = "sqlite://"
+
+	// Milliseconds to wait for a lock before failing.
+	sqliteBusyTimeout = 10000
+)
+
+// The data streams, these are stored in the database and must never change.
+type sqliteStream int
+
+const (
+	sqliteSamples sqliteStream = 1
+	sqliteSysinfo sqliteStream = 2
+	sqliteSacct   sqliteStream = 3
+	sqliteCluzter sqliteStream = 4
+)
+
+// The format column holds a DataReprType.  The filedb.FileAttr values are therefore part of the
+// database format and must never change.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS clusters (
+    name    TEXT PRIMARY KEY
+);
+CREATE TABLE IF NOT EXISTS records (
+    cluster TEXT NOT NULL,
+    stream  INTEGER NOT NULL,
+    format  INTEGER NOT NULL,
+    host    TEXT NOT NULL,
+    time    INTEGER NOT NULL,
+    payload BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS records_by_time ON records (cluster, stream, time);
+`
+
+// Return true iff the URI names an SQLite database.
+func IsSqliteURI(databaseURI string) bool {
+	return strings.HasPrefix(databaseURI, sqliteURIPrefix)
+}
+
+// As for the databaseConnection, there is one sqliteConnection globally and it is never closed.
+// The sql.DB is thread-safe.
+type sqliteConnection struct {
+	connection *sql.DB
+}
+
+func OpenSqliteURI(databaseURI string) (*sqliteConnection, error) {
+	filename := strings.TrimPrefix(databaseURI, sqliteURIPrefix)
+	if filename == "" {
+		return nil, errors.New("Empty file name in SQLite database URI")
+	}
+	dsn := fmt.Sprintf(
+		"file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)",
+		filename,
+		sqliteBusyTimeout,
+	)
+	connection, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to open database: %v", err)
+	}
+	_, err = connection.Exec(sqliteSchema)
+	if err != nil {
+		connection.Close()
+		return nil, fmt.Errorf("Unable to initialize database: %v", err)
+	}
+	return &sqliteConnection{connection}, nil
+}
+
+func (sc *sqliteConnection) EnumerateClusters() ([]string, error) {
+	rows, err := sc.connection.Query("SELECT name FROM clusters")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	clusters := make([]string, 0)
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, name)
+	}
+	return clusters, rows.Err()
+}
+
+type sqliteDB struct {
+	theDB     *sqliteConnection
+	cx        types.Context
+	timeCache *util.TimeCache
+}
+
+var _ = AppendablePersistentDataProvider((*sqliteDB)(nil))
+
+func openSqliteDB(theDB *sqliteConnection, cx types.Context) AppendablePersistentDataProvider {
+	timeCache := util.NewTimeCache(makeRefillSqliteTimeCache(theDB, cx.ClusterName()))
+	return &sqliteDB{theDB, cx, timeCache}
+}
+
+func (sdb *sqliteDB) ReadProcessSamples(
+	filter types.DataProviderFilter,
+) (sampleBlobs [][]*repr.Sample, softErrors int, err error) {
+	return sqliteReadRecords[repr.Sample](
+		sdb, sqliteSamples, filter, filedb.NewSampleFileMethods(filedb.SampleFileKindSample))
+}
+
+func (sdb *sqliteDB) ReadNodeSamples(
+	filter types.DataProviderFilter,
+) (sampleBlobs [][]*repr.NodeSample, softErrors int, err error) {
+	return sqliteReadRecords[repr.NodeSample](
+		sdb, sqliteSamples, filter, filedb.NewSampleFileMethods(filedb.SampleFileKindNodeSample))
+}
+
+func (sdb *sqliteDB) ReadDiskSamples(
+	filter types.DataProviderFilter,
+) (dataBlobs [][]*repr.DiskSample, softErrors int, err error) {
+	return sqliteReadRecords[repr.DiskSample](
+		sdb, sqliteSamples, filter, filedb.NewSampleFileMethods(filedb.SampleFileKindDiskSample))
+}
+
+func (sdb *sqliteDB) ReadCpuSamples(
+	filter types.DataProviderFilter,
+) (dataBlobs [][]*repr.CpuSamples, softErrors int, err error) {
+	return sqliteReadRecords[repr.CpuSamples](
+		sdb, sqliteSamples, filter, filedb.NewSampleFileMethods(filedb.SampleFileKindCpuSamples))
+}
+
+func (sdb *sqliteDB) ReadGpuSamples(
+	filter types.DataProviderFilter,
+) (dataBlobs [][]*repr.GpuSamples, softErrors int, err error) {
+	return sqliteReadRecords[repr.GpuSamples](
+		sdb, sqliteSamples, filter, filedb.NewSampleFileMethods(filedb.SampleFileKindGpuSamples))
+}
+
+func (sdb *sqliteDB) ReadSysinfoNodeData(
+	filter types.DataProviderFilter,
+) (sysinfoBlobs [][]*repr.SysinfoNodeData, softErrors int, err error) {
+	return sqliteReadRecords[repr.SysinfoNodeData](
+		sdb, sqliteSysinfo, filter, filedb.NewSysinfoFileMethods(filedb.SysinfoFileKindNodeData))
+}
+
+func (sdb *sqliteDB) ReadSysinfoCardData(
+	filter types.DataProviderFilter,
+) (sysinfoBlobs [][]*repr.SysinfoCardData, softErrors int, err error) {
+	return sqliteReadRecords[repr.SysinfoCardData](
+		sdb, sqliteSysinfo, filter, filedb.NewSysinfoFileMethods(filedb.SysinfoFileKindCardData))
+}
+
+func (sdb *sqliteDB) ReadSacctData(
+	filter types.DataProviderFilter,
+) (recordBlobs [][]*repr.SacctInfo, softErrors int, err error) {
+	return sqliteReadRecords[repr.SacctInfo](
+		sdb, sqliteSacct, filter, filedb.NewSacctFileMethods())
+}
+
+func (sdb *sqliteDB) ReadCluzterAttributeData(
+	filter types.DataProviderFilter,
+) (recordBlobs [][]*repr.CluzterAttributes, softErrors int, err error) {
+	return sqliteReadRecords[repr.CluzterAttributes](
+		sdb, sqliteCluzter, filter, filedb.NewCluzterFileMethods(filedb.CluzterFileKindAttributeData))
+}
+
+func (sdb *sqliteDB) ReadCluzterPartitionData(
+	filter types.DataProviderFilter,
+) (recordBlobs [][]*repr.CluzterPartitions, softErrors int, err error) {
+	return sqliteReadRecords[repr.CluzterPartitions](
+		sdb, sqliteCluzter, filter, filedb.NewCluzterFileMethods(filedb.CluzterFileKindPartitionData))
+}
+
+func (sdb *sqliteDB) ReadCluzterNodeData(
+	filter types.DataProviderFilter,
+) (recordBlobs [][]*repr.CluzterNodes, softErrors int, err error) {
+	return sqliteReadRecords[repr.CluzterNodes](
+		sdb, sqliteCluzter, filter, filedb.NewCluzterFileMethods(filedb.CluzterFileKindNodeData))
+}
+
+func (sdb *sqliteDB) MinTime(soft bool) (time.Time, error) {
+	return sdb.timeCache.MinTime(soft)
+}
+
+func (sdb *sqliteDB) MaxTime(soft bool) (time.Time, error) {
+	return sdb.timeCache.MaxTime(soft)
+}
+
+func makeRefillSqliteTimeCache(
+	sc *sqliteConnection,
+	clusterName string,
+) func() (time.Time, time.Time, error) {
+	return func() (low, high time.Time, err error) {
+		var lowp, highp sql.NullInt64
+		err = sc.connection.QueryRow(
+			`SELECT MIN(time), MAX(time) FROM records WHERE cluster = ?`,
+			clusterName,
+		).Scan(&lowp, &highp)
+		if err != nil {
+			return
+		}
+		if lowp.Valid {
+			low = time.Unix(lowp.Int64, 0).UTC()
+		}
+		if highp.Valid {
+			high = time.Unix(highp.Int64, 0).UTC()
+		}
+		return
+	}
+}
+
+// Select the records for the stream in the date range and parse them.  Runs of records (in time
+// order) with the same host and format are parsed together and yield one blob, much as a file
+// would.  The host filter is applied only to the streams that have host names.
+
+func sqliteReadRecords[T any](
+	sdb *sqliteDB,
+	stream sqliteStream,
+	filter types.DataProviderFilter,
+	methods filedb.ReadSyncMethods,
+) (recordBlobs [][]*T, softErrors int, err error) {
+	fromDate := ThisDay(filter.FromDate)
+	toDate := RoundupDay(filter.ToDate)
+	rows, err := sdb.theDB.connection.Query(
+		`SELECT host, format, payload FROM records `+
+			`WHERE cluster = ? AND stream = ? AND time >= ? AND time < ? `+
+			`ORDER BY host, time, rowid`,
+		sdb.cx.ClusterName(), int(stream), fromDate.Unix(), toDate.Unix(),
+	)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	filterHosts := (stream == sqliteSamples || stream == sqliteSysinfo) && !filter.Node.IsEmpty()
+	uf := NewUstrCache()
+	recordBlobs = make([][]*T, 0)
+
+	var (
+		buf         bytes.Buffer
+		currHost    string
+		currFormat  DataReprType
+		haveCurrent bool
+	)
+	flush := func() error {
+		if buf.Len() == 0 {
+			return nil
+		}
+		payload, soft, err := methods.ReadDataLocked(currFormat, &buf, uf)
+		buf.Reset()
+		if err != nil {
+			return err
+		}
+		softErrors += soft
+		if data := methods.SelectDataFromPayload(payload).([]*T); len(data) > 0 {
+			recordBlobs = append(recordBlobs, data)
+		}
+		return nil
+	}
+
+	for rows.Next() {
+		var (
+			host    string
+			format  int
+			payload []byte
+		)
+		err = rows.Scan(&host, &format, &payload)
+		if err != nil {
+			return
+		}
+		if filterHosts && !filter.Node.HostnameGlobber().Match(host) {
+			continue
+		}
+		if !haveCurrent || host != currHost || DataReprType(format) != currFormat {
+			err = flush()
+			if err != nil {
+				return
+			}
+			currHost = host
+			currFormat = DataReprType(format)
+			haveCurrent = true
+		}
+		buf.Write(payload)
+		if len(payload) > 0 && payload[len(payload)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return
+	}
+	err = flush()
+	if Verbose {
+		Log.Infof("SQLite: Retrieved %d blobs", len(recordBlobs))
+	}
+	return
+}
+
+func (sdb *sqliteDB) AppendSamplesAsync(ty DataReprType, host, timestamp string, payload any) error {
+	switch ty {
+	case DataSampleCSV, DataSampleV0JSON:
+		return sdb.insert(sqliteSamples, ty, host, timestamp, payload)
+	default:
+		panic("Unsupported 'sample' data format")
+	}
+}
+
+func (sdb *sqliteDB) AppendSysinfoAsync(ty DataReprType, host, timestamp string, payload any) error {
+	switch ty {
+	case DataSysinfoOldJSON, DataSysinfoV0JSON:
+		return sdb.insert(sqliteSysinfo, ty, host, timestamp, payload)
+	default:
+		panic("Unsupported 'sysinfo' data format")
+	}
+}
+
+func (sdb *sqliteDB) AppendSlurmSacctAsync(ty DataReprType, timestamp string, payload any) error {
+	switch ty {
+	case DataSlurmCSV, DataSlurmV0JSON:
+		return sdb.insert(sqliteSacct, ty, "", timestamp, payload)
+	default:
+		panic("Unsupported 'slurm' data format")
+	}
+}
+
+func (sdb *sqliteDB) AppendCluzterAsync(ty DataReprType, timestamp string, payload any) error {
+	switch ty {
+	case DataCluzterV0JSON:
+		return sdb.insert(sqliteCluzter, ty, "", timestamp, payload)
+	default:
+		panic("Unsupported 'cluzter' data format")
+	}
+}
+
+func (sdb *sqliteDB) insert(
+	stream sqliteStream,
+	ty DataReprType,
+	host, timestamp string,
+	payload any,
+) error {
+	var data []byte
+	switch x := payload.(type) {
+	case []byte:
+		data = x
+	case string:
+		data = []byte(x)
+	default:
+		return errors.New("Payload must be string or []byte")
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	tval, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return errs.BadTimestampErr
+	}
+
+	cluster := sdb.cx.ClusterName()
+	tx, err := sdb.theDB.connection.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(`INSERT OR IGNORE INTO clusters (name) VALUES (?)`, cluster)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`INSERT INTO records (cluster, stream, format, host, time, payload) VALUES (?, ?, ?, ?, ?, ?)`,
+		cluster, int(stream), int(ty), host, tval.UTC().Unix(), data,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (sdb *sqliteDB) FlushAsync() {
+	// Do nothing
+}
+
+func (sdb *sqliteDB) Close() error {
+	// Do nothing
+	return nil
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+
+	. "sonalyze/common"
+	"sonalyze/db/errs"
+	"sonalyze/db/filedb"
+	"sonalyze/db/special"
+	"sonalyze/db/types"
+)
+
+const sqliteTestData = "filedb/testdata/data/cluster1.uio.no"
+
+func TestSqlite(t *testing.T) {
+	uri := "sqlite://" + path.Join(t.TempDir(), "test.db")
+	conn, err := OpenSqliteURI(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := special.NewClusterEntry()
+	c.Name = "cluster1.uio.no"
+	c.HaveDatabase = true
+	c.DatabaseConnection = conn
+	cx := NewContextFromCluster(c)
+	ds := OpenConnectedDB(cx)
+
+	readFile := func(name string) []byte {
+		bs, err := os.ReadFile(path.Join(sqliteTestData, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bs
+	}
+	parseFile := func(name string, ty DataReprType, methods filedb.ReadSyncMethods) any {
+		f, err := os.Open(path.Join(sqliteTestData, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		payload, _, err := methods.ReadDataLocked(ty, f, NewUstrCache())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return methods.SelectDataFromPayload(payload)
+	}
+
+	err = ds.AppendSamplesAsync(DataSampleV0JSON, "n1.cluster1.uio.no", "2025-04-13T10:00:00Z",
+		readFile("2025/04/13/0+sample-n1.cluster1.uio.no.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ds.AppendSamplesAsync(DataSampleCSV, "n1.cluster1.uio.no", "2025-04-12T10:00:00Z",
+		string(readFile("2025/04/12/n1.cluster1.uio.no.csv")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ds.AppendSysinfoAsync(DataSysinfoV0JSON, "n1.cluster1.uio.no", "2025-04-13T10:00:00Z",
+		readFile("2025/04/13/0+sysinfo-n1.cluster1.uio.no.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ds.AppendSlurmSacctAsync(DataSlurmCSV, "2025-05-03T10:00:00Z",
+		readFile("2025/05/03/slurm-sacct.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ds.AppendCluzterAsync(DataCluzterV0JSON, "2025-05-03T10:00:00Z",
+		readFile("2025/05/03/0+cluzter-slurm.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ds.AppendSamplesAsync(DataSampleCSV, "n1.cluster1.uio.no", "yesterday", "x")
+	if err != errs.BadTimestampErr {
+		t.Errorf("Expected bad timestamp")
+	}
+	ds.FlushAsync()
+
+	clusters, err := conn.EnumerateClusters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(clusters, []string{"cluster1.uio.no"}) {
+		t.Fatalf("Clusters: %v", clusters)
+	}
+
+	all := types.DataProviderFilter{
+		FromDate: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		ToDate:   time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Records read from the database are the same as records read from the files.
+	samples, _, err := ds.ReadProcessSamples(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 ||
+		!reflect.DeepEqual(samples[0], parseFile("2025/04/12/n1.cluster1.uio.no.csv", DataSampleCSV,
+			filedb.NewSampleFileMethods(filedb.SampleFileKindSample))) ||
+		!reflect.DeepEqual(samples[1], parseFile("2025/04/13/0+sample-n1.cluster1.uio.no.json",
+			DataSampleV0JSON, filedb.NewSampleFileMethods(filedb.SampleFileKindSample))) {
+		t.Errorf("Samples differ")
+	}
+	nodeData, _, err := ds.ReadSysinfoNodeData(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodeData) != 1 ||
+		!reflect.DeepEqual(nodeData[0], parseFile("2025/04/13/0+sysinfo-n1.cluster1.uio.no.json",
+			DataSysinfoV0JSON, filedb.NewSysinfoFileMethods(filedb.SysinfoFileKindNodeData))) {
+		t.Errorf("Sysinfo differs")
+	}
+	sacct, _, err := ds.ReadSacctData(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sacct) != 1 ||
+		!reflect.DeepEqual(sacct[0], parseFile("2025/05/03/slurm-sacct.csv", DataSlurmCSV,
+			filedb.NewSacctFileMethods())) {
+		t.Errorf("Sacct data differ")
+	}
+	partitions, _, err := ds.ReadCluzterPartitionData(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(partitions) != 1 ||
+		!reflect.DeepEqual(partitions[0], parseFile("2025/05/03/0+cluzter-slurm.json",
+			DataCluzterV0JSON, filedb.NewCluzterFileMethods(filedb.CluzterFileKindPartitionData))) {
+		t.Errorf("Cluzter data differ")
+	}
+
+	// Date and host filtering.
+	samples, _, err = ds.ReadProcessSamples(types.DataProviderFilter{
+		FromDate: time.Date(2025, 4, 13, 0, 0, 0, 0, time.UTC),
+		ToDate:   time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 1 || samples[0][0].Timestamp < time.Date(2025, 4, 13, 0, 0, 0, 0, time.UTC).Unix() {
+		t.Errorf("Date filter")
+	}
+	hosts, err := NewHostsFromPatterns("n2*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples, _, err = ds.ReadProcessSamples(types.DataProviderFilter{
+		FromDate: all.FromDate,
+		ToDate:   all.ToDate,
+		Node:     hosts,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 0 {
+		t.Errorf("Host filter")
+	}
+
+	minTime, err := ds.MinTime(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxTime, err := ds.MaxTime(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if minTime != time.Date(2025, 4, 12, 10, 0, 0, 0, time.UTC) ||
+		maxTime != time.Date(2025, 5, 3, 10, 0, 0, 0, time.UTC) {
+		t.Errorf("Min/max time %v %v", minTime, maxTime)
+	}
+}
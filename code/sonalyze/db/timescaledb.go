@@ -4,7 +4,7 @@
 // code.
 //
 // The interface is read-only because ingestion into timescaledb is handled by external ingestion
-// code, as part of slurm-monitor.  The insertion methods on the DB returned from OpenConnectedDB
+// code, as part of slurm-monitor.  The insertion methods on the DB returned from openTimescaleDB
 // will panic, do not call them.  It is sufficient for the daemon to run without -kafka and with
 // -no-add for this functionality not to be touched.
 //
@@ -156,8 +156,7 @@ type connectedDB struct {
 
 var _ = AppendablePersistentDataProvider((*connectedDB)(nil))
 
-func OpenConnectedDB(cx types.Context) AppendablePersistentDataProvider {
-	theDB := cx.ConnectedDB().(*databaseConnection)
+func openTimescaleDB(theDB *databaseConnection, cx types.Context) AppendablePersistentDataProvider {
 	timeCache := util.NewTimeCache(makeRefillTimeCache(theDB, cx.ClusterName()))
 	return &connectedDB{theDB, cx, timeCache}
 }
@@ -231,7 +230,7 @@ func (cdb *connectedDB) ReadProcessSamples(
 		if gpuCount > 0 {
 			// Note, information about precise indices is lost
 			for i := 0; i < gpuCount; i++ {
-				gpus, _ = gpuset.Adjoin(gpus, 1<<i)
+				gpus = gpuset.Adjoin(gpus, uint32(i))
 			}
 		}
 		var gpuMemory uint64
@@ -994,7 +993,8 @@ func querySlice[T any](
 	// generating data.  Note in particular that we can apply lossy abbreviations as long as they
 	// find everything a precise match would find.
 
-	if !q.Node.IsEmpty() {
+	// Exclusions can't be expressed as a selection, so leave it all to the post-filtering.
+	if !q.Node.IsEmpty() && !hasExclusions(q.Node) {
 		fieldname := mapField("node", q.fieldMap)
 		if fieldname == "nodes" {
 			// As a special hack, when "node" maps to "nodes" then the selector turns into set
@@ -1143,3 +1143,12 @@ func (cdb *connectedDB) Close() error {
 	// Do nothing
 	return nil
 }
+
+func hasExclusions(h Hosts) bool {
+	for _, p := range h.Patterns() {
+		if _, isExclusion := hostglob.CutExclusion(p); isExclusion {
+			return true
+		}
+	}
+	return false
+}
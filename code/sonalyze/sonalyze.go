@@ -279,6 +279,9 @@ func OneShotHandleSingleCommand(
 		}
 	}
 	meta := db.NewContextFromCluster(ce)
+	if err := cmd.ResolveTimeZone(anyCmd, meta); err != nil {
+		return err
+	}
 	switch command := anyCmd.(type) {
 	case cmd.SampleAnalysisCommand:
 		return application.LocalSampleOperation(meta, command, stdin, stdout, stderr)
@@ -47,14 +47,14 @@ func FormatDateValue(val DateValue, ctx PrintMods) string {
 	if (ctx&PrintModNoDefaults) != 0 && val == 0 {
 		return "*skip*"
 	}
-	return time.Unix(int64(val), 0).UTC().Format("2006-01-02")
+	return time.Unix(int64(val), 0).In(PrintModsLocation(ctx)).Format("2006-01-02")
 }
 
 func FormatTimeValue(val TimeValue, ctx PrintMods) string {
 	if (ctx&PrintModNoDefaults) != 0 && val == 0 {
 		return "*skip*"
 	}
-	return time.Unix(int64(val), 0).UTC().Format("15:04")
+	return time.Unix(int64(val), 0).In(PrintModsLocation(ctx)).Format("15:04")
 }
 
 func FormatUstr(val Ustr, ctx PrintMods) string {
@@ -229,9 +229,9 @@ func FormatDateTimeValue(timestamp DateTimeValue, ctx PrintMods) string {
 		return fmt.Sprint(timestamp)
 	}
 	if (ctx & PrintModIso) != 0 {
-		return FormatIsoUtc(timestamp)
+		return FormatIso(timestamp, PrintModsLocation(ctx))
 	}
-	return FormatYyyyMmDdHhMmUtc(timestamp)
+	return FormatYyyyMmDdHhMm(timestamp, PrintModsLocation(ctx))
 }
 
 func FormatDateTimeValueOrBlank(val DateTimeValueOrBlank, ctx PrintMods) string {
@@ -254,11 +254,19 @@ func FormatIsoDateTimeOrUnknown(t IsoDateTimeOrUnknown, ctx PrintMods) string {
 }
 
 func FormatYyyyMmDdHhMmUtc(t int64) string {
-	return time.Unix(t, 0).UTC().Format("2006-01-02 15:04")
+	return FormatYyyyMmDdHhMm(t, time.UTC)
+}
+
+func FormatYyyyMmDdHhMm(t int64, loc *time.Location) string {
+	return time.Unix(t, 0).In(loc).Format("2006-01-02 15:04")
 }
 
 func FormatIsoUtc(t int64) string {
-	return time.Unix(t, 0).UTC().Format(time.RFC3339)
+	return FormatIso(t, time.UTC)
+}
+
+func FormatIso(t int64, loc *time.Location) string {
+	return time.Unix(t, 0).In(loc).Format(time.RFC3339)
 }
 
 func FormatHostnames(x *Hostnames, ctx PrintMods) string {
@@ -203,6 +203,19 @@ func TestDataFormatting(t *testing.T) {
 	if s := FormatDateTimeValue(now, PrintModIso); s != "2024-11-25T07:02:53Z" {
 		t.Fatalf("DateTimeValue %s", s)
 	}
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := FormatDateTimeValue(now, LocationPrintMods(oslo)); s != "2024-11-25 08:02" {
+		t.Fatalf("DateTimeValue %s", s)
+	}
+	if s := FormatDateTimeValue(now, LocationPrintMods(oslo)|PrintModIso); s != "2024-11-25T08:02:53+01:00" {
+		t.Fatalf("DateTimeValue %s", s)
+	}
+	if s := FormatDateTimeValue(now, LocationPrintMods(time.UTC)|PrintModIso); s != "2024-11-25T07:02:53Z" {
+		t.Fatalf("DateTimeValue %s", s)
+	}
 	if s := FormatDateTimeValue(0, PrintModNoDefaults); s != "*skip*" {
 		t.Fatalf("DateTimeValue %s", s)
 	}
@@ -372,8 +385,12 @@ func TestCvt2GpuSet(t *testing.T) {
 	xs, err = CvtString2GpuSet("1,2,3")
 	check(t, err)
 	s := gpuset.EmptyGpuSet()
-	s, err = gpuset.Adjoin(s, 1, 2, 3)
+	s = gpuset.Adjoin(s, 1, 2, 3)
+	same(t, xs, s)
+
+	xs, err = CvtString2GpuSet("40,3:1")
 	check(t, err)
+	s = gpuset.AdjoinMig(gpuset.Adjoin(gpuset.EmptyGpuSet(), 40), 3, 1)
 	same(t, xs, s)
 }
 
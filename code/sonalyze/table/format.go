@@ -9,6 +9,9 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 	"unicode/utf8"
 
 	umaps "go-utils/maps"
@@ -58,24 +61,66 @@ func ComputePrintMods(opts *FormatOptions) PrintMods {
 	if opts.NoDefaults && (opts.Csv || opts.Json || opts.Native) {
 		x |= PrintModNoDefaults
 	}
+	x |= LocationPrintMods(opts.Location)
 	return x
 }
 
+// The time zone for timestamps is carried in the high bits of the PrintMods as an index into a
+// table of locations that only ever grows, so that the formatters, which see only the PrintMods,
+// can print local time.  Index 0 is UTC.  The table is copy-on-write because the daemon runs
+// commands concurrently.
+
+const printModZoneShift = 16
+
+var (
+	zoneLock  sync.Mutex
+	zoneTable atomic.Pointer[[]*time.Location]
+)
+
+func LocationPrintMods(loc *time.Location) PrintMods {
+	if loc == nil || loc == time.UTC {
+		return 0
+	}
+	zoneLock.Lock()
+	defer zoneLock.Unlock()
+	var zones []*time.Location
+	if p := zoneTable.Load(); p != nil {
+		zones = *p
+	}
+	for i, z := range zones {
+		if z == loc || z.String() == loc.String() {
+			return (i + 1) << printModZoneShift
+		}
+	}
+	zones = append(slices.Clone(zones), loc)
+	zoneTable.Store(&zones)
+	return len(zones) << printModZoneShift
+}
+
+func PrintModsLocation(ctx PrintMods) *time.Location {
+	ix := ctx >> printModZoneShift
+	if ix == 0 {
+		return time.UTC
+	}
+	return (*zoneTable.Load())[ix-1]
+}
+
 ///////////////////////////////////////////////////////////////////////////////////////////////////
 //
 // Formatting specs
 
 type FormatOptions struct {
-	Tag        string // if not ""
-	Json       bool   // json explicitly requested
-	Native     bool   // native (= json with natural format) explicitly requested
-	Csv        bool   // csv or csvnamed explicitly requested
-	Awk        bool   // awk explicitly requested
-	Fixed      bool   // fixed output explicitly requested
-	Named      bool   // csvnamed explicitly requested
-	Header     bool   // true if nothing requested b/c fixed+header is default
-	NoDefaults bool   // if true and the string returned is "*skip*" and the mode is csv or json then print nothing
-	Separator  bool   // for some commands, print a separator between natural runs in the output
+	Tag        string         // if not ""
+	Json       bool           // json explicitly requested
+	Native     bool           // native (= json with natural format) explicitly requested
+	Csv        bool           // csv or csvnamed explicitly requested
+	Awk        bool           // awk explicitly requested
+	Fixed      bool           // fixed output explicitly requested
+	Named      bool           // csvnamed explicitly requested
+	Header     bool           // true if nothing requested b/c fixed+header is default
+	NoDefaults bool           // if true and the string returned is "*skip*" and the mode is csv or json then print nothing
+	Separator  bool           // for some commands, print a separator between natural runs in the output
+	Location   *time.Location // time zone for timestamps, nil for UTC
 }
 
 func (fo *FormatOptions) IsDefaultFormat() bool {
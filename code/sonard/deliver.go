@@ -0,0 +1,197 @@
+// Delivery of sonar output to the daemon, with an optional on-disk spool.
+//
+// Spooled records are files named by their creation time in nanoseconds, so they sort in the order
+// they were created and are delivered in that order.  A record is written to a temporary name and
+// then renamed, so a crash never leaves a partial record in the spool.  Records that the daemon
+// rejects are renamed with a .rejected suffix and left for inspection.  When the spool grows larger
+// than its limit the oldest records, rejected or not, are removed.
+//
+// Authentication failures (bad credentials or an unknown cluster) and throttling are problems that
+// can be fixed on the daemon's side, so those records are retried like the ones that could not be
+// delivered at all, not rejected.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"go-utils/httpclient"
+	"go-utils/status"
+)
+
+const (
+	insertPath   = "/api/v1/insert/sample"
+	jsonMimetype = "application/json"
+	maxBackoff   = time.Hour
+	spoolSuffix  = ".json"
+	rejectSuffix = ".rejected"
+)
+
+type deliverer struct {
+	client      *httpclient.HttpClient
+	spool       string // "" for no spool
+	maxSpool    int64  // bytes, 0 for no limit
+	minBackoff  time.Duration
+	backoff     time.Duration // 0 when the last attempt succeeded
+	nextAttempt time.Time
+}
+
+func newDeliverer(
+	client *httpclient.HttpClient,
+	spool string,
+	maxSpool int64,
+	minBackoff time.Duration,
+) (*deliverer, error) {
+	if spool != "" {
+		if err := os.MkdirAll(spool, 0755); err != nil {
+			return nil, err
+		}
+	}
+	return &deliverer{
+		client:     client,
+		spool:      spool,
+		maxSpool:   maxSpool,
+		minBackoff: minBackoff,
+	}, nil
+}
+
+func (d *deliverer) Deliver(record []byte) {
+	if len(strings.TrimSpace(string(record))) == 0 {
+		return
+	}
+
+	if d.spool == "" {
+		if _, err := d.client.TryPostData(insertPath, jsonMimetype, record); err != nil {
+			status.Warningf("Dropping record: %v", err)
+		}
+		return
+	}
+
+	if err := d.spoolRecord(record); err != nil {
+		status.Errorf("Could not spool record: %v", err)
+		return
+	}
+	d.trimSpool()
+	if time.Now().Before(d.nextAttempt) {
+		return
+	}
+	d.flush()
+}
+
+func (d *deliverer) spoolRecord(record []byte) error {
+	name := path.Join(d.spool, fmt.Sprintf("%020d", time.Now().UnixNano()))
+	if err := os.WriteFile(name+".tmp", record, 0644); err != nil {
+		return err
+	}
+	return os.Rename(name+".tmp", name+spoolSuffix)
+}
+
+// Remove the oldest files from the spool until it is no larger than the limit.
+
+func (d *deliverer) trimSpool() {
+	if d.maxSpool == 0 {
+		return
+	}
+	entries, err := os.ReadDir(d.spool)
+	if err != nil {
+		status.Errorf("Could not read spool: %v", err)
+		return
+	}
+	type spooled struct {
+		name string
+		size int64
+	}
+	files := make([]spooled, 0, len(entries))
+	var total int64
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), spoolSuffix) && !strings.HasSuffix(e.Name(), rejectSuffix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, spooled{e.Name(), info.Size()})
+		total += info.Size()
+	}
+	slices.SortFunc(files, func(a, b spooled) int {
+		return strings.Compare(a.name, b.name)
+	})
+	dropped := 0
+	for _, f := range files {
+		if total <= d.maxSpool {
+			break
+		}
+		if err := os.Remove(path.Join(d.spool, f.name)); err != nil {
+			status.Errorf("Could not remove spooled record: %v", err)
+			continue
+		}
+		total -= f.size
+		dropped++
+	}
+	if dropped > 0 {
+		status.Warningf("Spool is full, dropped the %d oldest records", dropped)
+	}
+}
+
+func retriableStatus(err error) bool {
+	var se *httpclient.StatusError
+	if !errors.As(err, &se) {
+		return false
+	}
+	return se.StatusCode == 401 || se.StatusCode == 403 || se.StatusCode == 429
+}
+
+// Deliver spooled records, oldest first, until the spool is empty or the daemon can't be reached.
+
+func (d *deliverer) flush() {
+	entries, err := os.ReadDir(d.spool)
+	if err != nil {
+		status.Errorf("Could not read spool: %v", err)
+		return
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), spoolSuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		fn := path.Join(d.spool, name)
+		record, err := os.ReadFile(fn)
+		if err != nil {
+			status.Errorf("Could not read spooled record: %v", err)
+			continue
+		}
+		retriable, err := d.client.TryPostData(insertPath, jsonMimetype, record)
+		if err != nil && (retriable || retriableStatus(err)) {
+			if d.backoff == 0 {
+				d.backoff = d.minBackoff
+			} else {
+				d.backoff = min(2*d.backoff, maxBackoff)
+			}
+			d.nextAttempt = time.Now().Add(d.backoff)
+			status.Warningf("Could not deliver, %d records spooled, retrying in %v: %v",
+				len(names), d.backoff, err)
+			return
+		}
+		if err != nil {
+			status.Errorf("Record %s was rejected: %v", name, err)
+			err = os.Rename(fn, fn+rejectSuffix)
+		} else {
+			err = os.Remove(fn)
+		}
+		if err != nil {
+			status.Errorf("Could not remove spooled record: %v", err)
+		}
+	}
+	d.backoff = 0
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"go-utils/httpclient"
+)
+
+// A server that responds with the status in `code`, which the test can change.
+
+func newTestServer(t *testing.T, code *int, received *[]string) *httpclient.HttpClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != insertPath {
+			t.Errorf("Bad path %s", r.URL.Path)
+		}
+		if *code < 300 {
+			body, _ := io.ReadAll(r.Body)
+			*received = append(*received, string(body))
+		}
+		w.WriteHeader(*code)
+	}))
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := httpclient.NewClient(target, "", "", "", 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func spoolContents(t *testing.T, spool string) []string {
+	entries, err := os.ReadDir(spool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, e := range entries {
+		names = append(names, path.Ext(e.Name()))
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestDeliverSpooled(t *testing.T) {
+	code := 202
+	var received []string
+	client := newTestServer(t, &code, &received)
+	spool := t.TempDir()
+	d, err := newDeliverer(client, spool, 0, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2xx: delivered and removed from the spool.
+	d.Deliver([]byte("record 1\n"))
+	if len(received) != 1 || received[0] != "record 1\n" {
+		t.Fatalf("Received %v", received)
+	}
+	if s := spoolContents(t, spool); len(s) != 0 {
+		t.Fatalf("Spool %v", s)
+	}
+
+	// 5xx and 401: kept in the spool and retried after the backoff, in order.
+	for _, c := range []int{503, 401} {
+		code = c
+		d.Deliver([]byte("record 2\n"))
+		if s := spoolContents(t, spool); !slices.Equal(s, []string{".json"}) {
+			t.Fatalf("Spool after %d: %v", c, s)
+		}
+		if d.backoff != time.Hour || !d.nextAttempt.After(time.Now()) {
+			t.Fatalf("Backoff after %d: %v %v", c, d.backoff, d.nextAttempt)
+		}
+		// Within the backoff period nothing is attempted.
+		code = 202
+		d.Deliver([]byte("record 3\n"))
+		if len(received) != 1 {
+			t.Fatalf("Received during backoff %v", received)
+		}
+		if s := spoolContents(t, spool); !slices.Equal(s, []string{".json", ".json"}) {
+			t.Fatalf("Spool during backoff: %v", s)
+		}
+		d.nextAttempt = time.Time{}
+		d.flush()
+		if !slices.Equal(received[1:], []string{"record 2\n", "record 3\n"}) {
+			t.Fatalf("Received after %d %v", c, received)
+		}
+		received = received[:1]
+		if d.backoff != 0 {
+			t.Fatalf("Backoff not reset after %d", c)
+		}
+	}
+
+	// Other 4xx: rejected and set aside.
+	code = 400
+	d.Deliver([]byte("record 4\n"))
+	if s := spoolContents(t, spool); !slices.Equal(s, []string{".rejected"}) {
+		t.Fatalf("Spool after 400: %v", s)
+	}
+	if d.backoff != 0 {
+		t.Fatalf("Backoff after 400")
+	}
+}
+
+func TestDeliverUnspooled(t *testing.T) {
+	code := 503
+	var received []string
+	client := newTestServer(t, &code, &received)
+	d, err := newDeliverer(client, "", 0, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Deliver([]byte("dropped\n"))
+	code = 200
+	d.Deliver([]byte("delivered\n"))
+	d.Deliver([]byte(" \n"))
+	if !slices.Equal(received, []string{"delivered\n"}) {
+		t.Fatalf("Received %v", received)
+	}
+}
+
+func TestTrimSpool(t *testing.T) {
+	code := 503
+	var received []string
+	client := newTestServer(t, &code, &received)
+	spool := t.TempDir()
+	d, err := newDeliverer(client, spool, 25, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []string{"record 1\n", "record 2\n", "record 3\n", "record 4\n"} {
+		d.Deliver([]byte(r))
+		d.nextAttempt = time.Time{}
+		// Make sure the names, which are nanosecond times, are distinct.
+		time.Sleep(time.Millisecond)
+	}
+	entries, err := os.ReadDir(spool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := make([]string, 0)
+	for _, e := range entries {
+		bs, err := os.ReadFile(path.Join(spool, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(bs))
+	}
+	if !slices.Equal(contents, []string{"record 3\n", "record 4\n"}) {
+		t.Fatalf("Spool %v", contents)
+	}
+}
@@ -0,0 +1,89 @@
+// Size-bounded log file with rotation.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+type rotatingLog struct {
+	name    string
+	maxSize int64 // 0 means unbounded
+	keep    int
+	file    *os.File
+	size    int64
+}
+
+func openRotatingLog(name string, maxSize int64, keep int) (*rotatingLog, error) {
+	l := &rotatingLog{name: name, maxSize: maxSize, keep: keep}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *rotatingLog) open() error {
+	file, err := os.OpenFile(l.name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// The data are written as a unit, so a record is never split across files.  The file is rotated
+// after the write that makes it too large.
+
+func (l *rotatingLog) Write(bs []byte) error {
+	n, err := l.file.Write(bs)
+	l.size += int64(n)
+	if err != nil {
+		return err
+	}
+	if l.maxSize > 0 && l.size > l.maxSize {
+		return l.rotate()
+	}
+	return nil
+}
+
+// If the files can't be shifted, logging continues in the current file.
+
+func (l *rotatingLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	err := l.shift()
+	if e := l.open(); e != nil {
+		return errors.Join(err, e)
+	}
+	return err
+}
+
+// The oldest file, if there is one, is overwritten.
+
+func (l *rotatingLog) shift() error {
+	if l.keep == 0 {
+		return os.Remove(l.name)
+	}
+	for i := l.keep - 1; i > 0; i-- {
+		from := fmt.Sprintf("%s.%d", l.name, i)
+		if _, err := os.Stat(from); err == nil {
+			if err := os.Rename(from, fmt.Sprintf("%s.%d", l.name, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Rename(l.name, l.name+".1")
+}
+
+func (l *rotatingLog) Close() error {
+	return l.file.Close()
+}
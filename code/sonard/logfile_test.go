@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func readFile(t *testing.T, name string) string {
+	bs, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(bs)
+}
+
+func TestRotatingLog(t *testing.T) {
+	name := path.Join(t.TempDir(), "log")
+	l, err := openRotatingLog(name, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// The write that makes the file too large goes into it, and then the file is rotated.
+	for _, r := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n", "eeeeee\n", "ffffff\n"} {
+		if err := l.Write([]byte(r)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := readFile(t, name); s != "" {
+		t.Fatalf("Current %q", s)
+	}
+	if s := readFile(t, name+".1"); s != "eeeeee\nffffff\n" {
+		t.Fatalf("First %q", s)
+	}
+	if s := readFile(t, name+".2"); s != "cccccc\ndddddd\n" {
+		t.Fatalf("Second %q", s)
+	}
+	if _, err := os.Stat(name + ".3"); err == nil {
+		t.Fatalf("Kept too many files")
+	}
+}
+
+func TestRotatingLogAppends(t *testing.T) {
+	name := path.Join(t.TempDir(), "log")
+	if err := os.WriteFile(name, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := openRotatingLog(name, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Write([]byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	if s := readFile(t, name); s != "old\nnew\n" {
+		t.Fatalf("Appended %q", s)
+	}
+}
+
+func TestRotatingLogKeepNone(t *testing.T) {
+	name := path.Join(t.TempDir(), "log")
+	l, err := openRotatingLog(name, 4, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := l.Write([]byte("aaaaaa\n")); err != nil {
+		t.Fatal(err)
+	}
+	if s := readFile(t, name); s != "" {
+		t.Fatalf("Current %q", s)
+	}
+	if _, err := os.Stat(name + ".1"); err == nil {
+		t.Fatalf("Kept a file")
+	}
+}
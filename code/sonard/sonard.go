@@ -1,30 +1,48 @@
 // Run sonar repeatedly with sensible options
 //
 // Usage:
-//   sonard [-i interval-in-seconds] [-m min-cpu-time-in-seconds] [-v] -s path-to-sonar logfile
+//   sonard [-i interval-in-seconds] [-m min-cpu-time-in-seconds] [-v] -s path-to-sonar
+//          [-slurm auto|yes|no] [-log-max megabytes] [-log-keep n]
+//          [-url daemon-url -cluster name [-auth-file filename] [-ca-cert filename]
+//           [-spool directory [-spool-max megabytes]]]
+//          [logfile]
 //
-// TODO: Figure out some way of getting --batchless right.  --batchless is right for the ML nodes but
-// would be wrong for systems running Slurm, for example.  It may be that we can look for slurm
-// executables to determine whether to include that option or not, and provide some override for
-// obscure.  But slurm executables may or may not be available on compute nodes on HPC systems.
+// Sonar's output is appended to the logfile, if there is one.  If the logfile grows larger than
+// -log-max then it is rotated: logfile is renamed as logfile.1, logfile.1 as logfile.2, and so on,
+// keeping at most -log-keep old files.
 //
-// TODO: Maybe it should be possible to ask for --rollup, too, or maybe --rollup and !--batchless
-// go hand in hand: On HPC systems the option is "--rollup", on the ML nodes it is "--batchless".
+// With -url, sonar is run with --json and --cluster, so the logfile, if any, receives JSON and not
+// CSV, and each record is also POSTed to the daemon's /api/v1/insert/sample endpoint (sonard only
+// runs `sonar ps`).  With -spool, each record is first written to the spool directory and removed
+// from it when the daemon has accepted it.  If the daemon can't be reached, delivery is retried
+// with exponential backoff, and the spooled records survive a restart of sonard.  If the spool
+// grows larger than -spool-max then the oldest records are dropped.  Without -spool, records that
+// can't be delivered are dropped.
+//
+// Sonar must be run with --batchless on nodes without a batch system and with --rollup on nodes
+// that run Slurm.  With -slurm=auto (the default) the node is taken to run Slurm if slurmd is
+// running or some process is in a Slurm job's cgroup, this is checked once at startup.
 
 package main
 
 import (
+	"bytes"
 	"errors"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
 	"path"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"go-utils/auth"
+	"go-utils/httpclient"
 	"go-utils/process"
+	"go-utils/slurm"
 	"go-utils/status"
 )
 
@@ -32,6 +50,8 @@ const (
 	defMinCpu   = 30
 	defInterval = 60
 	minInterval = 1
+	defLogKeep  = 5
+	defSpoolMax = 100
 )
 
 var (
@@ -39,13 +59,22 @@ var (
 	minCpu    = flag.Uint("m", defMinCpu, "Minimum CPU time consumption in `seconds` for a job before sonar records it")
 	sonarName = flag.String("s", "", "Sonar executable `filename`")
 	verbose   = flag.Bool("v", false, "Print informational messages")
+	slurmMode = flag.String("slurm", "auto", "Whether the node runs Slurm: `auto`, yes, or no")
+	logMax    = flag.Uint("log-max", 0, "Rotate the logfile when it is larger than `megabytes` (0 = never)")
+	logKeep   = flag.Uint("log-keep", defLogKeep, "Keep at most `n` rotated logfiles")
+	targetURL = flag.String("url", "", "POST records to the daemon at `url`, eg https://host:port")
+	cluster   = flag.String("cluster", "", "Cluster `name` for the records (required with -url)")
+	authFile  = flag.String("auth-file", "", "Read upload credentials from `filename` (user:password)")
+	caCert    = flag.String("ca-cert", "", "Certificate `filename` for https (required for https)")
+	spoolDir  = flag.String("spool", "", "Spool undelivered records in `directory`")
+	spoolMax  = flag.Uint("spool-max", defSpoolMax, "Drop the oldest spooled records when the spool is larger than `megabytes` (0 = never)")
 )
 
 func main() {
 	flag.Usage = func() {
-		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] output-logfile\nOptions:\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] [output-logfile]\nOptions:\n", os.Args[0])
 		flag.PrintDefaults()
-		fmt.Fprintf(flag.CommandLine.Output(), "  output-logfile\n    \tDestination for sonar log records\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  output-logfile\n    \tDestination for sonar log records (required without -url)\n")
 	}
 	flag.Parse()
 	if *verbose {
@@ -57,33 +86,42 @@ func main() {
 	if *interval < minInterval {
 		status.Fatalf("Minimum -i value is %d seconds, have %d", minInterval, *interval)
 	}
+	if *slurmMode != "auto" && *slurmMode != "yes" && *slurmMode != "no" {
+		status.Fatalf("-slurm must be auto, yes, or no, have %s", *slurmMode)
+	}
 	rest := flag.Args()
-	if len(rest) != 1 {
-		status.Fatalf("There must be exactly one logfile argument at the end, I see %v", rest)
+	if len(rest) > 1 || len(rest) == 0 && *targetURL == "" {
+		status.Fatalf(
+			"There must be at most one logfile argument, and one is required without -url, I see %v", rest)
 	}
-	logfileName := path.Clean(rest[0])
 
-	logfile, err := os.OpenFile(logfileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		status.Fatalf("Could not open log file %s for appending", logfileName)
+	var logfile *rotatingLog
+	if len(rest) == 1 {
+		var err error
+		logfile, err = openRotatingLog(path.Clean(rest[0]), int64(*logMax)*1024*1024, int(*logKeep))
+		if err != nil {
+			status.Fatalf("Could not open log file %s for appending", rest[0])
+		}
+		defer logfile.Close()
 	}
-	defer logfile.Close()
 
-	arguments := []string{
-		"ps",
-		"--exclude-system-jobs",
-		"--exclude-commands=bash,ssh,zsh,tmux,systemd",
-		"--batchless",
+	var deliverer *deliverer
+	if *targetURL != "" {
+		deliverer = makeDeliverer()
 	}
-	if *minCpu > 0 {
-		arguments = append(arguments, "--min-cpu-time", fmt.Sprint(*minCpu))
+
+	rollup := *slurmMode == "yes" || *slurmMode == "auto" && isSlurmNode()
+	if *verbose {
+		status.Infof("Slurm node: %v", rollup)
 	}
 
 	go func() {
 		for {
+			arguments := sonarArguments(rollup, deliverer != nil)
 			cmd := exec.Command(*sonarName, arguments...)
+			var stdout bytes.Buffer
 			var stderr strings.Builder
-			cmd.Stdout = logfile
+			cmd.Stdout = &stdout
 			cmd.Stderr = &stderr
 			if *verbose {
 				status.Infof("Running %s %v", *sonarName, arguments)
@@ -93,6 +131,14 @@ func main() {
 			if err != nil || len(errout) != 0 {
 				status.Fatalf("Sonar exited with an error\n%v", errors.Join(err, errors.New(errout)))
 			}
+			if logfile != nil {
+				if err := logfile.Write(stdout.Bytes()); err != nil {
+					status.Errorf("Could not write log file: %v", err)
+				}
+			}
+			if deliverer != nil {
+				deliverer.Deliver(stdout.Bytes())
+			}
 			time.Sleep(time.Duration(*interval) * time.Second)
 		}
 	}()
@@ -100,3 +146,88 @@ func main() {
 	// Catch sensible signals and terminate normally.
 	process.WaitForSignal(syscall.SIGHUP, syscall.SIGTERM, syscall.SIGINT)
 }
+
+func makeDeliverer() *deliverer {
+	if *cluster == "" {
+		status.Fatalf("-cluster is required with -url")
+	}
+	target, err := url.Parse(*targetURL)
+	if err != nil {
+		status.Fatalf("Bad -url: %v", err)
+	}
+	if (target.Scheme == "https") != (*caCert != "") {
+		status.Fatalf("-ca-cert is required for https and only for https")
+	}
+	var user, pass string
+	if *authFile != "" {
+		user, pass, err = auth.ParseAuth(*authFile)
+		if err != nil {
+			status.Fatalf("Bad -auth-file: %v", err)
+		}
+	}
+	client, err := httpclient.NewClient(target, *caCert, user, pass, 0, 0, *verbose)
+	if err != nil {
+		status.Fatalf("Could not create HTTP client: %v", err)
+	}
+	d, err := newDeliverer(
+		client,
+		*spoolDir,
+		int64(*spoolMax)*1024*1024,
+		time.Duration(*interval)*time.Second,
+	)
+	if err != nil {
+		status.Fatalf("Could not set up spool: %v", err)
+	}
+	return d
+}
+
+func sonarArguments(rollup, json bool) []string {
+	arguments := []string{
+		"ps",
+		"--exclude-system-jobs",
+		"--exclude-commands=bash,ssh,zsh,tmux,systemd",
+	}
+	if rollup {
+		arguments = append(arguments, "--rollup")
+	} else {
+		arguments = append(arguments, "--batchless")
+	}
+	if *minCpu > 0 {
+		arguments = append(arguments, "--min-cpu-time", fmt.Sprint(*minCpu))
+	}
+	if json {
+		arguments = append(arguments, "--json", "--cluster", *cluster)
+	}
+	return arguments
+}
+
+// The node runs Slurm if slurmd is running or if some process is in a Slurm job.  On a compute
+// node that is not running any jobs slurmd is still running, but slurmd may not be visible to us,
+// and then -slurm=yes must be used.
+
+func isSlurmNode() bool {
+	return hasSlurmProcess("/proc", slurm.SlurmJobIdFromPid)
+}
+
+// `procDir` is the proc file system and `jobIdFromPid` returns the Slurm job of a process, or -1.
+
+func hasSlurmProcess(procDir string, jobIdFromPid func(pid uint) int) bool {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		pid, err := strconv.ParseUint(e.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+		if comm, err := os.ReadFile(path.Join(procDir, e.Name(), "comm")); err == nil &&
+			strings.TrimSpace(string(comm)) == "slurmd" {
+			return true
+		}
+		if jobIdFromPid(uint(pid)) != -1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func makeProc(t *testing.T, comms map[string]string) string {
+	dir := t.TempDir()
+	for pid, comm := range comms {
+		if err := os.Mkdir(path.Join(dir, pid), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path.Join(dir, pid, "comm"), []byte(comm+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestHasSlurmProcess(t *testing.T) {
+	noJobs := func(uint) int { return -1 }
+
+	proc := makeProc(t, map[string]string{"1": "systemd", "17": "slurmd", "self": "bash"})
+	if !hasSlurmProcess(proc, noJobs) {
+		t.Fatalf("slurmd not found")
+	}
+
+	proc = makeProc(t, map[string]string{"1": "systemd", "42": "python"})
+	if hasSlurmProcess(proc, noJobs) {
+		t.Fatalf("Slurm found without slurmd or jobs")
+	}
+	inJob := func(pid uint) int {
+		if pid == 42 {
+			return 1234
+		}
+		return -1
+	}
+	if !hasSlurmProcess(proc, inJob) {
+		t.Fatalf("Job process not found")
+	}
+
+	if hasSlurmProcess(path.Join(proc, "nonexistent"), inJob) {
+		t.Fatalf("Slurm found without proc")
+	}
+}
@@ -1,2 +1,8 @@
 output=$($SLURMINFO -background auxfile.json -input slurminfo-test.txt)
 CHECK slurminfo_smoke "$(cat slurminfo-expect.txt)" "$output"
+
+output=$($SLURMINFO -input sinfo-json-test.txt)
+CHECK slurminfo_sinfo_json "$(cat sinfo-json-expect.txt)" "$output"
+
+output=$($SLURMINFO -input scontrol-json-test.txt)
+CHECK slurminfo_scontrol_json "$(cat scontrol-json-expect.txt)" "$output"
@@ -0,0 +1,27 @@
+# The gap across midnight is downtime and is split between the two days.  The leading gap is
+# missing data, as the following samples show no boot, while there is no boot information after
+# the trailing gap.
+
+output=$($SONALYZE coverage -data-dir data -from 2025-04-13 -to 2025-04-14 -type sample -fmt csv,default)
+CHECK coverage_days \
+      "2025-04-13,c1,sample,121,0d0h10m,2,0d2h0m,0d2h0m,0d0h0m
+2025-04-14,c1,sample,98,0d0h10m,2,0d2h0m,0d2h0m,0d4h0m" \
+      "$output"
+
+output=$($SONALYZE coverage -data-dir data -from 2025-04-13 -to 2025-04-14 -type sample -gaps \
+                   -fmt csv,start,end,duration,cause,boot)
+CHECK coverage_gaps \
+      "2025-04-13 00:00,2025-04-13 02:00,0d2h0m,missing,\"                \"
+2025-04-13 22:00,2025-04-14 02:00,0d4h0m,down,2025-04-14 01:30
+2025-04-14 10:00,2025-04-14 12:00,0d2h0m,missing,\"                \"
+2025-04-14 20:00,2025-04-14 23:59,0d4h0m,unknown,\"                \"" \
+      "$output"
+
+# With a larger gap factor only the long gaps remain.
+
+output=$($SONALYZE coverage -data-dir data -from 2025-04-13 -to 2025-04-14 -type sample -gaps \
+                   -gap-factor 15 -fmt csv,start,cause)
+CHECK coverage_gap_factor \
+      "2025-04-13 22:00,down
+2025-04-14 20:00,unknown" \
+      "$output"
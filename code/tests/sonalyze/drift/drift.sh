@@ -0,0 +1,26 @@
+# Changes between consecutive sysinfo readings, and the newest reading against the config.
+
+output=$($SONALYZE drift -data-dir data -from 2025-04-13 -to 2025-04-14 -fmt csv,default)
+CHECK drift_changes \
+      "2025-04-13T12:00:00Z,d1,memory,,64,60
+2025-04-14T10:00:00Z,d1,card-added,GPU-C,,Test GPU
+2025-04-14T10:00:00Z,d1,card-driver,GPU-A,550.1,560.2
+2025-04-14T10:00:00Z,d1,card-removed,GPU-B,Test GPU," \
+      "$output"
+
+output=$($SONALYZE drift -data-dir data -from 2025-04-13 -to 2025-04-14 -config-file config.json \
+                   -q 'What =~ /^config-/' -fmt csv,default)
+CHECK drift_config \
+      "2025-04-14T10:00:00Z,d1,config-cores,,16,8
+2025-04-14T10:00:00Z,d1,config-mem,,64,60" \
+      "$output"
+
+# With only the first day there is no card change, and the newest reading is the 12:00 one.
+
+output=$($SONALYZE drift -data-dir data -from 2025-04-13 -to 2025-04-13 -config-file config.json \
+                   -fmt csv,default)
+CHECK drift_first_day \
+      "2025-04-13T12:00:00Z,d1,config-cores,,16,8
+2025-04-13T12:00:00Z,d1,config-mem,,64,60
+2025-04-13T12:00:00Z,d1,memory,,64,60" \
+      "$output"
@@ -0,0 +1,14 @@
+# One interval per job and card.  Job 1002 also runs on n2 but uses no GPU there.
+
+output=$($SONALYZE cardusage -data-dir data -from 2025-04-13 -to 2025-04-13 -fmt csv,default)
+CHECK cardusage_all \
+      "n1,0,2025-04-13 10:00,2025-04-13 10:30,1001,alice,80,80
+n1,1,2025-04-13 10:00,2025-04-13 11:00,1002,bob,19,40
+n2,1,2025-04-13 10:20,2025-04-13 10:50,1003,alice,50,50" \
+      "$output"
+
+output=$($SONALYZE cardusage -data-dir data -from 2025-04-13 -to 2025-04-13 -user alice -fmt csv,default)
+CHECK cardusage_user \
+      "n1,0,2025-04-13 10:00,2025-04-13 10:30,1001,alice,80,80
+n2,1,2025-04-13 10:20,2025-04-13 10:50,1003,alice,50,50" \
+      "$output"
@@ -0,0 +1,22 @@
+# Jobs 1001 and 1003 ran for the same length of time, 1003 with half the CPU and GPU and growing
+# memory.
+
+output=$($SONALYZE compare -data-dir data -from 2025-04-13 -to 2025-04-13 -j 1001 -j 1003 -step 10m \
+                   -fmt csv,default)
+CHECK compare_series \
+      "0d0h0m,200,100,4,3.33,80,50,2,3,0,0
+0d0h10m,200,100,4,4,80,50,2,3,26,52
+0d0h20m,200,100,4,4.67,80,50,2,3,26,52
+0d0h30m,200,100,4,5.33,80,50,2,3,26,52" \
+      "$output"
+
+output=$($SONALYZE compare -data-dir data -from 2025-04-13 -to 2025-04-13 -j 1001 -j 1003 -summary \
+                   -fmt csv,default)
+CHECK compare_summary \
+      "elapsed,h,0.5,0.5,0,0.5,0.5,0
+cpu,%,200,100,-50,200,100,-50
+res,GB,4,4.33,8,4,5.33,33
+gpu,%,80,50,-38,80,50,-38
+gpumem,GB,2,3,50,2,3,50
+io,KB/s,21.94,43.89,100,25.6,51.2,100" \
+      "$output"
@@ -0,0 +1,22 @@
+# Requested vs used resources, from the sample data joined with the Slurm data.  Job 1004 has no
+# samples and is not reported.  Job 1002 runs on two nodes, it requests 2 cores in total and 8GB
+# per node.
+
+output=$($SONALYZE efficiency -data-dir data -from 2025-04-13 -to 2025-04-13 \
+                   -fmt csv,job,user,account,elapsed,req-cores,cpu-avg,cpu-peak,req-mem,res-peak,req-gpus,gpu-avg,gpu-peak,cpu-waste,mem-waste,gpu-waste,rec-cores,rec-mem,rec-gpus)
+CHECK efficiency_jobs \
+      "1001,alice,proj1,0d0h30m,4,200,200,16,4,1,80,80,50,75,20,3,5,1
+1002,bob,proj2,0d1h0m,2,54,100,16,2,1,19,40,74,88,82,2,3,1
+1003,alice,proj1,0d0h30m,2,100,100,8,6,1,50,50,50,34,50,2,7,1" \
+      "$output"
+
+output=$($SONALYZE efficiency -data-dir data -from 2025-04-13 -to 2025-04-13 -rollup account \
+                   -fmt csv,account,jobs,core-hours,wasted-core-hours,cpu-waste,gpu-hours,wasted-gpu-hours,gpu-waste)
+CHECK efficiency_rollup_account \
+      "proj1,2,3,2,50,1,1,35
+proj2,1,2,2,74,1,1,82" \
+      "$output"
+
+output=$($SONALYZE efficiency -data-dir data -from 2025-04-13 -to 2025-04-13 -headroom 50 -partition normal \
+                   -fmt csv,job,rec-cores,rec-mem,rec-gpus)
+CHECK efficiency_headroom "1003,2,8,1" "$output"
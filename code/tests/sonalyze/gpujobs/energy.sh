@@ -0,0 +1,23 @@
+# GPU energy per job, user, and node.  The energy of a sample interval is attributed to the jobs
+# using the card at the start of the interval, and the interval runs to the next sample, so a job's
+# end is five minutes after its last sample unless it is running at the end of the data.
+
+output=$($SONALYZE energy -data-dir data -from 2025-04-13 -to 2025-04-13 -fmt csv,default)
+CHECK energy_job \
+      "1001,alice,proj1,n1,2025-04-13 10:00,2025-04-13 10:35,0.58,0.128
+1002,bob,proj2,n1,2025-04-13 10:00,2025-04-13 11:00,1,0.1
+1003,alice,proj1,n2,2025-04-13 10:20,2025-04-13 10:55,0.58,0.093" \
+      "$output"
+
+output=$($SONALYZE energy -data-dir data -from 2025-04-13 -to 2025-04-13 -by user -fmt csv,user,jobs,card-hours,kwh)
+CHECK energy_user \
+      "alice,2,1.17,0.222
+bob,1,1,0.1" \
+      "$output"
+
+output=$($SONALYZE energy -data-dir data -from 2025-04-13 -to 2025-04-13 -by node \
+                   -fmt csv,host,jobs,card-hours,kwh,idle-kwh,total-kwh)
+CHECK energy_node \
+      "n1,2,1.58,0.228,0.025,0.253
+n2,1,0.58,0.093,0.085,0.178" \
+      "$output"
@@ -0,0 +1,14 @@
+# Job 1002 is the only job running at the end of the data, and it has no activity after 10:30.  The
+# I/O counters are updated one sample late so with the default checks the idle window starts at
+# 10:30, while with only the GPU checked it starts at the last sample with GPU activity, 10:25.
+
+output=$($SONALYZE idle -data-dir data -from 2025-04-13 -to 2025-04-13 -min-idle 20m \
+                   -fmt csv,job,user,host,idle-since,idle,cpu-avg,gpu-avg,io-avg,gpus,res,gpumem)
+CHECK idle_default "1002,bob,n[1-2],2025-04-13 10:30,0d0h30m,0,0,0,1,2,1" "$output"
+
+output=$($SONALYZE idle -data-dir data -from 2025-04-13 -to 2025-04-13 -min-idle 20m -check gpu \
+                   -fmt csv,job,idle-since,idle,cpu-avg)
+CHECK idle_gpu_only "1002,2025-04-13 10:25,0d0h35m,15" "$output"
+
+output=$($SONALYZE idle -data-dir data -from 2025-04-13 -to 2025-04-13 -min-idle 40m -fmt csv,job)
+CHECK idle_too_short "" "$output"
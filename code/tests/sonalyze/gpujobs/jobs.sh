@@ -0,0 +1,10 @@
+# Memory growth and projected OOM time.  Job 1003's resident memory grows steadily, so it gets a
+# projected OOM time on n2, while the flat memory use of job 1001 projects no OOM.
+
+output=$($SONALYZE jobs -data-dir data -from 2025-04-13 -to 2025-04-13 -u - -j 1003 \
+                   -fmt csv,job,res-growth,oom-time,gpumem-growth,gpu-oom-time)
+CHECK jobs_growth_oom "1003,4,2025-04-14 01:29,0,\"                \"" "$output"
+
+output=$($SONALYZE jobs -data-dir data -from 2025-04-13 -to 2025-04-13 -u - -j 1001 \
+                   -fmt csv,job,res-growth,oom-time)
+CHECK jobs_growth_flat "1001,0,\"                \"" "$output"
@@ -0,0 +1,18 @@
+# Queue wait times from the Slurm data, all jobs including the failed CPU-only job 1004 and job 1005,
+# which has no requested core count and is in the unknown size class.
+
+output=$($SONALYZE waittime -data-dir data -from 2025-04-13 -to 2025-04-13 -fmt csvnamed,default)
+CHECK waittime_partition \
+      "partition=gpu,gpu-type=,size=,jobs=2,mean=0d0h13m,p50=0d0h5m,p90=0d0h20m,p99=0d0h20m,max=0d0h20m
+partition=normal,gpu-type=,size=,jobs=3,mean=0d0h28m,p50=0d0h30m,p90=0d0h45m,p99=0d0h45m,max=0d0h45m" \
+      "$output"
+
+output=$($SONALYZE waittime -data-dir data -from 2025-04-13 -to 2025-04-13 -by size -fmt csv,default)
+CHECK waittime_size \
+      ",,1,1,0d0h45m,0d0h45m,0d0h45m,0d0h45m,0d0h45m
+,,2-4,3,0d0h12m,0d0h10m,0d0h20m,0d0h20m,0d0h20m
+,,unknown,1,0d0h30m,0d0h30m,0d0h30m,0d0h30m,0d0h30m" \
+      "$output"
+
+output=$($SONALYZE waittime -data-dir data -from 2025-04-13 -to 2025-04-13 -state FAILED -fmt csv,default)
+CHECK waittime_state "normal,,,1,0d0h45m,0d0h45m,0d0h45m,0d0h45m,0d0h45m" "$output"
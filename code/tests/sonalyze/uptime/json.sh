@@ -0,0 +1,51 @@
+# Uptime from JSON data.  See README.md for a description of the data.
+
+# With boot times, the host is down until it boots, the 14:00-15:00 gap is missing data and not
+# downtime, and the cards have their own windows within the host's up windows.
+
+output=$($SONALYZE uptime -data-dir data -from 2025-04-13 -to 2025-04-13 -host u1 \
+                   -fmt csv,device,host,state,start,end,reboots)
+CHECK uptime_json_boot \
+      "host,u1,down,2025-04-13 00:00,2025-04-13 01:00,1
+host,u1,up,2025-04-13 01:00,2025-04-13 08:00,0
+gpu,u1,up,2025-04-13 02:00,2025-04-13 08:00,0
+gpu0,u1,up,2025-04-13 02:00,2025-04-13 08:00,0
+gpu1,u1,up,2025-04-13 02:00,2025-04-13 08:00,0
+host,u1,down,2025-04-13 08:00,2025-04-13 10:30,1
+host,u1,up,2025-04-13 10:30,2025-04-13 22:00,0
+gpu,u1,up,2025-04-13 12:00,2025-04-13 22:00,0
+gpu0,u1,up,2025-04-13 12:00,2025-04-13 22:00,0
+gpu1,u1,up,2025-04-13 12:00,2025-04-13 16:00,0
+gpu1,u1,down,2025-04-13 16:00,2025-04-13 18:00,0
+gpu1,u1,up,2025-04-13 18:00,2025-04-13 22:00,0
+host,u1,down,2025-04-13 22:00,2025-04-13 23:59,0" \
+      "$output"
+
+output=$($SONALYZE uptime -data-dir data -from 2025-04-13 -to 2025-04-13 -host u1 -only-down \
+                   -fmt csv,device,start,end)
+CHECK uptime_json_only_down \
+      "host,2025-04-13 00:00,2025-04-13 01:00
+host,2025-04-13 08:00,2025-04-13 10:30
+gpu1,2025-04-13 16:00,2025-04-13 18:00
+host,2025-04-13 22:00,2025-04-13 23:59" \
+      "$output"
+
+# Without boot times and without -interval the gap threshold is twice the observed sampling
+# interval, so the one-hour gap is downtime.  With -interval 60 it is not.
+
+output=$($SONALYZE uptime -data-dir data -from 2025-04-13 -to 2025-04-13 -host u2 \
+                   -fmt csv,device,state,start,end)
+CHECK uptime_json_observed_interval \
+      "host,up,2025-04-13 00:00,2025-04-13 10:00
+gpu,up,2025-04-13 00:00,2025-04-13 10:00
+host,down,2025-04-13 10:00,2025-04-13 11:00
+host,up,2025-04-13 11:00,2025-04-13 23:50
+gpu,up,2025-04-13 11:00,2025-04-13 23:50" \
+      "$output"
+
+output=$($SONALYZE uptime -data-dir data -from 2025-04-13 -to 2025-04-13 -host u2 -interval 60 \
+                   -fmt csv,device,state,start,end)
+CHECK uptime_json_given_interval \
+      "host,up,2025-04-13 00:00,2025-04-13 23:50
+gpu,up,2025-04-13 00:00,2025-04-13 23:50" \
+      "$output"